@@ -0,0 +1,121 @@
+package main
+
+import (
+	"os"
+
+	"github.com/rs/zerolog/log"
+	"github.com/umschlag/umschlag-api/pkg/config"
+	"github.com/umschlag/umschlag-api/pkg/token"
+	"gopkg.in/urfave/cli.v2"
+)
+
+// Keys provides the sub-command to manage the signing keys.
+func Keys(cfg *config.Config) *cli.Command {
+	return &cli.Command{
+		Name:   "keys",
+		Usage:  "manage signing keys",
+		Flags:  keysFlags(cfg),
+		Before: keysBefore(cfg),
+		Subcommands: []*cli.Command{
+			{
+				Name:   "rotate",
+				Usage:  "activate staged key and stage a new key",
+				Action: keysRotateAction(cfg),
+			},
+			{
+				Name:   "bundle",
+				Usage:  "print certificate bundle for the registry",
+				Action: keysBundleAction(cfg),
+			},
+		},
+	}
+}
+
+func keysFlags(cfg *config.Config) []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:        "keys-path",
+			Value:       "keys/",
+			Usage:       "path to the token signing keys",
+			EnvVars:     []string{"UMSCHLAG_API_KEYS_PATH"},
+			Destination: &cfg.Keys.Path,
+		},
+		&cli.StringFlag{
+			Name:        "keys-algorithm",
+			Value:       "RS256",
+			Usage:       "algorithm for generated signing keys",
+			EnvVars:     []string{"UMSCHLAG_API_KEYS_ALGORITHM"},
+			Destination: &cfg.Keys.Algorithm,
+		},
+		&cli.IntFlag{
+			Name:        "keys-retain",
+			Value:       2,
+			Usage:       "number of retired keys to keep",
+			EnvVars:     []string{"UMSCHLAG_API_KEYS_RETAIN"},
+			Destination: &cfg.Keys.Retain,
+		},
+	}
+}
+
+func keysBefore(cfg *config.Config) cli.BeforeFunc {
+	return func(c *cli.Context) error {
+		setupLogger(cfg)
+		return nil
+	}
+}
+
+func keysRotateAction(cfg *config.Config) cli.ActionFunc {
+	return func(c *cli.Context) error {
+		dir := &token.KeyDir{
+			Path:      cfg.Keys.Path,
+			Algorithm: cfg.Keys.Algorithm,
+			Retain:    cfg.Keys.Retain,
+		}
+
+		if err := dir.Rotate(); err != nil {
+			log.Error().
+				Err(err).
+				Msg("failed to rotate signing keys")
+
+			return err
+		}
+
+		keys, err := dir.Load()
+
+		if err != nil {
+			log.Error().
+				Err(err).
+				Msg("failed to load signing keys")
+
+			return err
+		}
+
+		log.Info().
+			Str("kid", keys.Active().ID).
+			Int("keys", len(keys.Keys())).
+			Msg("rotated signing keys, restart the server to apply")
+
+		return nil
+	}
+}
+
+func keysBundleAction(cfg *config.Config) cli.ActionFunc {
+	return func(c *cli.Context) error {
+		dir := &token.KeyDir{
+			Path: cfg.Keys.Path,
+		}
+
+		bundle, err := dir.Bundle()
+
+		if err != nil {
+			log.Error().
+				Err(err).
+				Msg("failed to bundle certificates")
+
+			return err
+		}
+
+		_, err = os.Stdout.Write(bundle)
+		return err
+	}
+}
@@ -81,5 +81,6 @@ func globalCommands(cfg *config.Config) []*cli.Command {
 	return []*cli.Command{
 		Server(cfg),
 		Health(cfg),
+		Keys(cfg),
 	}
 }
@@ -10,7 +10,11 @@ import (
 	"github.com/oklog/oklog/pkg/group"
 	"github.com/rs/zerolog/log"
 	"github.com/umschlag/umschlag-api/pkg/config"
+	"github.com/umschlag/umschlag-api/pkg/password"
+	"github.com/umschlag/umschlag-api/pkg/registry/catalog"
+	"github.com/umschlag/umschlag-api/pkg/registry/retention"
 	"github.com/umschlag/umschlag-api/pkg/router"
+	"github.com/umschlag/umschlag-api/pkg/store"
 	"gopkg.in/urfave/cli.v2"
 )
 
@@ -109,7 +113,7 @@ func serverFlags(cfg *config.Config) []cli.Flag {
 			Value:       "admin",
 			Usage:       "initial admin password",
 			EnvVars:     []string{"UMSCHLAG_API_ADMIN_PASSWORD"},
-			Destination: &cfg.Admin.Username,
+			Destination: &cfg.Admin.Password,
 		},
 		&cli.StringFlag{
 			Name:        "admin-email",
@@ -118,6 +122,104 @@ func serverFlags(cfg *config.Config) []cli.Flag {
 			EnvVars:     []string{"UMSCHLAG_API_ADMIN_EMAIL"},
 			Destination: &cfg.Admin.Email,
 		},
+		&cli.BoolFlag{
+			Name:        "admin-allow-default",
+			Value:       false,
+			Usage:       "allow the default admin password",
+			EnvVars:     []string{"UMSCHLAG_API_ADMIN_ALLOW_DEFAULT"},
+			Destination: &cfg.Admin.AllowDefault,
+		},
+		&cli.IntFlag{
+			Name:        "password-cost",
+			Value:       password.DefaultCost,
+			Usage:       "bcrypt cost for password hashes",
+			EnvVars:     []string{"UMSCHLAG_API_PASSWORD_COST"},
+			Destination: &cfg.Password.Cost,
+		},
+		&cli.DurationFlag{
+			Name:        "session-expire",
+			Value:       24 * time.Hour,
+			Usage:       "expiration of the session tokens",
+			EnvVars:     []string{"UMSCHLAG_API_SESSION_EXPIRE"},
+			Destination: &cfg.Session.Expire,
+		},
+		&cli.DurationFlag{
+			Name:        "session-prune",
+			Value:       time.Hour,
+			Usage:       "interval to prune revocations of expired tokens",
+			EnvVars:     []string{"UMSCHLAG_API_SESSION_PRUNE"},
+			Destination: &cfg.Session.Prune,
+		},
+		&cli.StringFlag{
+			Name:        "registry-service",
+			Value:       "registry",
+			Usage:       "service name of the docker registry",
+			EnvVars:     []string{"UMSCHLAG_API_REGISTRY_SERVICE"},
+			Destination: &cfg.Registry.Service,
+		},
+		&cli.StringFlag{
+			Name:        "registry-issuer",
+			Value:       "umschlag",
+			Usage:       "issuer of the registry tokens",
+			EnvVars:     []string{"UMSCHLAG_API_REGISTRY_ISSUER"},
+			Destination: &cfg.Registry.Issuer,
+		},
+		&cli.DurationFlag{
+			Name:        "registry-expire",
+			Value:       5 * time.Minute,
+			Usage:       "expiration of the registry tokens",
+			EnvVars:     []string{"UMSCHLAG_API_REGISTRY_EXPIRE"},
+			Destination: &cfg.Registry.Expire,
+		},
+		&cli.StringFlag{
+			Name:        "registry-url",
+			Value:       "",
+			Usage:       "url of the docker registry api to sync",
+			EnvVars:     []string{"UMSCHLAG_API_REGISTRY_URL"},
+			Destination: &cfg.Registry.URL,
+		},
+		&cli.DurationFlag{
+			Name:        "registry-sync",
+			Value:       15 * time.Minute,
+			Usage:       "interval to sync the registry catalog",
+			EnvVars:     []string{"UMSCHLAG_API_REGISTRY_SYNC"},
+			Destination: &cfg.Registry.Sync,
+		},
+		&cli.DurationFlag{
+			Name:        "registry-retention",
+			Value:       24 * time.Hour,
+			Usage:       "interval to apply the retention policies",
+			EnvVars:     []string{"UMSCHLAG_API_REGISTRY_RETENTION"},
+			Destination: &cfg.Registry.Retention,
+		},
+		&cli.StringFlag{
+			Name:        "registry-events-token",
+			Value:       "",
+			Usage:       "token to authenticate registry notifications",
+			EnvVars:     []string{"UMSCHLAG_API_REGISTRY_EVENTS_TOKEN"},
+			Destination: &cfg.Registry.EventsToken,
+		},
+		&cli.StringFlag{
+			Name:        "keys-path",
+			Value:       "keys/",
+			Usage:       "path to the token signing keys",
+			EnvVars:     []string{"UMSCHLAG_API_KEYS_PATH"},
+			Destination: &cfg.Keys.Path,
+		},
+		&cli.StringFlag{
+			Name:        "keys-algorithm",
+			Value:       "RS256",
+			Usage:       "algorithm for generated signing keys",
+			EnvVars:     []string{"UMSCHLAG_API_KEYS_ALGORITHM"},
+			Destination: &cfg.Keys.Algorithm,
+		},
+		&cli.IntFlag{
+			Name:        "keys-retain",
+			Value:       2,
+			Usage:       "number of retired keys to keep",
+			EnvVars:     []string{"UMSCHLAG_API_KEYS_RETAIN"},
+			Destination: &cfg.Keys.Retain,
+		},
 		&cli.BoolFlag{
 			Name:        "tracing-enabled",
 			Value:       false,
@@ -168,6 +270,20 @@ func serverAction(cfg *config.Config) cli.ActionFunc {
 			defer storage.Close()
 		}
 
+		if err := setupAdmin(cfg, storage); err != nil {
+			log.Fatal().
+				Err(err).
+				Msg("failed to bootstrap admin")
+		}
+
+		keys, err := setupKeys(cfg)
+
+		if err != nil {
+			log.Fatal().
+				Err(err).
+				Msg("failed to setup signing keys")
+		}
+
 		uploads, err := setupUploads(cfg)
 
 		if err != nil {
@@ -188,7 +304,7 @@ func serverAction(cfg *config.Config) cli.ActionFunc {
 		{
 			server := &http.Server{
 				Addr:         cfg.Server.Addr,
-				Handler:      router.Server(cfg, storage, uploads),
+				Handler:      router.Server(cfg, storage, uploads, keys),
 				ReadTimeout:  5 * time.Second,
 				WriteTimeout: 10 * time.Second,
 			}
@@ -249,6 +365,81 @@ func serverAction(cfg *config.Config) cli.ActionFunc {
 			})
 		}
 
+		if cfg.Session.Prune > 0 {
+			ticker := time.NewTicker(cfg.Session.Prune)
+			stop := make(chan struct{})
+
+			gr.Add(func() error {
+				for {
+					select {
+					case <-ticker.C:
+						pruneRevocations(storage)
+					case <-stop:
+						return nil
+					}
+				}
+			}, func(reason error) {
+				ticker.Stop()
+				close(stop)
+			})
+		}
+
+		if cfg.Registry.URL != "" && cfg.Registry.Sync > 0 {
+			syncer, err := setupCatalog(cfg, storage, keys)
+
+			if err != nil {
+				log.Fatal().
+					Err(err).
+					Msg("failed to setup registry sync")
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			ticker := time.NewTicker(cfg.Registry.Sync)
+
+			gr.Add(func() error {
+				for {
+					syncCatalog(ctx, syncer)
+
+					select {
+					case <-ticker.C:
+					case <-ctx.Done():
+						return nil
+					}
+				}
+			}, func(reason error) {
+				ticker.Stop()
+				cancel()
+			})
+		}
+
+		if cfg.Registry.URL != "" && cfg.Registry.Retention > 0 {
+			runner, err := setupRetention(cfg, storage, keys)
+
+			if err != nil {
+				log.Fatal().
+					Err(err).
+					Msg("failed to setup retention policies")
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			ticker := time.NewTicker(cfg.Registry.Retention)
+
+			gr.Add(func() error {
+				for {
+					applyRetention(ctx, runner)
+
+					select {
+					case <-ticker.C:
+					case <-ctx.Done():
+						return nil
+					}
+				}
+			}, func(reason error) {
+				ticker.Stop()
+				cancel()
+			})
+		}
+
 		{
 			stop := make(chan os.Signal, 1)
 
@@ -266,3 +457,61 @@ func serverAction(cfg *config.Config) cli.ActionFunc {
 		return gr.Run()
 	}
 }
+
+// syncCatalog mirrors the registry catalog into the store, failures only
+// get logged as the next run will try again.
+func syncCatalog(ctx context.Context, syncer *catalog.Syncer) {
+	started := time.Now()
+
+	if err := syncer.Run(ctx); err != nil {
+		if ctx.Err() == nil {
+			log.Error().
+				Err(err).
+				Msg("failed to sync registry catalog")
+		}
+
+		return
+	}
+
+	log.Info().
+		Dur("duration", time.Since(started)).
+		Msg("synced registry catalog")
+}
+
+// applyRetention applies the retention policies, failures only get logged
+// as the next run will try again.
+func applyRetention(ctx context.Context, runner *retention.Runner) {
+	started := time.Now()
+
+	if err := runner.Run(ctx); err != nil {
+		if ctx.Err() == nil {
+			log.Error().
+				Err(err).
+				Msg("failed to apply retention policies")
+		}
+
+		return
+	}
+
+	log.Info().
+		Dur("duration", time.Since(started)).
+		Msg("applied retention policies")
+}
+
+// pruneRevocations drops the revocations of expired tokens, failures only
+// get logged as the next run will try again.
+func pruneRevocations(storage store.Store) {
+	count, err := storage.Revocations().Prune(context.Background(), time.Now().UTC())
+
+	if err != nil {
+		log.Error().
+			Err(err).
+			Msg("failed to prune token revocations")
+
+		return
+	}
+
+	log.Debug().
+		Int("count", count).
+		Msg("pruned token revocations")
+}
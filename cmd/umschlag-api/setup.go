@@ -1,16 +1,25 @@
 package main
 
 import (
+	"context"
 	"io"
 	"net/url"
 	"os"
 	"strings"
 
 	"github.com/umschlag/umschlag-api/pkg/config"
+	"github.com/umschlag/umschlag-api/pkg/model"
+	"github.com/umschlag/umschlag-api/pkg/password"
+	"github.com/umschlag/umschlag-api/pkg/registry/auth"
+	"github.com/umschlag/umschlag-api/pkg/registry/catalog"
+	"github.com/umschlag/umschlag-api/pkg/registry/cleanup"
+	"github.com/umschlag/umschlag-api/pkg/registry/retention"
 	"github.com/umschlag/umschlag-api/pkg/store"
 	"github.com/umschlag/umschlag-api/pkg/store/boltdb"
+	"github.com/umschlag/umschlag-api/pkg/store/memory"
 	"github.com/umschlag/umschlag-api/pkg/store/mysql"
 	"github.com/umschlag/umschlag-api/pkg/store/postgres"
+	"github.com/umschlag/umschlag-api/pkg/token"
 	"github.com/umschlag/umschlag-api/pkg/upload"
 	"github.com/umschlag/umschlag-api/pkg/upload/file"
 	"github.com/umschlag/umschlag-api/pkg/upload/s3"
@@ -114,7 +123,168 @@ func setupStorage(cfg *config.Config) (store.Store, error) {
 		return mysql.New(parsed)
 	case "mariadb":
 		return mysql.New(parsed)
+	case "memory":
+		return memory.New(parsed)
 	}
 
 	return nil, store.ErrUnknownDriver
 }
+
+func setupAdmin(cfg *config.Config, storage store.Store) error {
+	if !cfg.Admin.Create {
+		log.Info().
+			Msg("admin bootstrap is disabled")
+
+		return nil
+	}
+
+	ctx := context.Background()
+	insecure := cfg.Admin.Password == "admin" && !cfg.Admin.AllowDefault
+
+	user, err := storage.Users().ByUsername(ctx, cfg.Admin.Username)
+
+	if err == store.ErrNotFound {
+		if insecure {
+			log.Error().
+				Str("username", cfg.Admin.Username).
+				Msg("refusing to create admin with default password, define a password or allow the default")
+
+			return nil
+		}
+
+		hash, err := password.Hash(cfg.Admin.Password, cfg.Password.Cost)
+
+		if err != nil {
+			return err
+		}
+
+		user, err = storage.Users().Create(ctx, &model.User{
+			Username: cfg.Admin.Username,
+			Password: hash,
+			Email:    cfg.Admin.Email,
+			Admin:    true,
+			Active:   true,
+		})
+
+		if err != nil {
+			return errors.Wrap(err, "failed to create admin")
+		}
+
+		log.Info().
+			Str("username", user.Username).
+			Str("id", user.ID).
+			Msg("created admin user")
+
+		return nil
+	}
+
+	if err != nil {
+		return errors.Wrap(err, "failed to fetch admin")
+	}
+
+	if insecure && password.Compare(user.Password, cfg.Admin.Password) == nil {
+		log.Warn().
+			Str("username", user.Username).
+			Msg("admin still uses the default password, please change it")
+	}
+
+	repaired := make([]string, 0)
+
+	if !user.Admin {
+		user.Admin = true
+		repaired = append(repaired, "admin")
+	}
+
+	if !user.Active {
+		user.Active = true
+		repaired = append(repaired, "active")
+	}
+
+	if user.Email == "" && cfg.Admin.Email != "" {
+		user.Email = cfg.Admin.Email
+		repaired = append(repaired, "email")
+	}
+
+	if user.Password == "" && !insecure {
+		hash, err := password.Hash(cfg.Admin.Password, cfg.Password.Cost)
+
+		if err != nil {
+			return err
+		}
+
+		user.Password = hash
+		repaired = append(repaired, "password")
+	}
+
+	if len(repaired) == 0 {
+		log.Info().
+			Str("username", user.Username).
+			Msg("admin user already present")
+
+		return nil
+	}
+
+	if _, err := storage.Users().Update(ctx, user); err != nil {
+		return errors.Wrap(err, "failed to repair admin")
+	}
+
+	log.Info().
+		Str("username", user.Username).
+		Strs("fields", repaired).
+		Msg("repaired admin user")
+
+	return nil
+}
+
+func setupKeys(cfg *config.Config) (*token.KeySet, error) {
+	dir := &token.KeyDir{
+		Path:      cfg.Keys.Path,
+		Algorithm: cfg.Keys.Algorithm,
+		Retain:    cfg.Keys.Retain,
+	}
+
+	keys, err := dir.Load()
+
+	if err == token.ErrMissingKey {
+		log.Info().
+			Str("path", cfg.Keys.Path).
+			Msg("generating initial signing keys")
+
+		if err := dir.Rotate(); err != nil {
+			return nil, err
+		}
+
+		keys, err = dir.Load()
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	log.Info().
+		Str("kid", keys.Active().ID).
+		Int("keys", len(keys.Keys())).
+		Msg("loaded signing keys")
+
+	return keys, nil
+}
+
+func setupCatalog(cfg *config.Config, storage store.Store, keys *token.KeySet) (*catalog.Syncer, error) {
+	registry, err := auth.Client(cfg, keys)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return catalog.New(storage, registry), nil
+}
+
+func setupRetention(cfg *config.Config, storage store.Store, keys *token.KeySet) (*retention.Runner, error) {
+	registry, err := auth.Client(cfg, keys)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return retention.New(storage, cleanup.New(storage, registry)), nil
+}
@@ -6,11 +6,20 @@ import (
 	"github.com/go-openapi/loads"
 	"github.com/go-openapi/runtime/middleware"
 	"github.com/rs/zerolog/log"
+	"github.com/umschlag/umschlag-api/pkg/api/v1/models"
 	"github.com/umschlag/umschlag-api/pkg/api/v1/restapi"
 	"github.com/umschlag/umschlag-api/pkg/api/v1/restapi/operations"
+	"github.com/umschlag/umschlag-api/pkg/authn"
+	"github.com/umschlag/umschlag-api/pkg/config"
+	"github.com/umschlag/umschlag-api/pkg/registry/auth"
+	"github.com/umschlag/umschlag-api/pkg/registry/cleanup"
+	"github.com/umschlag/umschlag-api/pkg/registry/client"
+	"github.com/umschlag/umschlag-api/pkg/store"
+	"github.com/umschlag/umschlag-api/pkg/token"
+	"github.com/umschlag/umschlag-api/pkg/upload"
 )
 
-//go:generate gorunpkg github.com/go-swagger/go-swagger/cmd/swagger generate server --target . --name Umschlag --spec ../../../openapi/v1.yml --exclude-main --regenerate-configureapi
+//go:generate gorunpkg github.com/go-swagger/go-swagger/cmd/swagger generate server --target . --name Umschlag --spec ../../../openapi/v1.yml --exclude-main --regenerate-configureapi --principal model.User
 
 // API provides the http.Handler for the OpenAPI implementation.
 type API struct {
@@ -18,7 +27,7 @@ type API struct {
 }
 
 // New creates a new API that adds the custom Handler implementations.
-func New() *API {
+func New(cfg *config.Config, storage store.Store, uploads upload.Upload, keys *token.KeySet) *API {
 	spec, err := loads.Analyzed(restapi.SwaggerJSON, "")
 
 	if err != nil {
@@ -31,6 +40,91 @@ func New() *API {
 
 	api := operations.NewUmschlagAPI(spec)
 
+	authenticator := authn.New(storage, keys, cfg.Password.Cost)
+
+	api.BasicAuthAuth = BasicAuth(authenticator)
+	api.BearerAuthAuth = BearerAuth(authenticator)
+	api.HeaderAuthAuth = HeaderAuth(authenticator)
+	api.APIAuthorizer = ScopeAuth()
+
+	cleaner := cleanup.New(storage, registry(cfg, keys))
+
+	api.AuthLoginUserHandler = LoginUserHandler(cfg, authenticator, keys)
+	api.AuthRefreshAuthHandler = RefreshAuthHandler(cfg, authenticator, keys)
+	api.AuthVerifyAuthHandler = VerifyAuthHandler(authenticator)
+	api.AuthLogoutAuthHandler = LogoutAuthHandler(authenticator)
+
+	api.ProfileShowProfileHandler = ShowProfileHandler(cfg)
+	api.ProfileUpdateProfileHandler = UpdateProfileHandler(cfg, storage)
+	api.ProfileLogoutProfileHandler = LogoutProfileHandler(authenticator)
+	api.ProfileTokenProfileHandler = TokenProfileHandler(keys)
+	api.ProfileListProfileTokensHandler = ListProfileTokensHandler(storage)
+	api.ProfileCreateProfileTokenHandler = CreateProfileTokenHandler(storage)
+	api.ProfileDeleteProfileTokenHandler = DeleteProfileTokenHandler(storage)
+	api.ProfileUpdateProfileAvatarHandler = UpdateProfileAvatarHandler(cfg, storage, uploads)
+	api.ProfileDeleteProfileAvatarHandler = DeleteProfileAvatarHandler(cfg, storage)
+
+	api.NamespaceListNamespacesHandler = ListNamespacesHandler(storage)
+	api.NamespaceShowNamespaceHandler = ShowNamespaceHandler(storage)
+	api.NamespaceCreateNamespaceHandler = CreateNamespaceHandler(storage)
+	api.NamespaceUpdateNamespaceHandler = UpdateNamespaceHandler(storage)
+	api.NamespaceDeleteNamespaceHandler = DeleteNamespaceHandler(storage)
+	api.NamespaceShowNamespaceRetentionHandler = ShowNamespaceRetentionHandler(storage)
+	api.NamespaceUpdateNamespaceRetentionHandler = UpdateNamespaceRetentionHandler(storage)
+	api.NamespaceDeleteNamespaceRetentionHandler = DeleteNamespaceRetentionHandler(storage)
+	api.NamespaceListNamespaceRetentionReportsHandler = ListNamespaceRetentionReportsHandler(storage)
+	api.NamespaceListNamespaceImmutablesHandler = ListNamespaceImmutablesHandler(storage)
+	api.NamespaceCreateNamespaceImmutableHandler = CreateNamespaceImmutableHandler(storage)
+	api.NamespaceDeleteNamespaceImmutableHandler = DeleteNamespaceImmutableHandler(storage)
+	api.NamespaceShowNamespaceUsageHandler = ShowNamespaceUsageHandler(storage)
+	api.NamespaceUpdateNamespaceQuotaHandler = UpdateNamespaceQuotaHandler(storage)
+	api.NamespaceDeleteNamespaceQuotaHandler = DeleteNamespaceQuotaHandler(storage)
+
+	api.RepositoryListRepositoriesHandler = ListRepositoriesHandler(storage)
+	api.RepositoryShowRepositoryHandler = ShowRepositoryHandler(storage)
+	api.RepositoryCreateRepositoryHandler = CreateRepositoryHandler(storage)
+	api.RepositoryUpdateRepositoryHandler = UpdateRepositoryHandler(storage)
+	api.RepositoryDeleteRepositoryHandler = DeleteRepositoryHandler(storage)
+	api.RepositoryListRepositoryTeamsHandler = ListRepositoryTeamsHandler(cfg, storage)
+	api.RepositoryAppendRepositoryTeamHandler = AppendRepositoryTeamHandler(storage)
+	api.RepositoryPermitRepositoryTeamHandler = PermitRepositoryTeamHandler(storage)
+	api.RepositoryDeleteRepositoryTeamHandler = DeleteRepositoryTeamHandler(storage)
+	api.RepositoryListRepositoryTagsHandler = ListRepositoryTagsHandler(storage)
+	api.RepositoryDeleteRepositoryTagHandler = DeleteRepositoryTagHandler(storage, cleaner)
+	api.RepositoryDeleteRepositoryManifestHandler = DeleteRepositoryManifestHandler(storage, cleaner)
+	api.RepositoryShowRepositoryRetentionHandler = ShowRepositoryRetentionHandler(storage)
+	api.RepositoryUpdateRepositoryRetentionHandler = UpdateRepositoryRetentionHandler(storage)
+	api.RepositoryDeleteRepositoryRetentionHandler = DeleteRepositoryRetentionHandler(storage)
+	api.RepositoryListRepositoryRetentionReportsHandler = ListRepositoryRetentionReportsHandler(storage)
+
+	api.TeamListTeamsHandler = ListTeamsHandler(cfg, storage)
+	api.TeamShowTeamHandler = ShowTeamHandler(cfg, storage)
+	api.TeamCreateTeamHandler = CreateTeamHandler(cfg, storage)
+	api.TeamUpdateTeamHandler = UpdateTeamHandler(cfg, storage)
+	api.TeamDeleteTeamHandler = DeleteTeamHandler(storage)
+	api.TeamListTeamUsersHandler = ListTeamUsersHandler(storage)
+	api.TeamAppendTeamToUserHandler = AppendTeamToUserHandler(storage)
+	api.TeamPermitTeamUserHandler = PermitTeamUserHandler(storage)
+	api.TeamDeleteTeamFromUserHandler = DeleteTeamFromUserHandler(storage)
+	api.TeamShowTeamUsageHandler = ShowTeamUsageHandler(storage)
+	api.TeamUpdateTeamQuotaHandler = UpdateTeamQuotaHandler(storage)
+	api.TeamDeleteTeamQuotaHandler = DeleteTeamQuotaHandler(storage)
+	api.TeamUpdateTeamAvatarHandler = UpdateTeamAvatarHandler(cfg, storage, uploads)
+	api.TeamDeleteTeamAvatarHandler = DeleteTeamAvatarHandler(cfg, storage)
+
+	api.UserListUsersHandler = ListUsersHandler(cfg, storage)
+	api.UserShowUserHandler = ShowUserHandler(cfg, storage)
+	api.UserCreateUserHandler = CreateUserHandler(cfg, storage)
+	api.UserUpdateUserHandler = UpdateUserHandler(cfg, storage)
+	api.UserDeleteUserHandler = DeleteUserHandler(storage)
+	api.UserUpdateUserAvatarHandler = UpdateUserAvatarHandler(cfg, storage, uploads)
+	api.UserDeleteUserAvatarHandler = DeleteUserAvatarHandler(cfg, storage)
+	api.UserLogoutUserHandler = LogoutUserHandler(storage, authenticator)
+	api.UserListUserTeamsHandler = ListUserTeamsHandler(storage)
+	api.UserAppendUserToTeamHandler = AppendUserToTeamHandler(storage)
+	api.UserPermitUserTeamHandler = PermitUserTeamHandler(storage)
+	api.UserDeleteUserFromTeamHandler = DeleteUserFromTeamHandler(storage)
+
 	api.Middleware = func(b middleware.Builder) http.Handler {
 		return middleware.Spec("", nil, api.Context().RoutesHandler(b))
 	}
@@ -39,3 +133,50 @@ func New() *API {
 		Handler: api.Serve(nil),
 	}
 }
+
+// generalError builds the payload for the general error responses.
+func generalError(status int, message string) *models.GeneralError {
+	code := int64(status)
+
+	return &models.GeneralError{
+		Status:  &code,
+		Message: &message,
+	}
+}
+
+// validationError builds the payload for a validation error of a field.
+func validationError(field, message string) *models.ValidationError {
+	code := int64(http.StatusUnprocessableEntity)
+	summary := "failed to validate request"
+
+	return &models.ValidationError{
+		Status:  &code,
+		Message: &summary,
+		Errors: []*models.ValidationErrorErrorsItems0{
+			{
+				Field:   field,
+				Message: message,
+			},
+		},
+	}
+}
+
+// registry initializes the client for the registry API, it returns nil if
+// the registry url is not configured.
+func registry(cfg *config.Config, keys *token.KeySet) *client.Client {
+	if cfg.Registry.URL == "" {
+		return nil
+	}
+
+	result, err := auth.Client(cfg, keys)
+
+	if err != nil {
+		log.Warn().
+			Err(err).
+			Msg("failed to initialize registry client")
+
+		return nil
+	}
+
+	return result
+}
@@ -0,0 +1,209 @@
+package v1
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/go-openapi/runtime/middleware"
+	"github.com/go-openapi/strfmt"
+	"github.com/rs/zerolog/log"
+	"github.com/umschlag/umschlag-api/pkg/api/v1/models"
+	"github.com/umschlag/umschlag-api/pkg/api/v1/restapi/operations/auth"
+	"github.com/umschlag/umschlag-api/pkg/authn"
+	"github.com/umschlag/umschlag-api/pkg/config"
+	"github.com/umschlag/umschlag-api/pkg/model"
+	"github.com/umschlag/umschlag-api/pkg/token"
+)
+
+// LoginUserHandler implements the handler for the AuthLoginUser operation.
+func LoginUserHandler(cfg *config.Config, authenticator *authn.Authenticator, keys *token.KeySet) auth.LoginUserHandlerFunc {
+	return func(params auth.LoginUserParams) middleware.Responder {
+		user, err := authenticator.Password(
+			params.HTTPRequest.Context(),
+			*params.AuthLogin.Username,
+			params.AuthLogin.Password.String(),
+		)
+
+		if err == authn.ErrInvalidCredentials {
+			return auth.NewLoginUserUnauthorized().WithPayload(
+				generalError(http.StatusUnauthorized, "wrong username or password"),
+			)
+		}
+
+		if err != nil {
+			log.Error().
+				Err(err).
+				Str("username", *params.AuthLogin.Username).
+				Msg("failed to authenticate user")
+
+			return auth.NewLoginUserDefault(http.StatusInternalServerError).WithPayload(
+				generalError(http.StatusInternalServerError, "failed to authenticate user"),
+			)
+		}
+
+		// A session would lift the scopes of the access token.
+		if user.Token != nil {
+			return auth.NewLoginUserUnauthorized().WithPayload(
+				generalError(http.StatusUnauthorized, "access tokens can't be used to login"),
+			)
+		}
+
+		result, err := sessionToken(cfg, keys, user)
+
+		if err != nil {
+			log.Error().
+				Err(err).
+				Str("username", user.Username).
+				Msg("failed to generate session token")
+
+			return auth.NewLoginUserDefault(http.StatusInternalServerError).WithPayload(
+				generalError(http.StatusInternalServerError, "failed to generate token"),
+			)
+		}
+
+		return auth.NewLoginUserOK().WithPayload(result)
+	}
+}
+
+// RefreshAuthHandler implements the handler for the AuthRefreshAuth operation.
+func RefreshAuthHandler(cfg *config.Config, authenticator *authn.Authenticator, keys *token.KeySet) auth.RefreshAuthHandlerFunc {
+	return func(params auth.RefreshAuthParams) middleware.Responder {
+		raw, err := token.Extract(params.HTTPRequest)
+
+		if err != nil {
+			return auth.NewRefreshAuthUnauthorized().WithPayload(
+				generalError(http.StatusUnauthorized, "invalid or expired token"),
+			)
+		}
+
+		user, err := authenticator.Token(params.HTTPRequest.Context(), raw, token.SessToken)
+
+		if err != nil {
+			return auth.NewRefreshAuthUnauthorized().WithPayload(
+				generalError(http.StatusUnauthorized, "invalid or expired token"),
+			)
+		}
+
+		result, err := sessionToken(cfg, keys, user)
+
+		if err != nil {
+			log.Error().
+				Err(err).
+				Str("username", user.Username).
+				Msg("failed to refresh session token")
+
+			return auth.NewRefreshAuthDefault(http.StatusInternalServerError).WithPayload(
+				generalError(http.StatusInternalServerError, "failed to generate token"),
+			)
+		}
+
+		return auth.NewRefreshAuthOK().WithPayload(result)
+	}
+}
+
+// LogoutAuthHandler implements the handler for the AuthLogoutAuth operation.
+func LogoutAuthHandler(authenticator *authn.Authenticator) auth.LogoutAuthHandlerFunc {
+	return func(params auth.LogoutAuthParams) middleware.Responder {
+		raw, err := token.Extract(params.HTTPRequest)
+
+		if err != nil {
+			return auth.NewLogoutAuthUnauthorized().WithPayload(
+				generalError(http.StatusUnauthorized, "invalid or expired token"),
+			)
+		}
+
+		user, err := authenticator.Revoke(params.HTTPRequest.Context(), raw, token.SessToken)
+
+		if err == authn.ErrInvalidCredentials {
+			return auth.NewLogoutAuthUnauthorized().WithPayload(
+				generalError(http.StatusUnauthorized, "invalid or expired token"),
+			)
+		}
+
+		if err != nil {
+			log.Error().
+				Err(err).
+				Msg("failed to revoke session token")
+
+			return auth.NewLogoutAuthDefault(http.StatusInternalServerError).WithPayload(
+				generalError(http.StatusInternalServerError, "failed to revoke token"),
+			)
+		}
+
+		log.Debug().
+			Str("username", user.Username).
+			Msg("revoked session token")
+
+		return auth.NewLogoutAuthOK().WithPayload(
+			generalError(http.StatusOK, "successfully logged out"),
+		)
+	}
+}
+
+// VerifyAuthHandler implements the handler for the AuthVerifyAuth operation.
+func VerifyAuthHandler(authenticator *authn.Authenticator) auth.VerifyAuthHandlerFunc {
+	return func(params auth.VerifyAuthParams) middleware.Responder {
+		user, err := authenticator.Token(
+			params.HTTPRequest.Context(),
+			params.Token,
+			token.SessToken,
+			token.UserToken,
+		)
+
+		if err != nil {
+			return auth.NewVerifyAuthUnauthorized().WithPayload(
+				generalError(http.StatusUnauthorized, "invalid or expired token"),
+			)
+		}
+
+		createdAt := strfmt.DateTime(user.CreatedAt)
+
+		return auth.NewVerifyAuthOK().WithPayload(&models.AuthVerify{
+			Username:  &user.Username,
+			CreatedAt: &createdAt,
+		})
+	}
+}
+
+// sessionToken signs an expiring session token for the user.
+func sessionToken(cfg *config.Config, keys *token.KeySet, user *model.User) (*models.AuthToken, error) {
+	result, err := token.New(token.SessToken, user.ID).SignExpiring(
+		keys.Active(),
+		cfg.Session.Expire,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	expire, err := time.Parse(time.RFC3339, result.Expire)
+
+	if err != nil {
+		return nil, err
+	}
+
+	expiresAt := strfmt.DateTime(expire)
+
+	return &models.AuthToken{
+		Token:     &result.Token,
+		ExpiresAt: &expiresAt,
+	}, nil
+}
+
+// logoutBefore resolves the point in time to revoke the tokens before, it
+// defaults to now and must not be in the future.
+func logoutBefore(params *models.LogoutParams) (time.Time, bool) {
+	now := time.Now().UTC()
+
+	if params == nil || params.Before == nil {
+		return now, true
+	}
+
+	before := time.Time(*params.Before).UTC()
+
+	if before.After(now) {
+		return time.Time{}, false
+	}
+
+	return before, true
+}
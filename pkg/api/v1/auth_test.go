@@ -0,0 +1,119 @@
+package v1
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/umschlag/umschlag-api/pkg/api/v1/models"
+	"github.com/umschlag/umschlag-api/pkg/api/v1/restapi/operations/auth"
+	"github.com/umschlag/umschlag-api/pkg/authn"
+	"github.com/umschlag/umschlag-api/pkg/config"
+	"github.com/umschlag/umschlag-api/pkg/model"
+	"github.com/umschlag/umschlag-api/pkg/password"
+	"github.com/umschlag/umschlag-api/pkg/store/memory"
+	"github.com/umschlag/umschlag-api/pkg/token"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestLoginUser(t *testing.T) {
+	ctx := context.Background()
+	dsn, _ := url.Parse("memory://")
+	storage := memory.Must(dsn)
+
+	hash, err := password.Hash("secret", bcrypt.MinCost)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user, err := storage.Users().Create(ctx, &model.User{
+		Username: "jane",
+		Email:    "jane@example.com",
+		Password: hash,
+		Active:   true,
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	secret, tokenHash, err := authn.GenerateToken()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := storage.Tokens().Create(ctx, &model.AccessToken{
+		UserID: user.ID,
+		Name:   "ci",
+		Hash:   tokenHash,
+		Scopes: []string{model.ScopeRegistryPull},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	key, err := token.NewSecretKey("ORSXG5DJNZTQ====")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keys := token.NewKeySet(key)
+	cfg := &config.Config{Session: config.Session{Expire: time.Hour}}
+	handler := LoginUserHandler(cfg, authn.New(storage, keys, bcrypt.MinCost), keys)
+
+	tests := []struct {
+		name     string
+		password string
+		ok       bool
+	}{
+		{
+			name:     "password",
+			password: "secret",
+			ok:       true,
+		},
+		{
+			name:     "wrong password",
+			password: "wrong",
+			ok:       false,
+		},
+		{
+			name:     "access token",
+			password: secret,
+			ok:       false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			username := "jane"
+			secret := strfmt.Password(tt.password)
+
+			responder := handler(auth.LoginUserParams{
+				HTTPRequest: httptest.NewRequest(http.MethodPost, "/api/v1/auth/login", nil),
+				AuthLogin: &models.AuthLogin{
+					Username: &username,
+					Password: &secret,
+				},
+			})
+
+			switch responder.(type) {
+			case *auth.LoginUserOK:
+				if !tt.ok {
+					t.Errorf("expected login to be rejected")
+				}
+			case *auth.LoginUserUnauthorized:
+				if tt.ok {
+					t.Errorf("expected login to succeed")
+				}
+			default:
+				t.Errorf("unexpected response %T", responder)
+			}
+		})
+	}
+}
@@ -0,0 +1,317 @@
+package v1
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/go-openapi/runtime/middleware"
+	"github.com/rs/zerolog/log"
+	"github.com/umschlag/umschlag-api/pkg/api/v1/restapi/operations/profile"
+	"github.com/umschlag/umschlag-api/pkg/api/v1/restapi/operations/team"
+	"github.com/umschlag/umschlag-api/pkg/api/v1/restapi/operations/user"
+	"github.com/umschlag/umschlag-api/pkg/avatar"
+	"github.com/umschlag/umschlag-api/pkg/config"
+	"github.com/umschlag/umschlag-api/pkg/model"
+	"github.com/umschlag/umschlag-api/pkg/policy"
+	"github.com/umschlag/umschlag-api/pkg/store"
+	"github.com/umschlag/umschlag-api/pkg/upload"
+)
+
+// UpdateProfileAvatarHandler implements the handler for the ProfileUpdateProfileAvatar operation.
+func UpdateProfileAvatarHandler(cfg *config.Config, storage store.Store, uploads upload.Upload) profile.UpdateProfileAvatarHandlerFunc {
+	return func(params profile.UpdateProfileAvatarParams, principal *model.User) middleware.Responder {
+		ctx := params.HTTPRequest.Context()
+		defer params.Avatar.Close()
+
+		record := *principal
+		hash, err := storeAvatar(ctx, uploads, params.Avatar)
+
+		if invalidAvatar(err) {
+			return profile.NewUpdateProfileAvatarUnprocessableEntity().WithPayload(
+				validationError("avatar", err.Error()),
+			)
+		}
+
+		var updated *model.User
+
+		if err == nil {
+			record.Avatar = hash
+			updated, err = storage.Users().Update(ctx, &record)
+		}
+
+		if err == nil {
+			return profile.NewUpdateProfileAvatarOK().WithPayload(convertProfile(cfg, updated))
+		}
+
+		log.Error().
+			Err(err).
+			Str("username", principal.Username).
+			Msg("failed to update avatar")
+
+		return profile.NewUpdateProfileAvatarDefault(http.StatusInternalServerError).WithPayload(
+			generalError(http.StatusInternalServerError, "failed to update avatar"),
+		)
+	}
+}
+
+// DeleteProfileAvatarHandler implements the handler for the ProfileDeleteProfileAvatar operation.
+func DeleteProfileAvatarHandler(cfg *config.Config, storage store.Store) profile.DeleteProfileAvatarHandlerFunc {
+	return func(params profile.DeleteProfileAvatarParams, principal *model.User) middleware.Responder {
+		record := *principal
+		record.Avatar = ""
+
+		updated, err := storage.Users().Update(params.HTTPRequest.Context(), &record)
+
+		if err == nil {
+			return profile.NewDeleteProfileAvatarOK().WithPayload(convertProfile(cfg, updated))
+		}
+
+		log.Error().
+			Err(err).
+			Str("username", principal.Username).
+			Msg("failed to remove avatar")
+
+		return profile.NewDeleteProfileAvatarDefault(http.StatusInternalServerError).WithPayload(
+			generalError(http.StatusInternalServerError, "failed to remove avatar"),
+		)
+	}
+}
+
+// UpdateUserAvatarHandler implements the handler for the UserUpdateUserAvatar operation.
+func UpdateUserAvatarHandler(cfg *config.Config, storage store.Store, uploads upload.Upload) user.UpdateUserAvatarHandlerFunc {
+	return func(params user.UpdateUserAvatarParams, principal *model.User) middleware.Responder {
+		ctx := params.HTTPRequest.Context()
+		defer params.Avatar.Close()
+
+		record, err := storage.Users().Show(ctx, params.UserID)
+
+		if err == nil {
+			err = authorize(ctx, storage, principal, policy.UserUpdate, &policy.Target{
+				UserID: record.ID,
+			})
+		}
+
+		if err == nil {
+			record.Avatar, err = storeAvatar(ctx, uploads, params.Avatar)
+		}
+
+		if invalidAvatar(err) {
+			return user.NewUpdateUserAvatarUnprocessableEntity().WithPayload(
+				validationError("avatar", err.Error()),
+			)
+		}
+
+		if err == nil {
+			record, err = storage.Users().Update(ctx, record)
+		}
+
+		switch {
+		case err == nil:
+			return user.NewUpdateUserAvatarOK().WithPayload(convertUser(cfg, record))
+		case forbidden(err):
+			return user.NewUpdateUserAvatarForbidden().WithPayload(
+				generalError(http.StatusForbidden, err.Error()),
+			)
+		case err == store.ErrNotFound:
+			return user.NewUpdateUserAvatarDefault(http.StatusNotFound).WithPayload(
+				generalError(http.StatusNotFound, "user not found"),
+			)
+		}
+
+		log.Error().
+			Err(err).
+			Str("user", params.UserID).
+			Msg("failed to update avatar")
+
+		return user.NewUpdateUserAvatarDefault(http.StatusInternalServerError).WithPayload(
+			generalError(http.StatusInternalServerError, "failed to update avatar"),
+		)
+	}
+}
+
+// DeleteUserAvatarHandler implements the handler for the UserDeleteUserAvatar operation.
+func DeleteUserAvatarHandler(cfg *config.Config, storage store.Store) user.DeleteUserAvatarHandlerFunc {
+	return func(params user.DeleteUserAvatarParams, principal *model.User) middleware.Responder {
+		ctx := params.HTTPRequest.Context()
+
+		record, err := storage.Users().Show(ctx, params.UserID)
+
+		if err == nil {
+			err = authorize(ctx, storage, principal, policy.UserUpdate, &policy.Target{
+				UserID: record.ID,
+			})
+		}
+
+		if err == nil {
+			record.Avatar = ""
+			record, err = storage.Users().Update(ctx, record)
+		}
+
+		switch {
+		case err == nil:
+			return user.NewDeleteUserAvatarOK().WithPayload(convertUser(cfg, record))
+		case forbidden(err):
+			return user.NewDeleteUserAvatarForbidden().WithPayload(
+				generalError(http.StatusForbidden, err.Error()),
+			)
+		case err == store.ErrNotFound:
+			return user.NewDeleteUserAvatarDefault(http.StatusNotFound).WithPayload(
+				generalError(http.StatusNotFound, "user not found"),
+			)
+		}
+
+		log.Error().
+			Err(err).
+			Str("user", params.UserID).
+			Msg("failed to remove avatar")
+
+		return user.NewDeleteUserAvatarDefault(http.StatusInternalServerError).WithPayload(
+			generalError(http.StatusInternalServerError, "failed to remove avatar"),
+		)
+	}
+}
+
+// UpdateTeamAvatarHandler implements the handler for the TeamUpdateTeamAvatar operation.
+func UpdateTeamAvatarHandler(cfg *config.Config, storage store.Store, uploads upload.Upload) team.UpdateTeamAvatarHandlerFunc {
+	return func(params team.UpdateTeamAvatarParams, principal *model.User) middleware.Responder {
+		ctx := params.HTTPRequest.Context()
+		defer params.Avatar.Close()
+
+		record, err := storage.Teams().Show(ctx, params.TeamID)
+
+		if err == nil {
+			err = authorize(ctx, storage, principal, policy.TeamUpdate, &policy.Target{
+				TeamID: record.ID,
+			})
+		}
+
+		if err == nil {
+			record.Avatar, err = storeAvatar(ctx, uploads, params.Avatar)
+		}
+
+		if invalidAvatar(err) {
+			return team.NewUpdateTeamAvatarUnprocessableEntity().WithPayload(
+				validationError("avatar", err.Error()),
+			)
+		}
+
+		if err == nil {
+			record, err = storage.Teams().Update(ctx, record)
+		}
+
+		switch {
+		case err == nil:
+			return team.NewUpdateTeamAvatarOK().WithPayload(convertTeam(cfg, record))
+		case forbidden(err):
+			return team.NewUpdateTeamAvatarForbidden().WithPayload(
+				generalError(http.StatusForbidden, err.Error()),
+			)
+		case err == store.ErrNotFound:
+			return team.NewUpdateTeamAvatarDefault(http.StatusNotFound).WithPayload(
+				generalError(http.StatusNotFound, "team not found"),
+			)
+		}
+
+		log.Error().
+			Err(err).
+			Str("team", params.TeamID).
+			Msg("failed to update avatar")
+
+		return team.NewUpdateTeamAvatarDefault(http.StatusInternalServerError).WithPayload(
+			generalError(http.StatusInternalServerError, "failed to update avatar"),
+		)
+	}
+}
+
+// DeleteTeamAvatarHandler implements the handler for the TeamDeleteTeamAvatar operation.
+func DeleteTeamAvatarHandler(cfg *config.Config, storage store.Store) team.DeleteTeamAvatarHandlerFunc {
+	return func(params team.DeleteTeamAvatarParams, principal *model.User) middleware.Responder {
+		ctx := params.HTTPRequest.Context()
+
+		record, err := storage.Teams().Show(ctx, params.TeamID)
+
+		if err == nil {
+			err = authorize(ctx, storage, principal, policy.TeamUpdate, &policy.Target{
+				TeamID: record.ID,
+			})
+		}
+
+		if err == nil {
+			record.Avatar = ""
+			record, err = storage.Teams().Update(ctx, record)
+		}
+
+		switch {
+		case err == nil:
+			return team.NewDeleteTeamAvatarOK().WithPayload(convertTeam(cfg, record))
+		case forbidden(err):
+			return team.NewDeleteTeamAvatarForbidden().WithPayload(
+				generalError(http.StatusForbidden, err.Error()),
+			)
+		case err == store.ErrNotFound:
+			return team.NewDeleteTeamAvatarDefault(http.StatusNotFound).WithPayload(
+				generalError(http.StatusNotFound, "team not found"),
+			)
+		}
+
+		log.Error().
+			Err(err).
+			Str("team", params.TeamID).
+			Msg("failed to remove avatar")
+
+		return team.NewDeleteTeamAvatarDefault(http.StatusInternalServerError).WithPayload(
+			generalError(http.StatusInternalServerError, "failed to remove avatar"),
+		)
+	}
+}
+
+// storeAvatar processes the uploaded image and stores the thumbnails which
+// are not already present, it returns the content hash of the upload.
+func storeAvatar(ctx context.Context, uploads upload.Upload, content io.Reader) (string, error) {
+	result, err := avatar.Process(content)
+
+	if err != nil {
+		return "", err
+	}
+
+	for _, size := range avatar.Sizes {
+		key := avatar.Key(result.Hash, size)
+
+		if _, err := uploads.Stat(ctx, key); err == nil {
+			continue
+		} else if err != upload.ErrNotFound {
+			return "", err
+		}
+
+		if err := uploads.Put(ctx, key, bytes.NewReader(result.Images[size]), "image/png"); err != nil {
+			return "", err
+		}
+	}
+
+	return result.Hash, nil
+}
+
+// invalidAvatar checks if the error got caused by an invalid image.
+func invalidAvatar(err error) bool {
+	return err == avatar.ErrTooLarge ||
+		err == avatar.ErrUnsupported ||
+		err == avatar.ErrDimensions
+}
+
+// avatarURL builds the public url of the default thumbnail.
+func avatarURL(cfg *config.Config, hash string) string {
+	if hash == "" {
+		return ""
+	}
+
+	return strings.TrimSuffix(cfg.Server.Host, "/") + path.Join(
+		cfg.Server.Root,
+		"api",
+		"storage",
+		avatar.Key(hash, avatar.Default),
+	)
+}
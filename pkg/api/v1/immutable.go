@@ -0,0 +1,175 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/go-openapi/runtime/middleware"
+	"github.com/go-openapi/strfmt"
+	"github.com/rs/zerolog/log"
+	"github.com/umschlag/umschlag-api/pkg/api/v1/models"
+	"github.com/umschlag/umschlag-api/pkg/api/v1/restapi/operations/namespace"
+	"github.com/umschlag/umschlag-api/pkg/model"
+	"github.com/umschlag/umschlag-api/pkg/policy"
+	"github.com/umschlag/umschlag-api/pkg/store"
+)
+
+// ListNamespaceImmutablesHandler implements the handler for the NamespaceListNamespaceImmutables operation.
+func ListNamespaceImmutablesHandler(storage store.Store) namespace.ListNamespaceImmutablesHandlerFunc {
+	return func(params namespace.ListNamespaceImmutablesParams, principal *model.User) middleware.Responder {
+		ctx := params.HTTPRequest.Context()
+		ns, err := storage.Namespaces().Show(ctx, params.NamespaceID)
+
+		if err == nil {
+			err = authorize(ctx, storage, principal, policy.ImmutableShow, &policy.Target{
+				UserID: ns.UserID,
+				TeamID: ns.TeamID,
+			})
+		}
+
+		var records []*model.Immutable
+
+		if err == nil {
+			records, err = storage.Immutables().List(ctx, ns.ID)
+		}
+
+		switch {
+		case err == nil:
+			payload := make([]*models.Immutable, 0, len(records))
+
+			for _, immutable := range records {
+				payload = append(payload, convertImmutable(immutable))
+			}
+
+			return namespace.NewListNamespaceImmutablesOK().WithPayload(payload)
+		case forbidden(err):
+			return namespace.NewListNamespaceImmutablesForbidden().WithPayload(
+				generalError(http.StatusForbidden, err.Error()),
+			)
+		case err == store.ErrNotFound:
+			return namespace.NewListNamespaceImmutablesDefault(http.StatusNotFound).WithPayload(
+				generalError(http.StatusNotFound, "namespace not found"),
+			)
+		}
+
+		log.Error().
+			Err(err).
+			Str("namespace", params.NamespaceID).
+			Msg("failed to fetch immutable tag rules")
+
+		return namespace.NewListNamespaceImmutablesDefault(http.StatusInternalServerError).WithPayload(
+			generalError(http.StatusInternalServerError, "failed to fetch immutable tag rules"),
+		)
+	}
+}
+
+// CreateNamespaceImmutableHandler implements the handler for the NamespaceCreateNamespaceImmutable operation.
+func CreateNamespaceImmutableHandler(storage store.Store) namespace.CreateNamespaceImmutableHandlerFunc {
+	return func(params namespace.CreateNamespaceImmutableParams, principal *model.User) middleware.Responder {
+		ctx := params.HTTPRequest.Context()
+		ns, err := storage.Namespaces().Show(ctx, params.NamespaceID)
+
+		if err == nil {
+			err = authorize(ctx, storage, principal, policy.ImmutableUpdate, &policy.Target{
+				UserID: ns.UserID,
+				TeamID: ns.TeamID,
+			})
+		}
+
+		record := &model.Immutable{
+			Pattern: *params.Immutable.Pattern,
+			Exclude: params.Immutable.Exclude,
+		}
+
+		if err == nil && !record.Valid() {
+			return namespace.NewCreateNamespaceImmutableUnprocessableEntity().WithPayload(
+				validationError("pattern", "is not a valid glob pattern"),
+			)
+		}
+
+		if err == nil {
+			record.NamespaceID = ns.ID
+			record, err = storage.Immutables().Create(ctx, record)
+		}
+
+		switch {
+		case err == nil:
+			return namespace.NewCreateNamespaceImmutableOK().WithPayload(convertImmutable(record))
+		case forbidden(err):
+			return namespace.NewCreateNamespaceImmutableForbidden().WithPayload(
+				generalError(http.StatusForbidden, err.Error()),
+			)
+		case err == store.ErrNotFound:
+			return namespace.NewCreateNamespaceImmutableDefault(http.StatusNotFound).WithPayload(
+				generalError(http.StatusNotFound, "namespace not found"),
+			)
+		case err == store.ErrDuplicatePattern:
+			return namespace.NewCreateNamespaceImmutableUnprocessableEntity().WithPayload(
+				validationError("pattern", "is already defined"),
+			)
+		}
+
+		log.Error().
+			Err(err).
+			Str("namespace", params.NamespaceID).
+			Msg("failed to create immutable tag rule")
+
+		return namespace.NewCreateNamespaceImmutableDefault(http.StatusInternalServerError).WithPayload(
+			generalError(http.StatusInternalServerError, "failed to create immutable tag rule"),
+		)
+	}
+}
+
+// DeleteNamespaceImmutableHandler implements the handler for the NamespaceDeleteNamespaceImmutable operation.
+func DeleteNamespaceImmutableHandler(storage store.Store) namespace.DeleteNamespaceImmutableHandlerFunc {
+	return func(params namespace.DeleteNamespaceImmutableParams, principal *model.User) middleware.Responder {
+		ctx := params.HTTPRequest.Context()
+		ns, err := storage.Namespaces().Show(ctx, params.NamespaceID)
+
+		if err == nil {
+			err = authorize(ctx, storage, principal, policy.ImmutableUpdate, &policy.Target{
+				UserID: ns.UserID,
+				TeamID: ns.TeamID,
+			})
+		}
+
+		if err == nil {
+			err = storage.Immutables().Delete(ctx, ns.ID, params.ImmutableID)
+		}
+
+		switch {
+		case err == nil:
+			return namespace.NewDeleteNamespaceImmutableOK().WithPayload(
+				generalError(http.StatusOK, "successfully deleted immutable tag rule"),
+			)
+		case forbidden(err):
+			return namespace.NewDeleteNamespaceImmutableForbidden().WithPayload(
+				generalError(http.StatusForbidden, err.Error()),
+			)
+		case err == store.ErrNotFound:
+			return namespace.NewDeleteNamespaceImmutableDefault(http.StatusNotFound).WithPayload(
+				generalError(http.StatusNotFound, "namespace or immutable tag rule not found"),
+			)
+		}
+
+		log.Error().
+			Err(err).
+			Str("namespace", params.NamespaceID).
+			Str("immutable", params.ImmutableID).
+			Msg("failed to delete immutable tag rule")
+
+		return namespace.NewDeleteNamespaceImmutableDefault(http.StatusInternalServerError).WithPayload(
+			generalError(http.StatusInternalServerError, "failed to delete immutable tag rule"),
+		)
+	}
+}
+
+// convertImmutable converts an immutable tag rule for responses.
+func convertImmutable(record *model.Immutable) *models.Immutable {
+	return &models.Immutable{
+		ID:          strfmt.UUID(record.ID),
+		NamespaceID: strfmt.UUID(record.NamespaceID),
+		Pattern:     &record.Pattern,
+		Exclude:     record.Exclude,
+		CreatedAt:   strfmt.DateTime(record.CreatedAt),
+	}
+}
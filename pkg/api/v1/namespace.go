@@ -0,0 +1,314 @@
+package v1
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/go-openapi/runtime/middleware"
+	"github.com/go-openapi/strfmt"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+	"github.com/umschlag/umschlag-api/pkg/api/v1/models"
+	"github.com/umschlag/umschlag-api/pkg/api/v1/restapi/operations/namespace"
+	"github.com/umschlag/umschlag-api/pkg/model"
+	"github.com/umschlag/umschlag-api/pkg/policy"
+	"github.com/umschlag/umschlag-api/pkg/store"
+)
+
+var (
+	// errOwnerConflict is returned if a namespace should be owned by a user
+	// and a team at the same time.
+	errOwnerConflict = errors.New("can't be combined with user_id")
+
+	// errOwnerMissing is returned if the owner of a namespace doesn't exist.
+	errOwnerMissing = errors.New("owner does not exist")
+)
+
+// ListNamespacesHandler implements the handler for the NamespaceListNamespaces operation.
+func ListNamespacesHandler(storage store.Store) namespace.ListNamespacesHandlerFunc {
+	return func(params namespace.ListNamespacesParams, principal *model.User) middleware.Responder {
+		ctx := params.HTTPRequest.Context()
+		err := authorize(ctx, storage, principal, policy.NamespaceList, nil)
+
+		if forbidden(err) {
+			return namespace.NewListNamespacesForbidden().WithPayload(
+				generalError(http.StatusForbidden, err.Error()),
+			)
+		}
+
+		var records []*model.Namespace
+
+		if err == nil {
+			records, err = storage.Namespaces().List(ctx)
+		}
+
+		if err != nil {
+			log.Error().
+				Err(err).
+				Msg("failed to fetch namespaces")
+
+			return namespace.NewListNamespacesDefault(http.StatusInternalServerError).WithPayload(
+				generalError(http.StatusInternalServerError, "failed to fetch namespaces"),
+			)
+		}
+
+		payload := make([]*models.Namespace, 0, len(records))
+
+		for _, record := range records {
+			payload = append(payload, convertNamespace(record))
+		}
+
+		return namespace.NewListNamespacesOK().WithPayload(payload)
+	}
+}
+
+// ShowNamespaceHandler implements the handler for the NamespaceShowNamespace operation.
+func ShowNamespaceHandler(storage store.Store) namespace.ShowNamespaceHandlerFunc {
+	return func(params namespace.ShowNamespaceParams, principal *model.User) middleware.Responder {
+		ctx := params.HTTPRequest.Context()
+		record, err := storage.Namespaces().Show(ctx, params.NamespaceID)
+
+		if err == nil {
+			err = authorize(ctx, storage, principal, policy.NamespaceShow, nil)
+		}
+
+		switch {
+		case err == nil:
+			return namespace.NewShowNamespaceOK().WithPayload(convertNamespace(record))
+		case forbidden(err):
+			return namespace.NewShowNamespaceForbidden().WithPayload(
+				generalError(http.StatusForbidden, err.Error()),
+			)
+		case err == store.ErrNotFound:
+			return namespace.NewShowNamespaceDefault(http.StatusNotFound).WithPayload(
+				generalError(http.StatusNotFound, "namespace not found"),
+			)
+		}
+
+		log.Error().
+			Err(err).
+			Str("namespace", params.NamespaceID).
+			Msg("failed to fetch namespace")
+
+		return namespace.NewShowNamespaceDefault(http.StatusInternalServerError).WithPayload(
+			generalError(http.StatusInternalServerError, "failed to fetch namespace"),
+		)
+	}
+}
+
+// CreateNamespaceHandler implements the handler for the NamespaceCreateNamespace operation.
+func CreateNamespaceHandler(storage store.Store) namespace.CreateNamespaceHandlerFunc {
+	return func(params namespace.CreateNamespaceParams, principal *model.User) middleware.Responder {
+		ctx := params.HTTPRequest.Context()
+
+		record := &model.Namespace{
+			Name:        *params.Namespace.Name,
+			Description: params.Namespace.Description,
+			UserID:      principal.ID,
+		}
+
+		err := namespaceOwner(ctx, storage, params.Namespace, record)
+
+		if err == nil {
+			err = authorize(ctx, storage, principal, policy.NamespaceCreate, &policy.Target{
+				UserID: record.UserID,
+				TeamID: record.TeamID,
+			})
+		}
+
+		if err == nil {
+			record, err = storage.Namespaces().Create(ctx, record)
+		}
+
+		switch {
+		case err == nil:
+			return namespace.NewCreateNamespaceOK().WithPayload(convertNamespace(record))
+		case forbidden(err):
+			return namespace.NewCreateNamespaceForbidden().WithPayload(
+				generalError(http.StatusForbidden, err.Error()),
+			)
+		case err == errOwnerConflict:
+			return namespace.NewCreateNamespaceUnprocessableEntity().WithPayload(
+				validationError("team_id", err.Error()),
+			)
+		case err == errOwnerMissing:
+			return namespace.NewCreateNamespaceUnprocessableEntity().WithPayload(
+				validationError("owner", err.Error()),
+			)
+		case err == store.ErrDuplicateName:
+			return namespace.NewCreateNamespaceUnprocessableEntity().WithPayload(
+				validationError("name", "is already taken"),
+			)
+		}
+
+		log.Error().
+			Err(err).
+			Msg("failed to create namespace")
+
+		return namespace.NewCreateNamespaceDefault(http.StatusInternalServerError).WithPayload(
+			generalError(http.StatusInternalServerError, "failed to create namespace"),
+		)
+	}
+}
+
+// UpdateNamespaceHandler implements the handler for the NamespaceUpdateNamespace operation.
+func UpdateNamespaceHandler(storage store.Store) namespace.UpdateNamespaceHandlerFunc {
+	return func(params namespace.UpdateNamespaceParams, principal *model.User) middleware.Responder {
+		ctx := params.HTTPRequest.Context()
+		record, err := storage.Namespaces().Show(ctx, params.NamespaceID)
+
+		if err == nil {
+			err = authorize(ctx, storage, principal, policy.NamespaceUpdate, &policy.Target{
+				UserID: record.UserID,
+				TeamID: record.TeamID,
+			})
+		}
+
+		if err == nil {
+			userID, teamID := record.UserID, record.TeamID
+
+			record.Name = *params.Namespace.Name
+			record.Description = params.Namespace.Description
+
+			err = namespaceOwner(ctx, storage, params.Namespace, record)
+
+			if err == nil && (record.UserID != userID || record.TeamID != teamID) {
+				err = authorize(ctx, storage, principal, policy.NamespaceCreate, &policy.Target{
+					UserID: record.UserID,
+					TeamID: record.TeamID,
+				})
+			}
+		}
+
+		if err == nil {
+			record, err = storage.Namespaces().Update(ctx, record)
+		}
+
+		switch {
+		case err == nil:
+			return namespace.NewUpdateNamespaceOK().WithPayload(convertNamespace(record))
+		case forbidden(err):
+			return namespace.NewUpdateNamespaceForbidden().WithPayload(
+				generalError(http.StatusForbidden, err.Error()),
+			)
+		case err == store.ErrNotFound:
+			return namespace.NewUpdateNamespaceDefault(http.StatusNotFound).WithPayload(
+				generalError(http.StatusNotFound, "namespace not found"),
+			)
+		case err == errOwnerConflict:
+			return namespace.NewUpdateNamespaceUnprocessableEntity().WithPayload(
+				validationError("team_id", err.Error()),
+			)
+		case err == errOwnerMissing:
+			return namespace.NewUpdateNamespaceUnprocessableEntity().WithPayload(
+				validationError("owner", err.Error()),
+			)
+		case err == store.ErrDuplicateName:
+			return namespace.NewUpdateNamespaceUnprocessableEntity().WithPayload(
+				validationError("name", "is already taken"),
+			)
+		}
+
+		log.Error().
+			Err(err).
+			Str("namespace", params.NamespaceID).
+			Msg("failed to update namespace")
+
+		return namespace.NewUpdateNamespaceDefault(http.StatusInternalServerError).WithPayload(
+			generalError(http.StatusInternalServerError, "failed to update namespace"),
+		)
+	}
+}
+
+// DeleteNamespaceHandler implements the handler for the NamespaceDeleteNamespace operation.
+func DeleteNamespaceHandler(storage store.Store) namespace.DeleteNamespaceHandlerFunc {
+	return func(params namespace.DeleteNamespaceParams, principal *model.User) middleware.Responder {
+		ctx := params.HTTPRequest.Context()
+		record, err := storage.Namespaces().Show(ctx, params.NamespaceID)
+
+		if err == nil {
+			err = authorize(ctx, storage, principal, policy.NamespaceDelete, &policy.Target{
+				UserID: record.UserID,
+				TeamID: record.TeamID,
+			})
+		}
+
+		if err == nil {
+			err = storage.Namespaces().Delete(ctx, record.ID)
+		}
+
+		switch {
+		case err == nil:
+			return namespace.NewDeleteNamespaceOK().WithPayload(
+				generalError(http.StatusOK, "successfully deleted namespace"),
+			)
+		case forbidden(err):
+			return namespace.NewDeleteNamespaceForbidden().WithPayload(
+				generalError(http.StatusForbidden, err.Error()),
+			)
+		case err == store.ErrNotFound:
+			return namespace.NewDeleteNamespaceDefault(http.StatusNotFound).WithPayload(
+				generalError(http.StatusNotFound, "namespace not found"),
+			)
+		}
+
+		log.Error().
+			Err(err).
+			Str("namespace", params.NamespaceID).
+			Msg("failed to delete namespace")
+
+		return namespace.NewDeleteNamespaceDefault(http.StatusInternalServerError).WithPayload(
+			generalError(http.StatusInternalServerError, "failed to delete namespace"),
+		)
+	}
+}
+
+// namespaceOwner resolves the user or team of the payload by ID or slug and
+// assigns it as owner of the record, otherwise the owner stays untouched.
+func namespaceOwner(ctx context.Context, storage store.Store, payload *models.Namespace, record *model.Namespace) error {
+	switch {
+	case payload.UserID != "" && payload.TeamID != "":
+		return errOwnerConflict
+	case payload.UserID != "":
+		user, err := storage.Users().Show(ctx, payload.UserID)
+
+		if err == store.ErrNotFound {
+			return errOwnerMissing
+		}
+
+		if err != nil {
+			return err
+		}
+
+		record.UserID = user.ID
+		record.TeamID = ""
+	case payload.TeamID != "":
+		team, err := storage.Teams().Show(ctx, payload.TeamID)
+
+		if err == store.ErrNotFound {
+			return errOwnerMissing
+		}
+
+		if err != nil {
+			return err
+		}
+
+		record.UserID = ""
+		record.TeamID = team.ID
+	}
+
+	return nil
+}
+
+// convertNamespace converts a namespace for responses.
+func convertNamespace(record *model.Namespace) *models.Namespace {
+	return &models.Namespace{
+		ID:          strfmt.UUID(record.ID),
+		Name:        &record.Name,
+		Description: record.Description,
+		UserID:      record.UserID,
+		TeamID:      record.TeamID,
+		CreatedAt:   strfmt.DateTime(record.CreatedAt),
+		UpdatedAt:   strfmt.DateTime(record.UpdatedAt),
+	}
+}
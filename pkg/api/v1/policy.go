@@ -0,0 +1,146 @@
+package v1
+
+import (
+	"context"
+
+	"github.com/umschlag/umschlag-api/pkg/model"
+	"github.com/umschlag/umschlag-api/pkg/policy"
+	"github.com/umschlag/umschlag-api/pkg/store"
+)
+
+// subject loads the memberships of the principal for policy decisions.
+func subject(ctx context.Context, storage store.Store, principal *model.User) (*policy.Subject, error) {
+	members, err := storage.Members().ListByUser(ctx, principal.ID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &policy.Subject{
+		User:    principal,
+		Members: members,
+	}, nil
+}
+
+// authorize loads the subject of the principal and checks the action.
+func authorize(ctx context.Context, storage store.Store, principal *model.User, action policy.Action, target *policy.Target) error {
+	sub, err := subject(ctx, storage, principal)
+
+	if err != nil {
+		return err
+	}
+
+	return policy.Authorize(sub, action, target)
+}
+
+// authorizeRepository checks the action against the access of the principal
+// on the repository, or on the namespace if the repository is nil.
+func authorizeRepository(ctx context.Context, storage store.Store, principal *model.User, action policy.Action, namespace *model.Namespace, repository *model.Repository) error {
+	sub, err := subject(ctx, storage, principal)
+
+	if err != nil {
+		return err
+	}
+
+	access, err := repositoryAccess(ctx, storage, sub, namespace, repository)
+
+	if err != nil {
+		return err
+	}
+
+	return policy.Authorize(sub, action, &policy.Target{
+		Access: access,
+	})
+}
+
+// repositoryAccess loads the team permissions and resolves the access of the
+// subject on the repository, or on the namespace if the repository is nil.
+func repositoryAccess(ctx context.Context, storage store.Store, sub *policy.Subject, namespace *model.Namespace, repository *model.Repository) (string, error) {
+	var teams []*model.RepositoryTeam
+
+	if repository != nil {
+		records, err := storage.Repositories().ListTeams(ctx, repository.ID)
+
+		if err != nil {
+			return "", err
+		}
+
+		teams = records
+	}
+
+	return policy.Access(sub, namespace, repository, teams), nil
+}
+
+// membership builds the policy target for a membership of a user in a team.
+func membership(ctx context.Context, storage store.Store, teamID, userID, perm string) (*policy.Target, error) {
+	members, err := storage.Members().ListByTeam(ctx, teamID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	target := &policy.Target{
+		TeamID: teamID,
+		UserID: userID,
+		Perm:   perm,
+	}
+
+	for _, member := range members {
+		if member.UserID == userID {
+			target.Current = member.Perm
+		}
+
+		if member.Perm == model.PermOwner {
+			target.Owners++
+		}
+	}
+
+	return target, nil
+}
+
+// changeMember applies a membership action after resolving the team and the
+// user by ID or slug and checking the policy. Both the team and the user
+// operations share this implementation.
+func changeMember(ctx context.Context, storage store.Store, principal *model.User, action policy.Action, teamID, userID, perm string) error {
+	team, err := storage.Teams().Show(ctx, teamID)
+
+	if err != nil {
+		return err
+	}
+
+	user, err := storage.Users().Show(ctx, userID)
+
+	if err != nil {
+		return err
+	}
+
+	target, err := membership(ctx, storage, team.ID, user.ID, perm)
+
+	if err != nil {
+		return err
+	}
+
+	if action != policy.MemberAssign && target.Current == "" {
+		return store.ErrNotAssigned
+	}
+
+	if err := authorize(ctx, storage, principal, action, target); err != nil {
+		return err
+	}
+
+	switch action {
+	case policy.MemberAssign:
+		return storage.Members().Assign(ctx, team.ID, user.ID, perm)
+	case policy.MemberPermit:
+		return storage.Members().Permit(ctx, team.ID, user.ID, perm)
+	case policy.MemberUnassign:
+		return storage.Members().Unassign(ctx, team.ID, user.ID)
+	}
+
+	return policy.ErrUnknownAction
+}
+
+// forbidden checks if the error has been caused by the policy.
+func forbidden(err error) bool {
+	return err == policy.ErrForbidden || err == policy.ErrLastOwner
+}
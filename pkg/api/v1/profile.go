@@ -0,0 +1,143 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/go-openapi/runtime/middleware"
+	"github.com/go-openapi/strfmt"
+	"github.com/rs/zerolog/log"
+	"github.com/umschlag/umschlag-api/pkg/api/v1/models"
+	"github.com/umschlag/umschlag-api/pkg/api/v1/restapi/operations/profile"
+	"github.com/umschlag/umschlag-api/pkg/authn"
+	"github.com/umschlag/umschlag-api/pkg/config"
+	"github.com/umschlag/umschlag-api/pkg/model"
+	"github.com/umschlag/umschlag-api/pkg/password"
+	"github.com/umschlag/umschlag-api/pkg/store"
+	"github.com/umschlag/umschlag-api/pkg/token"
+)
+
+// ShowProfileHandler implements the handler for the ProfileShowProfile operation.
+func ShowProfileHandler(cfg *config.Config) profile.ShowProfileHandlerFunc {
+	return func(params profile.ShowProfileParams, principal *model.User) middleware.Responder {
+		return profile.NewShowProfileOK().WithPayload(convertProfile(cfg, principal))
+	}
+}
+
+// UpdateProfileHandler implements the handler for the ProfileUpdateProfile operation.
+func UpdateProfileHandler(cfg *config.Config, storage store.Store) profile.UpdateProfileHandlerFunc {
+	return func(params profile.UpdateProfileParams, principal *model.User) middleware.Responder {
+		record := *principal
+		record.Slug = params.Profile.Slug
+		record.Username = *params.Profile.Username
+		record.Email = *params.Profile.Email
+
+		if params.Profile.Password != "" {
+			hash, err := password.Hash(params.Profile.Password.String(), cfg.Password.Cost)
+
+			if err != nil {
+				log.Error().
+					Err(err).
+					Str("username", principal.Username).
+					Msg("failed to hash password")
+
+				return profile.NewUpdateProfileDefault(http.StatusInternalServerError).WithPayload(
+					generalError(http.StatusInternalServerError, "failed to update profile"),
+				)
+			}
+
+			record.Password = hash
+		}
+
+		updated, err := storage.Users().Update(params.HTTPRequest.Context(), &record)
+
+		if err == nil {
+			return profile.NewUpdateProfileOK().WithPayload(convertProfile(cfg, updated))
+		}
+
+		if field, ok := duplicateField(err); ok {
+			return profile.NewUpdateProfileUnprocessableEntity().WithPayload(
+				validationError(field, "is already taken"),
+			)
+		}
+
+		log.Error().
+			Err(err).
+			Str("username", principal.Username).
+			Msg("failed to update profile")
+
+		return profile.NewUpdateProfileDefault(http.StatusInternalServerError).WithPayload(
+			generalError(http.StatusInternalServerError, "failed to update profile"),
+		)
+	}
+}
+
+// LogoutProfileHandler implements the handler for the ProfileLogoutProfile operation.
+func LogoutProfileHandler(authenticator *authn.Authenticator) profile.LogoutProfileHandlerFunc {
+	return func(params profile.LogoutProfileParams, principal *model.User) middleware.Responder {
+		before, ok := logoutBefore(params.Params)
+
+		if !ok {
+			return profile.NewLogoutProfileUnprocessableEntity().WithPayload(
+				validationError("before", "must not be in the future"),
+			)
+		}
+
+		if err := authenticator.RevokeBefore(params.HTTPRequest.Context(), principal, before); err != nil {
+			log.Error().
+				Err(err).
+				Str("username", principal.Username).
+				Msg("failed to revoke tokens")
+
+			return profile.NewLogoutProfileDefault(http.StatusInternalServerError).WithPayload(
+				generalError(http.StatusInternalServerError, "failed to revoke tokens"),
+			)
+		}
+
+		return profile.NewLogoutProfileOK().WithPayload(
+			generalError(http.StatusOK, "successfully logged out everywhere"),
+		)
+	}
+}
+
+// TokenProfileHandler implements the handler for the ProfileTokenProfile operation.
+func TokenProfileHandler(keys *token.KeySet) profile.TokenProfileHandlerFunc {
+	return func(params profile.TokenProfileParams, principal *model.User) middleware.Responder {
+		if principal.Token != nil {
+			return profile.NewTokenProfileForbidden().WithPayload(
+				generalError(http.StatusForbidden, errTokenPrincipal),
+			)
+		}
+
+		result, err := token.New(token.UserToken, principal.ID).SignUnlimited(keys.Active())
+
+		if err != nil {
+			log.Error().
+				Err(err).
+				Str("username", principal.Username).
+				Msg("failed to generate user token")
+
+			return profile.NewTokenProfileInternalServerError().WithPayload(
+				generalError(http.StatusInternalServerError, "failed to generate token"),
+			)
+		}
+
+		return profile.NewTokenProfileOK().WithPayload(&models.AuthToken{
+			Token: &result.Token,
+		})
+	}
+}
+
+// convertProfile converts a profile for responses, the password is never included.
+func convertProfile(cfg *config.Config, record *model.User) *models.Profile {
+	return &models.Profile{
+		ID:        strfmt.UUID(record.ID),
+		Slug:      record.Slug,
+		Username:  &record.Username,
+		Email:     &record.Email,
+		Admin:     record.Admin,
+		Active:    record.Active,
+		AvatarURL: avatarURL(cfg, record.Avatar),
+		CreatedAt: strfmt.DateTime(record.CreatedAt),
+		UpdatedAt: strfmt.DateTime(record.UpdatedAt),
+	}
+}
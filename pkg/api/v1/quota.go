@@ -0,0 +1,364 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/go-openapi/runtime/middleware"
+	"github.com/go-openapi/strfmt"
+	"github.com/go-openapi/swag"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+	"github.com/umschlag/umschlag-api/pkg/api/v1/models"
+	"github.com/umschlag/umschlag-api/pkg/api/v1/restapi/operations/namespace"
+	"github.com/umschlag/umschlag-api/pkg/api/v1/restapi/operations/team"
+	"github.com/umschlag/umschlag-api/pkg/model"
+	"github.com/umschlag/umschlag-api/pkg/policy"
+	"github.com/umschlag/umschlag-api/pkg/registry/quota"
+	"github.com/umschlag/umschlag-api/pkg/store"
+)
+
+var (
+	errInvalidQuota = errors.New("quota needs a limit and the soft limit must not exceed the hard limit")
+)
+
+// ShowNamespaceUsageHandler implements the handler for the NamespaceShowNamespaceUsage operation.
+func ShowNamespaceUsageHandler(storage store.Store) namespace.ShowNamespaceUsageHandlerFunc {
+	return func(params namespace.ShowNamespaceUsageParams, principal *model.User) middleware.Responder {
+		ctx := params.HTTPRequest.Context()
+		record, err := storage.Namespaces().Show(ctx, params.NamespaceID)
+
+		if err == nil {
+			err = authorize(ctx, storage, principal, policy.UsageShow, &policy.Target{
+				UserID: record.UserID,
+				TeamID: record.TeamID,
+			})
+		}
+
+		var (
+			usage *model.Usage
+			limit *model.Quota
+		)
+
+		if err == nil {
+			usage, err = quota.Namespace(ctx, storage, record)
+		}
+
+		if err == nil {
+			limit, err = storage.Quotas().Show(ctx, model.QuotaNamespace, record.ID)
+
+			if err == store.ErrNotFound {
+				err = nil
+			}
+		}
+
+		switch {
+		case err == nil:
+			return namespace.NewShowNamespaceUsageOK().WithPayload(convertUsage(usage, limit))
+		case forbidden(err):
+			return namespace.NewShowNamespaceUsageForbidden().WithPayload(
+				generalError(http.StatusForbidden, err.Error()),
+			)
+		case err == store.ErrNotFound:
+			return namespace.NewShowNamespaceUsageDefault(http.StatusNotFound).WithPayload(
+				generalError(http.StatusNotFound, "namespace not found"),
+			)
+		}
+
+		log.Error().
+			Err(err).
+			Str("namespace", params.NamespaceID).
+			Msg("failed to calculate storage usage")
+
+		return namespace.NewShowNamespaceUsageDefault(http.StatusInternalServerError).WithPayload(
+			generalError(http.StatusInternalServerError, "failed to calculate storage usage"),
+		)
+	}
+}
+
+// UpdateNamespaceQuotaHandler implements the handler for the NamespaceUpdateNamespaceQuota operation.
+func UpdateNamespaceQuotaHandler(storage store.Store) namespace.UpdateNamespaceQuotaHandlerFunc {
+	return func(params namespace.UpdateNamespaceQuotaParams, principal *model.User) middleware.Responder {
+		ctx := params.HTTPRequest.Context()
+		record, err := storage.Namespaces().Show(ctx, params.NamespaceID)
+
+		if err == nil {
+			err = authorize(ctx, storage, principal, policy.QuotaUpdate, &policy.Target{
+				UserID: record.UserID,
+				TeamID: record.TeamID,
+			})
+		}
+
+		var limit *model.Quota
+
+		if err == nil {
+			limit = &model.Quota{
+				Kind:    model.QuotaNamespace,
+				OwnerID: record.ID,
+				Hard:    swag.Int64Value(params.Quota.Hard),
+				Soft:    swag.Int64Value(params.Quota.Soft),
+			}
+
+			if !limit.Valid() {
+				return namespace.NewUpdateNamespaceQuotaUnprocessableEntity().WithPayload(
+					validationError("soft", errInvalidQuota.Error()),
+				)
+			}
+
+			limit, err = storage.Quotas().Save(ctx, limit)
+		}
+
+		switch {
+		case err == nil:
+			return namespace.NewUpdateNamespaceQuotaOK().WithPayload(convertQuota(limit))
+		case forbidden(err):
+			return namespace.NewUpdateNamespaceQuotaForbidden().WithPayload(
+				generalError(http.StatusForbidden, err.Error()),
+			)
+		case err == store.ErrNotFound:
+			return namespace.NewUpdateNamespaceQuotaDefault(http.StatusNotFound).WithPayload(
+				generalError(http.StatusNotFound, "namespace not found"),
+			)
+		}
+
+		log.Error().
+			Err(err).
+			Str("namespace", params.NamespaceID).
+			Msg("failed to update storage quota")
+
+		return namespace.NewUpdateNamespaceQuotaDefault(http.StatusInternalServerError).WithPayload(
+			generalError(http.StatusInternalServerError, "failed to update storage quota"),
+		)
+	}
+}
+
+// DeleteNamespaceQuotaHandler implements the handler for the NamespaceDeleteNamespaceQuota operation.
+func DeleteNamespaceQuotaHandler(storage store.Store) namespace.DeleteNamespaceQuotaHandlerFunc {
+	return func(params namespace.DeleteNamespaceQuotaParams, principal *model.User) middleware.Responder {
+		ctx := params.HTTPRequest.Context()
+		record, err := storage.Namespaces().Show(ctx, params.NamespaceID)
+
+		if err == nil {
+			err = authorize(ctx, storage, principal, policy.QuotaUpdate, &policy.Target{
+				UserID: record.UserID,
+				TeamID: record.TeamID,
+			})
+		}
+
+		if err == nil {
+			err = storage.Quotas().Delete(ctx, model.QuotaNamespace, record.ID)
+		}
+
+		switch {
+		case err == nil:
+			return namespace.NewDeleteNamespaceQuotaOK().WithPayload(
+				generalError(http.StatusOK, "successfully deleted storage quota"),
+			)
+		case forbidden(err):
+			return namespace.NewDeleteNamespaceQuotaForbidden().WithPayload(
+				generalError(http.StatusForbidden, err.Error()),
+			)
+		case err == store.ErrNotFound:
+			return namespace.NewDeleteNamespaceQuotaDefault(http.StatusNotFound).WithPayload(
+				generalError(http.StatusNotFound, "namespace or storage quota not found"),
+			)
+		}
+
+		log.Error().
+			Err(err).
+			Str("namespace", params.NamespaceID).
+			Msg("failed to delete storage quota")
+
+		return namespace.NewDeleteNamespaceQuotaDefault(http.StatusInternalServerError).WithPayload(
+			generalError(http.StatusInternalServerError, "failed to delete storage quota"),
+		)
+	}
+}
+
+// ShowTeamUsageHandler implements the handler for the TeamShowTeamUsage operation.
+func ShowTeamUsageHandler(storage store.Store) team.ShowTeamUsageHandlerFunc {
+	return func(params team.ShowTeamUsageParams, principal *model.User) middleware.Responder {
+		ctx := params.HTTPRequest.Context()
+		record, err := storage.Teams().Show(ctx, params.TeamID)
+
+		if err == nil {
+			err = authorize(ctx, storage, principal, policy.UsageShow, &policy.Target{
+				TeamID: record.ID,
+			})
+		}
+
+		var (
+			usage *model.Usage
+			limit *model.Quota
+		)
+
+		if err == nil {
+			usage, err = quota.Team(ctx, storage, record.ID)
+		}
+
+		if err == nil {
+			limit, err = storage.Quotas().Show(ctx, model.QuotaTeam, record.ID)
+
+			if err == store.ErrNotFound {
+				err = nil
+			}
+		}
+
+		switch {
+		case err == nil:
+			return team.NewShowTeamUsageOK().WithPayload(convertUsage(usage, limit))
+		case forbidden(err):
+			return team.NewShowTeamUsageForbidden().WithPayload(
+				generalError(http.StatusForbidden, err.Error()),
+			)
+		case err == store.ErrNotFound:
+			return team.NewShowTeamUsageDefault(http.StatusNotFound).WithPayload(
+				generalError(http.StatusNotFound, "team not found"),
+			)
+		}
+
+		log.Error().
+			Err(err).
+			Str("team", params.TeamID).
+			Msg("failed to calculate storage usage")
+
+		return team.NewShowTeamUsageDefault(http.StatusInternalServerError).WithPayload(
+			generalError(http.StatusInternalServerError, "failed to calculate storage usage"),
+		)
+	}
+}
+
+// UpdateTeamQuotaHandler implements the handler for the TeamUpdateTeamQuota operation.
+func UpdateTeamQuotaHandler(storage store.Store) team.UpdateTeamQuotaHandlerFunc {
+	return func(params team.UpdateTeamQuotaParams, principal *model.User) middleware.Responder {
+		ctx := params.HTTPRequest.Context()
+		record, err := storage.Teams().Show(ctx, params.TeamID)
+
+		if err == nil {
+			err = authorize(ctx, storage, principal, policy.QuotaUpdate, &policy.Target{
+				TeamID: record.ID,
+			})
+		}
+
+		var limit *model.Quota
+
+		if err == nil {
+			limit = &model.Quota{
+				Kind:    model.QuotaTeam,
+				OwnerID: record.ID,
+				Hard:    swag.Int64Value(params.Quota.Hard),
+				Soft:    swag.Int64Value(params.Quota.Soft),
+			}
+
+			if !limit.Valid() {
+				return team.NewUpdateTeamQuotaUnprocessableEntity().WithPayload(
+					validationError("soft", errInvalidQuota.Error()),
+				)
+			}
+
+			limit, err = storage.Quotas().Save(ctx, limit)
+		}
+
+		switch {
+		case err == nil:
+			return team.NewUpdateTeamQuotaOK().WithPayload(convertQuota(limit))
+		case forbidden(err):
+			return team.NewUpdateTeamQuotaForbidden().WithPayload(
+				generalError(http.StatusForbidden, err.Error()),
+			)
+		case err == store.ErrNotFound:
+			return team.NewUpdateTeamQuotaDefault(http.StatusNotFound).WithPayload(
+				generalError(http.StatusNotFound, "team not found"),
+			)
+		}
+
+		log.Error().
+			Err(err).
+			Str("team", params.TeamID).
+			Msg("failed to update storage quota")
+
+		return team.NewUpdateTeamQuotaDefault(http.StatusInternalServerError).WithPayload(
+			generalError(http.StatusInternalServerError, "failed to update storage quota"),
+		)
+	}
+}
+
+// DeleteTeamQuotaHandler implements the handler for the TeamDeleteTeamQuota operation.
+func DeleteTeamQuotaHandler(storage store.Store) team.DeleteTeamQuotaHandlerFunc {
+	return func(params team.DeleteTeamQuotaParams, principal *model.User) middleware.Responder {
+		ctx := params.HTTPRequest.Context()
+		record, err := storage.Teams().Show(ctx, params.TeamID)
+
+		if err == nil {
+			err = authorize(ctx, storage, principal, policy.QuotaUpdate, &policy.Target{
+				TeamID: record.ID,
+			})
+		}
+
+		if err == nil {
+			err = storage.Quotas().Delete(ctx, model.QuotaTeam, record.ID)
+		}
+
+		switch {
+		case err == nil:
+			return team.NewDeleteTeamQuotaOK().WithPayload(
+				generalError(http.StatusOK, "successfully deleted storage quota"),
+			)
+		case forbidden(err):
+			return team.NewDeleteTeamQuotaForbidden().WithPayload(
+				generalError(http.StatusForbidden, err.Error()),
+			)
+		case err == store.ErrNotFound:
+			return team.NewDeleteTeamQuotaDefault(http.StatusNotFound).WithPayload(
+				generalError(http.StatusNotFound, "team or storage quota not found"),
+			)
+		}
+
+		log.Error().
+			Err(err).
+			Str("team", params.TeamID).
+			Msg("failed to delete storage quota")
+
+		return team.NewDeleteTeamQuotaDefault(http.StatusInternalServerError).WithPayload(
+			generalError(http.StatusInternalServerError, "failed to delete storage quota"),
+		)
+	}
+}
+
+// convertQuota converts a storage quota for responses.
+func convertQuota(record *model.Quota) *models.Quota {
+	return &models.Quota{
+		Hard:      &record.Hard,
+		Soft:      &record.Soft,
+		CreatedAt: strfmt.DateTime(record.CreatedAt),
+		UpdatedAt: strfmt.DateTime(record.UpdatedAt),
+	}
+}
+
+// convertUsage converts a storage usage together with the optional quota
+// for responses.
+func convertUsage(record *model.Usage, limit *model.Quota) *models.Usage {
+	items := make([]*models.UsageItem, 0, len(record.Items))
+
+	for _, item := range record.Items {
+		id := strfmt.UUID(item.ID)
+
+		items = append(items, &models.UsageItem{
+			ID:   &id,
+			Name: &item.Name,
+			Size: &item.Size,
+		})
+	}
+
+	result := &models.Usage{
+		Size:  &record.Size,
+		Items: items,
+	}
+
+	if limit != nil {
+		result.Quota = convertQuota(limit)
+		result.Exceeded = limit.Exceeded(record.Size)
+		result.Warned = limit.Warned(record.Size)
+	}
+
+	return result
+}
@@ -0,0 +1,526 @@
+package v1
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/go-openapi/runtime/middleware"
+	"github.com/go-openapi/strfmt"
+	"github.com/rs/zerolog/log"
+	"github.com/umschlag/umschlag-api/pkg/api/v1/models"
+	"github.com/umschlag/umschlag-api/pkg/api/v1/restapi/operations/repository"
+	"github.com/umschlag/umschlag-api/pkg/config"
+	"github.com/umschlag/umschlag-api/pkg/model"
+	"github.com/umschlag/umschlag-api/pkg/policy"
+	"github.com/umschlag/umschlag-api/pkg/store"
+)
+
+// ListRepositoriesHandler implements the handler for the RepositoryListRepositories operation.
+func ListRepositoriesHandler(storage store.Store) repository.ListRepositoriesHandlerFunc {
+	return func(params repository.ListRepositoriesParams, principal *model.User) middleware.Responder {
+		ctx := params.HTTPRequest.Context()
+		ns, err := storage.Namespaces().Show(ctx, params.NamespaceID)
+
+		if err == store.ErrNotFound {
+			return repository.NewListRepositoriesDefault(http.StatusNotFound).WithPayload(
+				generalError(http.StatusNotFound, "namespace not found"),
+			)
+		}
+
+		var (
+			sub     *policy.Subject
+			records []*model.Repository
+		)
+
+		if err == nil {
+			sub, err = subject(ctx, storage, principal)
+		}
+
+		if err == nil {
+			records, err = storage.Repositories().List(ctx, ns.ID)
+		}
+
+		payload := make([]*models.Repository, 0, len(records))
+
+		for _, record := range records {
+			if err != nil {
+				break
+			}
+
+			var access string
+
+			if access, err = repositoryAccess(ctx, storage, sub, ns, record); access != "" {
+				payload = append(payload, convertRepository(record))
+			}
+		}
+
+		if err != nil {
+			log.Error().
+				Err(err).
+				Str("namespace", params.NamespaceID).
+				Msg("failed to fetch repositories")
+
+			return repository.NewListRepositoriesDefault(http.StatusInternalServerError).WithPayload(
+				generalError(http.StatusInternalServerError, "failed to fetch repositories"),
+			)
+		}
+
+		return repository.NewListRepositoriesOK().WithPayload(payload)
+	}
+}
+
+// ShowRepositoryHandler implements the handler for the RepositoryShowRepository operation.
+func ShowRepositoryHandler(storage store.Store) repository.ShowRepositoryHandlerFunc {
+	return func(params repository.ShowRepositoryParams, principal *model.User) middleware.Responder {
+		ctx := params.HTTPRequest.Context()
+		ns, record, err := showRepository(ctx, storage, params.NamespaceID, params.RepositoryID)
+
+		if err == nil {
+			err = authorizeRepository(ctx, storage, principal, policy.RepositoryShow, ns, record)
+		}
+
+		switch {
+		case err == nil:
+			return repository.NewShowRepositoryOK().WithPayload(convertRepository(record))
+		case forbidden(err):
+			return repository.NewShowRepositoryForbidden().WithPayload(
+				generalError(http.StatusForbidden, err.Error()),
+			)
+		case err == store.ErrNotFound:
+			return repository.NewShowRepositoryDefault(http.StatusNotFound).WithPayload(
+				generalError(http.StatusNotFound, "repository not found"),
+			)
+		}
+
+		log.Error().
+			Err(err).
+			Str("namespace", params.NamespaceID).
+			Str("repository", params.RepositoryID).
+			Msg("failed to fetch repository")
+
+		return repository.NewShowRepositoryDefault(http.StatusInternalServerError).WithPayload(
+			generalError(http.StatusInternalServerError, "failed to fetch repository"),
+		)
+	}
+}
+
+// CreateRepositoryHandler implements the handler for the RepositoryCreateRepository operation.
+func CreateRepositoryHandler(storage store.Store) repository.CreateRepositoryHandlerFunc {
+	return func(params repository.CreateRepositoryParams, principal *model.User) middleware.Responder {
+		ctx := params.HTTPRequest.Context()
+		ns, err := storage.Namespaces().Show(ctx, params.NamespaceID)
+
+		if err == nil {
+			err = authorizeRepository(ctx, storage, principal, policy.RepositoryCreate, ns, nil)
+		}
+
+		var record *model.Repository
+
+		if err == nil {
+			record, err = storage.Repositories().Create(ctx, &model.Repository{
+				NamespaceID: ns.ID,
+				Name:        *params.Repository.Name,
+				Description: params.Repository.Description,
+				Visibility:  params.Repository.Visibility,
+			})
+		}
+
+		switch {
+		case err == nil:
+			return repository.NewCreateRepositoryOK().WithPayload(convertRepository(record))
+		case forbidden(err):
+			return repository.NewCreateRepositoryForbidden().WithPayload(
+				generalError(http.StatusForbidden, err.Error()),
+			)
+		case err == store.ErrNotFound:
+			return repository.NewCreateRepositoryDefault(http.StatusNotFound).WithPayload(
+				generalError(http.StatusNotFound, "namespace not found"),
+			)
+		case err == store.ErrDuplicateName:
+			return repository.NewCreateRepositoryUnprocessableEntity().WithPayload(
+				validationError("name", "is already taken"),
+			)
+		}
+
+		log.Error().
+			Err(err).
+			Str("namespace", params.NamespaceID).
+			Msg("failed to create repository")
+
+		return repository.NewCreateRepositoryDefault(http.StatusInternalServerError).WithPayload(
+			generalError(http.StatusInternalServerError, "failed to create repository"),
+		)
+	}
+}
+
+// UpdateRepositoryHandler implements the handler for the RepositoryUpdateRepository operation.
+func UpdateRepositoryHandler(storage store.Store) repository.UpdateRepositoryHandlerFunc {
+	return func(params repository.UpdateRepositoryParams, principal *model.User) middleware.Responder {
+		ctx := params.HTTPRequest.Context()
+		ns, record, err := showRepository(ctx, storage, params.NamespaceID, params.RepositoryID)
+
+		if err == nil {
+			err = authorizeRepository(ctx, storage, principal, policy.RepositoryUpdate, ns, record)
+		}
+
+		if err == nil {
+			record.Name = *params.Repository.Name
+			record.Description = params.Repository.Description
+
+			if params.Repository.Visibility != "" {
+				record.Visibility = params.Repository.Visibility
+			}
+
+			record, err = storage.Repositories().Update(ctx, record)
+		}
+
+		switch {
+		case err == nil:
+			return repository.NewUpdateRepositoryOK().WithPayload(convertRepository(record))
+		case forbidden(err):
+			return repository.NewUpdateRepositoryForbidden().WithPayload(
+				generalError(http.StatusForbidden, err.Error()),
+			)
+		case err == store.ErrNotFound:
+			return repository.NewUpdateRepositoryDefault(http.StatusNotFound).WithPayload(
+				generalError(http.StatusNotFound, "repository not found"),
+			)
+		case err == store.ErrDuplicateName:
+			return repository.NewUpdateRepositoryUnprocessableEntity().WithPayload(
+				validationError("name", "is already taken"),
+			)
+		}
+
+		log.Error().
+			Err(err).
+			Str("namespace", params.NamespaceID).
+			Str("repository", params.RepositoryID).
+			Msg("failed to update repository")
+
+		return repository.NewUpdateRepositoryDefault(http.StatusInternalServerError).WithPayload(
+			generalError(http.StatusInternalServerError, "failed to update repository"),
+		)
+	}
+}
+
+// DeleteRepositoryHandler implements the handler for the RepositoryDeleteRepository operation.
+func DeleteRepositoryHandler(storage store.Store) repository.DeleteRepositoryHandlerFunc {
+	return func(params repository.DeleteRepositoryParams, principal *model.User) middleware.Responder {
+		ctx := params.HTTPRequest.Context()
+		ns, record, err := showRepository(ctx, storage, params.NamespaceID, params.RepositoryID)
+
+		if err == nil {
+			err = authorizeRepository(ctx, storage, principal, policy.RepositoryDelete, ns, record)
+		}
+
+		if err == nil {
+			err = storage.Repositories().Delete(ctx, record.ID)
+		}
+
+		switch {
+		case err == nil:
+			return repository.NewDeleteRepositoryOK().WithPayload(
+				generalError(http.StatusOK, "successfully deleted repository"),
+			)
+		case forbidden(err):
+			return repository.NewDeleteRepositoryForbidden().WithPayload(
+				generalError(http.StatusForbidden, err.Error()),
+			)
+		case err == store.ErrNotFound:
+			return repository.NewDeleteRepositoryDefault(http.StatusNotFound).WithPayload(
+				generalError(http.StatusNotFound, "repository not found"),
+			)
+		}
+
+		log.Error().
+			Err(err).
+			Str("namespace", params.NamespaceID).
+			Str("repository", params.RepositoryID).
+			Msg("failed to delete repository")
+
+		return repository.NewDeleteRepositoryDefault(http.StatusInternalServerError).WithPayload(
+			generalError(http.StatusInternalServerError, "failed to delete repository"),
+		)
+	}
+}
+
+// ListRepositoryTeamsHandler implements the handler for the RepositoryListRepositoryTeams operation.
+func ListRepositoryTeamsHandler(cfg *config.Config, storage store.Store) repository.ListRepositoryTeamsHandlerFunc {
+	return func(params repository.ListRepositoryTeamsParams, principal *model.User) middleware.Responder {
+		ctx := params.HTTPRequest.Context()
+		ns, record, err := showRepository(ctx, storage, params.NamespaceID, params.RepositoryID)
+
+		if err == nil {
+			err = authorizeRepository(ctx, storage, principal, policy.RepositoryShow, ns, record)
+		}
+
+		var records []*model.RepositoryTeam
+
+		if err == nil {
+			records, err = storage.Repositories().ListTeams(ctx, record.ID)
+		}
+
+		switch {
+		case err == nil:
+			payload := make([]*models.RepositoryTeam, 0, len(records))
+
+			for _, team := range records {
+				payload = append(payload, convertRepositoryTeam(cfg, team))
+			}
+
+			return repository.NewListRepositoryTeamsOK().WithPayload(payload)
+		case forbidden(err):
+			return repository.NewListRepositoryTeamsForbidden().WithPayload(
+				generalError(http.StatusForbidden, err.Error()),
+			)
+		case err == store.ErrNotFound:
+			return repository.NewListRepositoryTeamsDefault(http.StatusNotFound).WithPayload(
+				generalError(http.StatusNotFound, "repository not found"),
+			)
+		}
+
+		log.Error().
+			Err(err).
+			Str("namespace", params.NamespaceID).
+			Str("repository", params.RepositoryID).
+			Msg("failed to fetch repository teams")
+
+		return repository.NewListRepositoryTeamsDefault(http.StatusInternalServerError).WithPayload(
+			generalError(http.StatusInternalServerError, "failed to fetch repository teams"),
+		)
+	}
+}
+
+// AppendRepositoryTeamHandler implements the handler for the RepositoryAppendRepositoryTeam operation.
+func AppendRepositoryTeamHandler(storage store.Store) repository.AppendRepositoryTeamHandlerFunc {
+	return func(params repository.AppendRepositoryTeamParams, principal *model.User) middleware.Responder {
+		err := changeRepositoryTeam(
+			params.HTTPRequest.Context(),
+			storage,
+			principal,
+			params.NamespaceID,
+			params.RepositoryID,
+			*params.RepositoryTeam.Team,
+			*params.RepositoryTeam.Perm,
+			false,
+		)
+
+		switch {
+		case err == nil:
+			return repository.NewAppendRepositoryTeamOK().WithPayload(
+				generalError(http.StatusOK, "successfully assigned team to repository"),
+			)
+		case forbidden(err):
+			return repository.NewAppendRepositoryTeamForbidden().WithPayload(
+				generalError(http.StatusForbidden, err.Error()),
+			)
+		case err == store.ErrNotFound:
+			return repository.NewAppendRepositoryTeamDefault(http.StatusNotFound).WithPayload(
+				generalError(http.StatusNotFound, "repository or team not found"),
+			)
+		case err == store.ErrAlreadyAssigned:
+			return repository.NewAppendRepositoryTeamUnprocessableEntity().WithPayload(
+				generalError(http.StatusUnprocessableEntity, "team is already assigned"),
+			)
+		}
+
+		log.Error().
+			Err(err).
+			Str("namespace", params.NamespaceID).
+			Str("repository", params.RepositoryID).
+			Msg("failed to assign team to repository")
+
+		return repository.NewAppendRepositoryTeamDefault(http.StatusInternalServerError).WithPayload(
+			generalError(http.StatusInternalServerError, "failed to assign team to repository"),
+		)
+	}
+}
+
+// PermitRepositoryTeamHandler implements the handler for the RepositoryPermitRepositoryTeam operation.
+func PermitRepositoryTeamHandler(storage store.Store) repository.PermitRepositoryTeamHandlerFunc {
+	return func(params repository.PermitRepositoryTeamParams, principal *model.User) middleware.Responder {
+		err := changeRepositoryTeam(
+			params.HTTPRequest.Context(),
+			storage,
+			principal,
+			params.NamespaceID,
+			params.RepositoryID,
+			*params.RepositoryTeam.Team,
+			*params.RepositoryTeam.Perm,
+			true,
+		)
+
+		switch {
+		case err == nil:
+			return repository.NewPermitRepositoryTeamOK().WithPayload(
+				generalError(http.StatusOK, "successfully updated team perms"),
+			)
+		case forbidden(err):
+			return repository.NewPermitRepositoryTeamForbidden().WithPayload(
+				generalError(http.StatusForbidden, err.Error()),
+			)
+		case err == store.ErrNotFound:
+			return repository.NewPermitRepositoryTeamDefault(http.StatusNotFound).WithPayload(
+				generalError(http.StatusNotFound, "repository or team not found"),
+			)
+		case err == store.ErrNotAssigned:
+			return repository.NewPermitRepositoryTeamUnprocessableEntity().WithPayload(
+				generalError(http.StatusUnprocessableEntity, "team is not assigned"),
+			)
+		}
+
+		log.Error().
+			Err(err).
+			Str("namespace", params.NamespaceID).
+			Str("repository", params.RepositoryID).
+			Msg("failed to update team perms")
+
+		return repository.NewPermitRepositoryTeamDefault(http.StatusInternalServerError).WithPayload(
+			generalError(http.StatusInternalServerError, "failed to update team perms"),
+		)
+	}
+}
+
+// DeleteRepositoryTeamHandler implements the handler for the RepositoryDeleteRepositoryTeam operation.
+func DeleteRepositoryTeamHandler(storage store.Store) repository.DeleteRepositoryTeamHandlerFunc {
+	return func(params repository.DeleteRepositoryTeamParams, principal *model.User) middleware.Responder {
+		ctx := params.HTTPRequest.Context()
+		ns, record, err := showRepository(ctx, storage, params.NamespaceID, params.RepositoryID)
+
+		if err == nil {
+			err = authorizeRepository(ctx, storage, principal, policy.RepositoryTeams, ns, record)
+		}
+
+		var team *model.Team
+
+		if err == nil {
+			team, err = storage.Teams().Show(ctx, *params.RepositoryTeam.Team)
+		}
+
+		if err == nil {
+			err = storage.Repositories().RevokeTeam(ctx, record.ID, team.ID)
+		}
+
+		switch {
+		case err == nil:
+			return repository.NewDeleteRepositoryTeamOK().WithPayload(
+				generalError(http.StatusOK, "successfully removed team from repository"),
+			)
+		case forbidden(err):
+			return repository.NewDeleteRepositoryTeamForbidden().WithPayload(
+				generalError(http.StatusForbidden, err.Error()),
+			)
+		case err == store.ErrNotFound:
+			return repository.NewDeleteRepositoryTeamDefault(http.StatusNotFound).WithPayload(
+				generalError(http.StatusNotFound, "repository or team not found"),
+			)
+		case err == store.ErrNotAssigned:
+			return repository.NewDeleteRepositoryTeamUnprocessableEntity().WithPayload(
+				generalError(http.StatusUnprocessableEntity, "team is not assigned"),
+			)
+		}
+
+		log.Error().
+			Err(err).
+			Str("namespace", params.NamespaceID).
+			Str("repository", params.RepositoryID).
+			Msg("failed to remove team from repository")
+
+		return repository.NewDeleteRepositoryTeamDefault(http.StatusInternalServerError).WithPayload(
+			generalError(http.StatusInternalServerError, "failed to remove team from repository"),
+		)
+	}
+}
+
+// showRepository resolves the namespace and the repository by ID or name.
+func showRepository(ctx context.Context, storage store.Store, namespaceID, repositoryID string) (*model.Namespace, *model.Repository, error) {
+	ns, err := storage.Namespaces().Show(ctx, namespaceID)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	record, err := storage.Repositories().Show(ctx, ns.ID, repositoryID)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return ns, record, nil
+}
+
+// changeRepositoryTeam grants a team a perm on a repository after checking
+// the policy. Existing grants are only updated if exists is true, otherwise
+// only new grants are added.
+func changeRepositoryTeam(ctx context.Context, storage store.Store, principal *model.User, namespaceID, repositoryID, teamID, perm string, exists bool) error {
+	ns, record, err := showRepository(ctx, storage, namespaceID, repositoryID)
+
+	if err != nil {
+		return err
+	}
+
+	if err := authorizeRepository(ctx, storage, principal, policy.RepositoryTeams, ns, record); err != nil {
+		return err
+	}
+
+	team, err := storage.Teams().Show(ctx, teamID)
+
+	if err != nil {
+		return err
+	}
+
+	teams, err := storage.Repositories().ListTeams(ctx, record.ID)
+
+	if err != nil {
+		return err
+	}
+
+	assigned := false
+
+	for _, t := range teams {
+		if t.TeamID == team.ID {
+			assigned = true
+		}
+	}
+
+	if assigned && !exists {
+		return store.ErrAlreadyAssigned
+	}
+
+	if !assigned && exists {
+		return store.ErrNotAssigned
+	}
+
+	return storage.Repositories().PermitTeam(ctx, record.ID, team.ID, perm)
+}
+
+// convertRepository converts a repository for responses.
+func convertRepository(record *model.Repository) *models.Repository {
+	return &models.Repository{
+		ID:          strfmt.UUID(record.ID),
+		NamespaceID: strfmt.UUID(record.NamespaceID),
+		Name:        &record.Name,
+		Description: record.Description,
+		Visibility:  record.Visibility,
+		CreatedAt:   strfmt.DateTime(record.CreatedAt),
+		UpdatedAt:   strfmt.DateTime(record.UpdatedAt),
+	}
+}
+
+// convertRepositoryTeam converts a team permission for responses.
+func convertRepositoryTeam(cfg *config.Config, record *model.RepositoryTeam) *models.RepositoryTeam {
+	repositoryID := strfmt.UUID(record.RepositoryID)
+	teamID := strfmt.UUID(record.TeamID)
+
+	result := &models.RepositoryTeam{
+		RepositoryID: &repositoryID,
+		TeamID:       &teamID,
+		Perm:         &record.Perm,
+	}
+
+	if record.Team != nil {
+		result.Team = convertTeam(cfg, record.Team)
+	}
+
+	return result
+}
@@ -0,0 +1,448 @@
+package v1
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/go-openapi/runtime/middleware"
+	"github.com/go-openapi/strfmt"
+	"github.com/go-openapi/swag"
+	"github.com/rs/zerolog/log"
+	"github.com/umschlag/umschlag-api/pkg/api/v1/models"
+	"github.com/umschlag/umschlag-api/pkg/api/v1/restapi/operations/namespace"
+	"github.com/umschlag/umschlag-api/pkg/api/v1/restapi/operations/repository"
+	"github.com/umschlag/umschlag-api/pkg/model"
+	"github.com/umschlag/umschlag-api/pkg/policy"
+	"github.com/umschlag/umschlag-api/pkg/registry/retention"
+	"github.com/umschlag/umschlag-api/pkg/store"
+)
+
+// ShowNamespaceRetentionHandler implements the handler for the NamespaceShowNamespaceRetention operation.
+func ShowNamespaceRetentionHandler(storage store.Store) namespace.ShowNamespaceRetentionHandlerFunc {
+	return func(params namespace.ShowNamespaceRetentionParams, principal *model.User) middleware.Responder {
+		ctx := params.HTTPRequest.Context()
+		ns, repositoryID, err := retentionScope(ctx, storage, principal, policy.RetentionShow, params.NamespaceID, "")
+
+		var record *model.Retention
+
+		if err == nil {
+			record, err = storage.Retentions().Show(ctx, ns.ID, repositoryID)
+		}
+
+		switch {
+		case err == nil:
+			return namespace.NewShowNamespaceRetentionOK().WithPayload(convertRetention(record))
+		case forbidden(err):
+			return namespace.NewShowNamespaceRetentionForbidden().WithPayload(
+				generalError(http.StatusForbidden, err.Error()),
+			)
+		case err == store.ErrNotFound:
+			return namespace.NewShowNamespaceRetentionDefault(http.StatusNotFound).WithPayload(
+				generalError(http.StatusNotFound, "namespace or retention policy not found"),
+			)
+		}
+
+		log.Error().
+			Err(err).
+			Str("namespace", params.NamespaceID).
+			Msg("failed to fetch retention policy")
+
+		return namespace.NewShowNamespaceRetentionDefault(http.StatusInternalServerError).WithPayload(
+			generalError(http.StatusInternalServerError, "failed to fetch retention policy"),
+		)
+	}
+}
+
+// UpdateNamespaceRetentionHandler implements the handler for the NamespaceUpdateNamespaceRetention operation.
+func UpdateNamespaceRetentionHandler(storage store.Store) namespace.UpdateNamespaceRetentionHandlerFunc {
+	return func(params namespace.UpdateNamespaceRetentionParams, principal *model.User) middleware.Responder {
+		ctx := params.HTTPRequest.Context()
+		ns, repositoryID, err := retentionScope(ctx, storage, principal, policy.RetentionUpdate, params.NamespaceID, "")
+
+		var record *model.Retention
+
+		if err == nil {
+			record = &model.Retention{
+				NamespaceID:  ns.ID,
+				RepositoryID: repositoryID,
+				KeepLast:     int(swag.Int64Value(params.Retention.KeepLast)),
+				KeepPattern:  params.Retention.KeepPattern,
+				OlderThan:    int(swag.Int64Value(params.Retention.OlderThan)),
+			}
+
+			err = retention.Validate(record)
+		}
+
+		if err == nil {
+			record, err = storage.Retentions().Save(ctx, record)
+		}
+
+		switch {
+		case err == nil:
+			return namespace.NewUpdateNamespaceRetentionOK().WithPayload(convertRetention(record))
+		case forbidden(err):
+			return namespace.NewUpdateNamespaceRetentionForbidden().WithPayload(
+				generalError(http.StatusForbidden, err.Error()),
+			)
+		case err == store.ErrNotFound:
+			return namespace.NewUpdateNamespaceRetentionDefault(http.StatusNotFound).WithPayload(
+				generalError(http.StatusNotFound, "namespace not found"),
+			)
+		case err == retention.ErrInvalid:
+			return namespace.NewUpdateNamespaceRetentionUnprocessableEntity().WithPayload(
+				validationError("retention", err.Error()),
+			)
+		case err == retention.ErrPattern:
+			return namespace.NewUpdateNamespaceRetentionUnprocessableEntity().WithPayload(
+				validationError("keep_pattern", err.Error()),
+			)
+		}
+
+		log.Error().
+			Err(err).
+			Str("namespace", params.NamespaceID).
+			Msg("failed to update retention policy")
+
+		return namespace.NewUpdateNamespaceRetentionDefault(http.StatusInternalServerError).WithPayload(
+			generalError(http.StatusInternalServerError, "failed to update retention policy"),
+		)
+	}
+}
+
+// DeleteNamespaceRetentionHandler implements the handler for the NamespaceDeleteNamespaceRetention operation.
+func DeleteNamespaceRetentionHandler(storage store.Store) namespace.DeleteNamespaceRetentionHandlerFunc {
+	return func(params namespace.DeleteNamespaceRetentionParams, principal *model.User) middleware.Responder {
+		ctx := params.HTTPRequest.Context()
+		ns, repositoryID, err := retentionScope(ctx, storage, principal, policy.RetentionUpdate, params.NamespaceID, "")
+
+		if err == nil {
+			err = storage.Retentions().Delete(ctx, ns.ID, repositoryID)
+		}
+
+		switch {
+		case err == nil:
+			return namespace.NewDeleteNamespaceRetentionOK().WithPayload(
+				generalError(http.StatusOK, "successfully deleted retention policy"),
+			)
+		case forbidden(err):
+			return namespace.NewDeleteNamespaceRetentionForbidden().WithPayload(
+				generalError(http.StatusForbidden, err.Error()),
+			)
+		case err == store.ErrNotFound:
+			return namespace.NewDeleteNamespaceRetentionDefault(http.StatusNotFound).WithPayload(
+				generalError(http.StatusNotFound, "namespace or retention policy not found"),
+			)
+		}
+
+		log.Error().
+			Err(err).
+			Str("namespace", params.NamespaceID).
+			Msg("failed to delete retention policy")
+
+		return namespace.NewDeleteNamespaceRetentionDefault(http.StatusInternalServerError).WithPayload(
+			generalError(http.StatusInternalServerError, "failed to delete retention policy"),
+		)
+	}
+}
+
+// ListNamespaceRetentionReportsHandler implements the handler for the NamespaceListNamespaceRetentionReports operation.
+func ListNamespaceRetentionReportsHandler(storage store.Store) namespace.ListNamespaceRetentionReportsHandlerFunc {
+	return func(params namespace.ListNamespaceRetentionReportsParams, principal *model.User) middleware.Responder {
+		ctx := params.HTTPRequest.Context()
+		ns, repositoryID, err := retentionScope(ctx, storage, principal, policy.RetentionShow, params.NamespaceID, "")
+
+		var (
+			record  *model.Retention
+			records []*model.Report
+		)
+
+		if err == nil {
+			record, err = storage.Retentions().Show(ctx, ns.ID, repositoryID)
+		}
+
+		if err == nil {
+			records, err = storage.Retentions().ListReports(ctx, record.ID)
+		}
+
+		switch {
+		case err == nil:
+			payload := make([]*models.Report, 0, len(records))
+
+			for _, report := range records {
+				payload = append(payload, convertReport(report))
+			}
+
+			return namespace.NewListNamespaceRetentionReportsOK().WithPayload(payload)
+		case forbidden(err):
+			return namespace.NewListNamespaceRetentionReportsForbidden().WithPayload(
+				generalError(http.StatusForbidden, err.Error()),
+			)
+		case err == store.ErrNotFound:
+			return namespace.NewListNamespaceRetentionReportsDefault(http.StatusNotFound).WithPayload(
+				generalError(http.StatusNotFound, "namespace or retention policy not found"),
+			)
+		}
+
+		log.Error().
+			Err(err).
+			Str("namespace", params.NamespaceID).
+			Msg("failed to fetch retention reports")
+
+		return namespace.NewListNamespaceRetentionReportsDefault(http.StatusInternalServerError).WithPayload(
+			generalError(http.StatusInternalServerError, "failed to fetch retention reports"),
+		)
+	}
+}
+
+// ShowRepositoryRetentionHandler implements the handler for the RepositoryShowRepositoryRetention operation.
+func ShowRepositoryRetentionHandler(storage store.Store) repository.ShowRepositoryRetentionHandlerFunc {
+	return func(params repository.ShowRepositoryRetentionParams, principal *model.User) middleware.Responder {
+		ctx := params.HTTPRequest.Context()
+		ns, repositoryID, err := retentionScope(ctx, storage, principal, policy.RetentionShow, params.NamespaceID, params.RepositoryID)
+
+		var record *model.Retention
+
+		if err == nil {
+			record, err = storage.Retentions().Show(ctx, ns.ID, repositoryID)
+		}
+
+		switch {
+		case err == nil:
+			return repository.NewShowRepositoryRetentionOK().WithPayload(convertRetention(record))
+		case forbidden(err):
+			return repository.NewShowRepositoryRetentionForbidden().WithPayload(
+				generalError(http.StatusForbidden, err.Error()),
+			)
+		case err == store.ErrNotFound:
+			return repository.NewShowRepositoryRetentionDefault(http.StatusNotFound).WithPayload(
+				generalError(http.StatusNotFound, "repository or retention policy not found"),
+			)
+		}
+
+		log.Error().
+			Err(err).
+			Str("namespace", params.NamespaceID).
+			Str("repository", params.RepositoryID).
+			Msg("failed to fetch retention policy")
+
+		return repository.NewShowRepositoryRetentionDefault(http.StatusInternalServerError).WithPayload(
+			generalError(http.StatusInternalServerError, "failed to fetch retention policy"),
+		)
+	}
+}
+
+// UpdateRepositoryRetentionHandler implements the handler for the RepositoryUpdateRepositoryRetention operation.
+func UpdateRepositoryRetentionHandler(storage store.Store) repository.UpdateRepositoryRetentionHandlerFunc {
+	return func(params repository.UpdateRepositoryRetentionParams, principal *model.User) middleware.Responder {
+		ctx := params.HTTPRequest.Context()
+		ns, repositoryID, err := retentionScope(ctx, storage, principal, policy.RetentionUpdate, params.NamespaceID, params.RepositoryID)
+
+		var record *model.Retention
+
+		if err == nil {
+			record = &model.Retention{
+				NamespaceID:  ns.ID,
+				RepositoryID: repositoryID,
+				KeepLast:     int(swag.Int64Value(params.Retention.KeepLast)),
+				KeepPattern:  params.Retention.KeepPattern,
+				OlderThan:    int(swag.Int64Value(params.Retention.OlderThan)),
+			}
+
+			err = retention.Validate(record)
+		}
+
+		if err == nil {
+			record, err = storage.Retentions().Save(ctx, record)
+		}
+
+		switch {
+		case err == nil:
+			return repository.NewUpdateRepositoryRetentionOK().WithPayload(convertRetention(record))
+		case forbidden(err):
+			return repository.NewUpdateRepositoryRetentionForbidden().WithPayload(
+				generalError(http.StatusForbidden, err.Error()),
+			)
+		case err == store.ErrNotFound:
+			return repository.NewUpdateRepositoryRetentionDefault(http.StatusNotFound).WithPayload(
+				generalError(http.StatusNotFound, "repository not found"),
+			)
+		case err == retention.ErrInvalid:
+			return repository.NewUpdateRepositoryRetentionUnprocessableEntity().WithPayload(
+				validationError("retention", err.Error()),
+			)
+		case err == retention.ErrPattern:
+			return repository.NewUpdateRepositoryRetentionUnprocessableEntity().WithPayload(
+				validationError("keep_pattern", err.Error()),
+			)
+		}
+
+		log.Error().
+			Err(err).
+			Str("namespace", params.NamespaceID).
+			Str("repository", params.RepositoryID).
+			Msg("failed to update retention policy")
+
+		return repository.NewUpdateRepositoryRetentionDefault(http.StatusInternalServerError).WithPayload(
+			generalError(http.StatusInternalServerError, "failed to update retention policy"),
+		)
+	}
+}
+
+// DeleteRepositoryRetentionHandler implements the handler for the RepositoryDeleteRepositoryRetention operation.
+func DeleteRepositoryRetentionHandler(storage store.Store) repository.DeleteRepositoryRetentionHandlerFunc {
+	return func(params repository.DeleteRepositoryRetentionParams, principal *model.User) middleware.Responder {
+		ctx := params.HTTPRequest.Context()
+		ns, repositoryID, err := retentionScope(ctx, storage, principal, policy.RetentionUpdate, params.NamespaceID, params.RepositoryID)
+
+		if err == nil {
+			err = storage.Retentions().Delete(ctx, ns.ID, repositoryID)
+		}
+
+		switch {
+		case err == nil:
+			return repository.NewDeleteRepositoryRetentionOK().WithPayload(
+				generalError(http.StatusOK, "successfully deleted retention policy"),
+			)
+		case forbidden(err):
+			return repository.NewDeleteRepositoryRetentionForbidden().WithPayload(
+				generalError(http.StatusForbidden, err.Error()),
+			)
+		case err == store.ErrNotFound:
+			return repository.NewDeleteRepositoryRetentionDefault(http.StatusNotFound).WithPayload(
+				generalError(http.StatusNotFound, "repository or retention policy not found"),
+			)
+		}
+
+		log.Error().
+			Err(err).
+			Str("namespace", params.NamespaceID).
+			Str("repository", params.RepositoryID).
+			Msg("failed to delete retention policy")
+
+		return repository.NewDeleteRepositoryRetentionDefault(http.StatusInternalServerError).WithPayload(
+			generalError(http.StatusInternalServerError, "failed to delete retention policy"),
+		)
+	}
+}
+
+// ListRepositoryRetentionReportsHandler implements the handler for the RepositoryListRepositoryRetentionReports operation.
+func ListRepositoryRetentionReportsHandler(storage store.Store) repository.ListRepositoryRetentionReportsHandlerFunc {
+	return func(params repository.ListRepositoryRetentionReportsParams, principal *model.User) middleware.Responder {
+		ctx := params.HTTPRequest.Context()
+		ns, repositoryID, err := retentionScope(ctx, storage, principal, policy.RetentionShow, params.NamespaceID, params.RepositoryID)
+
+		var (
+			record  *model.Retention
+			records []*model.Report
+		)
+
+		if err == nil {
+			record, err = storage.Retentions().Show(ctx, ns.ID, repositoryID)
+		}
+
+		if err == nil {
+			records, err = storage.Retentions().ListReports(ctx, record.ID)
+		}
+
+		switch {
+		case err == nil:
+			payload := make([]*models.Report, 0, len(records))
+
+			for _, report := range records {
+				payload = append(payload, convertReport(report))
+			}
+
+			return repository.NewListRepositoryRetentionReportsOK().WithPayload(payload)
+		case forbidden(err):
+			return repository.NewListRepositoryRetentionReportsForbidden().WithPayload(
+				generalError(http.StatusForbidden, err.Error()),
+			)
+		case err == store.ErrNotFound:
+			return repository.NewListRepositoryRetentionReportsDefault(http.StatusNotFound).WithPayload(
+				generalError(http.StatusNotFound, "repository or retention policy not found"),
+			)
+		}
+
+		log.Error().
+			Err(err).
+			Str("namespace", params.NamespaceID).
+			Str("repository", params.RepositoryID).
+			Msg("failed to fetch retention reports")
+
+		return repository.NewListRepositoryRetentionReportsDefault(http.StatusInternalServerError).WithPayload(
+			generalError(http.StatusInternalServerError, "failed to fetch retention reports"),
+		)
+	}
+}
+
+// retentionScope resolves the namespace and the optional repository of a
+// retention policy by ID or name and authorizes the action against the
+// owner of the namespace.
+func retentionScope(ctx context.Context, storage store.Store, principal *model.User, action policy.Action, namespaceID, repositoryID string) (*model.Namespace, string, error) {
+	ns, err := storage.Namespaces().Show(ctx, namespaceID)
+
+	if err != nil {
+		return nil, "", err
+	}
+
+	if repositoryID != "" {
+		record, err := storage.Repositories().Show(ctx, ns.ID, repositoryID)
+
+		if err != nil {
+			return nil, "", err
+		}
+
+		repositoryID = record.ID
+	}
+
+	if err := authorize(ctx, storage, principal, action, &policy.Target{
+		UserID: ns.UserID,
+		TeamID: ns.TeamID,
+	}); err != nil {
+		return nil, "", err
+	}
+
+	return ns, repositoryID, nil
+}
+
+// convertRetention converts a retention policy for responses.
+func convertRetention(record *model.Retention) *models.Retention {
+	keepLast := int64(record.KeepLast)
+	olderThan := int64(record.OlderThan)
+
+	return &models.Retention{
+		ID:           strfmt.UUID(record.ID),
+		NamespaceID:  strfmt.UUID(record.NamespaceID),
+		RepositoryID: strfmt.UUID(record.RepositoryID),
+		KeepLast:     &keepLast,
+		KeepPattern:  record.KeepPattern,
+		OlderThan:    &olderThan,
+		CreatedAt:    strfmt.DateTime(record.CreatedAt),
+		UpdatedAt:    strfmt.DateTime(record.UpdatedAt),
+	}
+}
+
+// convertReport converts a retention report for responses.
+func convertReport(record *model.Report) *models.Report {
+	id := strfmt.UUID(record.ID)
+	retentionID := strfmt.UUID(record.RetentionID)
+	entries := make([]*models.ReportEntry, 0, len(record.Entries))
+
+	for _, entry := range record.Entries {
+		entries = append(entries, &models.ReportEntry{
+			Repository: &entry.Repository,
+			Digest:     &entry.Digest,
+			Tags:       entry.Tags,
+			Action:     &entry.Action,
+			Message:    entry.Message,
+		})
+	}
+
+	return &models.Report{
+		ID:          &id,
+		RetentionID: &retentionID,
+		StartedAt:   strfmt.DateTime(record.StartedAt),
+		FinishedAt:  strfmt.DateTime(record.FinishedAt),
+		Entries:     entries,
+	}
+}
@@ -0,0 +1,91 @@
+package v1
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/go-openapi/errors"
+	"github.com/go-openapi/runtime"
+	"github.com/rs/zerolog/log"
+	"github.com/umschlag/umschlag-api/pkg/authn"
+	"github.com/umschlag/umschlag-api/pkg/model"
+	"github.com/umschlag/umschlag-api/pkg/token"
+)
+
+// BasicAuth authenticates the principal by username and password.
+func BasicAuth(authenticator *authn.Authenticator) func(string, string) (*model.User, error) {
+	return func(username, password string) (*model.User, error) {
+		user, err := authenticator.Password(context.Background(), username, password)
+		return principal(user, err, "basic")
+	}
+}
+
+// BearerAuth authenticates the principal by a session token.
+func BearerAuth(authenticator *authn.Authenticator) func(string) (*model.User, error) {
+	return func(header string) (*model.User, error) {
+		if len(header) < 7 || !strings.EqualFold(header[:7], "bearer ") {
+			return nil, errors.Unauthenticated("bearer")
+		}
+
+		user, err := authenticator.Token(context.Background(), header[7:], token.SessToken)
+		return principal(user, err, "bearer")
+	}
+}
+
+// HeaderAuth authenticates the principal by a personal access token, the
+// deprecated unlimited user tokens are still accepted.
+func HeaderAuth(authenticator *authn.Authenticator) func(string) (*model.User, error) {
+	return func(key string) (*model.User, error) {
+		if authn.IsToken(key) {
+			user, err := authenticator.AccessToken(context.Background(), key)
+			return principal(user, err, "header")
+		}
+
+		user, err := authenticator.Token(context.Background(), key, token.UserToken)
+		return principal(user, err, "header")
+	}
+}
+
+// ScopeAuth restricts principals authenticated by an access token to the
+// granted scopes, safe methods require read access and all others write access.
+func ScopeAuth() runtime.Authorizer {
+	return runtime.AuthorizerFunc(func(r *http.Request, p interface{}) error {
+		user, ok := p.(*model.User)
+
+		if !ok {
+			return nil
+		}
+
+		scope := model.ScopeAPIWrite
+
+		switch r.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			scope = model.ScopeAPIRead
+		}
+
+		if !user.Grants(scope) {
+			return errors.New(http.StatusForbidden, "access token requires the %s scope", scope)
+		}
+
+		return nil
+	})
+}
+
+// principal translates the authentication result for the openapi runtime.
+func principal(user *model.User, err error, scheme string) (*model.User, error) {
+	if err == authn.ErrInvalidCredentials {
+		return nil, errors.Unauthenticated(scheme)
+	}
+
+	if err != nil {
+		log.Error().
+			Err(err).
+			Str("scheme", scheme).
+			Msg("failed to authenticate principal")
+
+		return nil, errors.New(http.StatusInternalServerError, "failed to authenticate")
+	}
+
+	return user, nil
+}
@@ -0,0 +1,206 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/go-openapi/runtime/middleware"
+	"github.com/go-openapi/strfmt"
+	"github.com/rs/zerolog/log"
+	"github.com/umschlag/umschlag-api/pkg/api/v1/models"
+	"github.com/umschlag/umschlag-api/pkg/api/v1/restapi/operations/repository"
+	"github.com/umschlag/umschlag-api/pkg/model"
+	"github.com/umschlag/umschlag-api/pkg/policy"
+	"github.com/umschlag/umschlag-api/pkg/registry/cleanup"
+	"github.com/umschlag/umschlag-api/pkg/store"
+)
+
+// ListRepositoryTagsHandler implements the handler for the RepositoryListRepositoryTags operation.
+func ListRepositoryTagsHandler(storage store.Store) repository.ListRepositoryTagsHandlerFunc {
+	return func(params repository.ListRepositoryTagsParams, principal *model.User) middleware.Responder {
+		ctx := params.HTTPRequest.Context()
+		ns, record, err := showRepository(ctx, storage, params.NamespaceID, params.RepositoryID)
+
+		if err == nil {
+			err = authorizeRepository(ctx, storage, principal, policy.RepositoryShow, ns, record)
+		}
+
+		var records []*model.Tag
+
+		if err == nil {
+			records, err = storage.Tags().List(ctx, record.ID)
+		}
+
+		switch {
+		case err == nil:
+			payload := make([]*models.Tag, 0, len(records))
+
+			for _, tag := range records {
+				payload = append(payload, convertTag(tag))
+			}
+
+			return repository.NewListRepositoryTagsOK().WithPayload(payload)
+		case forbidden(err):
+			return repository.NewListRepositoryTagsForbidden().WithPayload(
+				generalError(http.StatusForbidden, err.Error()),
+			)
+		case err == store.ErrNotFound:
+			return repository.NewListRepositoryTagsDefault(http.StatusNotFound).WithPayload(
+				generalError(http.StatusNotFound, "repository not found"),
+			)
+		}
+
+		log.Error().
+			Err(err).
+			Str("namespace", params.NamespaceID).
+			Str("repository", params.RepositoryID).
+			Msg("failed to fetch repository tags")
+
+		return repository.NewListRepositoryTagsDefault(http.StatusInternalServerError).WithPayload(
+			generalError(http.StatusInternalServerError, "failed to fetch repository tags"),
+		)
+	}
+}
+
+// DeleteRepositoryTagHandler implements the handler for the RepositoryDeleteRepositoryTag operation.
+func DeleteRepositoryTagHandler(storage store.Store, cleaner *cleanup.Cleaner) repository.DeleteRepositoryTagHandlerFunc {
+	return func(params repository.DeleteRepositoryTagParams, principal *model.User) middleware.Responder {
+		ctx := params.HTTPRequest.Context()
+		ns, record, err := showRepository(ctx, storage, params.NamespaceID, params.RepositoryID)
+
+		if err == nil {
+			err = authorize(ctx, storage, principal, policy.TagDelete, &policy.Target{
+				UserID: ns.UserID,
+				TeamID: ns.TeamID,
+			})
+		}
+
+		var result *cleanup.Result
+
+		if err == nil {
+			result, err = cleaner.Tag(ctx, ns, record, params.TagName, *params.DryRun)
+		}
+
+		switch {
+		case err == nil:
+			return repository.NewDeleteRepositoryTagOK().WithPayload(convertDeletion(result))
+		case forbidden(err):
+			return repository.NewDeleteRepositoryTagForbidden().WithPayload(
+				generalError(http.StatusForbidden, err.Error()),
+			)
+		case err == store.ErrNotFound:
+			return repository.NewDeleteRepositoryTagDefault(http.StatusNotFound).WithPayload(
+				generalError(http.StatusNotFound, "repository or tag not found"),
+			)
+		case err == cleanup.ErrImmutable:
+			return repository.NewDeleteRepositoryTagConflict().WithPayload(
+				generalError(http.StatusConflict, err.Error()),
+			)
+		case err == cleanup.ErrShared:
+			return repository.NewDeleteRepositoryTagUnprocessableEntity().WithPayload(
+				generalError(http.StatusUnprocessableEntity, err.Error()),
+			)
+		case err == cleanup.ErrUnconfigured:
+			return repository.NewDeleteRepositoryTagDefault(http.StatusServiceUnavailable).WithPayload(
+				generalError(http.StatusServiceUnavailable, err.Error()),
+			)
+		}
+
+		log.Error().
+			Err(err).
+			Str("namespace", params.NamespaceID).
+			Str("repository", params.RepositoryID).
+			Str("tag", params.TagName).
+			Msg("failed to delete repository tag")
+
+		return repository.NewDeleteRepositoryTagDefault(http.StatusInternalServerError).WithPayload(
+			generalError(http.StatusInternalServerError, "failed to delete repository tag"),
+		)
+	}
+}
+
+// DeleteRepositoryManifestHandler implements the handler for the RepositoryDeleteRepositoryManifest operation.
+func DeleteRepositoryManifestHandler(storage store.Store, cleaner *cleanup.Cleaner) repository.DeleteRepositoryManifestHandlerFunc {
+	return func(params repository.DeleteRepositoryManifestParams, principal *model.User) middleware.Responder {
+		ctx := params.HTTPRequest.Context()
+		ns, record, err := showRepository(ctx, storage, params.NamespaceID, params.RepositoryID)
+
+		if err == nil {
+			err = authorize(ctx, storage, principal, policy.ManifestDelete, &policy.Target{
+				UserID: ns.UserID,
+				TeamID: ns.TeamID,
+			})
+		}
+
+		var result *cleanup.Result
+
+		if err == nil {
+			result, err = cleaner.Manifest(ctx, ns, record, params.Digest, *params.DryRun)
+		}
+
+		switch {
+		case err == nil:
+			return repository.NewDeleteRepositoryManifestOK().WithPayload(convertDeletion(result))
+		case forbidden(err):
+			return repository.NewDeleteRepositoryManifestForbidden().WithPayload(
+				generalError(http.StatusForbidden, err.Error()),
+			)
+		case err == store.ErrNotFound:
+			return repository.NewDeleteRepositoryManifestDefault(http.StatusNotFound).WithPayload(
+				generalError(http.StatusNotFound, "repository or manifest not found"),
+			)
+		case err == cleanup.ErrImmutable:
+			return repository.NewDeleteRepositoryManifestConflict().WithPayload(
+				generalError(http.StatusConflict, err.Error()),
+			)
+		case err == cleanup.ErrUnconfigured:
+			return repository.NewDeleteRepositoryManifestDefault(http.StatusServiceUnavailable).WithPayload(
+				generalError(http.StatusServiceUnavailable, err.Error()),
+			)
+		}
+
+		log.Error().
+			Err(err).
+			Str("namespace", params.NamespaceID).
+			Str("repository", params.RepositoryID).
+			Str("digest", params.Digest).
+			Msg("failed to delete repository manifest")
+
+		return repository.NewDeleteRepositoryManifestDefault(http.StatusInternalServerError).WithPayload(
+			generalError(http.StatusInternalServerError, "failed to delete repository manifest"),
+		)
+	}
+}
+
+// convertTag converts a tag for responses.
+func convertTag(record *model.Tag) *models.Tag {
+	repositoryID := strfmt.UUID(record.RepositoryID)
+
+	result := &models.Tag{
+		RepositoryID: &repositoryID,
+		Name:         &record.Name,
+		Digest:       &record.Digest,
+		Pulls:        record.Pulls,
+		UpdatedAt:    strfmt.DateTime(record.UpdatedAt),
+	}
+
+	if record.PulledAt != nil {
+		pulledAt := strfmt.DateTime(*record.PulledAt)
+		result.PulledAt = &pulledAt
+	}
+
+	if record.OverwrittenAt != nil {
+		overwrittenAt := strfmt.DateTime(*record.OverwrittenAt)
+		result.OverwrittenAt = &overwrittenAt
+	}
+
+	return result
+}
+
+// convertDeletion converts a cleanup result for responses.
+func convertDeletion(result *cleanup.Result) *models.Deletion {
+	return &models.Deletion{
+		DryRun:    &result.DryRun,
+		Tags:      result.Tags,
+		Manifests: result.Manifests,
+	}
+}
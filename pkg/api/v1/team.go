@@ -0,0 +1,435 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/go-openapi/runtime/middleware"
+	"github.com/go-openapi/strfmt"
+	"github.com/rs/zerolog/log"
+	"github.com/umschlag/umschlag-api/pkg/api/v1/models"
+	"github.com/umschlag/umschlag-api/pkg/api/v1/restapi/operations/team"
+	"github.com/umschlag/umschlag-api/pkg/config"
+	"github.com/umschlag/umschlag-api/pkg/model"
+	"github.com/umschlag/umschlag-api/pkg/policy"
+	"github.com/umschlag/umschlag-api/pkg/store"
+)
+
+// ListTeamsHandler implements the handler for the TeamListTeams operation.
+func ListTeamsHandler(cfg *config.Config, storage store.Store) team.ListTeamsHandlerFunc {
+	return func(params team.ListTeamsParams, principal *model.User) middleware.Responder {
+		ctx := params.HTTPRequest.Context()
+		sub, err := subject(ctx, storage, principal)
+
+		if err == nil {
+			err = policy.Authorize(sub, policy.TeamList, nil)
+		}
+
+		if forbidden(err) {
+			return team.NewListTeamsForbidden().WithPayload(
+				generalError(http.StatusForbidden, err.Error()),
+			)
+		}
+
+		var records []*model.Team
+
+		if err == nil {
+			records, err = storage.Teams().List(ctx)
+		}
+
+		if err != nil {
+			log.Error().
+				Err(err).
+				Msg("failed to fetch teams")
+
+			return team.NewListTeamsDefault(http.StatusInternalServerError).WithPayload(
+				generalError(http.StatusInternalServerError, "failed to fetch teams"),
+			)
+		}
+
+		payload := make([]*models.Team, 0, len(records))
+
+		for _, record := range records {
+			if !principal.Admin && sub.Perm(record.ID) == "" {
+				continue
+			}
+
+			payload = append(payload, convertTeam(cfg, record))
+		}
+
+		return team.NewListTeamsOK().WithPayload(payload)
+	}
+}
+
+// ShowTeamHandler implements the handler for the TeamShowTeam operation.
+func ShowTeamHandler(cfg *config.Config, storage store.Store) team.ShowTeamHandlerFunc {
+	return func(params team.ShowTeamParams, principal *model.User) middleware.Responder {
+		ctx := params.HTTPRequest.Context()
+		record, err := storage.Teams().Show(ctx, params.TeamID)
+
+		if err == nil {
+			err = authorize(ctx, storage, principal, policy.TeamShow, &policy.Target{
+				TeamID: record.ID,
+			})
+		}
+
+		switch {
+		case err == nil:
+			return team.NewShowTeamOK().WithPayload(convertTeam(cfg, record))
+		case forbidden(err):
+			return team.NewShowTeamForbidden().WithPayload(
+				generalError(http.StatusForbidden, err.Error()),
+			)
+		case err == store.ErrNotFound:
+			return team.NewShowTeamDefault(http.StatusNotFound).WithPayload(
+				generalError(http.StatusNotFound, "team not found"),
+			)
+		}
+
+		log.Error().
+			Err(err).
+			Str("team", params.TeamID).
+			Msg("failed to fetch team")
+
+		return team.NewShowTeamDefault(http.StatusInternalServerError).WithPayload(
+			generalError(http.StatusInternalServerError, "failed to fetch team"),
+		)
+	}
+}
+
+// CreateTeamHandler implements the handler for the TeamCreateTeam operation.
+func CreateTeamHandler(cfg *config.Config, storage store.Store) team.CreateTeamHandlerFunc {
+	return func(params team.CreateTeamParams, principal *model.User) middleware.Responder {
+		ctx := params.HTTPRequest.Context()
+
+		if err := authorize(ctx, storage, principal, policy.TeamCreate, nil); forbidden(err) {
+			return team.NewCreateTeamForbidden().WithPayload(
+				generalError(http.StatusForbidden, err.Error()),
+			)
+		} else if err != nil {
+			log.Error().
+				Err(err).
+				Msg("failed to authorize team creation")
+
+			return team.NewCreateTeamDefault(http.StatusInternalServerError).WithPayload(
+				generalError(http.StatusInternalServerError, "failed to create team"),
+			)
+		}
+
+		record, err := storage.Teams().Create(ctx, &model.Team{
+			Slug: params.Team.Slug,
+			Name: *params.Team.Name,
+		})
+
+		if err == store.ErrDuplicateSlug {
+			return team.NewCreateTeamUnprocessableEntity().WithPayload(
+				validationError("slug", "is already taken"),
+			)
+		}
+
+		if err == nil {
+			err = storage.Members().Assign(ctx, record.ID, principal.ID, model.PermOwner)
+		}
+
+		if err != nil {
+			log.Error().
+				Err(err).
+				Msg("failed to create team")
+
+			return team.NewCreateTeamDefault(http.StatusInternalServerError).WithPayload(
+				generalError(http.StatusInternalServerError, "failed to create team"),
+			)
+		}
+
+		return team.NewCreateTeamOK().WithPayload(convertTeam(cfg, record))
+	}
+}
+
+// UpdateTeamHandler implements the handler for the TeamUpdateTeam operation.
+func UpdateTeamHandler(cfg *config.Config, storage store.Store) team.UpdateTeamHandlerFunc {
+	return func(params team.UpdateTeamParams, principal *model.User) middleware.Responder {
+		ctx := params.HTTPRequest.Context()
+		record, err := storage.Teams().Show(ctx, params.TeamID)
+
+		if err == nil {
+			err = authorize(ctx, storage, principal, policy.TeamUpdate, &policy.Target{
+				TeamID: record.ID,
+			})
+		}
+
+		if err == nil {
+			record.Slug = params.Team.Slug
+			record.Name = *params.Team.Name
+
+			record, err = storage.Teams().Update(ctx, record)
+		}
+
+		switch {
+		case err == nil:
+			return team.NewUpdateTeamOK().WithPayload(convertTeam(cfg, record))
+		case forbidden(err):
+			return team.NewUpdateTeamForbidden().WithPayload(
+				generalError(http.StatusForbidden, err.Error()),
+			)
+		case err == store.ErrNotFound:
+			return team.NewUpdateTeamDefault(http.StatusNotFound).WithPayload(
+				generalError(http.StatusNotFound, "team not found"),
+			)
+		case err == store.ErrDuplicateSlug:
+			return team.NewUpdateTeamUnprocessableEntity().WithPayload(
+				validationError("slug", "is already taken"),
+			)
+		}
+
+		log.Error().
+			Err(err).
+			Str("team", params.TeamID).
+			Msg("failed to update team")
+
+		return team.NewUpdateTeamDefault(http.StatusInternalServerError).WithPayload(
+			generalError(http.StatusInternalServerError, "failed to update team"),
+		)
+	}
+}
+
+// DeleteTeamHandler implements the handler for the TeamDeleteTeam operation.
+func DeleteTeamHandler(storage store.Store) team.DeleteTeamHandlerFunc {
+	return func(params team.DeleteTeamParams, principal *model.User) middleware.Responder {
+		ctx := params.HTTPRequest.Context()
+		record, err := storage.Teams().Show(ctx, params.TeamID)
+
+		if err == nil {
+			err = authorize(ctx, storage, principal, policy.TeamDelete, &policy.Target{
+				TeamID: record.ID,
+			})
+		}
+
+		if err == nil {
+			err = storage.Teams().Delete(ctx, record.ID)
+		}
+
+		switch {
+		case err == nil:
+			return team.NewDeleteTeamOK().WithPayload(
+				generalError(http.StatusOK, "successfully deleted team"),
+			)
+		case forbidden(err):
+			return team.NewDeleteTeamForbidden().WithPayload(
+				generalError(http.StatusForbidden, err.Error()),
+			)
+		case err == store.ErrNotFound:
+			return team.NewDeleteTeamDefault(http.StatusNotFound).WithPayload(
+				generalError(http.StatusNotFound, "team not found"),
+			)
+		}
+
+		log.Error().
+			Err(err).
+			Str("team", params.TeamID).
+			Msg("failed to delete team")
+
+		return team.NewDeleteTeamDefault(http.StatusInternalServerError).WithPayload(
+			generalError(http.StatusInternalServerError, "failed to delete team"),
+		)
+	}
+}
+
+// ListTeamUsersHandler implements the handler for the TeamListTeamUsers operation.
+func ListTeamUsersHandler(storage store.Store) team.ListTeamUsersHandlerFunc {
+	return func(params team.ListTeamUsersParams, principal *model.User) middleware.Responder {
+		ctx := params.HTTPRequest.Context()
+		record, err := storage.Teams().Show(ctx, params.TeamID)
+
+		if err == nil {
+			err = authorize(ctx, storage, principal, policy.TeamUsers, &policy.Target{
+				TeamID: record.ID,
+			})
+		}
+
+		var records []*model.TeamUser
+
+		if err == nil {
+			records, err = storage.Members().ListByTeam(ctx, record.ID)
+		}
+
+		switch {
+		case err == nil:
+			payload := make([]*models.TeamUser, 0, len(records))
+
+			for _, member := range records {
+				payload = append(payload, convertTeamUser(member))
+			}
+
+			return team.NewListTeamUsersOK().WithPayload(payload)
+		case forbidden(err):
+			return team.NewListTeamUsersForbidden().WithPayload(
+				generalError(http.StatusForbidden, err.Error()),
+			)
+		case err == store.ErrNotFound:
+			return team.NewListTeamUsersDefault(http.StatusNotFound).WithPayload(
+				generalError(http.StatusNotFound, "team not found"),
+			)
+		}
+
+		log.Error().
+			Err(err).
+			Str("team", params.TeamID).
+			Msg("failed to fetch team users")
+
+		return team.NewListTeamUsersDefault(http.StatusInternalServerError).WithPayload(
+			generalError(http.StatusInternalServerError, "failed to fetch team users"),
+		)
+	}
+}
+
+// AppendTeamToUserHandler implements the handler for the TeamAppendTeamToUser operation.
+func AppendTeamToUserHandler(storage store.Store) team.AppendTeamToUserHandlerFunc {
+	return func(params team.AppendTeamToUserParams, principal *model.User) middleware.Responder {
+		err := changeMember(
+			params.HTTPRequest.Context(),
+			storage,
+			principal,
+			policy.MemberAssign,
+			params.TeamID,
+			*params.TeamUser.User,
+			*params.TeamUser.Perm,
+		)
+
+		switch {
+		case err == nil:
+			return team.NewAppendTeamToUserOK().WithPayload(
+				generalError(http.StatusOK, "successfully assigned team to user"),
+			)
+		case forbidden(err):
+			return team.NewAppendTeamToUserForbidden().WithPayload(
+				generalError(http.StatusForbidden, err.Error()),
+			)
+		case err == store.ErrNotFound:
+			return team.NewAppendTeamToUserDefault(http.StatusNotFound).WithPayload(
+				generalError(http.StatusNotFound, "team or user not found"),
+			)
+		case err == store.ErrAlreadyAssigned:
+			return team.NewAppendTeamToUserUnprocessableEntity().WithPayload(
+				generalError(http.StatusUnprocessableEntity, "user is already assigned"),
+			)
+		}
+
+		log.Error().
+			Err(err).
+			Str("team", params.TeamID).
+			Msg("failed to assign team to user")
+
+		return team.NewAppendTeamToUserDefault(http.StatusInternalServerError).WithPayload(
+			generalError(http.StatusInternalServerError, "failed to assign team to user"),
+		)
+	}
+}
+
+// PermitTeamUserHandler implements the handler for the TeamPermitTeamUser operation.
+func PermitTeamUserHandler(storage store.Store) team.PermitTeamUserHandlerFunc {
+	return func(params team.PermitTeamUserParams, principal *model.User) middleware.Responder {
+		err := changeMember(
+			params.HTTPRequest.Context(),
+			storage,
+			principal,
+			policy.MemberPermit,
+			params.TeamID,
+			*params.TeamUser.User,
+			*params.TeamUser.Perm,
+		)
+
+		switch {
+		case err == nil:
+			return team.NewPermitTeamUserOK().WithPayload(
+				generalError(http.StatusOK, "successfully updated user perms"),
+			)
+		case forbidden(err):
+			return team.NewPermitTeamUserForbidden().WithPayload(
+				generalError(http.StatusForbidden, err.Error()),
+			)
+		case err == store.ErrNotFound:
+			return team.NewPermitTeamUserDefault(http.StatusNotFound).WithPayload(
+				generalError(http.StatusNotFound, "team or user not found"),
+			)
+		case err == store.ErrNotAssigned:
+			return team.NewPermitTeamUserUnprocessableEntity().WithPayload(
+				generalError(http.StatusUnprocessableEntity, "user is not assigned"),
+			)
+		}
+
+		log.Error().
+			Err(err).
+			Str("team", params.TeamID).
+			Msg("failed to update user perms")
+
+		return team.NewPermitTeamUserDefault(http.StatusInternalServerError).WithPayload(
+			generalError(http.StatusInternalServerError, "failed to update user perms"),
+		)
+	}
+}
+
+// DeleteTeamFromUserHandler implements the handler for the TeamDeleteTeamFromUser operation.
+func DeleteTeamFromUserHandler(storage store.Store) team.DeleteTeamFromUserHandlerFunc {
+	return func(params team.DeleteTeamFromUserParams, principal *model.User) middleware.Responder {
+		err := changeMember(
+			params.HTTPRequest.Context(),
+			storage,
+			principal,
+			policy.MemberUnassign,
+			params.TeamID,
+			*params.TeamUser.User,
+			"",
+		)
+
+		switch {
+		case err == nil:
+			return team.NewDeleteTeamFromUserOK().WithPayload(
+				generalError(http.StatusOK, "successfully removed user from team"),
+			)
+		case forbidden(err):
+			return team.NewDeleteTeamFromUserForbidden().WithPayload(
+				generalError(http.StatusForbidden, err.Error()),
+			)
+		case err == store.ErrNotFound:
+			return team.NewDeleteTeamFromUserDefault(http.StatusNotFound).WithPayload(
+				generalError(http.StatusNotFound, "team or user not found"),
+			)
+		case err == store.ErrNotAssigned:
+			return team.NewDeleteTeamFromUserUnprocessableEntity().WithPayload(
+				generalError(http.StatusUnprocessableEntity, "user is not assigned"),
+			)
+		}
+
+		log.Error().
+			Err(err).
+			Str("team", params.TeamID).
+			Msg("failed to remove user from team")
+
+		return team.NewDeleteTeamFromUserDefault(http.StatusInternalServerError).WithPayload(
+			generalError(http.StatusInternalServerError, "failed to remove user from team"),
+		)
+	}
+}
+
+// convertTeam converts a team for responses.
+func convertTeam(cfg *config.Config, record *model.Team) *models.Team {
+	return &models.Team{
+		ID:        strfmt.UUID(record.ID),
+		Slug:      record.Slug,
+		Name:      &record.Name,
+		AvatarURL: avatarURL(cfg, record.Avatar),
+		CreatedAt: strfmt.DateTime(record.CreatedAt),
+		UpdatedAt: strfmt.DateTime(record.UpdatedAt),
+	}
+}
+
+// convertTeamUser converts a membership for responses.
+func convertTeamUser(record *model.TeamUser) *models.TeamUser {
+	teamID := strfmt.UUID(record.TeamID)
+	userID := strfmt.UUID(record.UserID)
+
+	return &models.TeamUser{
+		TeamID: &teamID,
+		UserID: &userID,
+		Perm:   &record.Perm,
+	}
+}
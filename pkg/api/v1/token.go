@@ -0,0 +1,193 @@
+package v1
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/go-openapi/runtime/middleware"
+	"github.com/go-openapi/strfmt"
+	"github.com/rs/zerolog/log"
+	"github.com/umschlag/umschlag-api/pkg/api/v1/models"
+	"github.com/umschlag/umschlag-api/pkg/api/v1/restapi/operations/profile"
+	"github.com/umschlag/umschlag-api/pkg/authn"
+	"github.com/umschlag/umschlag-api/pkg/model"
+	"github.com/umschlag/umschlag-api/pkg/store"
+)
+
+const (
+	// errTokenPrincipal is returned if an access token tries to manage tokens.
+	errTokenPrincipal = "access tokens are not allowed to manage tokens"
+)
+
+// ListProfileTokensHandler implements the handler for the ProfileListProfileTokens operation.
+func ListProfileTokensHandler(storage store.Store) profile.ListProfileTokensHandlerFunc {
+	return func(params profile.ListProfileTokensParams, principal *model.User) middleware.Responder {
+		if principal.Token != nil {
+			return profile.NewListProfileTokensForbidden().WithPayload(
+				generalError(http.StatusForbidden, errTokenPrincipal),
+			)
+		}
+
+		records, err := storage.Tokens().List(params.HTTPRequest.Context(), principal.ID)
+
+		if err != nil {
+			log.Error().
+				Err(err).
+				Str("username", principal.Username).
+				Msg("failed to fetch access tokens")
+
+			return profile.NewListProfileTokensDefault(http.StatusInternalServerError).WithPayload(
+				generalError(http.StatusInternalServerError, "failed to fetch access tokens"),
+			)
+		}
+
+		payload := make([]*models.AccessToken, 0, len(records))
+
+		for _, record := range records {
+			payload = append(payload, convertToken(record))
+		}
+
+		return profile.NewListProfileTokensOK().WithPayload(payload)
+	}
+}
+
+// CreateProfileTokenHandler implements the handler for the ProfileCreateProfileToken operation.
+func CreateProfileTokenHandler(storage store.Store) profile.CreateProfileTokenHandlerFunc {
+	return func(params profile.CreateProfileTokenParams, principal *model.User) middleware.Responder {
+		if principal.Token != nil {
+			return profile.NewCreateProfileTokenForbidden().WithPayload(
+				generalError(http.StatusForbidden, errTokenPrincipal),
+			)
+		}
+
+		record := &model.AccessToken{
+			UserID: principal.ID,
+			Name:   *params.Token.Name,
+			Scopes: unique(params.Token.Scopes),
+		}
+
+		if params.Token.ExpiresAt != nil {
+			expires := time.Time(*params.Token.ExpiresAt).UTC()
+
+			if !expires.After(time.Now()) {
+				return profile.NewCreateProfileTokenUnprocessableEntity().WithPayload(
+					validationError("expires_at", "must be in the future"),
+				)
+			}
+
+			record.ExpiresAt = &expires
+		}
+
+		secret, hash, err := authn.GenerateToken()
+
+		if err != nil {
+			log.Error().
+				Err(err).
+				Str("username", principal.Username).
+				Msg("failed to generate access token")
+
+			return profile.NewCreateProfileTokenDefault(http.StatusInternalServerError).WithPayload(
+				generalError(http.StatusInternalServerError, "failed to create access token"),
+			)
+		}
+
+		record.Hash = hash
+		created, err := storage.Tokens().Create(params.HTTPRequest.Context(), record)
+
+		if err == store.ErrDuplicateName {
+			return profile.NewCreateProfileTokenUnprocessableEntity().WithPayload(
+				validationError("name", "is already taken"),
+			)
+		}
+
+		if err != nil {
+			log.Error().
+				Err(err).
+				Str("username", principal.Username).
+				Msg("failed to create access token")
+
+			return profile.NewCreateProfileTokenDefault(http.StatusInternalServerError).WithPayload(
+				generalError(http.StatusInternalServerError, "failed to create access token"),
+			)
+		}
+
+		payload := convertToken(created)
+		payload.Token = secret
+
+		return profile.NewCreateProfileTokenOK().WithPayload(payload)
+	}
+}
+
+// DeleteProfileTokenHandler implements the handler for the ProfileDeleteProfileToken operation.
+func DeleteProfileTokenHandler(storage store.Store) profile.DeleteProfileTokenHandlerFunc {
+	return func(params profile.DeleteProfileTokenParams, principal *model.User) middleware.Responder {
+		if principal.Token != nil {
+			return profile.NewDeleteProfileTokenForbidden().WithPayload(
+				generalError(http.StatusForbidden, errTokenPrincipal),
+			)
+		}
+
+		err := storage.Tokens().Delete(params.HTTPRequest.Context(), principal.ID, params.TokenID)
+
+		if err == store.ErrNotFound {
+			return profile.NewDeleteProfileTokenNotFound().WithPayload(
+				generalError(http.StatusNotFound, "access token not found"),
+			)
+		}
+
+		if err != nil {
+			log.Error().
+				Err(err).
+				Str("username", principal.Username).
+				Str("token", params.TokenID).
+				Msg("failed to delete access token")
+
+			return profile.NewDeleteProfileTokenDefault(http.StatusInternalServerError).WithPayload(
+				generalError(http.StatusInternalServerError, "failed to delete access token"),
+			)
+		}
+
+		return profile.NewDeleteProfileTokenOK().WithPayload(
+			generalError(http.StatusOK, "successfully deleted access token"),
+		)
+	}
+}
+
+// convertToken converts an access token for responses, the hash is never included.
+func convertToken(record *model.AccessToken) *models.AccessToken {
+	result := &models.AccessToken{
+		ID:        strfmt.UUID(record.ID),
+		Name:      &record.Name,
+		Scopes:    record.Scopes,
+		CreatedAt: strfmt.DateTime(record.CreatedAt),
+	}
+
+	if record.ExpiresAt != nil {
+		expires := strfmt.DateTime(*record.ExpiresAt)
+		result.ExpiresAt = &expires
+	}
+
+	if record.LastUsedAt != nil {
+		used := strfmt.DateTime(*record.LastUsedAt)
+		result.LastUsedAt = &used
+	}
+
+	return result
+}
+
+// unique drops duplicated values while keeping the order.
+func unique(values []string) []string {
+	result := make([]string, 0, len(values))
+	seen := make(map[string]bool, len(values))
+
+	for _, value := range values {
+		if seen[value] {
+			continue
+		}
+
+		seen[value] = true
+		result = append(result, value)
+	}
+
+	return result
+}
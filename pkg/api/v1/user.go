@@ -0,0 +1,560 @@
+package v1
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/go-openapi/runtime/middleware"
+	"github.com/go-openapi/strfmt"
+	"github.com/rs/zerolog/log"
+	"github.com/umschlag/umschlag-api/pkg/api/v1/models"
+	"github.com/umschlag/umschlag-api/pkg/api/v1/restapi/operations/user"
+	"github.com/umschlag/umschlag-api/pkg/authn"
+	"github.com/umschlag/umschlag-api/pkg/config"
+	"github.com/umschlag/umschlag-api/pkg/model"
+	"github.com/umschlag/umschlag-api/pkg/password"
+	"github.com/umschlag/umschlag-api/pkg/policy"
+	"github.com/umschlag/umschlag-api/pkg/store"
+)
+
+// ListUsersHandler implements the handler for the UserListUsers operation.
+func ListUsersHandler(cfg *config.Config, storage store.Store) user.ListUsersHandlerFunc {
+	return func(params user.ListUsersParams, principal *model.User) middleware.Responder {
+		ctx := params.HTTPRequest.Context()
+		err := authorize(ctx, storage, principal, policy.UserList, nil)
+
+		if forbidden(err) {
+			return user.NewListUsersForbidden().WithPayload(
+				generalError(http.StatusForbidden, err.Error()),
+			)
+		}
+
+		var records []*model.User
+
+		if err == nil {
+			records, err = storage.Users().List(ctx)
+		}
+
+		if err != nil {
+			log.Error().
+				Err(err).
+				Msg("failed to fetch users")
+
+			return user.NewListUsersDefault(http.StatusInternalServerError).WithPayload(
+				generalError(http.StatusInternalServerError, "failed to fetch users"),
+			)
+		}
+
+		payload := make([]*models.PublicUser, 0, len(records))
+
+		for _, record := range records {
+			payload = append(payload, convertPublicUser(cfg, principal, record))
+		}
+
+		return user.NewListUsersOK().WithPayload(payload)
+	}
+}
+
+// ShowUserHandler implements the handler for the UserShowUser operation.
+func ShowUserHandler(cfg *config.Config, storage store.Store) user.ShowUserHandlerFunc {
+	return func(params user.ShowUserParams, principal *model.User) middleware.Responder {
+		ctx := params.HTTPRequest.Context()
+		record, err := storage.Users().Show(ctx, params.UserID)
+
+		if err == nil {
+			err = authorize(ctx, storage, principal, policy.UserShow, &policy.Target{
+				UserID: record.ID,
+			})
+		}
+
+		switch {
+		case err == nil:
+			return user.NewShowUserOK().WithPayload(convertPublicUser(cfg, principal, record))
+		case forbidden(err):
+			return user.NewShowUserForbidden().WithPayload(
+				generalError(http.StatusForbidden, err.Error()),
+			)
+		case err == store.ErrNotFound:
+			return user.NewShowUserDefault(http.StatusNotFound).WithPayload(
+				generalError(http.StatusNotFound, "user not found"),
+			)
+		}
+
+		log.Error().
+			Err(err).
+			Str("user", params.UserID).
+			Msg("failed to fetch user")
+
+		return user.NewShowUserDefault(http.StatusInternalServerError).WithPayload(
+			generalError(http.StatusInternalServerError, "failed to fetch user"),
+		)
+	}
+}
+
+// CreateUserHandler implements the handler for the UserCreateUser operation.
+func CreateUserHandler(cfg *config.Config, storage store.Store) user.CreateUserHandlerFunc {
+	return func(params user.CreateUserParams, principal *model.User) middleware.Responder {
+		ctx := params.HTTPRequest.Context()
+
+		if err := authorize(ctx, storage, principal, policy.UserCreate, nil); forbidden(err) {
+			return user.NewCreateUserForbidden().WithPayload(
+				generalError(http.StatusForbidden, err.Error()),
+			)
+		} else if err != nil {
+			log.Error().
+				Err(err).
+				Msg("failed to authorize user creation")
+
+			return user.NewCreateUserDefault(http.StatusInternalServerError).WithPayload(
+				generalError(http.StatusInternalServerError, "failed to create user"),
+			)
+		}
+
+		if params.User.Password == "" {
+			return user.NewCreateUserUnprocessableEntity().WithPayload(
+				validationError("password", "is required"),
+			)
+		}
+
+		hash, err := password.Hash(params.User.Password.String(), cfg.Password.Cost)
+
+		if err == nil {
+			var record *model.User
+
+			record, err = storage.Users().Create(ctx, &model.User{
+				Slug:     params.User.Slug,
+				Username: *params.User.Username,
+				Password: hash,
+				Email:    *params.User.Email,
+				Admin:    params.User.Admin,
+				Active:   params.User.Active,
+			})
+
+			if err == nil {
+				return user.NewCreateUserOK().WithPayload(convertUser(cfg, record))
+			}
+		}
+
+		if field, ok := duplicateField(err); ok {
+			return user.NewCreateUserUnprocessableEntity().WithPayload(
+				validationError(field, "is already taken"),
+			)
+		}
+
+		log.Error().
+			Err(err).
+			Msg("failed to create user")
+
+		return user.NewCreateUserDefault(http.StatusInternalServerError).WithPayload(
+			generalError(http.StatusInternalServerError, "failed to create user"),
+		)
+	}
+}
+
+// UpdateUserHandler implements the handler for the UserUpdateUser operation.
+func UpdateUserHandler(cfg *config.Config, storage store.Store) user.UpdateUserHandlerFunc {
+	return func(params user.UpdateUserParams, principal *model.User) middleware.Responder {
+		ctx := params.HTTPRequest.Context()
+		record, err := storage.Users().Show(ctx, params.UserID)
+
+		if err == nil {
+			err = authorize(ctx, storage, principal, policy.UserUpdate, &policy.Target{
+				UserID: record.ID,
+			})
+		}
+
+		if err == nil {
+			record.Slug = params.User.Slug
+			record.Username = *params.User.Username
+			record.Email = *params.User.Email
+			record.Admin = params.User.Admin
+			record.Active = params.User.Active
+
+			if params.User.Password != "" {
+				record.Password, err = password.Hash(params.User.Password.String(), cfg.Password.Cost)
+			}
+		}
+
+		if err == nil {
+			record, err = storage.Users().Update(ctx, record)
+		}
+
+		if field, ok := duplicateField(err); ok {
+			return user.NewUpdateUserUnprocessableEntity().WithPayload(
+				validationError(field, "is already taken"),
+			)
+		}
+
+		switch {
+		case err == nil:
+			return user.NewUpdateUserOK().WithPayload(convertUser(cfg, record))
+		case forbidden(err):
+			return user.NewUpdateUserForbidden().WithPayload(
+				generalError(http.StatusForbidden, err.Error()),
+			)
+		case err == store.ErrNotFound:
+			return user.NewUpdateUserDefault(http.StatusNotFound).WithPayload(
+				generalError(http.StatusNotFound, "user not found"),
+			)
+		}
+
+		log.Error().
+			Err(err).
+			Str("user", params.UserID).
+			Msg("failed to update user")
+
+		return user.NewUpdateUserDefault(http.StatusInternalServerError).WithPayload(
+			generalError(http.StatusInternalServerError, "failed to update user"),
+		)
+	}
+}
+
+// LogoutUserHandler implements the handler for the UserLogoutUser operation.
+func LogoutUserHandler(storage store.Store, authenticator *authn.Authenticator) user.LogoutUserHandlerFunc {
+	return func(params user.LogoutUserParams, principal *model.User) middleware.Responder {
+		before, ok := logoutBefore(params.Params)
+
+		if !ok {
+			return user.NewLogoutUserUnprocessableEntity().WithPayload(
+				validationError("before", "must not be in the future"),
+			)
+		}
+
+		ctx := params.HTTPRequest.Context()
+		record, err := storage.Users().Show(ctx, params.UserID)
+
+		if err == nil {
+			err = authorize(ctx, storage, principal, policy.UserUpdate, &policy.Target{
+				UserID: record.ID,
+			})
+		}
+
+		if err == nil {
+			err = authenticator.RevokeBefore(ctx, record, before)
+		}
+
+		switch {
+		case err == nil:
+			return user.NewLogoutUserOK().WithPayload(
+				generalError(http.StatusOK, "successfully logged out user everywhere"),
+			)
+		case forbidden(err):
+			return user.NewLogoutUserForbidden().WithPayload(
+				generalError(http.StatusForbidden, err.Error()),
+			)
+		case err == store.ErrNotFound:
+			return user.NewLogoutUserDefault(http.StatusNotFound).WithPayload(
+				generalError(http.StatusNotFound, "user not found"),
+			)
+		}
+
+		log.Error().
+			Err(err).
+			Str("user", params.UserID).
+			Msg("failed to revoke user tokens")
+
+		return user.NewLogoutUserDefault(http.StatusInternalServerError).WithPayload(
+			generalError(http.StatusInternalServerError, "failed to revoke tokens"),
+		)
+	}
+}
+
+// DeleteUserHandler implements the handler for the UserDeleteUser operation.
+func DeleteUserHandler(storage store.Store) user.DeleteUserHandlerFunc {
+	return func(params user.DeleteUserParams, principal *model.User) middleware.Responder {
+		ctx := params.HTTPRequest.Context()
+		record, err := storage.Users().Show(ctx, params.UserID)
+
+		if err == nil {
+			err = authorize(ctx, storage, principal, policy.UserDelete, &policy.Target{
+				UserID: record.ID,
+			})
+		}
+
+		if err == nil {
+			err = ownerships(ctx, storage, principal, record)
+		}
+
+		if err == nil {
+			err = storage.Users().Delete(ctx, record.ID)
+		}
+
+		switch {
+		case err == nil:
+			return user.NewDeleteUserOK().WithPayload(
+				generalError(http.StatusOK, "successfully deleted user"),
+			)
+		case forbidden(err):
+			return user.NewDeleteUserForbidden().WithPayload(
+				generalError(http.StatusForbidden, err.Error()),
+			)
+		case err == store.ErrNotFound:
+			return user.NewDeleteUserDefault(http.StatusNotFound).WithPayload(
+				generalError(http.StatusNotFound, "user not found"),
+			)
+		}
+
+		log.Error().
+			Err(err).
+			Str("user", params.UserID).
+			Msg("failed to delete user")
+
+		return user.NewDeleteUserDefault(http.StatusInternalServerError).WithPayload(
+			generalError(http.StatusInternalServerError, "failed to delete user"),
+		)
+	}
+}
+
+// ListUserTeamsHandler implements the handler for the UserListUserTeams operation.
+func ListUserTeamsHandler(storage store.Store) user.ListUserTeamsHandlerFunc {
+	return func(params user.ListUserTeamsParams, principal *model.User) middleware.Responder {
+		ctx := params.HTTPRequest.Context()
+		record, err := storage.Users().Show(ctx, params.UserID)
+
+		if err == nil {
+			err = authorize(ctx, storage, principal, policy.UserTeams, &policy.Target{
+				UserID: record.ID,
+			})
+		}
+
+		var records []*model.TeamUser
+
+		if err == nil {
+			records, err = storage.Members().ListByUser(ctx, record.ID)
+		}
+
+		switch {
+		case err == nil:
+			payload := make([]*models.TeamUser, 0, len(records))
+
+			for _, member := range records {
+				payload = append(payload, convertTeamUser(member))
+			}
+
+			return user.NewListUserTeamsOK().WithPayload(payload)
+		case forbidden(err):
+			return user.NewListUserTeamsForbidden().WithPayload(
+				generalError(http.StatusForbidden, err.Error()),
+			)
+		case err == store.ErrNotFound:
+			return user.NewListUserTeamsDefault(http.StatusNotFound).WithPayload(
+				generalError(http.StatusNotFound, "user not found"),
+			)
+		}
+
+		log.Error().
+			Err(err).
+			Str("user", params.UserID).
+			Msg("failed to fetch user teams")
+
+		return user.NewListUserTeamsDefault(http.StatusInternalServerError).WithPayload(
+			generalError(http.StatusInternalServerError, "failed to fetch user teams"),
+		)
+	}
+}
+
+// AppendUserToTeamHandler implements the handler for the UserAppendUserToTeam operation.
+func AppendUserToTeamHandler(storage store.Store) user.AppendUserToTeamHandlerFunc {
+	return func(params user.AppendUserToTeamParams, principal *model.User) middleware.Responder {
+		err := changeMember(
+			params.HTTPRequest.Context(),
+			storage,
+			principal,
+			policy.MemberAssign,
+			*params.UserTeam.Team,
+			params.UserID,
+			*params.UserTeam.Perm,
+		)
+
+		switch {
+		case err == nil:
+			return user.NewAppendUserToTeamOK().WithPayload(
+				generalError(http.StatusOK, "successfully assigned user to team"),
+			)
+		case forbidden(err):
+			return user.NewAppendUserToTeamForbidden().WithPayload(
+				generalError(http.StatusForbidden, err.Error()),
+			)
+		case err == store.ErrNotFound:
+			return user.NewAppendUserToTeamDefault(http.StatusNotFound).WithPayload(
+				generalError(http.StatusNotFound, "team or user not found"),
+			)
+		case err == store.ErrAlreadyAssigned:
+			return user.NewAppendUserToTeamUnprocessableEntity().WithPayload(
+				generalError(http.StatusUnprocessableEntity, "team is already assigned"),
+			)
+		}
+
+		log.Error().
+			Err(err).
+			Str("user", params.UserID).
+			Msg("failed to assign user to team")
+
+		return user.NewAppendUserToTeamDefault(http.StatusInternalServerError).WithPayload(
+			generalError(http.StatusInternalServerError, "failed to assign user to team"),
+		)
+	}
+}
+
+// PermitUserTeamHandler implements the handler for the UserPermitUserTeam operation.
+func PermitUserTeamHandler(storage store.Store) user.PermitUserTeamHandlerFunc {
+	return func(params user.PermitUserTeamParams, principal *model.User) middleware.Responder {
+		err := changeMember(
+			params.HTTPRequest.Context(),
+			storage,
+			principal,
+			policy.MemberPermit,
+			*params.UserTeam.Team,
+			params.UserID,
+			*params.UserTeam.Perm,
+		)
+
+		switch {
+		case err == nil:
+			return user.NewPermitUserTeamOK().WithPayload(
+				generalError(http.StatusOK, "successfully updated team perms"),
+			)
+		case forbidden(err):
+			return user.NewPermitUserTeamForbidden().WithPayload(
+				generalError(http.StatusForbidden, err.Error()),
+			)
+		case err == store.ErrNotFound:
+			return user.NewPermitUserTeamDefault(http.StatusNotFound).WithPayload(
+				generalError(http.StatusNotFound, "team or user not found"),
+			)
+		case err == store.ErrNotAssigned:
+			return user.NewPermitUserTeamUnprocessableEntity().WithPayload(
+				generalError(http.StatusUnprocessableEntity, "team is not assigned"),
+			)
+		}
+
+		log.Error().
+			Err(err).
+			Str("user", params.UserID).
+			Msg("failed to update team perms")
+
+		return user.NewPermitUserTeamDefault(http.StatusInternalServerError).WithPayload(
+			generalError(http.StatusInternalServerError, "failed to update team perms"),
+		)
+	}
+}
+
+// DeleteUserFromTeamHandler implements the handler for the UserDeleteUserFromTeam operation.
+func DeleteUserFromTeamHandler(storage store.Store) user.DeleteUserFromTeamHandlerFunc {
+	return func(params user.DeleteUserFromTeamParams, principal *model.User) middleware.Responder {
+		err := changeMember(
+			params.HTTPRequest.Context(),
+			storage,
+			principal,
+			policy.MemberUnassign,
+			*params.UserTeam.Team,
+			params.UserID,
+			"",
+		)
+
+		switch {
+		case err == nil:
+			return user.NewDeleteUserFromTeamOK().WithPayload(
+				generalError(http.StatusOK, "successfully removed team from user"),
+			)
+		case forbidden(err):
+			return user.NewDeleteUserFromTeamForbidden().WithPayload(
+				generalError(http.StatusForbidden, err.Error()),
+			)
+		case err == store.ErrNotFound:
+			return user.NewDeleteUserFromTeamDefault(http.StatusNotFound).WithPayload(
+				generalError(http.StatusNotFound, "team or user not found"),
+			)
+		case err == store.ErrNotAssigned:
+			return user.NewDeleteUserFromTeamUnprocessableEntity().WithPayload(
+				generalError(http.StatusUnprocessableEntity, "team is not assigned"),
+			)
+		}
+
+		log.Error().
+			Err(err).
+			Str("user", params.UserID).
+			Msg("failed to remove team from user")
+
+		return user.NewDeleteUserFromTeamDefault(http.StatusInternalServerError).WithPayload(
+			generalError(http.StatusInternalServerError, "failed to remove team from user"),
+		)
+	}
+}
+
+// ownerships checks that deleting the user doesn't leave teams without owner.
+func ownerships(ctx context.Context, storage store.Store, principal, record *model.User) error {
+	members, err := storage.Members().ListByUser(ctx, record.ID)
+
+	if err != nil {
+		return err
+	}
+
+	for _, member := range members {
+		if member.Perm != model.PermOwner {
+			continue
+		}
+
+		target, err := membership(ctx, storage, member.TeamID, record.ID, "")
+
+		if err != nil {
+			return err
+		}
+
+		if err := authorize(ctx, storage, principal, policy.MemberUnassign, target); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// duplicateField maps the uniqueness errors of the store to the fields.
+func duplicateField(err error) (string, bool) {
+	switch err {
+	case store.ErrDuplicateSlug:
+		return "slug", true
+	case store.ErrDuplicateUsername:
+		return "username", true
+	case store.ErrDuplicateEmail:
+		return "email", true
+	}
+
+	return "", false
+}
+
+// convertPublicUser converts a user for responses to other users, the email
+// is only included for the user itself and for admins.
+func convertPublicUser(cfg *config.Config, principal *model.User, record *model.User) *models.PublicUser {
+	result := &models.PublicUser{
+		ID:        strfmt.UUID(record.ID),
+		Slug:      record.Slug,
+		Username:  &record.Username,
+		Admin:     record.Admin,
+		Active:    record.Active,
+		AvatarURL: avatarURL(cfg, record.Avatar),
+		CreatedAt: strfmt.DateTime(record.CreatedAt),
+		UpdatedAt: strfmt.DateTime(record.UpdatedAt),
+	}
+
+	if principal.Admin || principal.ID == record.ID {
+		result.Email = record.Email
+	}
+
+	return result
+}
+
+// convertUser converts a user for responses, the password is never included.
+func convertUser(cfg *config.Config, record *model.User) *models.User {
+	return &models.User{
+		ID:        strfmt.UUID(record.ID),
+		Slug:      record.Slug,
+		Username:  &record.Username,
+		Email:     &record.Email,
+		Admin:     record.Admin,
+		Active:    record.Active,
+		AvatarURL: avatarURL(cfg, record.Avatar),
+		CreatedAt: strfmt.DateTime(record.CreatedAt),
+		UpdatedAt: strfmt.DateTime(record.UpdatedAt),
+	}
+}
@@ -0,0 +1,112 @@
+package v1
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/umschlag/umschlag-api/pkg/api/v1/models"
+	"github.com/umschlag/umschlag-api/pkg/api/v1/restapi/operations/user"
+	"github.com/umschlag/umschlag-api/pkg/config"
+	"github.com/umschlag/umschlag-api/pkg/model"
+	"github.com/umschlag/umschlag-api/pkg/store/memory"
+)
+
+func TestPublicUser(t *testing.T) {
+	ctx := context.Background()
+	dsn, _ := url.Parse("memory://")
+	storage := memory.Must(dsn)
+
+	users := make(map[string]*model.User)
+
+	for _, record := range []*model.User{
+		{Username: "admin", Email: "admin@example.com", Admin: true, Active: true},
+		{Username: "jane", Email: "jane@example.com", Active: true},
+		{Username: "john", Email: "john@example.com", Active: true},
+	} {
+		created, err := storage.Users().Create(ctx, record)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		users[created.Username] = created
+	}
+
+	cfg := &config.Config{}
+	list := ListUsersHandler(cfg, storage)
+	show := ShowUserHandler(cfg, storage)
+
+	tests := []struct {
+		name      string
+		principal string
+		target    string
+		email     string
+	}{
+		{
+			name:      "self",
+			principal: "jane",
+			target:    "jane",
+			email:     "jane@example.com",
+		},
+		{
+			name:      "admin",
+			principal: "admin",
+			target:    "jane",
+			email:     "jane@example.com",
+		},
+		{
+			name:      "other user",
+			principal: "john",
+			target:    "jane",
+			email:     "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			principal := users[tt.principal]
+			var payloads []*models.PublicUser
+
+			switch responder := show(user.ShowUserParams{
+				HTTPRequest: httptest.NewRequest(http.MethodGet, "/api/v1/users/"+tt.target, nil),
+				UserID:      tt.target,
+			}, principal).(type) {
+			case *user.ShowUserOK:
+				payloads = append(payloads, responder.Payload)
+			default:
+				t.Fatalf("unexpected show response %T", responder)
+			}
+
+			switch responder := list(user.ListUsersParams{
+				HTTPRequest: httptest.NewRequest(http.MethodGet, "/api/v1/users", nil),
+			}, principal).(type) {
+			case *user.ListUsersOK:
+				for _, payload := range responder.Payload {
+					if *payload.Username == tt.target {
+						payloads = append(payloads, payload)
+					}
+				}
+			default:
+				t.Fatalf("unexpected list response %T", responder)
+			}
+
+			if len(payloads) != 2 {
+				t.Fatalf("expected user within show and list, got %d", len(payloads))
+			}
+
+			for _, payload := range payloads {
+				if payload.Email != tt.email {
+					t.Errorf("expected email %q, got %q", tt.email, payload.Email)
+				}
+
+				if err := payload.Validate(strfmt.Default); err != nil {
+					t.Errorf("expected payload to match the schema, got %v", err)
+				}
+			}
+		})
+	}
+}
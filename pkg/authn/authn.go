@@ -0,0 +1,211 @@
+// Package authn resolves credentials to the users of the store.
+package authn
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+	"github.com/umschlag/umschlag-api/pkg/model"
+	"github.com/umschlag/umschlag-api/pkg/password"
+	"github.com/umschlag/umschlag-api/pkg/store"
+	"github.com/umschlag/umschlag-api/pkg/token"
+)
+
+var (
+	// ErrInvalidCredentials is returned if the credentials can't be used.
+	ErrInvalidCredentials = errors.New("invalid credentials")
+)
+
+// Authenticator verifies credentials against the store.
+type Authenticator struct {
+	storage store.Store
+	keys    *token.KeySet
+	cost    int
+}
+
+// Password authenticates an active user by username and password. Hashes
+// made with outdated parameters get replaced transparently. A personal
+// access token of the user is accepted in place of the password.
+func (a *Authenticator) Password(ctx context.Context, username, secret string) (*model.User, error) {
+	if IsToken(secret) {
+		user, err := a.AccessToken(ctx, secret)
+
+		if err == nil {
+			if !strings.EqualFold(user.Username, username) {
+				return nil, ErrInvalidCredentials
+			}
+
+			return user, nil
+		} else if err != ErrInvalidCredentials {
+			return nil, err
+		}
+	}
+
+	user, err := a.storage.Users().ByUsername(ctx, username)
+
+	if err == store.ErrNotFound {
+		return nil, ErrInvalidCredentials
+	} else if err != nil {
+		return nil, err
+	}
+
+	if !user.Active {
+		return nil, ErrInvalidCredentials
+	}
+
+	if err := password.Compare(user.Password, secret); err == password.ErrMismatch {
+		return nil, ErrInvalidCredentials
+	} else if err != nil {
+		return nil, err
+	}
+
+	if password.Outdated(user.Password, a.cost) {
+		a.rehash(ctx, user, secret)
+	}
+
+	return user, nil
+}
+
+// Token authenticates an active user by a signed token of one of the kinds.
+func (a *Authenticator) Token(ctx context.Context, raw string, kinds ...string) (*model.User, error) {
+	user, _, err := a.parse(ctx, raw, kinds)
+	return user, err
+}
+
+// Revoke invalidates a valid signed token of one of the kinds until it
+// expires, tokens without an ID can only be revoked by RevokeBefore.
+func (a *Authenticator) Revoke(ctx context.Context, raw string, kinds ...string) (*model.User, error) {
+	user, parsed, err := a.parse(ctx, raw, kinds)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if parsed.ID == "" {
+		return nil, ErrInvalidCredentials
+	}
+
+	revocation := &model.Revocation{
+		TokenID: parsed.ID,
+		UserID:  user.ID,
+	}
+
+	if !parsed.Expire.IsZero() {
+		expire := parsed.Expire.UTC()
+		revocation.ExpiresAt = &expire
+	}
+
+	if err := a.storage.Revocations().Revoke(ctx, revocation); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// RevokeBefore invalidates all signed tokens of a user issued before the
+// time, it gets truncated to the precision of the token issue dates.
+func (a *Authenticator) RevokeBefore(ctx context.Context, user *model.User, before time.Time) error {
+	return a.storage.Revocations().RevokeBefore(ctx, user.ID, before.Truncate(time.Millisecond))
+}
+
+// parse validates a signed token including the revocations and resolves
+// the related active user.
+func (a *Authenticator) parse(ctx context.Context, raw string, kinds []string) (*model.User, *token.Token, error) {
+	parsed, err := token.Direct(raw, a.keys.Lookup)
+
+	if err != nil {
+		return nil, nil, ErrInvalidCredentials
+	}
+
+	if !allowed(parsed.Kind, kinds) {
+		return nil, nil, ErrInvalidCredentials
+	}
+
+	user, err := a.storage.Users().Show(ctx, parsed.Text)
+
+	if err == store.ErrNotFound {
+		return nil, nil, ErrInvalidCredentials
+	} else if err != nil {
+		return nil, nil, err
+	}
+
+	if !user.Active {
+		return nil, nil, ErrInvalidCredentials
+	}
+
+	if parsed.ID != "" {
+		revoked, err := a.storage.Revocations().Revoked(ctx, parsed.ID)
+
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if revoked {
+			return nil, nil, ErrInvalidCredentials
+		}
+	}
+
+	before, err := a.storage.Revocations().RevokedBefore(ctx, user.ID)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !before.IsZero() && parsed.IssuedAt.Before(before) {
+		return nil, nil, ErrInvalidCredentials
+	}
+
+	return user, parsed, nil
+}
+
+// rehash stores a fresh hash for the user, failures only get logged as the
+// authentication itself already succeeded.
+func (a *Authenticator) rehash(ctx context.Context, user *model.User, secret string) {
+	hash, err := password.Hash(secret, a.cost)
+
+	if err != nil {
+		log.Error().
+			Err(err).
+			Str("username", user.Username).
+			Msg("failed to rehash password")
+
+		return
+	}
+
+	user.Password = hash
+
+	if _, err := a.storage.Users().Update(ctx, user); err != nil {
+		log.Error().
+			Err(err).
+			Str("username", user.Username).
+			Msg("failed to store rehashed password")
+
+		return
+	}
+
+	log.Debug().
+		Str("username", user.Username).
+		Msg("rehashed password")
+}
+
+// New initializes a new authenticator for the store.
+func New(storage store.Store, keys *token.KeySet, cost int) *Authenticator {
+	return &Authenticator{
+		storage: storage,
+		keys:    keys,
+		cost:    cost,
+	}
+}
+
+func allowed(kind string, kinds []string) bool {
+	for _, k := range kinds {
+		if k == kind {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,100 @@
+package authn
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/umschlag/umschlag-api/pkg/model"
+	"github.com/umschlag/umschlag-api/pkg/store"
+)
+
+const (
+	// TokenPrefix marks the secrets of personal access tokens.
+	TokenPrefix = "ums_"
+
+	// touchInterval limits how often the last usage gets written.
+	touchInterval = time.Minute
+)
+
+var (
+	encoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+)
+
+// GenerateToken creates a new random secret for a personal access token
+// and the hash that gets stored.
+func GenerateToken() (string, string, error) {
+	buf := make([]byte, 25)
+
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", err
+	}
+
+	secret := TokenPrefix + strings.ToLower(encoding.EncodeToString(buf))
+	return secret, HashToken(secret), nil
+}
+
+// HashToken calculates the hash of a personal access token secret.
+func HashToken(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// IsToken checks if the secret looks like a personal access token.
+func IsToken(secret string) bool {
+	return strings.HasPrefix(secret, TokenPrefix)
+}
+
+// AccessToken authenticates an active user by a personal access token, the
+// returned user is restricted to the scopes of the token.
+func (a *Authenticator) AccessToken(ctx context.Context, secret string) (*model.User, error) {
+	if !IsToken(secret) {
+		return nil, ErrInvalidCredentials
+	}
+
+	record, err := a.storage.Tokens().ByHash(ctx, HashToken(secret))
+
+	if err == store.ErrNotFound {
+		return nil, ErrInvalidCredentials
+	} else if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+
+	if record.Expired(now) {
+		return nil, ErrInvalidCredentials
+	}
+
+	user, err := a.storage.Users().Show(ctx, record.UserID)
+
+	if err == store.ErrNotFound {
+		return nil, ErrInvalidCredentials
+	} else if err != nil {
+		return nil, err
+	}
+
+	if !user.Active {
+		return nil, ErrInvalidCredentials
+	}
+
+	if record.LastUsedAt == nil || now.Sub(*record.LastUsedAt) >= touchInterval {
+		if err := a.storage.Tokens().Touch(ctx, record.ID, now); err != nil {
+			log.Error().
+				Err(err).
+				Str("username", user.Username).
+				Str("token", record.Name).
+				Msg("failed to update token usage")
+		} else {
+			record.LastUsedAt = &now
+		}
+	}
+
+	user.Token = record
+	return user, nil
+}
@@ -0,0 +1,188 @@
+package avatar
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/png"
+	"io"
+	"io/ioutil"
+	"net/http"
+
+	// Register the decoders for supported formats.
+	_ "image/gif"
+	_ "image/jpeg"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// MaxBytes defines the maximum size of uploaded images.
+	MaxBytes = 2 << 20
+
+	// MaxPixels defines the maximum width and height of uploaded images.
+	MaxPixels = 4096
+
+	// Default defines the thumbnail size used for the avatar url.
+	Default = 256
+)
+
+var (
+	// ErrTooLarge defines the error if the upload exceeds MaxBytes.
+	ErrTooLarge = errors.New("image must not be larger than 2MiB")
+
+	// ErrUnsupported defines the error for unsupported image types.
+	ErrUnsupported = errors.New("image must be a png, jpeg or gif")
+
+	// ErrDimensions defines the error if the image is empty or exceeds MaxPixels.
+	ErrDimensions = errors.New("image must be between 1x1 and 4096x4096 pixels")
+
+	// Sizes defines the generated square thumbnail sizes.
+	Sizes = []int{64, 128, Default}
+
+	types = map[string]bool{
+		"image/png":  true,
+		"image/jpeg": true,
+		"image/gif":  true,
+	}
+)
+
+// Avatar represents a processed upload with the thumbnails per size.
+type Avatar struct {
+	Hash   string
+	Images map[int][]byte
+}
+
+// Key returns the upload key of a thumbnail.
+func Key(hash string, size int) string {
+	return fmt.Sprintf("avatars/%s/%d.png", hash, size)
+}
+
+// Process validates the uploaded image, crops it to a square and renders
+// the thumbnails as png. The hash gets calculated from the original upload.
+func Process(r io.Reader) (*Avatar, error) {
+	content, err := ioutil.ReadAll(io.LimitReader(r, MaxBytes+1))
+
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read image")
+	}
+
+	if len(content) > MaxBytes {
+		return nil, ErrTooLarge
+	}
+
+	if !types[http.DetectContentType(content)] {
+		return nil, ErrUnsupported
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(content))
+
+	if err != nil {
+		return nil, ErrUnsupported
+	}
+
+	if cfg.Width < 1 || cfg.Height < 1 || cfg.Width > MaxPixels || cfg.Height > MaxPixels {
+		return nil, ErrDimensions
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(content))
+
+	if err != nil {
+		return nil, ErrUnsupported
+	}
+
+	hash := sha256.Sum256(content)
+	square := crop(img)
+
+	result := &Avatar{
+		Hash:   hex.EncodeToString(hash[:]),
+		Images: make(map[int][]byte, len(Sizes)),
+	}
+
+	for _, size := range Sizes {
+		buf := bytes.NewBuffer(nil)
+
+		if err := png.Encode(buf, resize(square, size)); err != nil {
+			return nil, errors.Wrap(err, "failed to encode thumbnail")
+		}
+
+		result.Images[size] = buf.Bytes()
+	}
+
+	return result, nil
+}
+
+// crop cuts the centered square out of the image.
+func crop(img image.Image) *image.RGBA {
+	bounds := img.Bounds()
+	side := bounds.Dx()
+
+	if bounds.Dy() < side {
+		side = bounds.Dy()
+	}
+
+	origin := image.Pt(
+		bounds.Min.X+(bounds.Dx()-side)/2,
+		bounds.Min.Y+(bounds.Dy()-side)/2,
+	)
+
+	result := image.NewRGBA(image.Rect(0, 0, side, side))
+	draw.Draw(result, result.Bounds(), img, origin, draw.Src)
+
+	return result
+}
+
+// resize scales the square image by averaging the covered source pixels,
+// smaller images simply get upscaled by the nearest pixel.
+func resize(src *image.RGBA, size int) *image.RGBA {
+	side := src.Bounds().Dx()
+	result := image.NewRGBA(image.Rect(0, 0, size, size))
+
+	for dy := 0; dy < size; dy++ {
+		y0, y1 := span(dy, size, side)
+
+		for dx := 0; dx < size; dx++ {
+			x0, x1 := span(dx, size, side)
+
+			var r, g, b, a, n int
+
+			for y := y0; y < y1; y++ {
+				offset := src.PixOffset(x0, y)
+
+				for x := x0; x < x1; x++ {
+					r += int(src.Pix[offset])
+					g += int(src.Pix[offset+1])
+					b += int(src.Pix[offset+2])
+					a += int(src.Pix[offset+3])
+
+					offset += 4
+					n++
+				}
+			}
+
+			offset := result.PixOffset(dx, dy)
+
+			result.Pix[offset] = uint8(r / n)
+			result.Pix[offset+1] = uint8(g / n)
+			result.Pix[offset+2] = uint8(b / n)
+			result.Pix[offset+3] = uint8(a / n)
+		}
+	}
+
+	return result
+}
+
+// span calculates the source range covered by a target pixel.
+func span(pos, size, side int) (int, int) {
+	start := pos * side / size
+	end := (pos + 1) * side / size
+
+	if end <= start {
+		end = start + 1
+	}
+
+	return start, end
+}
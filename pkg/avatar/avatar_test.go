@@ -0,0 +1,269 @@
+package avatar
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+)
+
+var (
+	red   = color.RGBA{R: 255, A: 255}
+	green = color.RGBA{G: 255, A: 255}
+	blue  = color.RGBA{B: 255, A: 255}
+)
+
+// encode renders an image of the size with vertical stripes of the colors.
+func encode(t *testing.T, width, height int, colors ...color.RGBA) []byte {
+	t.Helper()
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+
+	for x := 0; x < width; x++ {
+		for y := 0; y < height; y++ {
+			img.Set(x, y, colors[x*len(colors)/width])
+		}
+	}
+
+	buf := bytes.NewBuffer(nil)
+
+	if err := png.Encode(buf, img); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+// header returns a png with a valid header claiming the dimensions, but
+// without any decodable image data.
+func header(width, height uint32) []byte {
+	ihdr := make([]byte, 17)
+	copy(ihdr, "IHDR")
+	binary.BigEndian.PutUint32(ihdr[4:], width)
+	binary.BigEndian.PutUint32(ihdr[8:], height)
+	copy(ihdr[12:], []byte{8, 6, 0, 0, 0})
+
+	result := []byte("\x89PNG\r\n\x1a\n")
+	result = append(result, 0, 0, 0, 13)
+	result = append(result, ihdr...)
+	result = append(result, make([]byte, 4)...)
+	binary.BigEndian.PutUint32(result[len(result)-4:], crc32.ChecksumIEEE(ihdr))
+
+	return append(result, []byte("garbage instead of image data")...)
+}
+
+func TestProcessInvalid(t *testing.T) {
+	webp := append([]byte("RIFF\x00\x00\x00\x00WEBPVP8 "), make([]byte, 32)...)
+
+	tests := []struct {
+		name    string
+		content []byte
+		err     error
+	}{
+		{
+			name:    "too large",
+			content: append(encode(t, 1, 1, red), make([]byte, MaxBytes)...),
+			err:     ErrTooLarge,
+		},
+		{
+			name:    "webp",
+			content: webp,
+			err:     ErrUnsupported,
+		},
+		{
+			name:    "text",
+			content: []byte("definitely not an image"),
+			err:     ErrUnsupported,
+		},
+		{
+			name:    "too wide",
+			content: header(MaxPixels+1, 1),
+			err:     ErrDimensions,
+		},
+		{
+			name:    "too high",
+			content: header(1, MaxPixels+1),
+			err:     ErrDimensions,
+		},
+		{
+			name:    "empty",
+			content: header(0, 0),
+			err:     ErrUnsupported,
+		},
+		{
+			name:    "broken data",
+			content: header(16, 16),
+			err:     ErrUnsupported,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Process(bytes.NewReader(tt.content)); err != tt.err {
+				t.Errorf("expected %v, got %v", tt.err, err)
+			}
+		})
+	}
+}
+
+func TestProcess(t *testing.T) {
+	content := encode(t, 300, 100, red, green, blue)
+	result, err := Process(bytes.NewReader(content))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sum := sha256.Sum256(content)
+
+	if result.Hash != hex.EncodeToString(sum[:]) {
+		t.Errorf("expected hash of the upload, got %s", result.Hash)
+	}
+
+	if len(result.Images) != len(Sizes) {
+		t.Errorf("expected %d thumbnails, got %d", len(Sizes), len(result.Images))
+	}
+
+	for _, size := range []int{64, 128, 256} {
+		img, err := png.Decode(bytes.NewReader(result.Images[size]))
+
+		if err != nil {
+			t.Fatalf("failed to decode thumbnail %d: %v", size, err)
+		}
+
+		if bounds := img.Bounds(); bounds.Dx() != size || bounds.Dy() != size {
+			t.Errorf("expected thumbnail of %dx%d, got %v", size, size, bounds)
+		}
+
+		for _, pt := range []image.Point{{0, 0}, {size / 2, size / 2}, {size - 1, size - 1}} {
+			if c := color.RGBAModel.Convert(img.At(pt.X, pt.Y)); c != green {
+				t.Errorf("expected centered crop to be green at %v of %d, got %v", pt, size, c)
+			}
+		}
+	}
+
+	again, err := Process(bytes.NewReader(content))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if again.Hash != result.Hash {
+		t.Errorf("expected stable hash, got %s and %s", result.Hash, again.Hash)
+	}
+
+	other, err := Process(bytes.NewReader(encode(t, 300, 100, blue, green, red)))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if other.Hash == result.Hash {
+		t.Errorf("expected different uploads to differ in hash")
+	}
+}
+
+func TestProcessSmall(t *testing.T) {
+	result, err := Process(bytes.NewReader(encode(t, 2, 2, red, blue)))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	img, err := png.Decode(bytes.NewReader(result.Images[Default]))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if c := color.RGBAModel.Convert(img.At(0, 0)); c != red {
+		t.Errorf("expected upscaled left half to be red, got %v", c)
+	}
+
+	if c := color.RGBAModel.Convert(img.At(Default-1, Default-1)); c != blue {
+		t.Errorf("expected upscaled right half to be blue, got %v", c)
+	}
+}
+
+func TestCrop(t *testing.T) {
+	tests := []struct {
+		name   string
+		width  int
+		height int
+	}{
+		{name: "landscape", width: 30, height: 10},
+		{name: "portrait", width: 10, height: 30},
+		{name: "square", width: 10, height: 10},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := image.NewRGBA(image.Rect(0, 0, tt.width, tt.height))
+			center := image.Rect(
+				(tt.width-10)/2,
+				(tt.height-10)/2,
+				(tt.width-10)/2+10,
+				(tt.height-10)/2+10,
+			)
+
+			for x := 0; x < tt.width; x++ {
+				for y := 0; y < tt.height; y++ {
+					if image.Pt(x, y).In(center) {
+						src.Set(x, y, green)
+					} else {
+						src.Set(x, y, red)
+					}
+				}
+			}
+
+			result := crop(src)
+
+			if bounds := result.Bounds(); bounds != image.Rect(0, 0, 10, 10) {
+				t.Fatalf("expected square of 10 pixels, got %v", bounds)
+			}
+
+			for x := 0; x < 10; x++ {
+				for y := 0; y < 10; y++ {
+					if c := result.RGBAAt(x, y); c != green {
+						t.Fatalf("expected centered square at %d,%d, got %v", x, y, c)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestSpan(t *testing.T) {
+	tests := []struct {
+		name  string
+		pos   int
+		size  int
+		side  int
+		start int
+		end   int
+	}{
+		{name: "downscale first", pos: 0, size: 64, side: 256, start: 0, end: 4},
+		{name: "downscale last", pos: 63, size: 64, side: 256, start: 252, end: 256},
+		{name: "uneven", pos: 1, size: 64, side: 100, start: 1, end: 3},
+		{name: "same size", pos: 5, size: 64, side: 64, start: 5, end: 6},
+		{name: "upscale first", pos: 0, size: 256, side: 10, start: 0, end: 1},
+		{name: "upscale within pixel", pos: 1, size: 256, side: 10, start: 0, end: 1},
+		{name: "upscale last", pos: 255, size: 256, side: 10, start: 9, end: 10},
+		{name: "upscale single pixel", pos: 128, size: 256, side: 1, start: 0, end: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end := span(tt.pos, tt.size, tt.side)
+
+			if start != tt.start || end != tt.end {
+				t.Errorf("expected %d-%d, got %d-%d", tt.start, tt.end, start, end)
+			}
+		})
+	}
+}
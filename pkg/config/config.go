@@ -1,5 +1,9 @@
 package config
 
+import (
+	"time"
+)
+
 // Database defines the database configuration.
 type Database struct {
 	DSN string
@@ -27,10 +31,41 @@ type Metrics struct {
 
 // Admin defines the initial admin user configuration.
 type Admin struct {
-	Create   bool
-	Username string
-	Password string
-	Email    string
+	Create       bool
+	Username     string
+	Password     string
+	Email        string
+	AllowDefault bool
+}
+
+// Password defines the password hashing configuration.
+type Password struct {
+	Cost int
+}
+
+// Session defines the session token configuration.
+type Session struct {
+	Expire time.Duration
+	Prune  time.Duration
+}
+
+// Registry defines the docker distribution token, sync, retention and
+// notification configuration.
+type Registry struct {
+	Service     string
+	Issuer      string
+	Expire      time.Duration
+	URL         string
+	Sync        time.Duration
+	Retention   time.Duration
+	EventsToken string
+}
+
+// Keys defines the signing keys configuration.
+type Keys struct {
+	Path      string
+	Algorithm string
+	Retain    int
 }
 
 // Logs defines the level and color for log configuration.
@@ -53,6 +88,10 @@ type Config struct {
 	Server   Server
 	Metrics  Metrics
 	Admin    Admin
+	Password Password
+	Session  Session
+	Registry Registry
+	Keys     Keys
 	Logs     Logs
 	Tracing  Tracing
 }
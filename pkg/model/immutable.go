@@ -0,0 +1,53 @@
+package model
+
+import (
+	"path"
+	"time"
+)
+
+// Immutable represents an immutable tag rule of a namespace. Tags matching
+// the glob pattern must not be overwritten or deleted, exclusions take
+// precedence over all other rules of the namespace.
+type Immutable struct {
+	ID          string    `json:"id"`
+	NamespaceID string    `json:"namespace_id"`
+	Pattern     string    `json:"pattern"`
+	Exclude     bool      `json:"exclude"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// Valid checks if the pattern is a valid glob pattern.
+func (i *Immutable) Valid() bool {
+	if i.Pattern == "" {
+		return false
+	}
+
+	_, err := path.Match(i.Pattern, "")
+	return err == nil
+}
+
+// Matches checks if the tag name matches the pattern.
+func (i *Immutable) Matches(name string) bool {
+	matched, err := path.Match(i.Pattern, name)
+	return err == nil && matched
+}
+
+// Immutables checks if a tag name is immutable based on the rules of a
+// namespace, at least one rule has to match and no exclusion.
+func Immutables(rules []*Immutable, name string) bool {
+	result := false
+
+	for _, rule := range rules {
+		if !rule.Matches(name) {
+			continue
+		}
+
+		if rule.Exclude {
+			return false
+		}
+
+		result = true
+	}
+
+	return result
+}
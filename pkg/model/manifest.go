@@ -0,0 +1,24 @@
+package model
+
+import (
+	"time"
+)
+
+// Blob represents a config or layer blob referenced by a manifest.
+type Blob struct {
+	Digest string `json:"digest"`
+	Size   int64  `json:"size"`
+}
+
+// Manifest represents an image manifest within a repository. The size is
+// the sum of all referenced blobs, CreatedAt is the creation date of the
+// image if it could be detected from the config.
+type Manifest struct {
+	RepositoryID string     `json:"repository_id"`
+	Digest       string     `json:"digest"`
+	MediaType    string     `json:"media_type"`
+	Size         int64      `json:"size"`
+	Blobs        []*Blob    `json:"blobs"`
+	CreatedAt    *time.Time `json:"created_at"`
+	SyncedAt     time.Time  `json:"synced_at"`
+}
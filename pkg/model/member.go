@@ -0,0 +1,21 @@
+package model
+
+const (
+	// PermUser is the permission level of a regular team member.
+	PermUser = "user"
+
+	// PermAdmin is the permission level of a team administrator.
+	PermAdmin = "admin"
+
+	// PermOwner is the permission level of a team owner.
+	PermOwner = "owner"
+)
+
+// TeamUser represents the membership of a user within a team.
+type TeamUser struct {
+	TeamID string `json:"team_id"`
+	Team   *Team  `json:"-"`
+	UserID string `json:"user_id"`
+	User   *User  `json:"-"`
+	Perm   string `json:"perm"`
+}
@@ -0,0 +1,17 @@
+package model
+
+import (
+	"time"
+)
+
+// Namespace represents the first path component of repositories within the
+// registry, it's either owned by a user or by a team.
+type Namespace struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	UserID      string    `json:"user_id"`
+	TeamID      string    `json:"team_id"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
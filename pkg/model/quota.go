@@ -0,0 +1,61 @@
+package model
+
+import (
+	"time"
+)
+
+const (
+	// QuotaNamespace defines the kind of a namespace quota.
+	QuotaNamespace = "namespace"
+
+	// QuotaTeam defines the kind of a team quota, it covers all namespaces
+	// owned by the team.
+	QuotaTeam = "team"
+)
+
+// Quota represents the storage limits of a namespace or a team in bytes.
+// Pushes get denied once the usage reaches Hard, reaching Soft only warns,
+// zero disables a limit.
+type Quota struct {
+	Kind      string    `json:"kind"`
+	OwnerID   string    `json:"owner_id"`
+	Hard      int64     `json:"hard"`
+	Soft      int64     `json:"soft"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Valid checks if at least one limit is set and the soft limit doesn't
+// exceed the hard limit.
+func (q *Quota) Valid() bool {
+	if q.Hard < 0 || q.Soft < 0 || (q.Hard == 0 && q.Soft == 0) {
+		return false
+	}
+
+	return q.Hard == 0 || q.Soft <= q.Hard
+}
+
+// Exceeded checks if the usage reached the hard limit.
+func (q *Quota) Exceeded(size int64) bool {
+	return q.Hard > 0 && size >= q.Hard
+}
+
+// Warned checks if the usage reached the soft limit.
+func (q *Quota) Warned(size int64) bool {
+	return q.Soft > 0 && size >= q.Soft
+}
+
+// Usage represents the deduplicated storage usage of a namespace or a team
+// together with the usage of the contained repositories or namespaces.
+type Usage struct {
+	Size  int64        `json:"size"`
+	Items []*UsageItem `json:"items"`
+}
+
+// UsageItem represents the deduplicated storage usage of a single
+// repository or namespace.
+type UsageItem struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Size int64  `json:"size"`
+}
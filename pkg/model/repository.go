@@ -0,0 +1,48 @@
+package model
+
+import (
+	"time"
+)
+
+const (
+	// VisibilityPublic allows everybody to pull a repository.
+	VisibilityPublic = "public"
+
+	// VisibilityPrivate restricts a repository to the granted users.
+	VisibilityPrivate = "private"
+)
+
+const (
+	// RepoPull is the permission level to pull from a repository.
+	RepoPull = "pull"
+
+	// RepoPush is the permission level to push to a repository.
+	RepoPush = "push"
+
+	// RepoAdmin is the permission level to manage and delete a repository.
+	RepoAdmin = "admin"
+)
+
+// Repository represents a repository of the registry within a namespace.
+type Repository struct {
+	ID          string    `json:"id"`
+	NamespaceID string    `json:"namespace_id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Visibility  string    `json:"visibility"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// Public checks if the repository can be pulled by everybody.
+func (r *Repository) Public() bool {
+	return r.Visibility == VisibilityPublic
+}
+
+// RepositoryTeam represents the permission of a team on a repository.
+type RepositoryTeam struct {
+	RepositoryID string `json:"repository_id"`
+	TeamID       string `json:"team_id"`
+	Team         *Team  `json:"-"`
+	Perm         string `json:"perm"`
+}
@@ -0,0 +1,57 @@
+package model
+
+import (
+	"time"
+)
+
+const (
+	// ReportDeleted marks a report entry of a deleted manifest.
+	ReportDeleted = "deleted"
+
+	// ReportSkipped marks a report entry of a manifest which is still
+	// referenced by a protected or an immutable tag.
+	ReportSkipped = "skipped"
+
+	// ReportFailed marks a report entry of a failed deletion.
+	ReportFailed = "failed"
+)
+
+// Retention represents the retention policy of a namespace, or of a single
+// repository if RepositoryID is set. Tags are kept if they are within the
+// last KeepLast tags, match the KeepPattern or are younger than OlderThan
+// days, all other tags get deleted. The age is based on the creation date
+// of the image.
+type Retention struct {
+	ID           string    `json:"id"`
+	NamespaceID  string    `json:"namespace_id"`
+	RepositoryID string    `json:"repository_id"`
+	KeepLast     int       `json:"keep_last"`
+	KeepPattern  string    `json:"keep_pattern"`
+	OlderThan    int       `json:"older_than"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// Valid checks if at least one rule keeps tags, otherwise the policy would
+// delete all tags.
+func (r *Retention) Valid() bool {
+	return r.KeepLast > 0 || r.KeepPattern != "" || r.OlderThan > 0
+}
+
+// Report represents the result of a single run of a retention policy.
+type Report struct {
+	ID          string         `json:"id"`
+	RetentionID string         `json:"retention_id"`
+	StartedAt   time.Time      `json:"started_at"`
+	FinishedAt  time.Time      `json:"finished_at"`
+	Entries     []*ReportEntry `json:"entries"`
+}
+
+// ReportEntry represents the outcome for a manifest within a report.
+type ReportEntry struct {
+	Repository string   `json:"repository"`
+	Digest     string   `json:"digest"`
+	Tags       []string `json:"tags"`
+	Action     string   `json:"action"`
+	Message    string   `json:"message,omitempty"`
+}
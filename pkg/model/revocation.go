@@ -0,0 +1,18 @@
+package model
+
+import (
+	"time"
+)
+
+// Revocation represents a revoked token, it is kept until the token expires.
+type Revocation struct {
+	TokenID   string     `json:"token_id"`
+	UserID    string     `json:"user_id"`
+	ExpiresAt *time.Time `json:"expires_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// Expired checks if the revoked token is expired at the given time.
+func (r *Revocation) Expired(now time.Time) bool {
+	return r.ExpiresAt != nil && now.After(*r.ExpiresAt)
+}
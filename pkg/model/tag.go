@@ -0,0 +1,19 @@
+package model
+
+import (
+	"time"
+)
+
+// Tag represents a tag of a repository pointing to a manifest. OverwrittenAt
+// is set if an immutable tag got pointed to another manifest, CreatedAt is
+// set when the tag gets stored the first time and kept on later updates.
+type Tag struct {
+	RepositoryID  string     `json:"repository_id"`
+	Name          string     `json:"name"`
+	Digest        string     `json:"digest"`
+	Pulls         int64      `json:"pulls"`
+	PulledAt      *time.Time `json:"pulled_at,omitempty"`
+	OverwrittenAt *time.Time `json:"overwritten_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
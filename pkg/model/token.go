@@ -0,0 +1,69 @@
+package model
+
+import (
+	"time"
+)
+
+const (
+	// ScopeAPIRead grants read access to the API.
+	ScopeAPIRead = "api:read"
+
+	// ScopeAPIWrite grants read and write access to the API.
+	ScopeAPIWrite = "api:write"
+
+	// ScopeRegistryPull grants pull access to the registry.
+	ScopeRegistryPull = "registry:pull"
+
+	// ScopeRegistryPush grants pull and push access to the registry.
+	ScopeRegistryPush = "registry:push"
+)
+
+// Scopes lists all scopes available for access tokens.
+var Scopes = []string{
+	ScopeAPIRead,
+	ScopeAPIWrite,
+	ScopeRegistryPull,
+	ScopeRegistryPush,
+}
+
+// AccessToken represents a personal access token of a user, only the hash
+// of the secret gets stored.
+type AccessToken struct {
+	ID         string     `json:"id"`
+	UserID     string     `json:"user_id"`
+	Name       string     `json:"name"`
+	Hash       string     `json:"hash"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// Expired checks if the token is expired at the given time.
+func (t *AccessToken) Expired(now time.Time) bool {
+	return t.ExpiresAt != nil && !now.Before(*t.ExpiresAt)
+}
+
+// HasScope checks if the token has been granted the scope.
+func (t *AccessToken) HasScope(scope string) bool {
+	for _, s := range t.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Grants checks if the token permits the scope, the write scopes imply the
+// matching read scopes.
+func (t *AccessToken) Grants(scope string) bool {
+	switch scope {
+	case ScopeAPIRead:
+		return t.HasScope(ScopeAPIRead) || t.HasScope(ScopeAPIWrite)
+	case ScopeRegistryPull:
+		return t.HasScope(ScopeRegistryPull) || t.HasScope(ScopeRegistryPush)
+	}
+
+	return t.HasScope(scope)
+}
@@ -0,0 +1,27 @@
+package model
+
+import (
+	"time"
+)
+
+// User represents a user within the system. Token is only set if the user
+// has been authenticated by an access token and never gets stored.
+type User struct {
+	ID        string       `json:"id"`
+	Slug      string       `json:"slug"`
+	Username  string       `json:"username"`
+	Password  string       `json:"password"`
+	Email     string       `json:"email"`
+	Admin     bool         `json:"admin"`
+	Active    bool         `json:"active"`
+	Avatar    string       `json:"avatar"`
+	CreatedAt time.Time    `json:"created_at"`
+	UpdatedAt time.Time    `json:"updated_at"`
+	Token     *AccessToken `json:"-"`
+}
+
+// Grants checks if the user may act within the scope, only users that got
+// authenticated by an access token are restricted.
+func (u *User) Grants(scope string) bool {
+	return u.Token == nil || u.Token.Grants(scope)
+}
@@ -0,0 +1,95 @@
+// Package password handles the hashing and verification of user passwords.
+package password
+
+import (
+	"crypto/subtle"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	// DefaultCost defines the bcrypt cost used if nothing else is configured.
+	DefaultCost = bcrypt.DefaultCost
+)
+
+var (
+	// ErrMismatch is returned if the password doesn't match the hash.
+	ErrMismatch = errors.New("password does not match")
+
+	// ErrEmpty is returned if an empty password should be hashed.
+	ErrEmpty = errors.New("password is empty")
+)
+
+// Hash generates a bcrypt hash of the password with the provided cost.
+func Hash(password string, cost int) (string, error) {
+	if password == "" {
+		return "", ErrEmpty
+	}
+
+	if cost < bcrypt.MinCost || cost > bcrypt.MaxCost {
+		cost = DefaultCost
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), cost)
+
+	if err != nil {
+		return "", errors.Wrap(err, "failed to hash password")
+	}
+
+	return string(hash), nil
+}
+
+// Compare checks the password against the stored hash. Values that are not
+// bcrypt hashes are treated as legacy plaintext passwords.
+func Compare(hash, password string) error {
+	if hash == "" || password == "" {
+		return ErrMismatch
+	}
+
+	if !Hashed(hash) {
+		if subtle.ConstantTimeCompare([]byte(hash), []byte(password)) != 1 {
+			return ErrMismatch
+		}
+
+		return nil
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)); err != nil {
+		if err == bcrypt.ErrMismatchedHashAndPassword {
+			return ErrMismatch
+		}
+
+		return errors.Wrap(err, "failed to compare password")
+	}
+
+	return nil
+}
+
+// Hashed checks if the value is a bcrypt hash.
+func Hashed(hash string) bool {
+	return strings.HasPrefix(hash, "$2a$") ||
+		strings.HasPrefix(hash, "$2b$") ||
+		strings.HasPrefix(hash, "$2y$")
+}
+
+// Outdated checks if the hash has been generated with other parameters than
+// the current cost, or if it isn't hashed at all.
+func Outdated(hash string, cost int) bool {
+	if !Hashed(hash) {
+		return true
+	}
+
+	if cost < bcrypt.MinCost || cost > bcrypt.MaxCost {
+		cost = DefaultCost
+	}
+
+	current, err := bcrypt.Cost([]byte(hash))
+
+	if err != nil {
+		return true
+	}
+
+	return current != cost
+}
@@ -0,0 +1,389 @@
+// Package policy defines the rules who is allowed to act on users, teams,
+// the memberships in between and the namespaces and repositories they own.
+package policy
+
+import (
+	"github.com/pkg/errors"
+	"github.com/umschlag/umschlag-api/pkg/model"
+)
+
+var (
+	// ErrForbidden is returned if the subject is not allowed to act.
+	ErrForbidden = errors.New("not allowed to access this resource")
+
+	// ErrLastOwner is returned if a team would lose its last owner.
+	ErrLastOwner = errors.New("team requires at least one owner")
+
+	// ErrUnknownAction is returned if there is no rule for an action.
+	ErrUnknownAction = errors.New("unknown action")
+)
+
+// Action defines the operations the policy can decide on.
+type Action string
+
+const (
+	// UserList permits to list all users.
+	UserList Action = "user:list"
+
+	// UserShow permits to show a user.
+	UserShow Action = "user:show"
+
+	// UserCreate permits to create new users.
+	UserCreate Action = "user:create"
+
+	// UserUpdate permits to update a user.
+	UserUpdate Action = "user:update"
+
+	// UserDelete permits to delete a user.
+	UserDelete Action = "user:delete"
+
+	// UserTeams permits to list the teams of a user.
+	UserTeams Action = "user:teams"
+
+	// TeamList permits to list teams.
+	TeamList Action = "team:list"
+
+	// TeamShow permits to show a team.
+	TeamShow Action = "team:show"
+
+	// TeamCreate permits to create new teams.
+	TeamCreate Action = "team:create"
+
+	// TeamUpdate permits to update a team.
+	TeamUpdate Action = "team:update"
+
+	// TeamDelete permits to delete a team.
+	TeamDelete Action = "team:delete"
+
+	// TeamUsers permits to list the users of a team.
+	TeamUsers Action = "team:users"
+
+	// MemberAssign permits to assign a user to a team.
+	MemberAssign Action = "member:assign"
+
+	// MemberPermit permits to change the perm of a membership.
+	MemberPermit Action = "member:permit"
+
+	// MemberUnassign permits to remove a user from a team.
+	MemberUnassign Action = "member:unassign"
+
+	// NamespaceList permits to list namespaces.
+	NamespaceList Action = "namespace:list"
+
+	// NamespaceShow permits to show a namespace.
+	NamespaceShow Action = "namespace:show"
+
+	// NamespaceCreate permits to create a namespace for an owner.
+	NamespaceCreate Action = "namespace:create"
+
+	// NamespaceUpdate permits to update a namespace.
+	NamespaceUpdate Action = "namespace:update"
+
+	// NamespaceDelete permits to delete a namespace.
+	NamespaceDelete Action = "namespace:delete"
+
+	// RepositoryShow permits to show a repository.
+	RepositoryShow Action = "repository:show"
+
+	// RepositoryCreate permits to create a repository within a namespace.
+	RepositoryCreate Action = "repository:create"
+
+	// RepositoryUpdate permits to update a repository.
+	RepositoryUpdate Action = "repository:update"
+
+	// RepositoryDelete permits to delete a repository.
+	RepositoryDelete Action = "repository:delete"
+
+	// RepositoryTeams permits to manage the team permissions of a repository.
+	RepositoryTeams Action = "repository:teams"
+
+	// TagDelete permits to delete tags of the repositories of a namespace.
+	TagDelete Action = "tag:delete"
+
+	// ManifestDelete permits to delete manifests of the repositories of a
+	// namespace.
+	ManifestDelete Action = "manifest:delete"
+
+	// RetentionShow permits to show the retention policies and reports of a
+	// namespace.
+	RetentionShow Action = "retention:show"
+
+	// RetentionUpdate permits to manage the retention policies of a
+	// namespace.
+	RetentionUpdate Action = "retention:update"
+
+	// ImmutableShow permits to show the immutable tag rules of a namespace.
+	ImmutableShow Action = "immutable:show"
+
+	// ImmutableUpdate permits to manage the immutable tag rules of a
+	// namespace.
+	ImmutableUpdate Action = "immutable:update"
+
+	// UsageShow permits to show the storage usage of a namespace or a team.
+	UsageShow Action = "usage:show"
+
+	// QuotaUpdate permits to manage the storage quotas of namespaces and
+	// teams.
+	QuotaUpdate Action = "quota:update"
+)
+
+// Subject is the authenticated user together with the memberships.
+type Subject struct {
+	User    *model.User
+	Members []*model.TeamUser
+}
+
+// Perm returns the perm of the subject within a team, empty if not assigned.
+func (s *Subject) Perm(teamID string) string {
+	for _, member := range s.Members {
+		if member.TeamID == teamID {
+			return member.Perm
+		}
+	}
+
+	return ""
+}
+
+// Target describes the resource an action is applied to. For memberships
+// Perm is the requested perm, Current the existing one and Owners the
+// number of owners the team currently has. For namespaces UserID or TeamID
+// is the owner, for repositories Access is the perm resolved by Access.
+type Target struct {
+	TeamID  string
+	UserID  string
+	Perm    string
+	Current string
+	Owners  int
+	Access  string
+}
+
+type rule func(*Subject, *Target) bool
+
+var rules = map[Action]rule{
+	UserList:   anyone,
+	UserShow:   anyone,
+	UserCreate: nobody,
+	UserUpdate: nobody,
+	UserDelete: nobody,
+	UserTeams: func(s *Subject, t *Target) bool {
+		return s.User.ID == t.UserID
+	},
+	TeamList:   anyone,
+	TeamCreate: anyone,
+	TeamShow: func(s *Subject, t *Target) bool {
+		return atLeast(s.Perm(t.TeamID), model.PermUser)
+	},
+	TeamUsers: func(s *Subject, t *Target) bool {
+		return atLeast(s.Perm(t.TeamID), model.PermUser)
+	},
+	TeamUpdate: func(s *Subject, t *Target) bool {
+		return atLeast(s.Perm(t.TeamID), model.PermAdmin)
+	},
+	TeamDelete: func(s *Subject, t *Target) bool {
+		return atLeast(s.Perm(t.TeamID), model.PermOwner)
+	},
+	MemberAssign: func(s *Subject, t *Target) bool {
+		return manages(s.Perm(t.TeamID), t.Perm)
+	},
+	MemberPermit: func(s *Subject, t *Target) bool {
+		perm := s.Perm(t.TeamID)
+		return manages(perm, t.Perm) && manages(perm, t.Current)
+	},
+	MemberUnassign: func(s *Subject, t *Target) bool {
+		if s.User.ID == t.UserID {
+			return true
+		}
+
+		return manages(s.Perm(t.TeamID), t.Current)
+	},
+	NamespaceList: anyone,
+	NamespaceShow: anyone,
+	NamespaceCreate: func(s *Subject, t *Target) bool {
+		return owns(s, t, model.PermAdmin)
+	},
+	NamespaceUpdate: func(s *Subject, t *Target) bool {
+		return owns(s, t, model.PermAdmin)
+	},
+	NamespaceDelete: func(s *Subject, t *Target) bool {
+		return owns(s, t, model.PermOwner)
+	},
+	RepositoryShow: func(s *Subject, t *Target) bool {
+		return grants(t.Access, model.RepoPull)
+	},
+	RepositoryCreate: func(s *Subject, t *Target) bool {
+		return grants(t.Access, model.RepoPush)
+	},
+	RepositoryUpdate: func(s *Subject, t *Target) bool {
+		return grants(t.Access, model.RepoAdmin)
+	},
+	RepositoryDelete: func(s *Subject, t *Target) bool {
+		return grants(t.Access, model.RepoAdmin)
+	},
+	RepositoryTeams: func(s *Subject, t *Target) bool {
+		return grants(t.Access, model.RepoAdmin)
+	},
+	TagDelete: func(s *Subject, t *Target) bool {
+		return owns(s, t, model.PermAdmin)
+	},
+	ManifestDelete: func(s *Subject, t *Target) bool {
+		return owns(s, t, model.PermAdmin)
+	},
+	RetentionShow: func(s *Subject, t *Target) bool {
+		return owns(s, t, model.PermUser)
+	},
+	RetentionUpdate: func(s *Subject, t *Target) bool {
+		return owns(s, t, model.PermAdmin)
+	},
+	ImmutableShow: func(s *Subject, t *Target) bool {
+		return owns(s, t, model.PermUser)
+	},
+	ImmutableUpdate: func(s *Subject, t *Target) bool {
+		return owns(s, t, model.PermAdmin)
+	},
+	UsageShow: func(s *Subject, t *Target) bool {
+		return owns(s, t, model.PermUser)
+	},
+	QuotaUpdate: nobody,
+}
+
+// Authorize decides if the subject may apply the action to the target.
+// Admins are allowed to do everything, but even they can't remove the last
+// owner of a team.
+func Authorize(s *Subject, action Action, t *Target) error {
+	fn, ok := rules[action]
+
+	if !ok {
+		return ErrUnknownAction
+	}
+
+	if t == nil {
+		t = &Target{}
+	}
+
+	if s == nil || s.User == nil || !s.User.Active {
+		return ErrForbidden
+	}
+
+	if !s.User.Admin && !fn(s, t) {
+		return ErrForbidden
+	}
+
+	if orphans(action, t) {
+		return ErrLastOwner
+	}
+
+	return nil
+}
+
+// Access resolves the perm of the subject on a repository, a nil repository
+// resolves the perm on the namespace itself. A nil subject represents an
+// anonymous client which is only able to pull public repositories.
+func Access(s *Subject, namespace *model.Namespace, repository *model.Repository, teams []*model.RepositoryTeam) string {
+	access := ""
+
+	if repository != nil && repository.Public() {
+		access = model.RepoPull
+	}
+
+	if s == nil || s.User == nil || !s.User.Active {
+		return access
+	}
+
+	if s.User.Admin || (namespace.UserID != "" && namespace.UserID == s.User.ID) {
+		return model.RepoAdmin
+	}
+
+	if namespace.TeamID != "" {
+		access = higher(access, implied[s.Perm(namespace.TeamID)])
+	}
+
+	for _, team := range teams {
+		if s.Perm(team.TeamID) != "" {
+			access = higher(access, team.Perm)
+		}
+	}
+
+	return access
+}
+
+// orphans checks if the action would remove the last owner of a team.
+func orphans(action Action, t *Target) bool {
+	if t.Current != model.PermOwner || t.Owners > 1 {
+		return false
+	}
+
+	switch action {
+	case MemberPermit:
+		return t.Perm != model.PermOwner
+	case MemberUnassign:
+		return true
+	}
+
+	return false
+}
+
+// manages checks if a member with perm may grant or revoke the other perm,
+// admins manage users and admins while only owners manage owners.
+func manages(perm, other string) bool {
+	if other == model.PermOwner {
+		return atLeast(perm, model.PermOwner)
+	}
+
+	return atLeast(perm, model.PermAdmin)
+}
+
+// owns checks if the subject is the owning user of the target or has at
+// least the perm within the owning team.
+func owns(s *Subject, t *Target, perm string) bool {
+	if t.UserID != "" {
+		return s.User.ID == t.UserID
+	}
+
+	return t.TeamID != "" && atLeast(s.Perm(t.TeamID), perm)
+}
+
+// grants checks if the repository access is equal or higher than required.
+func grants(access, required string) bool {
+	return repoLevels[access] >= repoLevels[required] && repoLevels[access] > 0
+}
+
+// higher returns the more powerful of both repository perms.
+func higher(access, other string) string {
+	if repoLevels[other] > repoLevels[access] {
+		return other
+	}
+
+	return access
+}
+
+// atLeast checks if the perm is equal or higher than the required one.
+func atLeast(perm, required string) bool {
+	return levels[perm] >= levels[required] && levels[perm] > 0
+}
+
+var levels = map[string]int{
+	model.PermUser:  1,
+	model.PermAdmin: 2,
+	model.PermOwner: 3,
+}
+
+var repoLevels = map[string]int{
+	model.RepoPull:  1,
+	model.RepoPush:  2,
+	model.RepoAdmin: 3,
+}
+
+// implied maps the perm within the owning team to the repository perm.
+var implied = map[string]string{
+	model.PermUser:  model.RepoPull,
+	model.PermAdmin: model.RepoPush,
+	model.PermOwner: model.RepoAdmin,
+}
+
+func anyone(s *Subject, t *Target) bool {
+	return true
+}
+
+func nobody(s *Subject, t *Target) bool {
+	return false
+}
@@ -0,0 +1,416 @@
+package policy
+
+import (
+	"testing"
+
+	"github.com/umschlag/umschlag-api/pkg/model"
+)
+
+func subject(admin, active bool, perms map[string]string) *Subject {
+	result := &Subject{
+		User: &model.User{
+			ID:     "user",
+			Admin:  admin,
+			Active: active,
+		},
+	}
+
+	for team, perm := range perms {
+		result.Members = append(result.Members, &model.TeamUser{
+			TeamID: team,
+			UserID: "user",
+			Perm:   perm,
+		})
+	}
+
+	return result
+}
+
+func TestAuthorize(t *testing.T) {
+	tests := []struct {
+		name    string
+		subject *Subject
+		action  Action
+		target  *Target
+		err     error
+	}{
+		{
+			name:    "anonymous",
+			subject: nil,
+			action:  UserList,
+			err:     ErrForbidden,
+		},
+		{
+			name:    "unknown action",
+			subject: subject(true, true, nil),
+			action:  Action("unknown"),
+			err:     ErrUnknownAction,
+		},
+		{
+			name:    "active admin bypasses rules",
+			subject: subject(true, true, nil),
+			action:  UserCreate,
+		},
+		{
+			name:    "inactive admin is forbidden",
+			subject: subject(true, false, nil),
+			action:  UserList,
+			err:     ErrForbidden,
+		},
+		{
+			name:    "inactive user is forbidden",
+			subject: subject(false, false, nil),
+			action:  UserList,
+			err:     ErrForbidden,
+		},
+		{
+			name:    "user lists own teams",
+			subject: subject(false, true, nil),
+			action:  UserTeams,
+			target:  &Target{UserID: "user"},
+		},
+		{
+			name:    "user lists foreign teams",
+			subject: subject(false, true, nil),
+			action:  UserTeams,
+			target:  &Target{UserID: "other"},
+			err:     ErrForbidden,
+		},
+		{
+			name:    "admin assigns user",
+			subject: subject(false, true, map[string]string{"team": model.PermAdmin}),
+			action:  MemberAssign,
+			target:  &Target{TeamID: "team", Perm: model.PermUser},
+		},
+		{
+			name:    "admin assigns admin",
+			subject: subject(false, true, map[string]string{"team": model.PermAdmin}),
+			action:  MemberAssign,
+			target:  &Target{TeamID: "team", Perm: model.PermAdmin},
+		},
+		{
+			name:    "admin assigns owner",
+			subject: subject(false, true, map[string]string{"team": model.PermAdmin}),
+			action:  MemberAssign,
+			target:  &Target{TeamID: "team", Perm: model.PermOwner},
+			err:     ErrForbidden,
+		},
+		{
+			name:    "admin demotes owner",
+			subject: subject(false, true, map[string]string{"team": model.PermAdmin}),
+			action:  MemberPermit,
+			target:  &Target{TeamID: "team", Perm: model.PermUser, Current: model.PermOwner, Owners: 2},
+			err:     ErrForbidden,
+		},
+		{
+			name:    "owner assigns owner",
+			subject: subject(false, true, map[string]string{"team": model.PermOwner}),
+			action:  MemberAssign,
+			target:  &Target{TeamID: "team", Perm: model.PermOwner},
+		},
+		{
+			name:    "user assigns user",
+			subject: subject(false, true, map[string]string{"team": model.PermUser}),
+			action:  MemberAssign,
+			target:  &Target{TeamID: "team", Perm: model.PermUser},
+			err:     ErrForbidden,
+		},
+		{
+			name:    "owner demotes other owner",
+			subject: subject(false, true, map[string]string{"team": model.PermOwner}),
+			action:  MemberPermit,
+			target:  &Target{TeamID: "team", Perm: model.PermAdmin, Current: model.PermOwner, Owners: 2},
+		},
+		{
+			name:    "owner demotes last owner",
+			subject: subject(false, true, map[string]string{"team": model.PermOwner}),
+			action:  MemberPermit,
+			target:  &Target{TeamID: "team", Perm: model.PermAdmin, Current: model.PermOwner, Owners: 1},
+			err:     ErrLastOwner,
+		},
+		{
+			name:    "owner keeps last owner",
+			subject: subject(false, true, map[string]string{"team": model.PermOwner}),
+			action:  MemberPermit,
+			target:  &Target{TeamID: "team", Perm: model.PermOwner, Current: model.PermOwner, Owners: 1},
+		},
+		{
+			name:    "last owner leaves",
+			subject: subject(false, true, map[string]string{"team": model.PermOwner}),
+			action:  MemberUnassign,
+			target:  &Target{TeamID: "team", UserID: "user", Current: model.PermOwner, Owners: 1},
+			err:     ErrLastOwner,
+		},
+		{
+			name:    "admin removes last owner",
+			subject: subject(true, true, nil),
+			action:  MemberUnassign,
+			target:  &Target{TeamID: "team", UserID: "other", Current: model.PermOwner, Owners: 1},
+			err:     ErrLastOwner,
+		},
+		{
+			name:    "user leaves team",
+			subject: subject(false, true, map[string]string{"team": model.PermUser}),
+			action:  MemberUnassign,
+			target:  &Target{TeamID: "team", UserID: "user", Current: model.PermUser, Owners: 1},
+		},
+		{
+			name:    "owning user updates namespace",
+			subject: subject(false, true, nil),
+			action:  NamespaceUpdate,
+			target:  &Target{UserID: "user"},
+		},
+		{
+			name:    "team user updates namespace",
+			subject: subject(false, true, map[string]string{"team": model.PermUser}),
+			action:  NamespaceUpdate,
+			target:  &Target{TeamID: "team"},
+			err:     ErrForbidden,
+		},
+		{
+			name:    "team admin deletes namespace",
+			subject: subject(false, true, map[string]string{"team": model.PermAdmin}),
+			action:  NamespaceDelete,
+			target:  &Target{TeamID: "team"},
+			err:     ErrForbidden,
+		},
+		{
+			name:    "pull access shows repository",
+			subject: subject(false, true, nil),
+			action:  RepositoryShow,
+			target:  &Target{Access: model.RepoPull},
+		},
+		{
+			name:    "pull access updates repository",
+			subject: subject(false, true, nil),
+			action:  RepositoryUpdate,
+			target:  &Target{Access: model.RepoPull},
+			err:     ErrForbidden,
+		},
+		{
+			name:    "missing access shows repository",
+			subject: subject(false, true, nil),
+			action:  RepositoryShow,
+			target:  &Target{},
+			err:     ErrForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Authorize(tt.subject, tt.action, tt.target); err != tt.err {
+				t.Errorf("expected %v, got %v", tt.err, err)
+			}
+		})
+	}
+}
+
+func TestAccess(t *testing.T) {
+	public := &model.Repository{Visibility: model.VisibilityPublic}
+	private := &model.Repository{Visibility: model.VisibilityPrivate}
+
+	owned := &model.Namespace{UserID: "user"}
+	foreign := &model.Namespace{UserID: "other"}
+	team := &model.Namespace{TeamID: "team"}
+
+	tests := []struct {
+		name       string
+		subject    *Subject
+		namespace  *model.Namespace
+		repository *model.Repository
+		teams      []*model.RepositoryTeam
+		access     string
+	}{
+		{
+			name:       "anonymous pulls public",
+			subject:    nil,
+			namespace:  foreign,
+			repository: public,
+			access:     model.RepoPull,
+		},
+		{
+			name:       "anonymous on private",
+			subject:    nil,
+			namespace:  foreign,
+			repository: private,
+			access:     "",
+		},
+		{
+			name:       "anonymous on namespace",
+			subject:    nil,
+			namespace:  foreign,
+			repository: nil,
+			access:     "",
+		},
+		{
+			name:       "inactive user pulls public",
+			subject:    subject(false, false, nil),
+			namespace:  owned,
+			repository: public,
+			access:     model.RepoPull,
+		},
+		{
+			name:       "inactive admin on private",
+			subject:    subject(true, false, nil),
+			namespace:  foreign,
+			repository: private,
+			access:     "",
+		},
+		{
+			name:       "admin on private",
+			subject:    subject(true, true, nil),
+			namespace:  foreign,
+			repository: private,
+			access:     model.RepoAdmin,
+		},
+		{
+			name:       "owning user",
+			subject:    subject(false, true, nil),
+			namespace:  owned,
+			repository: private,
+			access:     model.RepoAdmin,
+		},
+		{
+			name:       "foreign user pulls public",
+			subject:    subject(false, true, nil),
+			namespace:  foreign,
+			repository: public,
+			access:     model.RepoPull,
+		},
+		{
+			name:       "foreign user on private",
+			subject:    subject(false, true, nil),
+			namespace:  foreign,
+			repository: private,
+			access:     "",
+		},
+		{
+			name:       "team user",
+			subject:    subject(false, true, map[string]string{"team": model.PermUser}),
+			namespace:  team,
+			repository: private,
+			access:     model.RepoPull,
+		},
+		{
+			name:       "team admin",
+			subject:    subject(false, true, map[string]string{"team": model.PermAdmin}),
+			namespace:  team,
+			repository: private,
+			access:     model.RepoPush,
+		},
+		{
+			name:       "team owner",
+			subject:    subject(false, true, map[string]string{"team": model.PermOwner}),
+			namespace:  team,
+			repository: private,
+			access:     model.RepoAdmin,
+		},
+		{
+			name:       "granted team",
+			subject:    subject(false, true, map[string]string{"granted": model.PermUser}),
+			namespace:  foreign,
+			repository: private,
+			teams: []*model.RepositoryTeam{
+				{TeamID: "granted", Perm: model.RepoPush},
+				{TeamID: "unrelated", Perm: model.RepoAdmin},
+			},
+			access: model.RepoPush,
+		},
+		{
+			name:       "granted team below namespace team",
+			subject:    subject(false, true, map[string]string{"team": model.PermOwner, "granted": model.PermUser}),
+			namespace:  team,
+			repository: private,
+			teams: []*model.RepositoryTeam{
+				{TeamID: "granted", Perm: model.RepoPull},
+			},
+			access: model.RepoAdmin,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if access := Access(tt.subject, tt.namespace, tt.repository, tt.teams); access != tt.access {
+				t.Errorf("expected access %q, got %q", tt.access, access)
+			}
+		})
+	}
+}
+
+func TestOrphans(t *testing.T) {
+	tests := []struct {
+		name   string
+		action Action
+		target *Target
+		result bool
+	}{
+		{
+			name:   "demote last owner",
+			action: MemberPermit,
+			target: &Target{Perm: model.PermAdmin, Current: model.PermOwner, Owners: 1},
+			result: true,
+		},
+		{
+			name:   "demote one of many owners",
+			action: MemberPermit,
+			target: &Target{Perm: model.PermAdmin, Current: model.PermOwner, Owners: 2},
+			result: false,
+		},
+		{
+			name:   "keep last owner",
+			action: MemberPermit,
+			target: &Target{Perm: model.PermOwner, Current: model.PermOwner, Owners: 1},
+			result: false,
+		},
+		{
+			name:   "unassign last owner",
+			action: MemberUnassign,
+			target: &Target{Current: model.PermOwner, Owners: 1},
+			result: true,
+		},
+		{
+			name:   "unassign admin",
+			action: MemberUnassign,
+			target: &Target{Current: model.PermAdmin, Owners: 1},
+			result: false,
+		},
+		{
+			name:   "unrelated action",
+			action: TeamUpdate,
+			target: &Target{Current: model.PermOwner, Owners: 1},
+			result: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := orphans(tt.action, tt.target); result != tt.result {
+				t.Errorf("expected %v, got %v", tt.result, result)
+			}
+		})
+	}
+}
+
+func TestManages(t *testing.T) {
+	tests := []struct {
+		perm   string
+		other  string
+		result bool
+	}{
+		{perm: "", other: model.PermUser, result: false},
+		{perm: model.PermUser, other: model.PermUser, result: false},
+		{perm: model.PermAdmin, other: model.PermUser, result: true},
+		{perm: model.PermAdmin, other: model.PermAdmin, result: true},
+		{perm: model.PermAdmin, other: model.PermOwner, result: false},
+		{perm: model.PermOwner, other: model.PermUser, result: true},
+		{perm: model.PermOwner, other: model.PermOwner, result: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.perm+"/"+tt.other, func(t *testing.T) {
+			if result := manages(tt.perm, tt.other); result != tt.result {
+				t.Errorf("expected %v, got %v", tt.result, result)
+			}
+		})
+	}
+}
@@ -0,0 +1,229 @@
+package auth
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+	"github.com/umschlag/umschlag-api/pkg/authn"
+	"github.com/umschlag/umschlag-api/pkg/config"
+	"github.com/umschlag/umschlag-api/pkg/model"
+	"github.com/umschlag/umschlag-api/pkg/registry/client"
+	"github.com/umschlag/umschlag-api/pkg/registry/quota"
+	"github.com/umschlag/umschlag-api/pkg/store"
+	"github.com/umschlag/umschlag-api/pkg/token"
+)
+
+const (
+	// usageTTL defines how long the usage for quota checks gets cached.
+	usageTTL = 30 * time.Second
+)
+
+var (
+	errInvalidCredentials = errors.New("invalid username or password")
+)
+
+// Server implements the token authentication for docker distribution.
+type Server struct {
+	config  *config.Config
+	storage store.Store
+	keys    *token.KeySet
+	authn   *authn.Authenticator
+	quotas  *quota.Cache
+}
+
+// ServeHTTP handles the token requests from the registry clients.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "UNSUPPORTED", "method not allowed")
+		return
+	}
+
+	if service := r.URL.Query().Get("service"); service != s.config.Registry.Service {
+		writeError(w, http.StatusBadRequest, "DENIED", "invalid service")
+		return
+	}
+
+	scopes, err := ParseScopes(r.URL.Query()["scope"])
+
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "DENIED", err.Error())
+		return
+	}
+
+	var (
+		user    *model.User
+		subject string
+	)
+
+	if username, password, ok := r.BasicAuth(); ok {
+		user, err = s.authn.Password(r.Context(), username, password)
+
+		if err == authn.ErrInvalidCredentials {
+			w.Header().Set("WWW-Authenticate", `Basic realm="umschlag"`)
+			writeError(w, http.StatusUnauthorized, "UNAUTHORIZED", errInvalidCredentials.Error())
+			return
+		}
+
+		if err != nil {
+			log.Error().
+				Err(err).
+				Str("username", username).
+				Msg("failed to authenticate registry user")
+
+			writeError(w, http.StatusInternalServerError, "UNKNOWN", "failed to authenticate")
+			return
+		}
+
+		subject = user.Username
+	}
+
+	access := make([]*Access, 0)
+
+	authorizer := &authorizer{
+		storage: s.storage,
+		quotas:  s.quotas,
+		user:    user,
+	}
+
+	for _, scope := range scopes {
+		actions, err := authorizer.Authorize(r.Context(), scope)
+
+		if err != nil {
+			log.Error().
+				Err(err).
+				Str("username", subject).
+				Str("scope", scope.Name).
+				Msg("failed to authorize registry user")
+
+			writeError(w, http.StatusInternalServerError, "UNKNOWN", "failed to authorize")
+			return
+		}
+
+		access = append(access, &Access{
+			Type:    scope.Type,
+			Name:    scope.Name,
+			Actions: actions,
+		})
+	}
+
+	resp, err := sign(s.config, s.keys, subject, access)
+
+	if err != nil {
+		log.Error().
+			Err(err).
+			Msg("failed to sign registry token")
+
+		writeError(w, http.StatusInternalServerError, "UNKNOWN", "failed to sign token")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	json.NewEncoder(w).Encode(resp)
+}
+
+// Service returns a token function for the registry client which grants
+// all requested actions to Umschlag itself, it's used by background jobs
+// talking to the registry API.
+func Service(cfg *config.Config, keys *token.KeySet) client.TokenFunc {
+	return func(scope string) (string, error) {
+		parsed, err := ParseScope(scope)
+
+		if err != nil {
+			return "", err
+		}
+
+		resp, err := sign(cfg, keys, cfg.Registry.Issuer, []*Access{
+			{
+				Type:    parsed.Type,
+				Name:    parsed.Name,
+				Actions: parsed.Actions,
+			},
+		})
+
+		if err != nil {
+			return "", err
+		}
+
+		return resp.Token, nil
+	}
+}
+
+// Client initializes a client for the configured registry API which is
+// authenticated by service tokens.
+func Client(cfg *config.Config, keys *token.KeySet) (*client.Client, error) {
+	return client.New(
+		cfg.Registry.URL,
+		nil,
+		Service(cfg, keys),
+	)
+}
+
+// sign generates the signed registry token for the granted access.
+func sign(cfg *config.Config, keys *token.KeySet, subject string, access []*Access) (*Response, error) {
+	id := make([]byte, 16)
+
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+
+	claims := &Claims{
+		StandardClaims: jwt.StandardClaims{
+			Id:        hex.EncodeToString(id),
+			Issuer:    cfg.Registry.Issuer,
+			Subject:   subject,
+			Audience:  cfg.Registry.Service,
+			IssuedAt:  now.Unix(),
+			NotBefore: now.Unix(),
+			ExpiresAt: now.Add(cfg.Registry.Expire).Unix(),
+		},
+		Access: access,
+	}
+
+	signed, err := keys.Active().Sign(claims)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &Response{
+		Token:       signed,
+		AccessToken: signed,
+		ExpiresIn:   int(cfg.Registry.Expire.Seconds()),
+		IssuedAt:    now.UTC().Format(time.RFC3339),
+	}, nil
+}
+
+// New initializes the token server for docker distribution.
+func New(cfg *config.Config, storage store.Store, keys *token.KeySet) *Server {
+	return &Server{
+		config:  cfg,
+		storage: storage,
+		authn:   authn.New(storage, keys, cfg.Password.Cost),
+		keys:    keys,
+		quotas:  quota.NewCache(storage, usageTTL),
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, code, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"errors": []map[string]string{
+			{
+				"code":    code,
+				"message": message,
+			},
+		},
+	})
+}
@@ -0,0 +1,195 @@
+package auth
+
+import (
+	"context"
+
+	"github.com/umschlag/umschlag-api/pkg/model"
+	"github.com/umschlag/umschlag-api/pkg/policy"
+	"github.com/umschlag/umschlag-api/pkg/registry/quota"
+	"github.com/umschlag/umschlag-api/pkg/store"
+)
+
+// authorizer resolves the granted actions for a single user, the user is
+// nil for anonymous clients.
+type authorizer struct {
+	storage store.Store
+	quotas  *quota.Cache
+	user    *model.User
+	subject *policy.Subject
+}
+
+// Authorize returns the subset of requested actions granted to the user.
+func (a *authorizer) Authorize(ctx context.Context, scope *Scope) ([]string, error) {
+	actions, err := a.authorize(ctx, scope)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return a.restrict(actions), nil
+}
+
+// authorize resolves the granted actions without the token restrictions.
+func (a *authorizer) authorize(ctx context.Context, scope *Scope) ([]string, error) {
+	switch scope.Type {
+	case TypeRegistry:
+		if a.user != nil && a.user.Admin && scope.Name == "catalog" {
+			return intersect(scope.Actions, ActionAll), nil
+		}
+
+		return []string{}, nil
+	case TypeRepository:
+		allowed, err := a.repository(ctx, scope)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return intersect(scope.Actions, allowed...), nil
+	}
+
+	return []string{}, nil
+}
+
+// repository resolves the allowed actions for a repository scope based on
+// the stored namespace and repository. Repositories which don't exist yet
+// are authorized against the namespace, so they can be pushed initially.
+// Admins are not limited by storage quotas.
+func (a *authorizer) repository(ctx context.Context, scope *Scope) ([]string, error) {
+	if a.user != nil && a.user.Admin {
+		return []string{ActionPull, ActionPush, ActionDelete, ActionAll}, nil
+	}
+
+	if scope.Namespace() == "" {
+		return []string{}, nil
+	}
+
+	namespace, err := a.storage.Namespaces().Show(ctx, scope.Namespace())
+
+	if err == store.ErrNotFound {
+		return []string{}, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	var teams []*model.RepositoryTeam
+
+	repository, err := a.storage.Repositories().Show(ctx, namespace.ID, scope.Repository())
+
+	switch {
+	case err == store.ErrNotFound:
+		repository = nil
+	case err != nil:
+		return nil, err
+	default:
+		teams, err = a.storage.Repositories().ListTeams(ctx, repository.ID)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	subject, err := a.load(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	switch policy.Access(subject, namespace, repository, teams) {
+	case model.RepoAdmin:
+		return a.limit(ctx, scope, namespace, []string{ActionPull, ActionPush, ActionDelete})
+	case model.RepoPush:
+		return a.limit(ctx, scope, namespace, []string{ActionPull, ActionPush})
+	case model.RepoPull:
+		return []string{ActionPull}, nil
+	}
+
+	return []string{}, nil
+}
+
+// limit drops the push action if the namespace or the owning team reached
+// the hard storage quota. The usage only gets calculated if a push has been
+// requested.
+func (a *authorizer) limit(ctx context.Context, scope *Scope, namespace *model.Namespace, actions []string) ([]string, error) {
+	if len(intersect(scope.Actions, ActionPush)) == 0 {
+		return actions, nil
+	}
+
+	allowed, err := a.quotas.Allowed(ctx, namespace)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if allowed {
+		return actions, nil
+	}
+
+	result := make([]string, 0, len(actions))
+
+	for _, action := range actions {
+		if action != ActionPush {
+			result = append(result, action)
+		}
+	}
+
+	return result, nil
+}
+
+// load fetches the memberships of the user once for all scopes.
+func (a *authorizer) load(ctx context.Context) (*policy.Subject, error) {
+	if a.user == nil || a.subject != nil {
+		return a.subject, nil
+	}
+
+	members, err := a.storage.Members().ListByUser(ctx, a.user.ID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	a.subject = &policy.Subject{
+		User:    a.user,
+		Members: members,
+	}
+
+	return a.subject, nil
+}
+
+// restrict limits the actions to the scopes of the used access token, the
+// tokens are never allowed to delete anything.
+func (a *authorizer) restrict(actions []string) []string {
+	if a.user == nil || a.user.Token == nil {
+		return actions
+	}
+
+	allowed := make([]string, 0)
+
+	if a.user.Grants(model.ScopeRegistryPull) {
+		allowed = append(allowed, ActionPull)
+	}
+
+	if a.user.Grants(model.ScopeRegistryPush) {
+		allowed = append(allowed, ActionPush)
+	}
+
+	return intersect(actions, allowed...)
+}
+
+// intersect returns the requested actions that are part of allowed.
+func intersect(requested []string, allowed ...string) []string {
+	result := make([]string, 0)
+
+	for _, action := range requested {
+		for _, allow := range allowed {
+			if action == allow {
+				result = append(result, action)
+				break
+			}
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,26 @@
+package auth
+
+import (
+	"github.com/dgrijalva/jwt-go"
+)
+
+// Access represents a single granted access within a registry token.
+type Access struct {
+	Type    string   `json:"type"`
+	Name    string   `json:"name"`
+	Actions []string `json:"actions"`
+}
+
+// Claims represents the claims of a registry token.
+type Claims struct {
+	jwt.StandardClaims
+	Access []*Access `json:"access"`
+}
+
+// Response represents the token response expected by the registry.
+type Response struct {
+	Token       string `json:"token"`
+	AccessToken string `json:"access_token"`
+	ExpiresIn   int    `json:"expires_in"`
+	IssuedAt    string `json:"issued_at"`
+}
@@ -0,0 +1,103 @@
+package auth
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// ActionPull defines the action to pull from a repository.
+	ActionPull = "pull"
+
+	// ActionPush defines the action to push to a repository.
+	ActionPush = "push"
+
+	// ActionDelete defines the action to delete from a repository.
+	ActionDelete = "delete"
+
+	// ActionAll defines the wildcard action used by the catalog.
+	ActionAll = "*"
+)
+
+const (
+	// TypeRepository defines the resource type for repositories.
+	TypeRepository = "repository"
+
+	// TypeRegistry defines the resource type for the registry itself.
+	TypeRegistry = "registry"
+)
+
+var (
+	// ErrInvalidScope is returned when a scope could not be parsed.
+	ErrInvalidScope = errors.New("invalid scope format")
+)
+
+// Scope represents a single scope requested by the registry client.
+type Scope struct {
+	Type    string
+	Name    string
+	Actions []string
+}
+
+// Namespace returns the first path segment of the scope name.
+func (s *Scope) Namespace() string {
+	if idx := strings.Index(s.Name, "/"); idx > 0 {
+		return s.Name[:idx]
+	}
+
+	return ""
+}
+
+// Repository returns the scope name without the namespace.
+func (s *Scope) Repository() string {
+	if idx := strings.Index(s.Name, "/"); idx > 0 {
+		return s.Name[idx+1:]
+	}
+
+	return ""
+}
+
+// ParseScope parses a scope like repository:team/app:pull,push.
+func ParseScope(val string) (*Scope, error) {
+	first := strings.Index(val, ":")
+	last := strings.LastIndex(val, ":")
+
+	if first < 1 || last == first || last == len(val)-1 {
+		return nil, ErrInvalidScope
+	}
+
+	scope := &Scope{
+		Type:    val[:first],
+		Name:    val[first+1 : last],
+		Actions: make([]string, 0),
+	}
+
+	for _, action := range strings.Split(val[last+1:], ",") {
+		if action = strings.TrimSpace(action); action != "" {
+			scope.Actions = append(scope.Actions, action)
+		}
+	}
+
+	return scope, nil
+}
+
+// ParseScopes parses a list of scopes, every value can contain multiple
+// scopes separated by spaces.
+func ParseScopes(vals []string) ([]*Scope, error) {
+	result := make([]*Scope, 0)
+
+	for _, val := range vals {
+		for _, raw := range strings.Fields(val) {
+			scope, err := ParseScope(raw)
+
+			if err != nil {
+				return nil, err
+			}
+
+			result = append(result, scope)
+		}
+	}
+
+	return result, nil
+}
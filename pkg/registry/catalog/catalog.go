@@ -0,0 +1,207 @@
+// Package catalog mirrors the repositories, tags and manifests of a docker
+// distribution instance into the store.
+package catalog
+
+import (
+	"context"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"github.com/umschlag/umschlag-api/pkg/model"
+	"github.com/umschlag/umschlag-api/pkg/registry/client"
+	"github.com/umschlag/umschlag-api/pkg/store"
+)
+
+// Syncer synchronizes the registry catalog with the store. Only namespaces
+// which already exist within the store are synchronized, missing
+// repositories get created and tags are updated incrementally.
+type Syncer struct {
+	storage store.Store
+	client  *client.Client
+}
+
+// Run walks the whole catalog of the registry, failures of a single
+// repository get logged and don't abort the run.
+func (s *Syncer) Run(ctx context.Context) error {
+	names, err := s.client.Catalog(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	for _, name := range names {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if err := s.Repository(ctx, name); err != nil {
+			log.Error().
+				Err(err).
+				Str("repository", name).
+				Msg("failed to sync repository")
+		}
+	}
+
+	return nil
+}
+
+// Repository synchronizes a single repository by its full name. Only the
+// manifests of tags with a changed digest get fetched, tags which are gone
+// from the registry get removed.
+func (s *Syncer) Repository(ctx context.Context, name string) error {
+	repository, err := Resolve(ctx, s.storage, name, true)
+
+	if err != nil || repository == nil {
+		return err
+	}
+
+	tags, err := s.client.Tags(ctx, name)
+
+	if err != nil && err != client.ErrNotFound {
+		return err
+	}
+
+	records, err := s.storage.Tags().List(ctx, repository.ID)
+
+	if err != nil {
+		return err
+	}
+
+	existing := make(map[string]*model.Tag, len(records))
+
+	for _, record := range records {
+		existing[record.Name] = record
+	}
+
+	for _, tag := range tags {
+		current := existing[tag]
+		delete(existing, tag)
+
+		digest, err := s.client.Digest(ctx, name, tag)
+
+		if err == client.ErrNotFound {
+			continue
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if current != nil && current.Digest == digest {
+			continue
+		}
+
+		if err := s.Manifest(ctx, repository, name, digest); err != nil {
+			return err
+		}
+
+		if _, err := s.storage.Tags().Save(ctx, &model.Tag{
+			RepositoryID: repository.ID,
+			Name:         tag,
+			Digest:       digest,
+		}); err != nil {
+			return err
+		}
+
+		log.Debug().
+			Str("repository", name).
+			Str("tag", tag).
+			Str("digest", digest).
+			Msg("synced registry tag")
+	}
+
+	for tag := range existing {
+		if err := s.storage.Tags().Delete(ctx, repository.ID, tag); err != nil && err != store.ErrNotFound {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Manifest stores the manifest of a repository by digest if it's not
+// already known, manifests are immutable so they are only fetched once.
+func (s *Syncer) Manifest(ctx context.Context, repository *model.Repository, name, digest string) error {
+	if _, err := s.storage.Manifests().Show(ctx, repository.ID, digest); err == nil {
+		return nil
+	} else if err != store.ErrNotFound {
+		return err
+	}
+
+	manifest, err := s.client.Manifest(ctx, name, digest)
+
+	if err != nil {
+		return err
+	}
+
+	record := &model.Manifest{
+		RepositoryID: repository.ID,
+		Digest:       manifest.Digest,
+		MediaType:    manifest.MediaType,
+		Size:         manifest.Size,
+		Blobs:        make([]*model.Blob, 0, len(manifest.Blobs)),
+		CreatedAt:    manifest.Created,
+	}
+
+	for _, blob := range manifest.Blobs {
+		record.Blobs = append(record.Blobs, &model.Blob{
+			Digest: blob.Digest,
+			Size:   blob.Size,
+		})
+	}
+
+	_, err = s.storage.Manifests().Save(ctx, record)
+	return err
+}
+
+// Resolve looks up the repository by its full name and optionally creates
+// it within the namespace. It returns nil if the namespace or the repository
+// is unknown.
+func Resolve(ctx context.Context, storage store.Store, name string, create bool) (*model.Repository, error) {
+	parts := strings.SplitN(name, "/", 2)
+
+	if len(parts) != 2 {
+		log.Debug().
+			Str("repository", name).
+			Msg("skipping repository without namespace")
+
+		return nil, nil
+	}
+
+	namespace, err := storage.Namespaces().Show(ctx, parts[0])
+
+	if err == store.ErrNotFound {
+		log.Debug().
+			Str("repository", name).
+			Msg("skipping repository of unknown namespace")
+
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	repository, err := storage.Repositories().Show(ctx, namespace.ID, parts[1])
+
+	if err == store.ErrNotFound {
+		if !create {
+			return nil, nil
+		}
+
+		repository, err = storage.Repositories().Create(ctx, &model.Repository{
+			NamespaceID: namespace.ID,
+			Name:        parts[1],
+		})
+	}
+
+	return repository, err
+}
+
+// New initializes a syncer for the registry reachable by the client.
+func New(storage store.Store, client *client.Client) *Syncer {
+	return &Syncer{
+		storage: storage,
+		client:  client,
+	}
+}
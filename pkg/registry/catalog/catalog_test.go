@@ -0,0 +1,374 @@
+package catalog
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/umschlag/umschlag-api/pkg/model"
+	"github.com/umschlag/umschlag-api/pkg/registry/client"
+	"github.com/umschlag/umschlag-api/pkg/store"
+	"github.com/umschlag/umschlag-api/pkg/store/memory"
+)
+
+// fakeRegistry serves the parts of the distribution API used by the syncer,
+// catalog and tag lists are paginated with a page size of one.
+type fakeRegistry struct {
+	mu        sync.Mutex
+	repos     map[string]map[string]string
+	manifests map[string][]byte
+	blobs     map[string][]byte
+	fetched   map[string]int
+}
+
+func newFakeRegistry() *fakeRegistry {
+	return &fakeRegistry{
+		repos:     make(map[string]map[string]string),
+		manifests: make(map[string][]byte),
+		blobs:     make(map[string][]byte),
+		fetched:   make(map[string]int),
+	}
+}
+
+// image registers a manifest with a config and a single layer and returns
+// the digest of the manifest.
+func (f *fakeRegistry) image(created time.Time, layer string) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	config, _ := json.Marshal(map[string]interface{}{
+		"created": created,
+	})
+
+	configDigest := digest(config)
+	f.blobs[configDigest] = config
+
+	body, _ := json.Marshal(map[string]interface{}{
+		"schemaVersion": 2,
+		"mediaType":     client.MediaTypeManifest,
+		"config": client.Descriptor{
+			MediaType: "application/vnd.docker.container.image.v1+json",
+			Digest:    configDigest,
+			Size:      int64(len(config)),
+		},
+		"layers": []client.Descriptor{
+			{
+				MediaType: "application/vnd.docker.image.rootfs.diff.tar.gzip",
+				Digest:    digest([]byte(layer)),
+				Size:      int64(len(layer)),
+			},
+		},
+	})
+
+	result := digest(body)
+	f.manifests[result] = body
+
+	return result
+}
+
+func (f *fakeRegistry) tag(repo, tag, digest string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.repos[repo] == nil {
+		f.repos[repo] = make(map[string]string)
+	}
+
+	f.repos[repo][tag] = digest
+}
+
+func (f *fakeRegistry) untag(repo, tag string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	delete(f.repos[repo], tag)
+}
+
+func (f *fakeRegistry) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	path := strings.TrimPrefix(r.URL.Path, "/v2/")
+
+	switch {
+	case path == "_catalog":
+		names := make([]string, 0, len(f.repos))
+
+		for name := range f.repos {
+			names = append(names, name)
+		}
+
+		f.page(w, r, "repositories", names)
+	case strings.HasSuffix(path, "/tags/list"):
+		tags, ok := f.repos[strings.TrimSuffix(path, "/tags/list")]
+
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+
+		names := make([]string, 0, len(tags))
+
+		for name := range tags {
+			names = append(names, name)
+		}
+
+		f.page(w, r, "tags", names)
+	case strings.Contains(path, "/manifests/"):
+		parts := strings.SplitN(path, "/manifests/", 2)
+		ref := parts[1]
+
+		if tagged, ok := f.repos[parts[0]][ref]; ok {
+			ref = tagged
+		}
+
+		body, ok := f.manifests[ref]
+
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+
+		w.Header().Set("Content-Type", client.MediaTypeManifest)
+		w.Header().Set("Docker-Content-Digest", ref)
+
+		if r.Method == http.MethodHead {
+			return
+		}
+
+		f.fetched[ref]++
+		w.Write(body)
+	case strings.Contains(path, "/blobs/"):
+		body, ok := f.blobs[strings.SplitN(path, "/blobs/", 2)[1]]
+
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+
+		w.Write(body)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// page writes a single entry after the last parameter and links the next
+// page like the distribution API does.
+func (f *fakeRegistry) page(w http.ResponseWriter, r *http.Request, key string, names []string) {
+	sort.Strings(names)
+
+	last := r.URL.Query().Get("last")
+	result := make([]string, 0, 1)
+
+	for i, name := range names {
+		if last != "" && name <= last {
+			continue
+		}
+
+		result = append(result, name)
+
+		if i < len(names)-1 {
+			next := url.Values{}
+			next.Set("last", name)
+			next.Set("n", strconv.Itoa(1))
+
+			w.Header().Set("Link", fmt.Sprintf(`<%s?%s>; rel="next"`, r.URL.Path, next.Encode()))
+		}
+
+		break
+	}
+
+	json.NewEncoder(w).Encode(map[string][]string{
+		key: result,
+	})
+}
+
+func digest(content []byte) string {
+	sum := sha256.Sum256(content)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+func TestSync(t *testing.T) {
+	ctx := context.Background()
+	dsn, _ := url.Parse("memory://")
+	storage := memory.Must(dsn)
+
+	user, err := storage.Users().Create(ctx, &model.User{Username: "jane", Email: "jane@example.com"})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	namespace, err := storage.Namespaces().Create(ctx, &model.Namespace{Name: "core", UserID: user.ID})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	created := time.Date(2019, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	fake := newFakeRegistry()
+	first := fake.image(created, "first")
+	second := fake.image(created.Add(time.Hour), "second")
+
+	fake.tag("core/app", "latest", second)
+	fake.tag("core/app", "v1", first)
+	fake.tag("core/app", "v2", second)
+	fake.tag("core/web", "latest", first)
+	fake.tag("other/tool", "latest", first)
+	fake.tag("plain", "latest", first)
+
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	registry, err := client.New(server.URL, server.Client(), nil)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	syncer := New(storage, registry)
+
+	if err := syncer.Run(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	repositories, err := storage.Repositories().List(ctx, namespace.ID)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	names := make([]string, 0, len(repositories))
+
+	for _, repository := range repositories {
+		names = append(names, repository.Name)
+	}
+
+	sort.Strings(names)
+
+	if got, want := strings.Join(names, ","), "app,web"; got != want {
+		t.Errorf("expected repositories %q, got %q", want, got)
+	}
+
+	if _, err := storage.Namespaces().Show(ctx, "other"); err != store.ErrNotFound {
+		t.Errorf("expected unknown namespace to be skipped, got %v", err)
+	}
+
+	app, err := storage.Repositories().Show(ctx, namespace.ID, "app")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assertTags(t, storage, app.ID, map[string]string{
+		"latest": second,
+		"v1":     first,
+		"v2":     second,
+	})
+
+	manifests, err := storage.Manifests().List(ctx, app.ID)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(manifests) != 2 {
+		t.Fatalf("expected 2 manifests, got %d", len(manifests))
+	}
+
+	for _, manifest := range manifests {
+		var want time.Time
+
+		switch manifest.Digest {
+		case first:
+			want = created
+		case second:
+			want = created.Add(time.Hour)
+		default:
+			t.Fatalf("unexpected manifest %s", manifest.Digest)
+		}
+
+		if manifest.CreatedAt == nil || !manifest.CreatedAt.Equal(want) {
+			t.Errorf("expected manifest %s to be created at %s, got %v", manifest.Digest, want, manifest.CreatedAt)
+		}
+
+		if manifest.MediaType != client.MediaTypeManifest {
+			t.Errorf("expected media type %q, got %q", client.MediaTypeManifest, manifest.MediaType)
+		}
+
+		if len(manifest.Blobs) != 2 {
+			t.Errorf("expected config and layer blobs, got %d", len(manifest.Blobs))
+		}
+
+		var size int64
+
+		for _, blob := range manifest.Blobs {
+			size += blob.Size
+		}
+
+		if manifest.Size != size {
+			t.Errorf("expected size %d, got %d", size, manifest.Size)
+		}
+	}
+
+	if fake.fetched[second] != 1 {
+		t.Errorf("expected shared manifest to be fetched once, got %d", fake.fetched[second])
+	}
+
+	third := fake.image(created.Add(2*time.Hour), "third")
+
+	fake.untag("core/app", "v1")
+	fake.tag("core/app", "latest", third)
+
+	if err := syncer.Run(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	assertTags(t, storage, app.ID, map[string]string{
+		"latest": third,
+		"v2":     second,
+	})
+
+	if fake.fetched[second] != 1 || fake.fetched[third] != 1 {
+		t.Errorf("expected only the new manifest to be fetched, got %v", fake.fetched)
+	}
+}
+
+func assertTags(t *testing.T, storage store.Store, repositoryID string, want map[string]string) {
+	t.Helper()
+
+	tags, err := storage.Tags().List(context.Background(), repositoryID)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := make(map[string]string, len(tags))
+
+	for _, tag := range tags {
+		got[tag.Name] = tag.Digest
+	}
+
+	if len(got) != len(want) {
+		t.Errorf("expected tags %v, got %v", want, got)
+		return
+	}
+
+	for name, digest := range want {
+		if got[name] != digest {
+			t.Errorf("expected tag %s at %s, got %q", name, digest, got[name])
+		}
+	}
+}
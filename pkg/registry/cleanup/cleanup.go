@@ -0,0 +1,206 @@
+// Package cleanup deletes tags and manifests through the registry API and
+// reflects the result within the store.
+package cleanup
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+	"github.com/umschlag/umschlag-api/pkg/model"
+	"github.com/umschlag/umschlag-api/pkg/registry/client"
+	"github.com/umschlag/umschlag-api/pkg/store"
+)
+
+var (
+	// ErrUnconfigured is returned if the registry API is not configured.
+	ErrUnconfigured = errors.New("registry api is not configured")
+
+	// ErrShared is returned if a tag shares its manifest with other tags
+	// and the registry is not able to delete a single tag.
+	ErrShared = errors.New("manifest is shared with other tags")
+
+	// ErrImmutable is returned if a deletion would remove a tag matching
+	// the immutable tag rules of the namespace.
+	ErrImmutable = errors.New("tag is immutable")
+)
+
+// Result lists the tags and manifests which have been removed, or which
+// would be removed for a dry run.
+type Result struct {
+	DryRun    bool
+	Tags      []string
+	Manifests []string
+}
+
+// Cleaner deletes tags and manifests from the registry and the store.
+type Cleaner struct {
+	storage store.Store
+	client  *client.Client
+}
+
+// Tag deletes a single tag. The manifest gets deleted as well if no other
+// tag points to it, otherwise only the tag gets deleted which requires a
+// registry supporting the deletion of tags. Immutable tags are refused.
+func (c *Cleaner) Tag(ctx context.Context, namespace *model.Namespace, repository *model.Repository, name string, dryRun bool) (*Result, error) {
+	tag, err := c.storage.Tags().Show(ctx, repository.ID, name)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.mutable(ctx, namespace, tag.Name); err != nil {
+		return nil, err
+	}
+
+	tags, err := c.storage.Tags().List(ctx, repository.ID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	shared := false
+
+	for _, other := range tags {
+		if other.Name != tag.Name && other.Digest == tag.Digest {
+			shared = true
+		}
+	}
+
+	result := &Result{
+		DryRun:    dryRun,
+		Tags:      []string{tag.Name},
+		Manifests: make([]string, 0),
+	}
+
+	if !shared {
+		result.Manifests = append(result.Manifests, tag.Digest)
+	}
+
+	if dryRun {
+		return result, nil
+	}
+
+	if c.client == nil {
+		return nil, ErrUnconfigured
+	}
+
+	if shared {
+		err = c.client.DeleteManifest(ctx, fullName(namespace, repository), tag.Name)
+	} else {
+		err = c.remove(ctx, fullName(namespace, repository), repository, tag.Digest)
+	}
+
+	if err == client.ErrUnsupported && shared {
+		return nil, ErrShared
+	}
+
+	if err != nil && err != client.ErrNotFound {
+		return nil, err
+	}
+
+	if err := c.storage.Tags().Delete(ctx, repository.ID, tag.Name); err != nil && err != store.ErrNotFound {
+		return nil, err
+	}
+
+	log.Info().
+		Str("repository", fullName(namespace, repository)).
+		Str("tag", tag.Name).
+		Msg("deleted registry tag")
+
+	return result, nil
+}
+
+// Manifest deletes a manifest by digest together with all tags pointing
+// to it, manifests referenced by an immutable tag are refused.
+func (c *Cleaner) Manifest(ctx context.Context, namespace *model.Namespace, repository *model.Repository, digest string, dryRun bool) (*Result, error) {
+	if _, err := c.storage.Manifests().Show(ctx, repository.ID, digest); err != nil {
+		return nil, err
+	}
+
+	tags, err := c.storage.Tags().List(ctx, repository.ID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{
+		DryRun:    dryRun,
+		Tags:      make([]string, 0),
+		Manifests: []string{digest},
+	}
+
+	for _, tag := range tags {
+		if tag.Digest == digest {
+			result.Tags = append(result.Tags, tag.Name)
+		}
+	}
+
+	if err := c.mutable(ctx, namespace, result.Tags...); err != nil {
+		return nil, err
+	}
+
+	if dryRun {
+		return result, nil
+	}
+
+	if c.client == nil {
+		return nil, ErrUnconfigured
+	}
+
+	if err := c.remove(ctx, fullName(namespace, repository), repository, digest); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// mutable checks the tag names against the immutable tag rules of the
+// namespace.
+func (c *Cleaner) mutable(ctx context.Context, namespace *model.Namespace, names ...string) error {
+	rules, err := c.storage.Immutables().List(ctx, namespace.ID)
+
+	if err != nil {
+		return err
+	}
+
+	for _, name := range names {
+		if model.Immutables(rules, name) {
+			return ErrImmutable
+		}
+	}
+
+	return nil
+}
+
+// remove deletes the manifest from the registry and the store, manifests
+// which are already gone from the registry are only removed from the store.
+func (c *Cleaner) remove(ctx context.Context, name string, repository *model.Repository, digest string) error {
+	if err := c.client.DeleteManifest(ctx, name, digest); err != nil && err != client.ErrNotFound {
+		return err
+	}
+
+	if err := c.storage.Manifests().Delete(ctx, repository.ID, digest); err != nil && err != store.ErrNotFound {
+		return err
+	}
+
+	log.Info().
+		Str("repository", name).
+		Str("digest", digest).
+		Msg("deleted registry manifest")
+
+	return nil
+}
+
+// New initializes a cleaner, without a client only dry runs are possible.
+func New(storage store.Store, client *client.Client) *Cleaner {
+	return &Cleaner{
+		storage: storage,
+		client:  client,
+	}
+}
+
+// fullName builds the name of the repository used by the registry.
+func fullName(namespace *model.Namespace, repository *model.Repository) string {
+	return namespace.Name + "/" + repository.Name
+}
@@ -0,0 +1,136 @@
+package cleanup
+
+import (
+	"context"
+	"net/url"
+	"reflect"
+	"testing"
+
+	"github.com/umschlag/umschlag-api/pkg/model"
+	"github.com/umschlag/umschlag-api/pkg/store"
+	"github.com/umschlag/umschlag-api/pkg/store/memory"
+)
+
+func prepare(t *testing.T, rules ...*model.Immutable) (store.Store, *model.Namespace, *model.Repository) {
+	t.Helper()
+
+	ctx := context.Background()
+	dsn, _ := url.Parse("memory://")
+	storage := memory.Must(dsn)
+
+	user, err := storage.Users().Create(ctx, &model.User{Username: "jane", Email: "jane@example.com"})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	namespace, err := storage.Namespaces().Create(ctx, &model.Namespace{Name: "core", UserID: user.ID})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	repository, err := storage.Repositories().Create(ctx, &model.Repository{NamespaceID: namespace.ID, Name: "app"})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for digest, names := range map[string][]string{
+		"sha256:release": {"v1.0", "stable"},
+		"sha256:develop": {"dev"},
+	} {
+		if _, err := storage.Manifests().Save(ctx, &model.Manifest{RepositoryID: repository.ID, Digest: digest}); err != nil {
+			t.Fatal(err)
+		}
+
+		for _, name := range names {
+			if _, err := storage.Tags().Save(ctx, &model.Tag{RepositoryID: repository.ID, Name: name, Digest: digest}); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	for _, rule := range rules {
+		rule.NamespaceID = namespace.ID
+
+		if _, err := storage.Immutables().Create(ctx, rule); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return storage, namespace, repository
+}
+
+func TestImmutable(t *testing.T) {
+	tests := []struct {
+		name      string
+		rules     []*model.Immutable
+		tag       string
+		digest    string
+		err       error
+		manifests []string
+	}{
+		{
+			name:      "mutable tag",
+			rules:     []*model.Immutable{{Pattern: "v*"}},
+			tag:       "dev",
+			manifests: []string{"sha256:develop"},
+		},
+		{
+			name:  "immutable tag",
+			rules: []*model.Immutable{{Pattern: "v*"}},
+			tag:   "v1.0",
+			err:   ErrImmutable,
+		},
+		{
+			name:      "excluded tag",
+			rules:     []*model.Immutable{{Pattern: "v*"}, {Pattern: "v1.0", Exclude: true}},
+			tag:       "v1.0",
+			manifests: []string{},
+		},
+		{
+			name:      "mutable manifest",
+			rules:     []*model.Immutable{{Pattern: "v*"}},
+			digest:    "sha256:develop",
+			manifests: []string{"sha256:develop"},
+		},
+		{
+			name:   "manifest of immutable tag",
+			rules:  []*model.Immutable{{Pattern: "v*"}},
+			digest: "sha256:release",
+			err:    ErrImmutable,
+		},
+		{
+			name:      "without rules",
+			digest:    "sha256:release",
+			manifests: []string{"sha256:release"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			storage, namespace, repository := prepare(t, tt.rules...)
+			cleaner := New(storage, nil)
+
+			var (
+				result *Result
+				err    error
+			)
+
+			if tt.tag != "" {
+				result, err = cleaner.Tag(context.Background(), namespace, repository, tt.tag, true)
+			} else {
+				result, err = cleaner.Manifest(context.Background(), namespace, repository, tt.digest, true)
+			}
+
+			if err != tt.err {
+				t.Fatalf("expected %v, got %v", tt.err, err)
+			}
+
+			if err == nil && !reflect.DeepEqual(result.Manifests, tt.manifests) {
+				t.Errorf("expected manifests %v, got %v", tt.manifests, result.Manifests)
+			}
+		})
+	}
+}
@@ -0,0 +1,395 @@
+// Package client implements the parts of the docker distribution HTTP API
+// required to mirror the catalog of a registry and to delete manifests.
+package client
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// MediaTypeManifest defines the media type of docker image manifests.
+	MediaTypeManifest = "application/vnd.docker.distribution.manifest.v2+json"
+
+	// MediaTypeManifestList defines the media type of docker manifest lists.
+	MediaTypeManifestList = "application/vnd.docker.distribution.manifest.list.v2+json"
+
+	// MediaTypeImageManifest defines the media type of OCI image manifests.
+	MediaTypeImageManifest = "application/vnd.oci.image.manifest.v1+json"
+
+	// MediaTypeImageIndex defines the media type of OCI image indexes.
+	MediaTypeImageIndex = "application/vnd.oci.image.index.v1+json"
+
+	// pageSize defines the number of entries requested per page.
+	pageSize = 100
+)
+
+var (
+	// ErrNotFound is returned if the registry doesn't know the resource.
+	ErrNotFound = errors.New("registry resource not found")
+
+	// ErrUnsupported is returned if the registry doesn't allow the operation.
+	ErrUnsupported = errors.New("registry operation not supported")
+
+	// linkNext matches the next page within a Link header.
+	linkNext = regexp.MustCompile(`<([^>]+)>\s*;\s*rel="?next"?`)
+)
+
+// TokenFunc returns a bearer token granting the scope of a request, an
+// empty token sends the request without authorization.
+type TokenFunc func(scope string) (string, error)
+
+// Descriptor references a blob or manifest by digest.
+type Descriptor struct {
+	MediaType string `json:"mediaType"`
+	Digest    string `json:"digest"`
+	Size      int64  `json:"size"`
+}
+
+// Manifest is the resolved manifest of an image. Blobs contains the config
+// and layer blobs, for manifest lists they are gathered from all the
+// referenced manifests.
+type Manifest struct {
+	Digest    string
+	MediaType string
+	Size      int64
+	Blobs     []Descriptor
+	Created   *time.Time
+}
+
+// Client talks to the HTTP API of a docker distribution instance.
+type Client struct {
+	endpoint *url.URL
+	http     *http.Client
+	token    TokenFunc
+}
+
+// Catalog retrieves the names of all repositories following the pages.
+func (c *Client) Catalog(ctx context.Context) ([]string, error) {
+	result := make([]string, 0)
+	next := fmt.Sprintf("/v2/_catalog?n=%d", pageSize)
+
+	for next != "" {
+		page := struct {
+			Repositories []string `json:"repositories"`
+		}{}
+
+		link, err := c.fetch(ctx, next, "registry:catalog:*", &page)
+
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, page.Repositories...)
+		next = link
+	}
+
+	return result, nil
+}
+
+// Tags retrieves the names of all tags of a repository following the pages.
+func (c *Client) Tags(ctx context.Context, name string) ([]string, error) {
+	result := make([]string, 0)
+	next := fmt.Sprintf("/v2/%s/tags/list?n=%d", name, pageSize)
+
+	for next != "" {
+		page := struct {
+			Tags []string `json:"tags"`
+		}{}
+
+		link, err := c.fetch(ctx, next, pull(name), &page)
+
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, page.Tags...)
+		next = link
+	}
+
+	return result, nil
+}
+
+// Digest resolves the digest of a manifest by tag or digest without
+// downloading the manifest itself.
+func (c *Client) Digest(ctx context.Context, name, reference string) (string, error) {
+	resp, err := c.do(ctx, http.MethodHead, "/v2/"+name+"/manifests/"+reference, pull(name), accepted())
+
+	if err != nil {
+		return "", err
+	}
+
+	defer resp.Body.Close()
+
+	if digest := resp.Header.Get("Docker-Content-Digest"); digest != "" {
+		return digest, nil
+	}
+
+	manifest, err := c.Manifest(ctx, name, reference)
+
+	if err != nil {
+		return "", err
+	}
+
+	return manifest.Digest, nil
+}
+
+// Manifest retrieves a manifest by tag or digest including the blobs and
+// the creation date from the image config.
+func (c *Client) Manifest(ctx context.Context, name, reference string) (*Manifest, error) {
+	resp, err := c.do(ctx, http.MethodGet, "/v2/"+name+"/manifests/"+reference, pull(name), accepted())
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+
+	if err != nil {
+		return nil, err
+	}
+
+	raw := struct {
+		MediaType string       `json:"mediaType"`
+		Config    *Descriptor  `json:"config"`
+		Layers    []Descriptor `json:"layers"`
+		Manifests []Descriptor `json:"manifests"`
+	}{}
+
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return nil, errors.Wrap(err, "failed to parse manifest")
+	}
+
+	result := &Manifest{
+		Digest:    resp.Header.Get("Docker-Content-Digest"),
+		MediaType: raw.MediaType,
+		Blobs:     make([]Descriptor, 0),
+	}
+
+	if result.MediaType == "" {
+		result.MediaType = strings.Split(resp.Header.Get("Content-Type"), ";")[0]
+	}
+
+	if result.Digest == "" {
+		sum := sha256.Sum256(body)
+		result.Digest = "sha256:" + hex.EncodeToString(sum[:])
+	}
+
+	if raw.Config != nil {
+		result.Blobs = append(result.Blobs, *raw.Config)
+
+		if result.Created, err = c.created(ctx, name, raw.Config.Digest); err != nil {
+			return nil, err
+		}
+	}
+
+	result.Blobs = append(result.Blobs, raw.Layers...)
+
+	for _, child := range raw.Manifests {
+		manifest, err := c.Manifest(ctx, name, child.Digest)
+
+		if err != nil {
+			return nil, err
+		}
+
+		result.Blobs = append(result.Blobs, manifest.Blobs...)
+
+		if manifest.Created != nil && (result.Created == nil || manifest.Created.After(*result.Created)) {
+			result.Created = manifest.Created
+		}
+	}
+
+	result.Blobs = unique(result.Blobs)
+
+	for _, blob := range result.Blobs {
+		result.Size += blob.Size
+	}
+
+	return result, nil
+}
+
+// DeleteManifest deletes a manifest by digest, or only the tag if the
+// reference is a tag and the registry supports deleting tags.
+func (c *Client) DeleteManifest(ctx context.Context, name, reference string) error {
+	resp, err := c.do(
+		ctx,
+		http.MethodDelete,
+		"/v2/"+name+"/manifests/"+reference,
+		remove(name),
+		accepted(),
+	)
+
+	if err != nil {
+		return err
+	}
+
+	return resp.Body.Close()
+}
+
+// created reads the creation date from the config blob of an image.
+func (c *Client) created(ctx context.Context, name, digest string) (*time.Time, error) {
+	config := struct {
+		Created *time.Time `json:"created"`
+	}{}
+
+	if _, err := c.fetch(ctx, "/v2/"+name+"/blobs/"+digest, pull(name), &config); err != nil {
+		return nil, err
+	}
+
+	if config.Created != nil {
+		created := config.Created.UTC()
+		return &created, nil
+	}
+
+	return nil, nil
+}
+
+// fetch decodes the JSON response of a GET request and returns the next
+// page from the Link header if there is any.
+func (c *Client) fetch(ctx context.Context, path, scope string, result interface{}) (string, error) {
+	resp, err := c.do(ctx, http.MethodGet, path, scope, "application/json")
+
+	if err != nil {
+		return "", err
+	}
+
+	defer resp.Body.Close()
+
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil && err != io.EOF {
+		return "", errors.Wrapf(err, "failed to parse response of %s", path)
+	}
+
+	if match := linkNext.FindStringSubmatch(resp.Header.Get("Link")); match != nil {
+		return match[1], nil
+	}
+
+	return "", nil
+}
+
+// do executes a request against the registry with a token for the scope,
+// responses with an unexpected status code are converted to errors.
+func (c *Client) do(ctx context.Context, method, path, scope, accept string) (*http.Response, error) {
+	target, err := c.endpoint.Parse(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(method, target.String(), nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	req = req.WithContext(ctx)
+	req.Header.Set("Accept", accept)
+
+	if c.token != nil {
+		token, err := c.token(scope)
+
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to sign registry token")
+		}
+
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+	}
+
+	resp, err := c.http.Do(req)
+
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		resp.Body.Close()
+		return nil, ErrNotFound
+	case resp.StatusCode == http.StatusMethodNotAllowed:
+		resp.Body.Close()
+		return nil, ErrUnsupported
+	case resp.StatusCode >= http.StatusBadRequest:
+		resp.Body.Close()
+		return nil, errors.Errorf("unexpected status %d for %s %s", resp.StatusCode, method, path)
+	}
+
+	return resp, nil
+}
+
+// New initializes a client for the registry API at the endpoint, the HTTP
+// client defaults to a client with a sane timeout if nil.
+func New(endpoint string, client *http.Client, token TokenFunc) (*Client, error) {
+	parsed, err := url.Parse(strings.TrimSuffix(endpoint, "/"))
+
+	if err != nil {
+		return nil, err
+	}
+
+	if parsed.Scheme == "" || parsed.Host == "" {
+		return nil, errors.Errorf("invalid registry endpoint %q", endpoint)
+	}
+
+	if client == nil {
+		client = &http.Client{
+			Timeout: 30 * time.Second,
+		}
+	}
+
+	return &Client{
+		endpoint: parsed,
+		http:     client,
+		token:    token,
+	}, nil
+}
+
+// pull builds the scope to pull from a repository.
+func pull(name string) string {
+	return "repository:" + name + ":pull"
+}
+
+// remove builds the scope to delete from a repository.
+func remove(name string) string {
+	return "repository:" + name + ":delete"
+}
+
+// accepted lists the manifest media types understood by the client.
+func accepted() string {
+	return strings.Join([]string{
+		MediaTypeManifest,
+		MediaTypeManifestList,
+		MediaTypeImageManifest,
+		MediaTypeImageIndex,
+	}, ", ")
+}
+
+// unique drops duplicated blobs shared between manifests of a list.
+func unique(blobs []Descriptor) []Descriptor {
+	seen := make(map[string]bool, len(blobs))
+	result := make([]Descriptor, 0, len(blobs))
+
+	for _, blob := range blobs {
+		if seen[blob.Digest] {
+			continue
+		}
+
+		seen[blob.Digest] = true
+		result = append(result, blob)
+	}
+
+	return result
+}
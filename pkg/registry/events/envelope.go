@@ -0,0 +1,74 @@
+package events
+
+import (
+	"time"
+
+	"github.com/umschlag/umschlag-api/pkg/registry/client"
+)
+
+const (
+	// ActionPush defines the action for pushed blobs and manifests.
+	ActionPush = "push"
+
+	// ActionPull defines the action for pulled blobs and manifests.
+	ActionPull = "pull"
+
+	// ActionDelete defines the action for deleted manifests and tags.
+	ActionDelete = "delete"
+
+	// ActionMount defines the action for cross repository blob mounts.
+	ActionMount = "mount"
+)
+
+// Envelope represents a notification request of docker distribution.
+type Envelope struct {
+	Events []*Event `json:"events"`
+}
+
+// Event represents a single event within the notification envelope.
+type Event struct {
+	ID        string    `json:"id"`
+	Timestamp time.Time `json:"timestamp"`
+	Action    string    `json:"action"`
+	Target    Target    `json:"target"`
+	Request   Request   `json:"request"`
+	Actor     Actor     `json:"actor"`
+}
+
+// Target represents the blob or manifest affected by an event.
+type Target struct {
+	MediaType  string `json:"mediaType"`
+	Size       int64  `json:"size"`
+	Digest     string `json:"digest"`
+	Length     int64  `json:"length"`
+	Repository string `json:"repository"`
+	URL        string `json:"url"`
+	Tag        string `json:"tag"`
+}
+
+// Manifest checks if the target is a manifest and not a blob.
+func (t Target) Manifest() bool {
+	switch t.MediaType {
+	case client.MediaTypeManifest,
+		client.MediaTypeManifestList,
+		client.MediaTypeImageManifest,
+		client.MediaTypeImageIndex:
+		return true
+	}
+
+	return false
+}
+
+// Request represents the request which triggered an event.
+type Request struct {
+	ID        string `json:"id"`
+	Addr      string `json:"addr"`
+	Host      string `json:"host"`
+	Method    string `json:"method"`
+	UserAgent string `json:"useragent"`
+}
+
+// Actor represents the user who triggered an event.
+type Actor struct {
+	Name string `json:"name"`
+}
@@ -0,0 +1,310 @@
+// Package events receives the notifications of docker distribution and
+// applies them to the store without waiting for the next catalog sync.
+package events
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/rs/zerolog/log"
+	"github.com/umschlag/umschlag-api/pkg/config"
+	"github.com/umschlag/umschlag-api/pkg/model"
+	"github.com/umschlag/umschlag-api/pkg/registry/auth"
+	"github.com/umschlag/umschlag-api/pkg/registry/catalog"
+	"github.com/umschlag/umschlag-api/pkg/registry/client"
+	"github.com/umschlag/umschlag-api/pkg/store"
+	"github.com/umschlag/umschlag-api/pkg/token"
+)
+
+const (
+	// maxEnvelope defines the maximum accepted size of a notification.
+	maxEnvelope = 4 << 20
+)
+
+var (
+	// ErrInvalidToken is returned when the request token is invalid.
+	ErrInvalidToken = errors.New("invalid or missing token")
+)
+
+var (
+	overwrites = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: "umschlag_api",
+		Subsystem: "registry",
+		Name:      "immutable_overwrites_total",
+		Help:      "How many immutable tags have been overwritten.",
+	})
+)
+
+// Receiver implements the notification endpoint for docker distribution.
+type Receiver struct {
+	config  *config.Config
+	storage store.Store
+	syncer  *catalog.Syncer
+	handled *handled
+}
+
+// ServeHTTP handles the notifications sent by the registry. Failed events
+// respond with an error, so the registry retries the whole envelope, events
+// which have already been applied get skipped on these retries.
+func (r *Receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !r.authenticate(req) {
+		http.Error(w, ErrInvalidToken.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	envelope := &Envelope{}
+
+	if err := json.NewDecoder(io.LimitReader(req.Body, maxEnvelope)).Decode(envelope); err != nil {
+		http.Error(w, "failed to parse notification", http.StatusBadRequest)
+		return
+	}
+
+	failed := false
+
+	for _, event := range envelope.Events {
+		if !r.handled.reserve(event.ID) {
+			log.Debug().
+				Str("event", event.ID).
+				Msg("skipped already applied registry event")
+
+			continue
+		}
+
+		if err := r.apply(req.Context(), event); err != nil {
+			r.handled.release(event.ID)
+
+			log.Error().
+				Err(err).
+				Str("event", event.ID).
+				Str("action", event.Action).
+				Str("repository", event.Target.Repository).
+				Msg("failed to apply registry event")
+
+			failed = true
+		}
+	}
+
+	if failed {
+		http.Error(w, "failed to apply events", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// authenticate checks the shared token sent by the registry.
+func (r *Receiver) authenticate(req *http.Request) bool {
+	header := req.Header.Get("Authorization")
+
+	if header == "" {
+		return false
+	}
+
+	return subtle.ConstantTimeCompare(
+		[]byte(header),
+		[]byte("Bearer "+r.config.Registry.EventsToken),
+	) == 1
+}
+
+// apply updates the store based on a single event, blob events and events
+// for unknown namespaces are ignored.
+func (r *Receiver) apply(ctx context.Context, event *Event) error {
+	switch event.Action {
+	case ActionPush:
+		if !event.Target.Manifest() {
+			return nil
+		}
+
+		return r.push(ctx, event)
+	case ActionPull:
+		if !event.Target.Manifest() || event.Target.Tag == "" {
+			return nil
+		}
+
+		return r.pull(ctx, event)
+	case ActionDelete:
+		return r.delete(ctx, event)
+	}
+
+	return nil
+}
+
+// push stores the pushed manifest and points the tag to it, the repository
+// gets created if it's not known yet.
+func (r *Receiver) push(ctx context.Context, event *Event) error {
+	repository, err := catalog.Resolve(ctx, r.storage, event.Target.Repository, true)
+
+	if err != nil || repository == nil {
+		return err
+	}
+
+	if err := r.manifest(ctx, repository, event); err == client.ErrNotFound {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	if event.Target.Tag == "" {
+		return nil
+	}
+
+	current, err := r.storage.Tags().Show(ctx, repository.ID, event.Target.Tag)
+
+	if err != nil && err != store.ErrNotFound {
+		return err
+	}
+
+	if _, err := r.storage.Tags().Save(ctx, &model.Tag{
+		RepositoryID: repository.ID,
+		Name:         event.Target.Tag,
+		Digest:       event.Target.Digest,
+	}); err != nil {
+		return err
+	}
+
+	if current != nil && current.Digest != event.Target.Digest {
+		if err := r.overwrite(ctx, repository, current, event); err != nil {
+			return err
+		}
+	}
+
+	log.Debug().
+		Str("repository", event.Target.Repository).
+		Str("tag", event.Target.Tag).
+		Str("digest", event.Target.Digest).
+		Msg("pushed registry tag")
+
+	return nil
+}
+
+// overwrite flags a tag which got pointed to another manifest and alerts
+// about it if the tag is immutable within the namespace. Docker tokens only
+// scope repositories, so overwrites can't be refused upfront.
+func (r *Receiver) overwrite(ctx context.Context, repository *model.Repository, current *model.Tag, event *Event) error {
+	rules, err := r.storage.Immutables().List(ctx, repository.NamespaceID)
+
+	if err != nil {
+		return err
+	}
+
+	if !model.Immutables(rules, current.Name) {
+		return nil
+	}
+
+	if err := r.storage.Tags().Flag(ctx, repository.ID, current.Name); err != nil {
+		return err
+	}
+
+	overwrites.Inc()
+
+	log.Warn().
+		Str("repository", event.Target.Repository).
+		Str("tag", current.Name).
+		Str("previous", current.Digest).
+		Str("digest", event.Target.Digest).
+		Str("actor", event.Actor.Name).
+		Msg("overwritten immutable registry tag")
+
+	return nil
+}
+
+// manifest stores the manifest of an event. Without a configured registry
+// url the blobs can't be fetched, so only the digest and media type are
+// recorded until the manifest gets stored by the catalog sync.
+func (r *Receiver) manifest(ctx context.Context, repository *model.Repository, event *Event) error {
+	if r.syncer != nil {
+		return r.syncer.Manifest(ctx, repository, event.Target.Repository, event.Target.Digest)
+	}
+
+	if _, err := r.storage.Manifests().Show(ctx, repository.ID, event.Target.Digest); err != store.ErrNotFound {
+		return err
+	}
+
+	_, err := r.storage.Manifests().Save(ctx, &model.Manifest{
+		RepositoryID: repository.ID,
+		Digest:       event.Target.Digest,
+		MediaType:    event.Target.MediaType,
+		Blobs:        make([]*model.Blob, 0),
+	})
+
+	return err
+}
+
+// pull increments the pull counter of the pulled tag.
+func (r *Receiver) pull(ctx context.Context, event *Event) error {
+	repository, err := catalog.Resolve(ctx, r.storage, event.Target.Repository, false)
+
+	if err != nil || repository == nil {
+		return err
+	}
+
+	if err := r.storage.Tags().Pull(ctx, repository.ID, event.Target.Tag); err != nil && err != store.ErrNotFound {
+		return err
+	}
+
+	return nil
+}
+
+// delete removes the deleted tag, or the manifest together with all of its
+// tags if the event doesn't reference a tag.
+func (r *Receiver) delete(ctx context.Context, event *Event) error {
+	repository, err := catalog.Resolve(ctx, r.storage, event.Target.Repository, false)
+
+	if err != nil || repository == nil {
+		return err
+	}
+
+	switch {
+	case event.Target.Tag != "":
+		err = r.storage.Tags().Delete(ctx, repository.ID, event.Target.Tag)
+	case event.Target.Digest != "":
+		err = r.storage.Manifests().Delete(ctx, repository.ID, event.Target.Digest)
+	}
+
+	if err != nil && err != store.ErrNotFound {
+		return err
+	}
+
+	log.Debug().
+		Str("repository", event.Target.Repository).
+		Str("tag", event.Target.Tag).
+		Str("digest", event.Target.Digest).
+		Msg("deleted registry manifest")
+
+	return nil
+}
+
+// New initializes the notification receiver, manifests get fetched from the
+// registry if its url is configured.
+func New(cfg *config.Config, storage store.Store, keys *token.KeySet) *Receiver {
+	r := &Receiver{
+		config:  cfg,
+		storage: storage,
+		handled: newHandled(maxHandled),
+	}
+
+	if cfg.Registry.URL != "" {
+		registry, err := auth.Client(cfg, keys)
+
+		if err != nil {
+			log.Warn().
+				Err(err).
+				Msg("failed to initialize registry client for events")
+		} else {
+			r.syncer = catalog.New(storage, registry)
+		}
+	}
+
+	return r
+}
@@ -0,0 +1,132 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/umschlag/umschlag-api/pkg/config"
+	"github.com/umschlag/umschlag-api/pkg/model"
+	"github.com/umschlag/umschlag-api/pkg/registry/client"
+	"github.com/umschlag/umschlag-api/pkg/store"
+	"github.com/umschlag/umschlag-api/pkg/store/memory"
+)
+
+// failingStore fails the first pull of the broken tag.
+type failingStore struct {
+	store.Store
+	failed bool
+}
+
+func (s *failingStore) Tags() store.TagStore {
+	return &failingTags{
+		TagStore: s.Store.Tags(),
+		parent:   s,
+	}
+}
+
+type failingTags struct {
+	store.TagStore
+	parent *failingStore
+}
+
+func (t *failingTags) Pull(ctx context.Context, repositoryID, name string) error {
+	if name == "broken" && !t.parent.failed {
+		t.parent.failed = true
+		return errors.New("temporary failure")
+	}
+
+	return t.TagStore.Pull(ctx, repositoryID, name)
+}
+
+func TestRetryIsIdempotent(t *testing.T) {
+	ctx := context.Background()
+	dsn, _ := url.Parse("memory://")
+	storage := &failingStore{Store: memory.Must(dsn)}
+
+	user, err := storage.Users().Create(ctx, &model.User{Username: "jane", Email: "jane@example.com"})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	namespace, err := storage.Namespaces().Create(ctx, &model.Namespace{Name: "core", UserID: user.ID})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &config.Config{}
+	cfg.Registry.EventsToken = "secret"
+	receiver := New(cfg, storage, nil)
+
+	send(t, receiver, http.StatusOK,
+		event("push-1", ActionPush, "latest"),
+		event("push-2", ActionPush, "broken"),
+	)
+
+	envelope := []*Event{
+		event("pull-1", ActionPull, "latest"),
+		event("pull-2", ActionPull, "broken"),
+	}
+
+	send(t, receiver, http.StatusInternalServerError, envelope...)
+	send(t, receiver, http.StatusOK, envelope...)
+	send(t, receiver, http.StatusOK, envelope...)
+
+	repository, err := storage.Repositories().Show(ctx, namespace.ID, "app")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{"latest", "broken"} {
+		tag, err := storage.Tags().Show(ctx, repository.ID, name)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if tag.Pulls != 1 {
+			t.Errorf("expected 1 pull of %s, got %d", name, tag.Pulls)
+		}
+	}
+}
+
+func event(id, action, tag string) *Event {
+	return &Event{
+		ID:     id,
+		Action: action,
+		Target: Target{
+			MediaType:  client.MediaTypeManifest,
+			Digest:     "sha256:" + tag,
+			Repository: "core/app",
+			Tag:        tag,
+		},
+	}
+}
+
+func send(t *testing.T, receiver *Receiver, code int, events ...*Event) {
+	t.Helper()
+
+	body, err := json.Marshal(&Envelope{Events: events})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/registry/events", bytes.NewReader(body))
+	req.Header.Set("Authorization", "Bearer secret")
+
+	rec := httptest.NewRecorder()
+	receiver.ServeHTTP(rec, req)
+
+	if rec.Code != code {
+		t.Fatalf("expected status %d, got %d: %s", code, rec.Code, rec.Body.String())
+	}
+}
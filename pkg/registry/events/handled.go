@@ -0,0 +1,64 @@
+package events
+
+import (
+	"sync"
+)
+
+const (
+	// maxHandled defines how many event ids are remembered.
+	maxHandled = 10000
+)
+
+// handled remembers the ids of applied events, so events of envelopes the
+// registry sends again after a partial failure are not applied twice.
+type handled struct {
+	mu    sync.Mutex
+	ids   map[string]bool
+	order []string
+	next  int
+}
+
+// newHandled initializes a set with the given capacity.
+func newHandled(limit int) *handled {
+	return &handled{
+		ids:   make(map[string]bool, limit),
+		order: make([]string, limit),
+	}
+}
+
+// reserve marks the event as handled, it returns false if the event has
+// already been reserved before. Events without id are always applied.
+func (h *handled) reserve(id string) bool {
+	if id == "" {
+		return true
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.ids[id] {
+		return false
+	}
+
+	if evicted := h.order[h.next]; evicted != "" {
+		delete(h.ids, evicted)
+	}
+
+	h.ids[id] = true
+	h.order[h.next] = id
+	h.next = (h.next + 1) % len(h.order)
+
+	return true
+}
+
+// release forgets a reserved event, so it gets applied on the next retry.
+func (h *handled) release(id string) {
+	if id == "" {
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	delete(h.ids, id)
+}
@@ -0,0 +1,274 @@
+// Package quota calculates the deduplicated registry storage usage of
+// namespaces and teams based on the blobs of the synced manifests.
+package quota
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/umschlag/umschlag-api/pkg/model"
+	"github.com/umschlag/umschlag-api/pkg/store"
+)
+
+// Namespace calculates the usage of a namespace, blobs shared between the
+// repositories are only counted once.
+func Namespace(ctx context.Context, storage store.Store, namespace *model.Namespace) (*model.Usage, error) {
+	usage := &model.Usage{
+		Items: make([]*model.UsageItem, 0),
+	}
+
+	total := make(map[string]int64)
+	repositories, err := storage.Repositories().List(ctx, namespace.ID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, repository := range repositories {
+		blobs, err := repositoryBlobs(ctx, storage, repository.ID)
+
+		if err != nil {
+			return nil, err
+		}
+
+		usage.Items = append(usage.Items, &model.UsageItem{
+			ID:   repository.ID,
+			Name: repository.Name,
+			Size: sum(blobs),
+		})
+
+		merge(total, blobs)
+	}
+
+	usage.Size = sum(total)
+	return usage, nil
+}
+
+// Team calculates the usage of all namespaces owned by a team, blobs shared
+// between the namespaces are only counted once.
+func Team(ctx context.Context, storage store.Store, teamID string) (*model.Usage, error) {
+	usage := &model.Usage{
+		Items: make([]*model.UsageItem, 0),
+	}
+
+	total := make(map[string]int64)
+	namespaces, err := storage.Namespaces().List(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, namespace := range namespaces {
+		if namespace.TeamID != teamID {
+			continue
+		}
+
+		blobs, err := namespaceBlobs(ctx, storage, namespace.ID)
+
+		if err != nil {
+			return nil, err
+		}
+
+		usage.Items = append(usage.Items, &model.UsageItem{
+			ID:   namespace.ID,
+			Name: namespace.Name,
+			Size: sum(blobs),
+		})
+
+		merge(total, blobs)
+	}
+
+	usage.Size = sum(total)
+	return usage, nil
+}
+
+// Cache remembers the calculated usage of namespaces and teams for a short
+// time, so token requests for pushes don't walk all manifests every time.
+// The usage is only updated by the catalog sync and the registry events
+// anyway, the quotas themselves are always read from the store.
+type Cache struct {
+	storage store.Store
+	ttl     time.Duration
+	mu      sync.Mutex
+	entries map[string]*entry
+}
+
+type entry struct {
+	size    int64
+	expires time.Time
+}
+
+// Allowed checks if pushes into the namespace are allowed. The quota of the
+// namespace and the quota of the owning team are checked, reaching a soft
+// limit only logs a warning.
+func (c *Cache) Allowed(ctx context.Context, namespace *model.Namespace) (bool, error) {
+	quota, err := c.storage.Quotas().Show(ctx, model.QuotaNamespace, namespace.ID)
+
+	switch {
+	case err == nil:
+		size, err := c.size(ctx, model.QuotaNamespace, namespace.ID, func() (*model.Usage, error) {
+			return Namespace(ctx, c.storage, namespace)
+		})
+
+		if err != nil {
+			return false, err
+		}
+
+		if !check(quota, size, namespace.Name) {
+			return false, nil
+		}
+	case err != store.ErrNotFound:
+		return false, err
+	}
+
+	if namespace.TeamID == "" {
+		return true, nil
+	}
+
+	quota, err = c.storage.Quotas().Show(ctx, model.QuotaTeam, namespace.TeamID)
+
+	switch {
+	case err == nil:
+		size, err := c.size(ctx, model.QuotaTeam, namespace.TeamID, func() (*model.Usage, error) {
+			return Team(ctx, c.storage, namespace.TeamID)
+		})
+
+		if err != nil {
+			return false, err
+		}
+
+		return check(quota, size, namespace.Name), nil
+	case err != store.ErrNotFound:
+		return false, err
+	}
+
+	return true, nil
+}
+
+// size returns the cached usage or calculates it if it's missing or expired.
+func (c *Cache) size(ctx context.Context, kind, id string, calculate func() (*model.Usage, error)) (int64, error) {
+	key := kind + ":" + id
+
+	c.mu.Lock()
+	cached, ok := c.entries[key]
+	c.mu.Unlock()
+
+	if ok && time.Now().Before(cached.expires) {
+		return cached.size, nil
+	}
+
+	usage, err := calculate()
+
+	if err != nil {
+		return 0, err
+	}
+
+	c.mu.Lock()
+	c.entries[key] = &entry{
+		size:    usage.Size,
+		expires: time.Now().Add(c.ttl),
+	}
+	c.mu.Unlock()
+
+	return usage.Size, nil
+}
+
+// check compares the usage with the limits of the quota.
+func check(quota *model.Quota, size int64, namespace string) bool {
+	if quota.Exceeded(size) {
+		log.Warn().
+			Str("namespace", namespace).
+			Str("kind", quota.Kind).
+			Int64("usage", size).
+			Int64("hard", quota.Hard).
+			Msg("registry quota exceeded, denied push")
+
+		return false
+	}
+
+	if quota.Warned(size) {
+		log.Warn().
+			Str("namespace", namespace).
+			Str("kind", quota.Kind).
+			Int64("usage", size).
+			Int64("soft", quota.Soft).
+			Msg("registry quota reached soft limit")
+	}
+
+	return true
+}
+
+// namespaceBlobs collects the blobs of all repositories in a namespace.
+func namespaceBlobs(ctx context.Context, storage store.Store, namespaceID string) (map[string]int64, error) {
+	result := make(map[string]int64)
+	repositories, err := storage.Repositories().List(ctx, namespaceID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, repository := range repositories {
+		blobs, err := repositoryBlobs(ctx, storage, repository.ID)
+
+		if err != nil {
+			return nil, err
+		}
+
+		merge(result, blobs)
+	}
+
+	return result, nil
+}
+
+// repositoryBlobs collects the blobs of all manifests in a repository by
+// digest. Manifests without known blobs count with their own size.
+func repositoryBlobs(ctx context.Context, storage store.Store, repositoryID string) (map[string]int64, error) {
+	result := make(map[string]int64)
+	manifests, err := storage.Manifests().List(ctx, repositoryID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, manifest := range manifests {
+		if len(manifest.Blobs) == 0 {
+			result[manifest.Digest] = manifest.Size
+			continue
+		}
+
+		for _, blob := range manifest.Blobs {
+			result[blob.Digest] = blob.Size
+		}
+	}
+
+	return result, nil
+}
+
+// merge adds the blobs to the target.
+func merge(target, blobs map[string]int64) {
+	for digest, size := range blobs {
+		target[digest] = size
+	}
+}
+
+// sum adds up the sizes of the blobs.
+func sum(blobs map[string]int64) int64 {
+	var result int64
+
+	for _, size := range blobs {
+		result += size
+	}
+
+	return result
+}
+
+// NewCache initializes a usage cache keeping the usage for the ttl.
+func NewCache(storage store.Store, ttl time.Duration) *Cache {
+	return &Cache{
+		storage: storage,
+		ttl:     ttl,
+		entries: make(map[string]*entry),
+	}
+}
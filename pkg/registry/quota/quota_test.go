@@ -0,0 +1,162 @@
+package quota
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/umschlag/umschlag-api/pkg/model"
+	"github.com/umschlag/umschlag-api/pkg/store"
+	"github.com/umschlag/umschlag-api/pkg/store/memory"
+)
+
+func prepare(t *testing.T) (store.Store, *model.Namespace, *model.Repository) {
+	t.Helper()
+
+	ctx := context.Background()
+	dsn, _ := url.Parse("memory://")
+	storage := memory.Must(dsn)
+
+	team, err := storage.Teams().Create(ctx, &model.Team{Name: "core"})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	namespace, err := storage.Namespaces().Create(ctx, &model.Namespace{Name: "core", TeamID: team.ID})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	repository, err := storage.Repositories().Create(ctx, &model.Repository{NamespaceID: namespace.ID, Name: "app"})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return storage, namespace, repository
+}
+
+func push(t *testing.T, storage store.Store, repository *model.Repository, digest string, blobs ...*model.Blob) {
+	t.Helper()
+
+	if _, err := storage.Manifests().Save(context.Background(), &model.Manifest{
+		RepositoryID: repository.ID,
+		Digest:       digest,
+		Blobs:        blobs,
+	}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func limit(t *testing.T, storage store.Store, kind, ownerID string, hard int64) {
+	t.Helper()
+
+	if _, err := storage.Quotas().Save(context.Background(), &model.Quota{
+		Kind:    kind,
+		OwnerID: ownerID,
+		Hard:    hard,
+	}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAllowed(t *testing.T) {
+	tests := []struct {
+		name    string
+		kind    string
+		hard    int64
+		allowed bool
+	}{
+		{
+			name:    "below namespace quota",
+			kind:    model.QuotaNamespace,
+			hard:    200,
+			allowed: true,
+		},
+		{
+			name:    "namespace quota reached",
+			kind:    model.QuotaNamespace,
+			hard:    150,
+			allowed: false,
+		},
+		{
+			name:    "below team quota",
+			kind:    model.QuotaTeam,
+			hard:    200,
+			allowed: true,
+		},
+		{
+			name:    "team quota reached",
+			kind:    model.QuotaTeam,
+			hard:    150,
+			allowed: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			storage, namespace, repository := prepare(t)
+
+			shared := &model.Blob{Digest: "sha256:shared", Size: 100}
+			push(t, storage, repository, "sha256:first", shared, &model.Blob{Digest: "sha256:first-layer", Size: 25})
+			push(t, storage, repository, "sha256:second", shared, &model.Blob{Digest: "sha256:second-layer", Size: 25})
+
+			owner := namespace.ID
+
+			if tt.kind == model.QuotaTeam {
+				owner = namespace.TeamID
+			}
+
+			limit(t, storage, tt.kind, owner, tt.hard)
+
+			allowed, err := NewCache(storage, time.Minute).Allowed(context.Background(), namespace)
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if allowed != tt.allowed {
+				t.Errorf("expected allowed to be %v, got %v", tt.allowed, allowed)
+			}
+		})
+	}
+}
+
+func TestCache(t *testing.T) {
+	ctx := context.Background()
+	storage, namespace, repository := prepare(t)
+
+	push(t, storage, repository, "sha256:first", &model.Blob{Digest: "sha256:layer", Size: 100})
+	limit(t, storage, model.QuotaNamespace, namespace.ID, 150)
+
+	cache := NewCache(storage, time.Minute)
+
+	if allowed, err := cache.Allowed(ctx, namespace); err != nil || !allowed {
+		t.Fatalf("expected push to be allowed, got %v, %v", allowed, err)
+	}
+
+	push(t, storage, repository, "sha256:second", &model.Blob{Digest: "sha256:other", Size: 100})
+
+	if allowed, err := cache.Allowed(ctx, namespace); err != nil || !allowed {
+		t.Errorf("expected cached usage to be used, got %v, %v", allowed, err)
+	}
+
+	limit(t, storage, model.QuotaNamespace, namespace.ID, 100)
+
+	if allowed, err := cache.Allowed(ctx, namespace); err != nil || allowed {
+		t.Errorf("expected changed quota to apply immediately, got %v, %v", allowed, err)
+	}
+
+	limit(t, storage, model.QuotaNamespace, namespace.ID, 150)
+
+	for _, cached := range cache.entries {
+		cached.expires = time.Now()
+	}
+
+	if allowed, err := cache.Allowed(ctx, namespace); err != nil || allowed {
+		t.Errorf("expected expired usage to be recalculated, got %v, %v", allowed, err)
+	}
+}
@@ -0,0 +1,281 @@
+// Package retention applies the retention policies of namespaces and
+// repositories by deleting outdated manifests through the registry API.
+package retention
+
+import (
+	"context"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+	"github.com/umschlag/umschlag-api/pkg/model"
+	"github.com/umschlag/umschlag-api/pkg/registry/cleanup"
+	"github.com/umschlag/umschlag-api/pkg/store"
+)
+
+var (
+	// ErrInvalid is returned if a policy doesn't keep any tag.
+	ErrInvalid = errors.New("retention policy must keep some tags")
+
+	// ErrPattern is returned if the keep pattern can't be compiled.
+	ErrPattern = errors.New("keep pattern is not a valid regular expression")
+)
+
+// Runner evaluates the retention policies and deletes all manifests which
+// are only referenced by tags that are not kept by any rule.
+type Runner struct {
+	storage store.Store
+	cleaner *cleanup.Cleaner
+}
+
+// Run applies all retention policies, failures of a single policy get
+// logged and don't abort the run.
+func (r *Runner) Run(ctx context.Context) error {
+	policies, err := r.storage.Retentions().List(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	for _, policy := range policies {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if _, err := r.Apply(ctx, policy); err != nil {
+			log.Error().
+				Err(err).
+				Str("retention", policy.ID).
+				Msg("failed to apply retention policy")
+		}
+	}
+
+	return nil
+}
+
+// Apply evaluates a single policy and stores the report of the run. The
+// policy of a namespace covers all repositories without an own policy.
+func (r *Runner) Apply(ctx context.Context, policy *model.Retention) (*model.Report, error) {
+	if err := Validate(policy); err != nil {
+		return nil, err
+	}
+
+	report := &model.Report{
+		RetentionID: policy.ID,
+		StartedAt:   time.Now().UTC(),
+		Entries:     make([]*model.ReportEntry, 0),
+	}
+
+	pattern, _ := compile(policy.KeepPattern)
+	namespace, err := r.storage.Namespaces().Show(ctx, policy.NamespaceID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	immutables, err := r.storage.Immutables().List(ctx, namespace.ID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	repositories, err := r.repositories(ctx, policy)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, repository := range repositories {
+		entries, err := r.repository(ctx, policy, pattern, immutables, namespace, repository)
+
+		if err != nil {
+			entries = append(entries, &model.ReportEntry{
+				Repository: namespace.Name + "/" + repository.Name,
+				Tags:       make([]string, 0),
+				Action:     model.ReportFailed,
+				Message:    err.Error(),
+			})
+		}
+
+		report.Entries = append(report.Entries, entries...)
+	}
+
+	report.FinishedAt = time.Now().UTC()
+	return r.storage.Retentions().CreateReport(ctx, report)
+}
+
+// repositories resolves the repositories covered by the policy.
+func (r *Runner) repositories(ctx context.Context, policy *model.Retention) ([]*model.Repository, error) {
+	if policy.RepositoryID != "" {
+		repository, err := r.storage.Repositories().Show(ctx, policy.NamespaceID, policy.RepositoryID)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return []*model.Repository{repository}, nil
+	}
+
+	records, err := r.storage.Repositories().List(ctx, policy.NamespaceID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*model.Repository, 0, len(records))
+
+	for _, record := range records {
+		_, err := r.storage.Retentions().Show(ctx, policy.NamespaceID, record.ID)
+
+		if err == store.ErrNotFound {
+			result = append(result, record)
+			continue
+		}
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+// repository applies the rules to the tags of a single repository. The
+// tags are ordered by the creation date of their images, manifests
+// referenced by a kept or an immutable tag are never deleted.
+func (r *Runner) repository(ctx context.Context, policy *model.Retention, pattern *regexp.Regexp, immutables []*model.Immutable, namespace *model.Namespace, repository *model.Repository) ([]*model.ReportEntry, error) {
+	entries := make([]*model.ReportEntry, 0)
+	tags, err := r.storage.Tags().List(ctx, repository.ID)
+
+	if err != nil {
+		return entries, err
+	}
+
+	manifests, err := r.storage.Manifests().List(ctx, repository.ID)
+
+	if err != nil {
+		return entries, err
+	}
+
+	created := make(map[string]time.Time, len(tags))
+
+	for _, tag := range tags {
+		created[tag.Name] = createdAt(tag, manifests)
+	}
+
+	sort.SliceStable(tags, func(i, j int) bool {
+		return created[tags[i].Name].After(created[tags[j].Name])
+	})
+
+	var (
+		cutoff     = time.Now().UTC().AddDate(0, 0, -policy.OlderThan)
+		protected  = make(map[string]bool)
+		immutable  = make(map[string]bool)
+		candidates = make(map[string][]string)
+		digests    = make([]string, 0)
+	)
+
+	for i, tag := range tags {
+		switch {
+		case i < policy.KeepLast:
+			protected[tag.Digest] = true
+		case pattern != nil && pattern.MatchString(tag.Name):
+			protected[tag.Digest] = true
+		case policy.OlderThan > 0 && created[tag.Name].After(cutoff):
+			protected[tag.Digest] = true
+		case model.Immutables(immutables, tag.Name):
+			immutable[tag.Digest] = true
+			fallthrough
+		default:
+			if _, ok := candidates[tag.Digest]; !ok {
+				digests = append(digests, tag.Digest)
+			}
+
+			candidates[tag.Digest] = append(candidates[tag.Digest], tag.Name)
+		}
+	}
+
+	for _, digest := range digests {
+		entry := &model.ReportEntry{
+			Repository: namespace.Name + "/" + repository.Name,
+			Digest:     digest,
+			Tags:       candidates[digest],
+		}
+
+		if immutable[digest] {
+			entry.Action = model.ReportSkipped
+			entry.Message = "referenced by an immutable tag"
+
+			entries = append(entries, entry)
+			continue
+		}
+
+		if protected[digest] {
+			entry.Action = model.ReportSkipped
+			entry.Message = "referenced by a protected tag"
+
+			entries = append(entries, entry)
+			continue
+		}
+
+		result, err := r.cleaner.Manifest(ctx, namespace, repository, digest, false)
+
+		if err != nil {
+			entry.Action = model.ReportFailed
+			entry.Message = err.Error()
+		} else {
+			entry.Action = model.ReportDeleted
+			entry.Tags = result.Tags
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+// New initializes a runner deleting through the cleaner.
+func New(storage store.Store, cleaner *cleanup.Cleaner) *Runner {
+	return &Runner{
+		storage: storage,
+		cleaner: cleaner,
+	}
+}
+
+// Validate checks if the policy keeps any tag and the keep pattern is a
+// valid regular expression.
+func Validate(policy *model.Retention) error {
+	if !policy.Valid() {
+		return ErrInvalid
+	}
+
+	if _, err := compile(policy.KeepPattern); err != nil {
+		return ErrPattern
+	}
+
+	return nil
+}
+
+// compile parses the keep pattern, an empty pattern keeps nothing.
+func compile(pattern string) (*regexp.Regexp, error) {
+	if pattern == "" {
+		return nil, nil
+	}
+
+	return regexp.Compile(pattern)
+}
+
+// createdAt returns the creation date of the image a tag points to. The
+// tag gets updated on every sync, so its own creation date is only used if
+// the manifest or its creation date is unknown.
+func createdAt(tag *model.Tag, manifests []*model.Manifest) time.Time {
+	for _, manifest := range manifests {
+		if manifest.Digest == tag.Digest && manifest.CreatedAt != nil {
+			return *manifest.CreatedAt
+		}
+	}
+
+	return tag.CreatedAt
+}
@@ -0,0 +1,102 @@
+package retention
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/umschlag/umschlag-api/pkg/model"
+	"github.com/umschlag/umschlag-api/pkg/registry/cleanup"
+	"github.com/umschlag/umschlag-api/pkg/store/memory"
+)
+
+func TestImmutable(t *testing.T) {
+	ctx := context.Background()
+	dsn, _ := url.Parse("memory://")
+	storage := memory.Must(dsn)
+
+	user, err := storage.Users().Create(ctx, &model.User{Username: "jane", Email: "jane@example.com"})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	namespace, err := storage.Namespaces().Create(ctx, &model.Namespace{Name: "core", UserID: user.ID})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	repository, err := storage.Repositories().Create(ctx, &model.Repository{NamespaceID: namespace.ID, Name: "app"})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now().UTC()
+
+	for i, tag := range []struct {
+		name   string
+		digest string
+	}{
+		{name: "latest", digest: "sha256:latest"},
+		{name: "v1.0", digest: "sha256:release"},
+		{name: "dev", digest: "sha256:develop"},
+	} {
+		created := now.AddDate(0, 0, -i)
+
+		if _, err := storage.Manifests().Save(ctx, &model.Manifest{
+			RepositoryID: repository.ID,
+			Digest:       tag.digest,
+			CreatedAt:    &created,
+		}); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := storage.Tags().Save(ctx, &model.Tag{
+			RepositoryID: repository.ID,
+			Name:         tag.name,
+			Digest:       tag.digest,
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := storage.Immutables().Create(ctx, &model.Immutable{NamespaceID: namespace.ID, Pattern: "v*"}); err != nil {
+		t.Fatal(err)
+	}
+
+	policy, err := storage.Retentions().Save(ctx, &model.Retention{NamespaceID: namespace.ID, KeepLast: 1})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := New(storage, cleanup.New(storage, nil)).Apply(ctx, policy)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	actions := make(map[string]string)
+
+	for _, entry := range report.Entries {
+		actions[entry.Digest] = entry.Action
+	}
+
+	expected := map[string]string{
+		"sha256:release": model.ReportSkipped,
+		"sha256:develop": model.ReportFailed,
+	}
+
+	if len(actions) != len(expected) {
+		t.Errorf("expected %d report entries, got %v", len(expected), actions)
+	}
+
+	for digest, action := range expected {
+		if actions[digest] != action {
+			t.Errorf("expected %s to be %s, got %q", digest, action, actions[digest])
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package router
 
 import (
+	"encoding/json"
 	"io"
 	"net/http"
 	"path"
@@ -13,7 +14,10 @@ import (
 	"github.com/umschlag/umschlag-api/pkg/config"
 	"github.com/umschlag/umschlag-api/pkg/middleware/header"
 	"github.com/umschlag/umschlag-api/pkg/middleware/prometheus"
+	"github.com/umschlag/umschlag-api/pkg/registry/auth"
+	"github.com/umschlag/umschlag-api/pkg/registry/events"
 	"github.com/umschlag/umschlag-api/pkg/store"
+	"github.com/umschlag/umschlag-api/pkg/token"
 	"github.com/umschlag/umschlag-api/pkg/upload"
 	"github.com/utahta/swagger-doc"
 
@@ -22,7 +26,7 @@ import (
 )
 
 // Server initializes the routing of the server.
-func Server(cfg *config.Config, storage store.Store, uploads upload.Upload) http.Handler {
+func Server(cfg *config.Config, storage store.Store, uploads upload.Upload, keys *token.KeySet) http.Handler {
 	mux := chi.NewRouter()
 
 	mux.Use(hlog.NewHandler(log.Logger))
@@ -50,6 +54,13 @@ func Server(cfg *config.Config, storage store.Store, uploads upload.Upload) http
 	mux.Use(header.Options)
 
 	mux.Route(cfg.Server.Root, func(root chi.Router) {
+		root.Get("/.well-known/jwks.json", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+
+			json.NewEncoder(w).Encode(keys.JWKS())
+		})
+
 		root.Route("/api", func(base chi.Router) {
 			base.Route("/v1", func(v1 chi.Router) {
 				if cfg.Server.Docs {
@@ -71,11 +82,17 @@ func Server(cfg *config.Config, storage store.Store, uploads upload.Upload) http
 					))
 				}
 
-				if api := apiv1.New(); api != nil {
+				if api := apiv1.New(cfg, storage, uploads, keys); api != nil {
 					v1.Mount("/", middleware.NoCache(api.Handler))
 				}
 			})
 
+			base.Handle("/token", auth.New(cfg, storage, keys))
+
+			if cfg.Registry.EventsToken != "" {
+				base.Handle("/registry/events", events.New(cfg, storage, keys))
+			}
+
 			if cfg.Server.Pprof {
 				base.Mount("/debug", middleware.Profiler())
 			}
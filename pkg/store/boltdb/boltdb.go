@@ -1,25 +1,241 @@
 package boltdb
 
 import (
+	"encoding/json"
 	"net/url"
+	"os"
+	"path"
+	"strconv"
+	"time"
 
+	"github.com/pkg/errors"
 	"github.com/umschlag/umschlag-api/pkg/store"
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	usersBucket            = []byte("users")
+	usersSlugBucket        = []byte("users_slug")
+	usersUsernameBucket    = []byte("users_username")
+	usersEmailBucket       = []byte("users_email")
+	teamsBucket            = []byte("teams")
+	teamsSlugBucket        = []byte("teams_slug")
+	membersBucket          = []byte("members")
+	membersUserBucket      = []byte("members_user")
+	tokensBucket           = []byte("tokens")
+	tokensHashBucket       = []byte("tokens_hash")
+	tokensUserBucket       = []byte("tokens_user")
+	revocationsBucket      = []byte("revocations")
+	revocationsUserBucket  = []byte("revocations_user")
+	namespacesBucket       = []byte("namespaces")
+	namespacesNameBucket   = []byte("namespaces_name")
+	repositoriesBucket     = []byte("repositories")
+	repositoriesNameBucket = []byte("repositories_name")
+	repositoryTeamsBucket  = []byte("repository_teams")
+	tagsBucket             = []byte("tags")
+	manifestsBucket        = []byte("manifests")
+	retentionsBucket       = []byte("retentions")
+	reportsBucket          = []byte("reports")
+	immutablesBucket       = []byte("immutables")
+	quotasBucket           = []byte("quotas")
 )
 
 type boltdb struct {
-	dsn *url.URL
+	dsn    *url.URL
+	handle *bolt.DB
 }
 
 // Close simply closes the BoltDB connection.
 func (s *boltdb) Close() error {
-	return nil
+	return s.handle.Close()
+}
+
+// Users provides access to the stored users.
+func (s *boltdb) Users() store.UserStore {
+	return &users{
+		handle: s.handle,
+	}
+}
+
+// Teams provides access to the stored teams.
+func (s *boltdb) Teams() store.TeamStore {
+	return &teams{
+		handle: s.handle,
+	}
+}
+
+// Members provides access to the team memberships.
+func (s *boltdb) Members() store.MemberStore {
+	return &members{
+		handle: s.handle,
+	}
+}
+
+// Tokens provides access to the personal access tokens.
+func (s *boltdb) Tokens() store.TokenStore {
+	return &tokens{
+		handle: s.handle,
+	}
+}
+
+// Revocations provides access to the revoked tokens.
+func (s *boltdb) Revocations() store.RevocationStore {
+	return &revocations{
+		handle: s.handle,
+	}
+}
+
+// Namespaces provides access to the stored namespaces.
+func (s *boltdb) Namespaces() store.NamespaceStore {
+	return &namespaces{
+		handle: s.handle,
+	}
+}
+
+// Repositories provides access to the stored repositories.
+func (s *boltdb) Repositories() store.RepositoryStore {
+	return &repositories{
+		handle: s.handle,
+	}
+}
+
+// Tags provides access to the tags of repositories.
+func (s *boltdb) Tags() store.TagStore {
+	return &tags{
+		handle: s.handle,
+	}
+}
+
+// Manifests provides access to the manifests of repositories.
+func (s *boltdb) Manifests() store.ManifestStore {
+	return &manifests{
+		handle: s.handle,
+	}
+}
+
+// Retentions provides access to the retention policies.
+func (s *boltdb) Retentions() store.RetentionStore {
+	return &retentions{
+		handle: s.handle,
+	}
+}
+
+// Immutables provides access to the immutable tag rules.
+func (s *boltdb) Immutables() store.ImmutableStore {
+	return &immutables{
+		handle: s.handle,
+	}
+}
+
+// Quotas provides access to the storage quotas.
+func (s *boltdb) Quotas() store.QuotaStore {
+	return &quotas{
+		handle: s.handle,
+	}
+}
+
+// prepare creates all required buckets if they are missing.
+func (s *boltdb) prepare() error {
+	return s.handle.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{
+			usersBucket,
+			usersSlugBucket,
+			usersUsernameBucket,
+			usersEmailBucket,
+			teamsBucket,
+			teamsSlugBucket,
+			membersBucket,
+			membersUserBucket,
+			tokensBucket,
+			tokensHashBucket,
+			tokensUserBucket,
+			revocationsBucket,
+			revocationsUserBucket,
+			namespacesBucket,
+			namespacesNameBucket,
+			repositoriesBucket,
+			repositoriesNameBucket,
+			repositoryTeamsBucket,
+			tagsBucket,
+			manifestsBucket,
+			retentionsBucket,
+			reportsBucket,
+			immutablesBucket,
+			quotasBucket,
+		} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return errors.Wrapf(err, "failed to create %s bucket", name)
+			}
+		}
+
+		return nil
+	})
+}
+
+// timeout retrieves the lock timeout from dsn or fallback.
+func (s *boltdb) timeout() time.Duration {
+	if val := s.dsn.Query().Get("timeout"); val != "" {
+		timeout, err := time.ParseDuration(val)
+
+		if err != nil {
+			return time.Second
+		}
+
+		return timeout
+	}
+
+	return time.Second
+}
+
+// perms retrieves the file perms from dsn or fallback.
+func (s *boltdb) perms() os.FileMode {
+	if val := s.dsn.Query().Get("perms"); val != "" {
+		u, err := strconv.ParseUint(val, 8, 32)
+
+		if err != nil {
+			return 0600
+		}
+
+		return os.FileMode(u)
+	}
+
+	return 0600
+}
+
+// path cleans the dsn and returns a valid path.
+func (s *boltdb) path() string {
+	return path.Join(
+		s.dsn.Host,
+		s.dsn.EscapedPath(),
+	)
 }
 
 // New initializes a new BoltDB connection.
 func New(dsn *url.URL) (store.Store, error) {
-	return &boltdb{
+	s := &boltdb{
 		dsn: dsn,
-	}, nil
+	}
+
+	handle, err := bolt.Open(
+		s.path(),
+		s.perms(),
+		&bolt.Options{
+			Timeout: s.timeout(),
+		},
+	)
+
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open database")
+	}
+
+	s.handle = handle
+
+	if err := s.prepare(); err != nil {
+		handle.Close()
+		return nil, err
+	}
+
+	return s, nil
 }
 
 // Must simply calls New and panics on an error.
@@ -32,3 +248,60 @@ func Must(dsn *url.URL) store.Store {
 
 	return db
 }
+
+// get decodes the record stored for key into the record.
+func get(bucket *bolt.Bucket, key []byte, record interface{}) error {
+	raw := bucket.Get(key)
+
+	if raw == nil {
+		return store.ErrNotFound
+	}
+
+	return json.Unmarshal(raw, record)
+}
+
+// put encodes the record and stores it for the key.
+func put(bucket *bolt.Bucket, key []byte, record interface{}) error {
+	raw, err := json.Marshal(record)
+
+	if err != nil {
+		return err
+	}
+
+	return bucket.Put(key, raw)
+}
+
+// index updates a unique secondary index, the old value gets removed and a
+// conflict with another record is reported by the provided error.
+func index(bucket *bolt.Bucket, id, old, val string, conflict error) error {
+	if old == val {
+		return nil
+	}
+
+	if val != "" {
+		if owner := bucket.Get([]byte(val)); owner != nil && string(owner) != id {
+			return conflict
+		}
+	}
+
+	if old != "" {
+		if err := bucket.Delete([]byte(old)); err != nil {
+			return err
+		}
+	}
+
+	if val != "" {
+		return bucket.Put([]byte(val), []byte(id))
+	}
+
+	return nil
+}
+
+// lookup resolves a record ID by the primary key or a secondary index.
+func lookup(tx *bolt.Tx, primary, secondary []byte, val string) []byte {
+	if tx.Bucket(primary).Get([]byte(val)) != nil {
+		return []byte(val)
+	}
+
+	return tx.Bucket(secondary).Get([]byte(val))
+}
@@ -0,0 +1,25 @@
+package boltdb
+
+import (
+	"net/url"
+	"path/filepath"
+	"testing"
+
+	"github.com/umschlag/umschlag-api/pkg/store"
+	"github.com/umschlag/umschlag-api/pkg/store/storetest"
+)
+
+func TestStore(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) store.Store {
+		s, err := New(&url.URL{
+			Scheme: "boltdb",
+			Path:   filepath.Join(t.TempDir(), "umschlag.db"),
+		})
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return s
+	})
+}
@@ -0,0 +1,101 @@
+package boltdb
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/umschlag/umschlag-api/pkg/model"
+	"github.com/umschlag/umschlag-api/pkg/store"
+	bolt "go.etcd.io/bbolt"
+)
+
+type immutables struct {
+	handle *bolt.DB
+}
+
+// List retrieves all immutable tag rules of a namespace.
+func (i *immutables) List(ctx context.Context, namespaceID string) ([]*model.Immutable, error) {
+	records := make([]*model.Immutable, 0)
+
+	err := i.handle.View(func(tx *bolt.Tx) error {
+		var err error
+		records, err = listImmutables(tx, namespaceID)
+
+		return err
+	})
+
+	sort.Slice(records, func(a, b int) bool {
+		return records[a].CreatedAt.Before(records[b].CreatedAt)
+	})
+
+	return records, err
+}
+
+// Create adds a new immutable tag rule to a namespace.
+func (i *immutables) Create(ctx context.Context, immutable *model.Immutable) (*model.Immutable, error) {
+	record := *immutable
+	record.ID = uuid.New().String()
+	record.CreatedAt = time.Now().UTC()
+
+	err := i.handle.Update(func(tx *bolt.Tx) error {
+		if tx.Bucket(namespacesBucket).Get([]byte(record.NamespaceID)) == nil {
+			return store.ErrNotFound
+		}
+
+		current, err := listImmutables(tx, record.NamespaceID)
+
+		if err != nil {
+			return err
+		}
+
+		for _, rule := range current {
+			if rule.Pattern == record.Pattern {
+				return store.ErrDuplicatePattern
+			}
+		}
+
+		return put(tx.Bucket(immutablesBucket), memberKey(record.NamespaceID, record.ID), &record)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &record, nil
+}
+
+// Delete removes an immutable tag rule of a namespace.
+func (i *immutables) Delete(ctx context.Context, namespaceID, id string) error {
+	return i.handle.Update(func(tx *bolt.Tx) error {
+		key := memberKey(namespaceID, id)
+
+		if tx.Bucket(immutablesBucket).Get(key) == nil {
+			return store.ErrNotFound
+		}
+
+		return tx.Bucket(immutablesBucket).Delete(key)
+	})
+}
+
+// listImmutables fetches the immutable tag rules of a namespace.
+func listImmutables(tx *bolt.Tx, namespaceID string) ([]*model.Immutable, error) {
+	records := make([]*model.Immutable, 0)
+	prefix := []byte(namespaceID + "/")
+	cursor := tx.Bucket(immutablesBucket).Cursor()
+
+	for k, v := cursor.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, v = cursor.Next() {
+		record := &model.Immutable{}
+
+		if err := json.Unmarshal(v, record); err != nil {
+			return nil, err
+		}
+
+		records = append(records, record)
+	}
+
+	return records, nil
+}
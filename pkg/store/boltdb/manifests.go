@@ -0,0 +1,124 @@
+package boltdb
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/umschlag/umschlag-api/pkg/model"
+	"github.com/umschlag/umschlag-api/pkg/store"
+	bolt "go.etcd.io/bbolt"
+)
+
+type manifests struct {
+	handle *bolt.DB
+}
+
+// List retrieves all manifests of a repository.
+func (m *manifests) List(ctx context.Context, repositoryID string) ([]*model.Manifest, error) {
+	records := make([]*model.Manifest, 0)
+
+	err := m.handle.View(func(tx *bolt.Tx) error {
+		prefix := []byte(repositoryID + "/")
+		cursor := tx.Bucket(manifestsBucket).Cursor()
+
+		for k, v := cursor.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, v = cursor.Next() {
+			record := &model.Manifest{}
+
+			if err := json.Unmarshal(v, record); err != nil {
+				return err
+			}
+
+			records = append(records, record)
+		}
+
+		return nil
+	})
+
+	return records, err
+}
+
+// Show retrieves a manifest of a repository by digest.
+func (m *manifests) Show(ctx context.Context, repositoryID, digest string) (*model.Manifest, error) {
+	record := &model.Manifest{}
+
+	err := m.handle.View(func(tx *bolt.Tx) error {
+		return get(tx.Bucket(manifestsBucket), memberKey(repositoryID, digest), record)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return record, nil
+}
+
+// Save creates or updates a manifest of a repository.
+func (m *manifests) Save(ctx context.Context, manifest *model.Manifest) (*model.Manifest, error) {
+	record := *manifest
+	record.SyncedAt = time.Now().UTC()
+
+	err := m.handle.Update(func(tx *bolt.Tx) error {
+		if tx.Bucket(repositoriesBucket).Get([]byte(record.RepositoryID)) == nil {
+			return store.ErrNotFound
+		}
+
+		return put(tx.Bucket(manifestsBucket), memberKey(record.RepositoryID, record.Digest), &record)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &record, nil
+}
+
+// Delete removes a manifest of a repository including the tags pointing
+// to it.
+func (m *manifests) Delete(ctx context.Context, repositoryID, digest string) error {
+	return m.handle.Update(func(tx *bolt.Tx) error {
+		key := memberKey(repositoryID, digest)
+
+		if tx.Bucket(manifestsBucket).Get(key) == nil {
+			return store.ErrNotFound
+		}
+
+		prefix := []byte(repositoryID + "/")
+		cursor := tx.Bucket(tagsBucket).Cursor()
+		tagged := make([][]byte, 0)
+
+		for k, v := cursor.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, v = cursor.Next() {
+			tag := &model.Tag{}
+
+			if err := json.Unmarshal(v, tag); err != nil {
+				return err
+			}
+
+			if tag.Digest == digest {
+				tagged = append(tagged, append([]byte{}, k...))
+			}
+		}
+
+		for _, k := range tagged {
+			if err := tx.Bucket(tagsBucket).Delete(k); err != nil {
+				return err
+			}
+		}
+
+		return tx.Bucket(manifestsBucket).Delete(key)
+	})
+}
+
+// removePrefix deletes all keys of the bucket starting with the prefix.
+func removePrefix(bucket *bolt.Bucket, prefix []byte) error {
+	cursor := bucket.Cursor()
+
+	for k, _ := cursor.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, _ = cursor.Seek(prefix) {
+		if err := cursor.Delete(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,158 @@
+package boltdb
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+
+	"github.com/umschlag/umschlag-api/pkg/model"
+	"github.com/umschlag/umschlag-api/pkg/store"
+	bolt "go.etcd.io/bbolt"
+)
+
+type members struct {
+	handle *bolt.DB
+}
+
+// ListByTeam retrieves all memberships of a team including the users.
+func (m *members) ListByTeam(ctx context.Context, teamID string) ([]*model.TeamUser, error) {
+	records := make([]*model.TeamUser, 0)
+
+	err := m.handle.View(func(tx *bolt.Tx) error {
+		prefix := []byte(teamID + "/")
+		cursor := tx.Bucket(membersBucket).Cursor()
+
+		for k, v := cursor.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, v = cursor.Next() {
+			record, err := m.decode(tx, v)
+
+			if err != nil {
+				return err
+			}
+
+			records = append(records, record)
+		}
+
+		return nil
+	})
+
+	return records, err
+}
+
+// ListByUser retrieves all memberships of a user including the teams.
+func (m *members) ListByUser(ctx context.Context, userID string) ([]*model.TeamUser, error) {
+	records := make([]*model.TeamUser, 0)
+
+	err := m.handle.View(func(tx *bolt.Tx) error {
+		prefix := []byte(userID + "/")
+		cursor := tx.Bucket(membersUserBucket).Cursor()
+
+		for k, _ := cursor.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, _ = cursor.Next() {
+			teamID := string(k[len(prefix):])
+			raw := tx.Bucket(membersBucket).Get(memberKey(teamID, userID))
+
+			if raw == nil {
+				continue
+			}
+
+			record, err := m.decode(tx, raw)
+
+			if err != nil {
+				return err
+			}
+
+			records = append(records, record)
+		}
+
+		return nil
+	})
+
+	return records, err
+}
+
+// Assign creates a new membership of a user within a team.
+func (m *members) Assign(ctx context.Context, teamID, userID, perm string) error {
+	return m.handle.Update(func(tx *bolt.Tx) error {
+		if tx.Bucket(teamsBucket).Get([]byte(teamID)) == nil {
+			return store.ErrNotFound
+		}
+
+		if tx.Bucket(usersBucket).Get([]byte(userID)) == nil {
+			return store.ErrNotFound
+		}
+
+		if tx.Bucket(membersBucket).Get(memberKey(teamID, userID)) != nil {
+			return store.ErrAlreadyAssigned
+		}
+
+		if err := put(tx.Bucket(membersBucket), memberKey(teamID, userID), &model.TeamUser{
+			TeamID: teamID,
+			UserID: userID,
+			Perm:   perm,
+		}); err != nil {
+			return err
+		}
+
+		return tx.Bucket(membersUserBucket).Put(memberKey(userID, teamID), []byte{})
+	})
+}
+
+// Permit updates the permission of an existing membership.
+func (m *members) Permit(ctx context.Context, teamID, userID, perm string) error {
+	return m.handle.Update(func(tx *bolt.Tx) error {
+		record := &model.TeamUser{}
+
+		if err := get(tx.Bucket(membersBucket), memberKey(teamID, userID), record); err != nil {
+			if err == store.ErrNotFound {
+				return store.ErrNotAssigned
+			}
+
+			return err
+		}
+
+		record.Perm = perm
+		return put(tx.Bucket(membersBucket), memberKey(teamID, userID), record)
+	})
+}
+
+// Unassign removes the membership of a user within a team.
+func (m *members) Unassign(ctx context.Context, teamID, userID string) error {
+	return m.handle.Update(func(tx *bolt.Tx) error {
+		if tx.Bucket(membersBucket).Get(memberKey(teamID, userID)) == nil {
+			return store.ErrNotAssigned
+		}
+
+		if err := tx.Bucket(membersBucket).Delete(memberKey(teamID, userID)); err != nil {
+			return err
+		}
+
+		return tx.Bucket(membersUserBucket).Delete(memberKey(userID, teamID))
+	})
+}
+
+// decode parses a membership and attaches the team and the user.
+func (m *members) decode(tx *bolt.Tx, raw []byte) (*model.TeamUser, error) {
+	record := &model.TeamUser{}
+
+	if err := json.Unmarshal(raw, record); err != nil {
+		return nil, err
+	}
+
+	record.Team = &model.Team{}
+
+	if err := get(tx.Bucket(teamsBucket), []byte(record.TeamID), record.Team); err != nil {
+		return nil, err
+	}
+
+	record.User = &model.User{}
+
+	if err := get(tx.Bucket(usersBucket), []byte(record.UserID), record.User); err != nil {
+		return nil, err
+	}
+
+	return record, nil
+}
+
+// memberKey builds the composite key used by the membership buckets.
+func memberKey(parent, child string) []byte {
+	return []byte(parent + "/" + child)
+}
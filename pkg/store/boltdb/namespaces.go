@@ -0,0 +1,215 @@
+package boltdb
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/umschlag/umschlag-api/pkg/model"
+	"github.com/umschlag/umschlag-api/pkg/store"
+	bolt "go.etcd.io/bbolt"
+)
+
+type namespaces struct {
+	handle *bolt.DB
+}
+
+// List retrieves all available namespaces.
+func (n *namespaces) List(ctx context.Context) ([]*model.Namespace, error) {
+	records := make([]*model.Namespace, 0)
+
+	err := n.handle.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(namespacesBucket).ForEach(func(k, v []byte) error {
+			record := &model.Namespace{}
+
+			if err := json.Unmarshal(v, record); err != nil {
+				return err
+			}
+
+			records = append(records, record)
+			return nil
+		})
+	})
+
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].Name < records[j].Name
+	})
+
+	return records, err
+}
+
+// Show retrieves a namespace by ID or name.
+func (n *namespaces) Show(ctx context.Context, id string) (*model.Namespace, error) {
+	record := &model.Namespace{}
+
+	err := n.handle.View(func(tx *bolt.Tx) error {
+		key := lookup(tx, namespacesBucket, namespacesNameBucket, id)
+
+		if key == nil {
+			return store.ErrNotFound
+		}
+
+		return get(tx.Bucket(namespacesBucket), key, record)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return record, nil
+}
+
+// Create stores a new namespace and fills the generated fields.
+func (n *namespaces) Create(ctx context.Context, namespace *model.Namespace) (*model.Namespace, error) {
+	record := *namespace
+	record.ID = uuid.New().String()
+	record.CreatedAt = time.Now().UTC()
+	record.UpdatedAt = record.CreatedAt
+
+	err := n.handle.Update(func(tx *bolt.Tx) error {
+		return n.save(tx, &model.Namespace{}, &record)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &record, nil
+}
+
+// Update stores the changes of an existing namespace.
+func (n *namespaces) Update(ctx context.Context, namespace *model.Namespace) (*model.Namespace, error) {
+	record := *namespace
+	record.UpdatedAt = time.Now().UTC()
+
+	err := n.handle.Update(func(tx *bolt.Tx) error {
+		existing := &model.Namespace{}
+
+		if err := get(tx.Bucket(namespacesBucket), []byte(record.ID), existing); err != nil {
+			return err
+		}
+
+		record.CreatedAt = existing.CreatedAt
+		return n.save(tx, existing, &record)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &record, nil
+}
+
+// Delete removes a namespace by ID or name including the repositories.
+func (n *namespaces) Delete(ctx context.Context, id string) error {
+	return n.handle.Update(func(tx *bolt.Tx) error {
+		key := lookup(tx, namespacesBucket, namespacesNameBucket, id)
+
+		if key == nil {
+			return store.ErrNotFound
+		}
+
+		record := &model.Namespace{}
+
+		if err := get(tx.Bucket(namespacesBucket), key, record); err != nil {
+			return err
+		}
+
+		return removeNamespace(tx, record)
+	})
+}
+
+func (n *namespaces) save(tx *bolt.Tx, existing, record *model.Namespace) error {
+	if (record.UserID == "") == (record.TeamID == "") {
+		return store.ErrNotFound
+	}
+
+	if record.UserID != "" && tx.Bucket(usersBucket).Get([]byte(record.UserID)) == nil {
+		return store.ErrNotFound
+	}
+
+	if record.TeamID != "" && tx.Bucket(teamsBucket).Get([]byte(record.TeamID)) == nil {
+		return store.ErrNotFound
+	}
+
+	if err := index(
+		tx.Bucket(namespacesNameBucket),
+		record.ID,
+		existing.Name,
+		record.Name,
+		store.ErrDuplicateName,
+	); err != nil {
+		return err
+	}
+
+	return put(tx.Bucket(namespacesBucket), []byte(record.ID), record)
+}
+
+// removeNamespace deletes the namespace including all repositories, the
+// retention policy, the immutable tag rules and the quota.
+func removeNamespace(tx *bolt.Tx, record *model.Namespace) error {
+	prefix := []byte(record.ID + "/")
+	cursor := tx.Bucket(repositoriesNameBucket).Cursor()
+
+	for k, v := cursor.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, v = cursor.Seek(prefix) {
+		repository := &model.Repository{}
+
+		if err := get(tx.Bucket(repositoriesBucket), v, repository); err != nil {
+			return err
+		}
+
+		if err := removeRepository(tx, repository); err != nil {
+			return err
+		}
+	}
+
+	if err := removeRetention(tx, memberKey(record.ID, "")); err != nil {
+		return err
+	}
+
+	if err := removePrefix(tx.Bucket(immutablesBucket), prefix); err != nil {
+		return err
+	}
+
+	if err := tx.Bucket(quotasBucket).Delete(memberKey(model.QuotaNamespace, record.ID)); err != nil {
+		return err
+	}
+
+	if err := tx.Bucket(namespacesNameBucket).Delete([]byte(record.Name)); err != nil {
+		return err
+	}
+
+	return tx.Bucket(namespacesBucket).Delete([]byte(record.ID))
+}
+
+// removeOwned deletes all namespaces owned by the user or the team.
+func removeOwned(tx *bolt.Tx, userID, teamID string) error {
+	owned := make([]*model.Namespace, 0)
+
+	if err := tx.Bucket(namespacesBucket).ForEach(func(k, v []byte) error {
+		record := &model.Namespace{}
+
+		if err := json.Unmarshal(v, record); err != nil {
+			return err
+		}
+
+		if (userID != "" && record.UserID == userID) || (teamID != "" && record.TeamID == teamID) {
+			owned = append(owned, record)
+		}
+
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	for _, record := range owned {
+		if err := removeNamespace(tx, record); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
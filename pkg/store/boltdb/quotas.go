@@ -0,0 +1,84 @@
+package boltdb
+
+import (
+	"context"
+	"time"
+
+	"github.com/umschlag/umschlag-api/pkg/model"
+	"github.com/umschlag/umschlag-api/pkg/store"
+	bolt "go.etcd.io/bbolt"
+)
+
+type quotas struct {
+	handle *bolt.DB
+}
+
+// Show retrieves the quota of a namespace or a team.
+func (q *quotas) Show(ctx context.Context, kind, ownerID string) (*model.Quota, error) {
+	record := &model.Quota{}
+
+	err := q.handle.View(func(tx *bolt.Tx) error {
+		return get(tx.Bucket(quotasBucket), memberKey(kind, ownerID), record)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return record, nil
+}
+
+// Save creates or updates the quota of a namespace or a team.
+func (q *quotas) Save(ctx context.Context, quota *model.Quota) (*model.Quota, error) {
+	record := *quota
+	record.UpdatedAt = time.Now().UTC()
+
+	err := q.handle.Update(func(tx *bolt.Tx) error {
+		var owners *bolt.Bucket
+
+		switch record.Kind {
+		case model.QuotaNamespace:
+			owners = tx.Bucket(namespacesBucket)
+		case model.QuotaTeam:
+			owners = tx.Bucket(teamsBucket)
+		default:
+			return store.ErrNotFound
+		}
+
+		if owners.Get([]byte(record.OwnerID)) == nil {
+			return store.ErrNotFound
+		}
+
+		key := memberKey(record.Kind, record.OwnerID)
+		current := &model.Quota{}
+
+		if err := get(tx.Bucket(quotasBucket), key, current); err == nil {
+			record.CreatedAt = current.CreatedAt
+		} else if err == store.ErrNotFound {
+			record.CreatedAt = record.UpdatedAt
+		} else {
+			return err
+		}
+
+		return put(tx.Bucket(quotasBucket), key, &record)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &record, nil
+}
+
+// Delete removes the quota of a namespace or a team.
+func (q *quotas) Delete(ctx context.Context, kind, ownerID string) error {
+	return q.handle.Update(func(tx *bolt.Tx) error {
+		key := memberKey(kind, ownerID)
+
+		if tx.Bucket(quotasBucket).Get(key) == nil {
+			return store.ErrNotFound
+		}
+
+		return tx.Bucket(quotasBucket).Delete(key)
+	})
+}
@@ -0,0 +1,279 @@
+package boltdb
+
+import (
+	"bytes"
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/umschlag/umschlag-api/pkg/model"
+	"github.com/umschlag/umschlag-api/pkg/store"
+	bolt "go.etcd.io/bbolt"
+)
+
+type repositories struct {
+	handle *bolt.DB
+}
+
+// List retrieves all repositories of a namespace.
+func (r *repositories) List(ctx context.Context, namespaceID string) ([]*model.Repository, error) {
+	records := make([]*model.Repository, 0)
+
+	err := r.handle.View(func(tx *bolt.Tx) error {
+		prefix := []byte(namespaceID + "/")
+		cursor := tx.Bucket(repositoriesNameBucket).Cursor()
+
+		for k, v := cursor.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, v = cursor.Next() {
+			record := &model.Repository{}
+
+			if err := get(tx.Bucket(repositoriesBucket), v, record); err != nil {
+				return err
+			}
+
+			records = append(records, record)
+		}
+
+		return nil
+	})
+
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].Name < records[j].Name
+	})
+
+	return records, err
+}
+
+// Show retrieves a repository of a namespace by ID or name.
+func (r *repositories) Show(ctx context.Context, namespaceID, id string) (*model.Repository, error) {
+	record := &model.Repository{}
+
+	err := r.handle.View(func(tx *bolt.Tx) error {
+		key := []byte(id)
+
+		if tx.Bucket(repositoriesBucket).Get(key) == nil {
+			key = tx.Bucket(repositoriesNameBucket).Get(memberKey(namespaceID, id))
+		}
+
+		if key == nil {
+			return store.ErrNotFound
+		}
+
+		if err := get(tx.Bucket(repositoriesBucket), key, record); err != nil {
+			return err
+		}
+
+		if record.NamespaceID != namespaceID {
+			return store.ErrNotFound
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return record, nil
+}
+
+// Create stores a new repository and fills the generated fields.
+func (r *repositories) Create(ctx context.Context, repository *model.Repository) (*model.Repository, error) {
+	record := *repository
+	record.ID = uuid.New().String()
+	record.CreatedAt = time.Now().UTC()
+	record.UpdatedAt = record.CreatedAt
+
+	if record.Visibility == "" {
+		record.Visibility = model.VisibilityPrivate
+	}
+
+	err := r.handle.Update(func(tx *bolt.Tx) error {
+		if tx.Bucket(namespacesBucket).Get([]byte(record.NamespaceID)) == nil {
+			return store.ErrNotFound
+		}
+
+		return r.save(tx, &model.Repository{}, &record)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &record, nil
+}
+
+// Update stores the changes of an existing repository, the namespace of a
+// repository can't be changed.
+func (r *repositories) Update(ctx context.Context, repository *model.Repository) (*model.Repository, error) {
+	record := *repository
+	record.UpdatedAt = time.Now().UTC()
+
+	if record.Visibility == "" {
+		record.Visibility = model.VisibilityPrivate
+	}
+
+	err := r.handle.Update(func(tx *bolt.Tx) error {
+		existing := &model.Repository{}
+
+		if err := get(tx.Bucket(repositoriesBucket), []byte(record.ID), existing); err != nil {
+			return err
+		}
+
+		record.NamespaceID = existing.NamespaceID
+		record.CreatedAt = existing.CreatedAt
+
+		return r.save(tx, existing, &record)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &record, nil
+}
+
+// Delete removes a repository by ID including the team permissions, tags
+// and manifests.
+func (r *repositories) Delete(ctx context.Context, id string) error {
+	return r.handle.Update(func(tx *bolt.Tx) error {
+		record := &model.Repository{}
+
+		if err := get(tx.Bucket(repositoriesBucket), []byte(id), record); err != nil {
+			return err
+		}
+
+		return removeRepository(tx, record)
+	})
+}
+
+// ListTeams retrieves the team permissions of a repository including the teams.
+func (r *repositories) ListTeams(ctx context.Context, repositoryID string) ([]*model.RepositoryTeam, error) {
+	records := make([]*model.RepositoryTeam, 0)
+
+	err := r.handle.View(func(tx *bolt.Tx) error {
+		prefix := []byte(repositoryID + "/")
+		cursor := tx.Bucket(repositoryTeamsBucket).Cursor()
+
+		for k, v := cursor.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, v = cursor.Next() {
+			record := &model.RepositoryTeam{
+				RepositoryID: repositoryID,
+				TeamID:       string(k[len(prefix):]),
+				Team:         &model.Team{},
+				Perm:         string(v),
+			}
+
+			if err := get(tx.Bucket(teamsBucket), []byte(record.TeamID), record.Team); err != nil {
+				return err
+			}
+
+			records = append(records, record)
+		}
+
+		return nil
+	})
+
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].Team.Name < records[j].Team.Name
+	})
+
+	return records, err
+}
+
+// PermitTeam grants or changes the permission of a team on a repository.
+func (r *repositories) PermitTeam(ctx context.Context, repositoryID, teamID, perm string) error {
+	return r.handle.Update(func(tx *bolt.Tx) error {
+		if tx.Bucket(repositoriesBucket).Get([]byte(repositoryID)) == nil {
+			return store.ErrNotFound
+		}
+
+		if tx.Bucket(teamsBucket).Get([]byte(teamID)) == nil {
+			return store.ErrNotFound
+		}
+
+		return tx.Bucket(repositoryTeamsBucket).Put(memberKey(repositoryID, teamID), []byte(perm))
+	})
+}
+
+// RevokeTeam removes the permission of a team on a repository.
+func (r *repositories) RevokeTeam(ctx context.Context, repositoryID, teamID string) error {
+	return r.handle.Update(func(tx *bolt.Tx) error {
+		key := memberKey(repositoryID, teamID)
+
+		if tx.Bucket(repositoryTeamsBucket).Get(key) == nil {
+			return store.ErrNotAssigned
+		}
+
+		return tx.Bucket(repositoryTeamsBucket).Delete(key)
+	})
+}
+
+func (r *repositories) save(tx *bolt.Tx, existing, record *model.Repository) error {
+	old := ""
+
+	if existing.Name != "" {
+		old = string(memberKey(existing.NamespaceID, existing.Name))
+	}
+
+	if err := index(
+		tx.Bucket(repositoriesNameBucket),
+		record.ID,
+		old,
+		string(memberKey(record.NamespaceID, record.Name)),
+		store.ErrDuplicateName,
+	); err != nil {
+		return err
+	}
+
+	return put(tx.Bucket(repositoriesBucket), []byte(record.ID), record)
+}
+
+// removeRepository deletes the repository including the team permissions,
+// tags, manifests and the retention policy.
+func removeRepository(tx *bolt.Tx, record *model.Repository) error {
+	prefix := []byte(record.ID + "/")
+
+	if err := removeRetention(tx, memberKey(record.NamespaceID, record.ID)); err != nil {
+		return err
+	}
+
+	for _, bucket := range [][]byte{
+		repositoryTeamsBucket,
+		tagsBucket,
+		manifestsBucket,
+	} {
+		if err := removePrefix(tx.Bucket(bucket), prefix); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Bucket(repositoriesNameBucket).Delete(memberKey(record.NamespaceID, record.Name)); err != nil {
+		return err
+	}
+
+	return tx.Bucket(repositoriesBucket).Delete([]byte(record.ID))
+}
+
+// removeGrants deletes all repository permissions of a team.
+func removeGrants(tx *bolt.Tx, teamID string) error {
+	granted := make([][]byte, 0)
+
+	if err := tx.Bucket(repositoryTeamsBucket).ForEach(func(k, v []byte) error {
+		if strings.HasSuffix(string(k), "/"+teamID) {
+			granted = append(granted, append([]byte{}, k...))
+		}
+
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	for _, key := range granted {
+		if err := tx.Bucket(repositoryTeamsBucket).Delete(key); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
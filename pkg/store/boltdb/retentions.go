@@ -0,0 +1,198 @@
+package boltdb
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/umschlag/umschlag-api/pkg/model"
+	"github.com/umschlag/umschlag-api/pkg/store"
+	bolt "go.etcd.io/bbolt"
+)
+
+type retentions struct {
+	handle *bolt.DB
+}
+
+// List retrieves all retention policies.
+func (r *retentions) List(ctx context.Context) ([]*model.Retention, error) {
+	records := make([]*model.Retention, 0)
+
+	err := r.handle.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(retentionsBucket).ForEach(func(k, v []byte) error {
+			record := &model.Retention{}
+
+			if err := json.Unmarshal(v, record); err != nil {
+				return err
+			}
+
+			records = append(records, record)
+			return nil
+		})
+	})
+
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].CreatedAt.Before(records[j].CreatedAt)
+	})
+
+	return records, err
+}
+
+// Show retrieves the retention policy of a namespace or a repository.
+func (r *retentions) Show(ctx context.Context, namespaceID, repositoryID string) (*model.Retention, error) {
+	record := &model.Retention{}
+
+	err := r.handle.View(func(tx *bolt.Tx) error {
+		return get(tx.Bucket(retentionsBucket), memberKey(namespaceID, repositoryID), record)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return record, nil
+}
+
+// Save creates or updates the retention policy of a namespace or a
+// repository.
+func (r *retentions) Save(ctx context.Context, retention *model.Retention) (*model.Retention, error) {
+	record := *retention
+	record.UpdatedAt = time.Now().UTC()
+
+	err := r.handle.Update(func(tx *bolt.Tx) error {
+		if tx.Bucket(namespacesBucket).Get([]byte(record.NamespaceID)) == nil {
+			return store.ErrNotFound
+		}
+
+		if record.RepositoryID != "" {
+			repository := &model.Repository{}
+
+			if err := get(tx.Bucket(repositoriesBucket), []byte(record.RepositoryID), repository); err != nil {
+				return err
+			}
+
+			if repository.NamespaceID != record.NamespaceID {
+				return store.ErrNotFound
+			}
+		}
+
+		key := memberKey(record.NamespaceID, record.RepositoryID)
+		current := &model.Retention{}
+
+		if err := get(tx.Bucket(retentionsBucket), key, current); err == nil {
+			record.ID = current.ID
+			record.CreatedAt = current.CreatedAt
+		} else if err == store.ErrNotFound {
+			record.ID = uuid.New().String()
+			record.CreatedAt = record.UpdatedAt
+		} else {
+			return err
+		}
+
+		return put(tx.Bucket(retentionsBucket), key, &record)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &record, nil
+}
+
+// Delete removes the retention policy of a namespace or a repository
+// including the reports.
+func (r *retentions) Delete(ctx context.Context, namespaceID, repositoryID string) error {
+	return r.handle.Update(func(tx *bolt.Tx) error {
+		key := memberKey(namespaceID, repositoryID)
+
+		if tx.Bucket(retentionsBucket).Get(key) == nil {
+			return store.ErrNotFound
+		}
+
+		return removeRetention(tx, key)
+	})
+}
+
+// ListReports retrieves the reports of a retention policy, newest first.
+func (r *retentions) ListReports(ctx context.Context, retentionID string) ([]*model.Report, error) {
+	records := make([]*model.Report, 0)
+
+	err := r.handle.View(func(tx *bolt.Tx) error {
+		prefix := []byte(retentionID + "/")
+		cursor := tx.Bucket(reportsBucket).Cursor()
+
+		for k, v := cursor.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, v = cursor.Next() {
+			record := &model.Report{}
+
+			if err := json.Unmarshal(v, record); err != nil {
+				return err
+			}
+
+			records = append(records, record)
+		}
+
+		return nil
+	})
+
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].StartedAt.After(records[j].StartedAt)
+	})
+
+	return records, err
+}
+
+// CreateReport stores the report of a retention policy run.
+func (r *retentions) CreateReport(ctx context.Context, report *model.Report) (*model.Report, error) {
+	record := *report
+	record.ID = uuid.New().String()
+
+	err := r.handle.Update(func(tx *bolt.Tx) error {
+		exists := false
+
+		if err := tx.Bucket(retentionsBucket).ForEach(func(k, v []byte) error {
+			retention := &model.Retention{}
+
+			if err := json.Unmarshal(v, retention); err != nil {
+				return err
+			}
+
+			exists = exists || retention.ID == record.RetentionID
+			return nil
+		}); err != nil {
+			return err
+		}
+
+		if !exists {
+			return store.ErrNotFound
+		}
+
+		return put(tx.Bucket(reportsBucket), memberKey(record.RetentionID, record.ID), &record)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &record, nil
+}
+
+// removeRetention deletes a retention policy by key including the reports,
+// missing policies are ignored.
+func removeRetention(tx *bolt.Tx, key []byte) error {
+	record := &model.Retention{}
+
+	if err := get(tx.Bucket(retentionsBucket), key, record); err == store.ErrNotFound {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	if err := removePrefix(tx.Bucket(reportsBucket), []byte(record.ID+"/")); err != nil {
+		return err
+	}
+
+	return tx.Bucket(retentionsBucket).Delete(key)
+}
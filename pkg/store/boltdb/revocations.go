@@ -0,0 +1,148 @@
+package boltdb
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/umschlag/umschlag-api/pkg/model"
+	"github.com/umschlag/umschlag-api/pkg/store"
+	bolt "go.etcd.io/bbolt"
+)
+
+type revocations struct {
+	handle *bolt.DB
+}
+
+// Revoke stores the revocation of a single token.
+func (r *revocations) Revoke(ctx context.Context, revocation *model.Revocation) error {
+	record := *revocation
+	record.CreatedAt = time.Now().UTC()
+
+	return r.handle.Update(func(tx *bolt.Tx) error {
+		if tx.Bucket(usersBucket).Get([]byte(record.UserID)) == nil {
+			return store.ErrNotFound
+		}
+
+		return put(tx.Bucket(revocationsBucket), []byte(record.TokenID), &record)
+	})
+}
+
+// Revoked checks if a token has been revoked.
+func (r *revocations) Revoked(ctx context.Context, tokenID string) (bool, error) {
+	revoked := false
+
+	err := r.handle.View(func(tx *bolt.Tx) error {
+		revoked = tx.Bucket(revocationsBucket).Get([]byte(tokenID)) != nil
+		return nil
+	})
+
+	return revoked, err
+}
+
+// RevokeBefore revokes all tokens of a user issued before the time, an
+// already later point in time is kept.
+func (r *revocations) RevokeBefore(ctx context.Context, userID string, before time.Time) error {
+	return r.handle.Update(func(tx *bolt.Tx) error {
+		if tx.Bucket(usersBucket).Get([]byte(userID)) == nil {
+			return store.ErrNotFound
+		}
+
+		current := time.Time{}
+
+		if err := get(tx.Bucket(revocationsUserBucket), []byte(userID), &current); err != nil && err != store.ErrNotFound {
+			return err
+		}
+
+		if !before.After(current) {
+			return nil
+		}
+
+		return put(tx.Bucket(revocationsUserBucket), []byte(userID), before.UTC())
+	})
+}
+
+// RevokedBefore retrieves the time before that all tokens of a user have
+// been revoked, it's zero if that never happened.
+func (r *revocations) RevokedBefore(ctx context.Context, userID string) (time.Time, error) {
+	before := time.Time{}
+
+	err := r.handle.View(func(tx *bolt.Tx) error {
+		return get(tx.Bucket(revocationsUserBucket), []byte(userID), &before)
+	})
+
+	if err == store.ErrNotFound {
+		return time.Time{}, nil
+	}
+
+	return before, err
+}
+
+// Prune removes the revocations of tokens that are expired anyway.
+func (r *revocations) Prune(ctx context.Context, now time.Time) (int, error) {
+	expired := make([][]byte, 0)
+
+	err := r.handle.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(revocationsBucket)
+
+		if err := bucket.ForEach(func(k, v []byte) error {
+			record := &model.Revocation{}
+
+			if err := json.Unmarshal(v, record); err != nil {
+				return err
+			}
+
+			if record.Expired(now) {
+				expired = append(expired, append([]byte{}, k...))
+			}
+
+			return nil
+		}); err != nil {
+			return err
+		}
+
+		for _, key := range expired {
+			if err := bucket.Delete(key); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return 0, err
+	}
+
+	return len(expired), nil
+}
+
+// removeRevocations deletes all revocations that belong to a user.
+func removeRevocations(tx *bolt.Tx, userID string) error {
+	bucket := tx.Bucket(revocationsBucket)
+	owned := make([][]byte, 0)
+
+	if err := bucket.ForEach(func(k, v []byte) error {
+		record := &model.Revocation{}
+
+		if err := json.Unmarshal(v, record); err != nil {
+			return err
+		}
+
+		if record.UserID == userID {
+			owned = append(owned, append([]byte{}, k...))
+		}
+
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	for _, key := range owned {
+		if err := bucket.Delete(key); err != nil {
+			return err
+		}
+	}
+
+	return tx.Bucket(revocationsUserBucket).Delete([]byte(userID))
+}
@@ -0,0 +1,140 @@
+package boltdb
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/umschlag/umschlag-api/pkg/model"
+	"github.com/umschlag/umschlag-api/pkg/store"
+	bolt "go.etcd.io/bbolt"
+)
+
+type tags struct {
+	handle *bolt.DB
+}
+
+// List retrieves all tags of a repository.
+func (t *tags) List(ctx context.Context, repositoryID string) ([]*model.Tag, error) {
+	records := make([]*model.Tag, 0)
+
+	err := t.handle.View(func(tx *bolt.Tx) error {
+		prefix := []byte(repositoryID + "/")
+		cursor := tx.Bucket(tagsBucket).Cursor()
+
+		for k, v := cursor.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, v = cursor.Next() {
+			record := &model.Tag{}
+
+			if err := json.Unmarshal(v, record); err != nil {
+				return err
+			}
+
+			records = append(records, record)
+		}
+
+		return nil
+	})
+
+	return records, err
+}
+
+// Show retrieves a tag of a repository by name.
+func (t *tags) Show(ctx context.Context, repositoryID, name string) (*model.Tag, error) {
+	record := &model.Tag{}
+
+	err := t.handle.View(func(tx *bolt.Tx) error {
+		return get(tx.Bucket(tagsBucket), memberKey(repositoryID, name), record)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return record, nil
+}
+
+// Save creates or updates a tag of a repository.
+func (t *tags) Save(ctx context.Context, tag *model.Tag) (*model.Tag, error) {
+	record := *tag
+	record.UpdatedAt = time.Now().UTC()
+	record.CreatedAt = record.UpdatedAt
+
+	err := t.handle.Update(func(tx *bolt.Tx) error {
+		if tx.Bucket(repositoriesBucket).Get([]byte(record.RepositoryID)) == nil {
+			return store.ErrNotFound
+		}
+
+		key := memberKey(record.RepositoryID, record.Name)
+		current := &model.Tag{}
+
+		if err := get(tx.Bucket(tagsBucket), key, current); err == nil {
+			record.Pulls = current.Pulls
+			record.PulledAt = current.PulledAt
+			record.OverwrittenAt = current.OverwrittenAt
+
+			if !current.CreatedAt.IsZero() {
+				record.CreatedAt = current.CreatedAt
+			}
+		} else if err != store.ErrNotFound {
+			return err
+		}
+
+		return put(tx.Bucket(tagsBucket), key, &record)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &record, nil
+}
+
+// Pull increments the pull counter of a tag.
+func (t *tags) Pull(ctx context.Context, repositoryID, name string) error {
+	return t.handle.Update(func(tx *bolt.Tx) error {
+		key := memberKey(repositoryID, name)
+		record := &model.Tag{}
+
+		if err := get(tx.Bucket(tagsBucket), key, record); err != nil {
+			return err
+		}
+
+		pulled := time.Now().UTC()
+
+		record.Pulls++
+		record.PulledAt = &pulled
+
+		return put(tx.Bucket(tagsBucket), key, record)
+	})
+}
+
+// Flag marks a tag as overwritten.
+func (t *tags) Flag(ctx context.Context, repositoryID, name string) error {
+	return t.handle.Update(func(tx *bolt.Tx) error {
+		key := memberKey(repositoryID, name)
+		record := &model.Tag{}
+
+		if err := get(tx.Bucket(tagsBucket), key, record); err != nil {
+			return err
+		}
+
+		overwritten := time.Now().UTC()
+		record.OverwrittenAt = &overwritten
+
+		return put(tx.Bucket(tagsBucket), key, record)
+	})
+}
+
+// Delete removes a tag of a repository by name.
+func (t *tags) Delete(ctx context.Context, repositoryID, name string) error {
+	return t.handle.Update(func(tx *bolt.Tx) error {
+		key := memberKey(repositoryID, name)
+
+		if tx.Bucket(tagsBucket).Get(key) == nil {
+			return store.ErrNotFound
+		}
+
+		return tx.Bucket(tagsBucket).Delete(key)
+	})
+}
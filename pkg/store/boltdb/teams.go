@@ -0,0 +1,171 @@
+package boltdb
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/umschlag/umschlag-api/pkg/model"
+	"github.com/umschlag/umschlag-api/pkg/store"
+	bolt "go.etcd.io/bbolt"
+)
+
+type teams struct {
+	handle *bolt.DB
+}
+
+// List retrieves all available teams.
+func (t *teams) List(ctx context.Context) ([]*model.Team, error) {
+	records := make([]*model.Team, 0)
+
+	err := t.handle.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(teamsBucket).ForEach(func(k, v []byte) error {
+			record := &model.Team{}
+
+			if err := json.Unmarshal(v, record); err != nil {
+				return err
+			}
+
+			records = append(records, record)
+			return nil
+		})
+	})
+
+	return records, err
+}
+
+// Show retrieves a team by ID or slug.
+func (t *teams) Show(ctx context.Context, id string) (*model.Team, error) {
+	record := &model.Team{}
+
+	err := t.handle.View(func(tx *bolt.Tx) error {
+		key := lookup(tx, teamsBucket, teamsSlugBucket, id)
+
+		if key == nil {
+			return store.ErrNotFound
+		}
+
+		return get(tx.Bucket(teamsBucket), key, record)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return record, nil
+}
+
+// Create stores a new team and fills the generated fields.
+func (t *teams) Create(ctx context.Context, team *model.Team) (*model.Team, error) {
+	record := *team
+	record.ID = uuid.New().String()
+	record.CreatedAt = time.Now().UTC()
+	record.UpdatedAt = record.CreatedAt
+
+	if record.Slug == "" {
+		record.Slug = store.Slugify(record.Name)
+	}
+
+	err := t.handle.Update(func(tx *bolt.Tx) error {
+		return t.save(tx, &model.Team{}, &record)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &record, nil
+}
+
+// Update stores the changes of an existing team.
+func (t *teams) Update(ctx context.Context, team *model.Team) (*model.Team, error) {
+	record := *team
+	record.UpdatedAt = time.Now().UTC()
+
+	if record.Slug == "" {
+		record.Slug = store.Slugify(record.Name)
+	}
+
+	err := t.handle.Update(func(tx *bolt.Tx) error {
+		existing := &model.Team{}
+
+		if err := get(tx.Bucket(teamsBucket), []byte(record.ID), existing); err != nil {
+			return err
+		}
+
+		record.CreatedAt = existing.CreatedAt
+		return t.save(tx, existing, &record)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &record, nil
+}
+
+// Delete removes a team by ID or slug including the memberships.
+func (t *teams) Delete(ctx context.Context, id string) error {
+	return t.handle.Update(func(tx *bolt.Tx) error {
+		key := lookup(tx, teamsBucket, teamsSlugBucket, id)
+
+		if key == nil {
+			return store.ErrNotFound
+		}
+
+		record := &model.Team{}
+
+		if err := get(tx.Bucket(teamsBucket), key, record); err != nil {
+			return err
+		}
+
+		if err := index(tx.Bucket(teamsSlugBucket), record.ID, record.Slug, "", nil); err != nil {
+			return err
+		}
+
+		prefix := []byte(record.ID + "/")
+		cursor := tx.Bucket(membersBucket).Cursor()
+
+		for k, _ := cursor.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, _ = cursor.Seek(prefix) {
+			userID := string(k[len(prefix):])
+
+			if err := tx.Bucket(membersUserBucket).Delete(memberKey(userID, record.ID)); err != nil {
+				return err
+			}
+
+			if err := cursor.Delete(); err != nil {
+				return err
+			}
+		}
+
+		if err := removeOwned(tx, "", record.ID); err != nil {
+			return err
+		}
+
+		if err := removeGrants(tx, record.ID); err != nil {
+			return err
+		}
+
+		if err := tx.Bucket(quotasBucket).Delete(memberKey(model.QuotaTeam, record.ID)); err != nil {
+			return err
+		}
+
+		return tx.Bucket(teamsBucket).Delete(key)
+	})
+}
+
+func (t *teams) save(tx *bolt.Tx, existing, record *model.Team) error {
+	if err := index(
+		tx.Bucket(teamsSlugBucket),
+		record.ID,
+		existing.Slug,
+		record.Slug,
+		store.ErrDuplicateSlug,
+	); err != nil {
+		return err
+	}
+
+	return put(tx.Bucket(teamsBucket), []byte(record.ID), record)
+}
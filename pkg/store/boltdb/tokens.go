@@ -0,0 +1,140 @@
+package boltdb
+
+import (
+	"bytes"
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/umschlag/umschlag-api/pkg/model"
+	"github.com/umschlag/umschlag-api/pkg/store"
+	bolt "go.etcd.io/bbolt"
+)
+
+type tokens struct {
+	handle *bolt.DB
+}
+
+// List retrieves all access tokens of a user.
+func (t *tokens) List(ctx context.Context, userID string) ([]*model.AccessToken, error) {
+	records := make([]*model.AccessToken, 0)
+
+	err := t.handle.View(func(tx *bolt.Tx) error {
+		prefix := []byte(userID + "/")
+		cursor := tx.Bucket(tokensUserBucket).Cursor()
+
+		for k, v := cursor.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, v = cursor.Next() {
+			record := &model.AccessToken{}
+
+			if err := get(tx.Bucket(tokensBucket), v, record); err != nil {
+				return err
+			}
+
+			records = append(records, record)
+		}
+
+		return nil
+	})
+
+	return records, err
+}
+
+// ByHash retrieves an access token by the hash of the secret.
+func (t *tokens) ByHash(ctx context.Context, hash string) (*model.AccessToken, error) {
+	record := &model.AccessToken{}
+
+	err := t.handle.View(func(tx *bolt.Tx) error {
+		key := tx.Bucket(tokensHashBucket).Get([]byte(hash))
+
+		if key == nil {
+			return store.ErrNotFound
+		}
+
+		return get(tx.Bucket(tokensBucket), key, record)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return record, nil
+}
+
+// Create stores a new access token and fills the generated fields.
+func (t *tokens) Create(ctx context.Context, token *model.AccessToken) (*model.AccessToken, error) {
+	record := *token
+	record.ID = uuid.New().String()
+	record.CreatedAt = time.Now().UTC()
+
+	err := t.handle.Update(func(tx *bolt.Tx) error {
+		if tx.Bucket(usersBucket).Get([]byte(record.UserID)) == nil {
+			return store.ErrNotFound
+		}
+
+		if tx.Bucket(tokensUserBucket).Get(memberKey(record.UserID, record.Name)) != nil {
+			return store.ErrDuplicateName
+		}
+
+		if err := index(tx.Bucket(tokensHashBucket), record.ID, "", record.Hash, store.ErrDuplicateName); err != nil {
+			return err
+		}
+
+		if err := tx.Bucket(tokensUserBucket).Put(memberKey(record.UserID, record.Name), []byte(record.ID)); err != nil {
+			return err
+		}
+
+		return put(tx.Bucket(tokensBucket), []byte(record.ID), &record)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &record, nil
+}
+
+// Touch updates the last usage of an access token.
+func (t *tokens) Touch(ctx context.Context, id string, at time.Time) error {
+	return t.handle.Update(func(tx *bolt.Tx) error {
+		record := &model.AccessToken{}
+
+		if err := get(tx.Bucket(tokensBucket), []byte(id), record); err != nil {
+			return err
+		}
+
+		used := at.UTC()
+		record.LastUsedAt = &used
+
+		return put(tx.Bucket(tokensBucket), []byte(id), record)
+	})
+}
+
+// Delete removes an access token of a user.
+func (t *tokens) Delete(ctx context.Context, userID, id string) error {
+	return t.handle.Update(func(tx *bolt.Tx) error {
+		record := &model.AccessToken{}
+
+		if err := get(tx.Bucket(tokensBucket), []byte(id), record); err != nil {
+			return err
+		}
+
+		if record.UserID != userID {
+			return store.ErrNotFound
+		}
+
+		return removeToken(tx, record)
+	})
+}
+
+// removeToken deletes the token including all index entries.
+func removeToken(tx *bolt.Tx, record *model.AccessToken) error {
+	if err := tx.Bucket(tokensHashBucket).Delete([]byte(record.Hash)); err != nil {
+		return err
+	}
+
+	if err := tx.Bucket(tokensUserBucket).Delete(memberKey(record.UserID, record.Name)); err != nil {
+		return err
+	}
+
+	return tx.Bucket(tokensBucket).Delete([]byte(record.ID))
+}
@@ -0,0 +1,235 @@
+package boltdb
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/umschlag/umschlag-api/pkg/model"
+	"github.com/umschlag/umschlag-api/pkg/store"
+	bolt "go.etcd.io/bbolt"
+)
+
+type users struct {
+	handle *bolt.DB
+}
+
+// List retrieves all available users.
+func (u *users) List(ctx context.Context) ([]*model.User, error) {
+	records := make([]*model.User, 0)
+
+	err := u.handle.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(usersBucket).ForEach(func(k, v []byte) error {
+			record := &model.User{}
+
+			if err := json.Unmarshal(v, record); err != nil {
+				return err
+			}
+
+			records = append(records, record)
+			return nil
+		})
+	})
+
+	return records, err
+}
+
+// Show retrieves a user by ID or slug.
+func (u *users) Show(ctx context.Context, id string) (*model.User, error) {
+	record := &model.User{}
+
+	err := u.handle.View(func(tx *bolt.Tx) error {
+		key := lookup(tx, usersBucket, usersSlugBucket, id)
+
+		if key == nil {
+			return store.ErrNotFound
+		}
+
+		return get(tx.Bucket(usersBucket), key, record)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return record, nil
+}
+
+// ByUsername retrieves a user by the case-insensitive username.
+func (u *users) ByUsername(ctx context.Context, username string) (*model.User, error) {
+	record := &model.User{}
+
+	err := u.handle.View(func(tx *bolt.Tx) error {
+		key := tx.Bucket(usersUsernameBucket).Get([]byte(strings.ToLower(username)))
+
+		if key == nil {
+			return store.ErrNotFound
+		}
+
+		return get(tx.Bucket(usersBucket), key, record)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return record, nil
+}
+
+// Create stores a new user and fills the generated fields.
+func (u *users) Create(ctx context.Context, user *model.User) (*model.User, error) {
+	record := *user
+	record.ID = uuid.New().String()
+	record.CreatedAt = time.Now().UTC()
+	record.UpdatedAt = record.CreatedAt
+
+	if record.Slug == "" {
+		record.Slug = store.Slugify(record.Username)
+	}
+
+	err := u.handle.Update(func(tx *bolt.Tx) error {
+		return u.save(tx, &model.User{}, &record)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &record, nil
+}
+
+// Update stores the changes of an existing user.
+func (u *users) Update(ctx context.Context, user *model.User) (*model.User, error) {
+	record := *user
+	record.UpdatedAt = time.Now().UTC()
+
+	if record.Slug == "" {
+		record.Slug = store.Slugify(record.Username)
+	}
+
+	err := u.handle.Update(func(tx *bolt.Tx) error {
+		existing := &model.User{}
+
+		if err := get(tx.Bucket(usersBucket), []byte(record.ID), existing); err != nil {
+			return err
+		}
+
+		record.CreatedAt = existing.CreatedAt
+		return u.save(tx, existing, &record)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &record, nil
+}
+
+// Delete removes a user by ID or slug including the memberships.
+func (u *users) Delete(ctx context.Context, id string) error {
+	return u.handle.Update(func(tx *bolt.Tx) error {
+		key := lookup(tx, usersBucket, usersSlugBucket, id)
+
+		if key == nil {
+			return store.ErrNotFound
+		}
+
+		record := &model.User{}
+
+		if err := get(tx.Bucket(usersBucket), key, record); err != nil {
+			return err
+		}
+
+		if err := u.unindex(tx, record); err != nil {
+			return err
+		}
+
+		prefix := []byte(record.ID + "/")
+		cursor := tx.Bucket(membersUserBucket).Cursor()
+
+		for k, _ := cursor.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, _ = cursor.Seek(prefix) {
+			teamID := string(k[len(prefix):])
+
+			if err := tx.Bucket(membersBucket).Delete(memberKey(teamID, record.ID)); err != nil {
+				return err
+			}
+
+			if err := cursor.Delete(); err != nil {
+				return err
+			}
+		}
+
+		cursor = tx.Bucket(tokensUserBucket).Cursor()
+
+		for k, v := cursor.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, v = cursor.Seek(prefix) {
+			token := &model.AccessToken{}
+
+			if err := get(tx.Bucket(tokensBucket), v, token); err != nil {
+				return err
+			}
+
+			if err := removeToken(tx, token); err != nil {
+				return err
+			}
+		}
+
+		if err := removeRevocations(tx, record.ID); err != nil {
+			return err
+		}
+
+		if err := removeOwned(tx, record.ID, ""); err != nil {
+			return err
+		}
+
+		return tx.Bucket(usersBucket).Delete(key)
+	})
+}
+
+func (u *users) save(tx *bolt.Tx, existing, record *model.User) error {
+	if err := index(
+		tx.Bucket(usersSlugBucket),
+		record.ID,
+		existing.Slug,
+		record.Slug,
+		store.ErrDuplicateSlug,
+	); err != nil {
+		return err
+	}
+
+	if err := index(
+		tx.Bucket(usersUsernameBucket),
+		record.ID,
+		strings.ToLower(existing.Username),
+		strings.ToLower(record.Username),
+		store.ErrDuplicateUsername,
+	); err != nil {
+		return err
+	}
+
+	if err := index(
+		tx.Bucket(usersEmailBucket),
+		record.ID,
+		strings.ToLower(existing.Email),
+		strings.ToLower(record.Email),
+		store.ErrDuplicateEmail,
+	); err != nil {
+		return err
+	}
+
+	return put(tx.Bucket(usersBucket), []byte(record.ID), record)
+}
+
+func (u *users) unindex(tx *bolt.Tx, record *model.User) error {
+	if err := index(tx.Bucket(usersSlugBucket), record.ID, record.Slug, "", nil); err != nil {
+		return err
+	}
+
+	if err := index(tx.Bucket(usersUsernameBucket), record.ID, strings.ToLower(record.Username), "", nil); err != nil {
+		return err
+	}
+
+	return index(tx.Bucket(usersEmailBucket), record.ID, strings.ToLower(record.Email), "", nil)
+}
@@ -0,0 +1,135 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/umschlag/umschlag-api/pkg/model"
+	"github.com/umschlag/umschlag-api/pkg/password"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// fixture defines the structure of seed files, memberships reference the
+// team and the user by slug. Plaintext passwords get hashed while seeding.
+type fixture struct {
+	Users   []*model.User `json:"users"`
+	Teams   []*model.Team `json:"teams"`
+	Members []struct {
+		Team string `json:"team"`
+		User string `json:"user"`
+		Perm string `json:"perm"`
+	} `json:"members"`
+}
+
+// seed loads the fixture file and creates all defined records.
+func seed(s *memory, path string) error {
+	raw, err := ioutil.ReadFile(path)
+
+	if err != nil {
+		return errors.Wrap(err, "failed to read fixture")
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yml", ".yaml":
+		raw, err = yamlToJSON(raw)
+
+		if err != nil {
+			return errors.Wrap(err, "failed to parse fixture")
+		}
+	}
+
+	f := &fixture{}
+
+	if err := json.Unmarshal(raw, f); err != nil {
+		return errors.Wrap(err, "failed to parse fixture")
+	}
+
+	ctx := context.Background()
+
+	for _, record := range f.Users {
+		if record.Password != "" && !password.Hashed(record.Password) {
+			hash, err := password.Hash(record.Password, password.DefaultCost)
+
+			if err != nil {
+				return errors.Wrapf(err, "failed to seed user %s", record.Username)
+			}
+
+			record.Password = hash
+		}
+
+		if _, err := s.Users().Create(ctx, record); err != nil {
+			return errors.Wrapf(err, "failed to seed user %s", record.Username)
+		}
+	}
+
+	for _, record := range f.Teams {
+		if _, err := s.Teams().Create(ctx, record); err != nil {
+			return errors.Wrapf(err, "failed to seed team %s", record.Name)
+		}
+	}
+
+	for _, record := range f.Members {
+		team, err := s.Teams().Show(ctx, record.Team)
+
+		if err != nil {
+			return errors.Wrapf(err, "failed to seed member of team %s", record.Team)
+		}
+
+		user, err := s.Users().Show(ctx, record.User)
+
+		if err != nil {
+			return errors.Wrapf(err, "failed to seed member %s", record.User)
+		}
+
+		perm := record.Perm
+
+		if perm == "" {
+			perm = model.PermUser
+		}
+
+		if err := s.Members().Assign(ctx, team.ID, user.ID, perm); err != nil {
+			return errors.Wrapf(err, "failed to seed member %s of team %s", record.User, record.Team)
+		}
+	}
+
+	return nil
+}
+
+// yamlToJSON converts YAML to JSON, this way the json tags of the models
+// are used for both formats.
+func yamlToJSON(raw []byte) ([]byte, error) {
+	var val interface{}
+
+	if err := yaml.Unmarshal(raw, &val); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(normalize(val))
+}
+
+// normalize converts the generic YAML maps into JSON compatible maps.
+func normalize(val interface{}) interface{} {
+	switch v := val.(type) {
+	case map[interface{}]interface{}:
+		result := make(map[string]interface{}, len(v))
+
+		for key, child := range v {
+			result[fmt.Sprint(key)] = normalize(child)
+		}
+
+		return result
+	case []interface{}:
+		for i, child := range v {
+			v[i] = normalize(child)
+		}
+
+		return v
+	}
+
+	return val
+}
@@ -0,0 +1,74 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/umschlag/umschlag-api/pkg/model"
+	"github.com/umschlag/umschlag-api/pkg/store"
+)
+
+type immutables struct {
+	*memory
+}
+
+// List retrieves all immutable tag rules of a namespace.
+func (i *immutables) List(ctx context.Context, namespaceID string) ([]*model.Immutable, error) {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	records := make([]*model.Immutable, 0)
+
+	for _, immutable := range i.immutables {
+		if immutable.NamespaceID == namespaceID {
+			record := *immutable
+			records = append(records, &record)
+		}
+	}
+
+	sort.Slice(records, func(a, b int) bool {
+		return records[a].CreatedAt.Before(records[b].CreatedAt)
+	})
+
+	return records, nil
+}
+
+// Create adds a new immutable tag rule to a namespace.
+func (i *immutables) Create(ctx context.Context, immutable *model.Immutable) (*model.Immutable, error) {
+	record := *immutable
+	record.ID = uuid.New().String()
+	record.CreatedAt = time.Now().UTC()
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if _, ok := i.namespaces[record.NamespaceID]; !ok {
+		return nil, store.ErrNotFound
+	}
+
+	for _, current := range i.immutables {
+		if current.NamespaceID == record.NamespaceID && current.Pattern == record.Pattern {
+			return nil, store.ErrDuplicatePattern
+		}
+	}
+
+	stored := record
+	i.immutables[record.ID] = &stored
+
+	return &record, nil
+}
+
+// Delete removes an immutable tag rule of a namespace.
+func (i *immutables) Delete(ctx context.Context, namespaceID, id string) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if immutable, ok := i.immutables[id]; !ok || immutable.NamespaceID != namespaceID {
+		return store.ErrNotFound
+	}
+
+	delete(i.immutables, id)
+	return nil
+}
@@ -0,0 +1,103 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/umschlag/umschlag-api/pkg/model"
+	"github.com/umschlag/umschlag-api/pkg/store"
+)
+
+type manifests struct {
+	*memory
+}
+
+// List retrieves all manifests of a repository.
+func (m *manifests) List(ctx context.Context, repositoryID string) ([]*model.Manifest, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	records := make([]*model.Manifest, 0, len(m.manifests[repositoryID]))
+
+	for _, manifest := range m.manifests[repositoryID] {
+		records = append(records, cloneManifest(manifest))
+	}
+
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].Digest < records[j].Digest
+	})
+
+	return records, nil
+}
+
+// Show retrieves a manifest of a repository by digest.
+func (m *manifests) Show(ctx context.Context, repositoryID, digest string) (*model.Manifest, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if manifest, ok := m.manifests[repositoryID][digest]; ok {
+		return cloneManifest(manifest), nil
+	}
+
+	return nil, store.ErrNotFound
+}
+
+// Save creates or updates a manifest of a repository.
+func (m *manifests) Save(ctx context.Context, manifest *model.Manifest) (*model.Manifest, error) {
+	record := cloneManifest(manifest)
+	record.SyncedAt = time.Now().UTC()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.repositories[record.RepositoryID]; !ok {
+		return nil, store.ErrNotFound
+	}
+
+	if _, ok := m.manifests[record.RepositoryID]; !ok {
+		m.manifests[record.RepositoryID] = make(map[string]*model.Manifest)
+	}
+
+	m.manifests[record.RepositoryID][record.Digest] = cloneManifest(record)
+	return record, nil
+}
+
+// Delete removes a manifest of a repository including the tags pointing
+// to it.
+func (m *manifests) Delete(ctx context.Context, repositoryID, digest string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.manifests[repositoryID][digest]; !ok {
+		return store.ErrNotFound
+	}
+
+	for name, tag := range m.tags[repositoryID] {
+		if tag.Digest == digest {
+			delete(m.tags[repositoryID], name)
+		}
+	}
+
+	delete(m.manifests[repositoryID], digest)
+	return nil
+}
+
+// cloneManifest copies the manifest including the blobs and the creation
+// date to keep the stored record isolated.
+func cloneManifest(manifest *model.Manifest) *model.Manifest {
+	record := *manifest
+	record.Blobs = make([]*model.Blob, 0, len(manifest.Blobs))
+
+	for _, blob := range manifest.Blobs {
+		b := *blob
+		record.Blobs = append(record.Blobs, &b)
+	}
+
+	if manifest.CreatedAt != nil {
+		created := *manifest.CreatedAt
+		record.CreatedAt = &created
+	}
+
+	return &record
+}
@@ -0,0 +1,114 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"github.com/umschlag/umschlag-api/pkg/model"
+	"github.com/umschlag/umschlag-api/pkg/store"
+)
+
+type members struct {
+	*memory
+}
+
+// ListByTeam retrieves all memberships of a team including the users.
+func (m *members) ListByTeam(ctx context.Context, teamID string) ([]*model.TeamUser, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	records := make([]*model.TeamUser, 0)
+
+	for userID, perm := range m.members[teamID] {
+		records = append(records, m.decode(teamID, userID, perm))
+	}
+
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].User.Username < records[j].User.Username
+	})
+
+	return records, nil
+}
+
+// ListByUser retrieves all memberships of a user including the teams.
+func (m *members) ListByUser(ctx context.Context, userID string) ([]*model.TeamUser, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	records := make([]*model.TeamUser, 0)
+
+	for teamID, assigned := range m.members {
+		if perm, ok := assigned[userID]; ok {
+			records = append(records, m.decode(teamID, userID, perm))
+		}
+	}
+
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].Team.Name < records[j].Team.Name
+	})
+
+	return records, nil
+}
+
+// Assign creates a new membership of a user within a team.
+func (m *members) Assign(ctx context.Context, teamID, userID, perm string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	assigned, ok := m.members[teamID]
+
+	if !ok {
+		return store.ErrNotFound
+	}
+
+	if _, ok := m.users[userID]; !ok {
+		return store.ErrNotFound
+	}
+
+	if _, ok := assigned[userID]; ok {
+		return store.ErrAlreadyAssigned
+	}
+
+	assigned[userID] = perm
+	return nil
+}
+
+// Permit updates the permission of an existing membership.
+func (m *members) Permit(ctx context.Context, teamID, userID, perm string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.members[teamID][userID]; !ok {
+		return store.ErrNotAssigned
+	}
+
+	m.members[teamID][userID] = perm
+	return nil
+}
+
+// Unassign removes the membership of a user within a team.
+func (m *members) Unassign(ctx context.Context, teamID, userID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.members[teamID][userID]; !ok {
+		return store.ErrNotAssigned
+	}
+
+	delete(m.members[teamID], userID)
+	return nil
+}
+
+// decode builds a membership with copies of the team and user.
+func (m *members) decode(teamID, userID, perm string) *model.TeamUser {
+	team := *m.teams[teamID]
+	user := *m.users[userID]
+
+	return &model.TeamUser{
+		TeamID: teamID,
+		Team:   &team,
+		UserID: userID,
+		User:   &user,
+		Perm:   perm,
+	}
+}
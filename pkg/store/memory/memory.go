@@ -0,0 +1,230 @@
+package memory
+
+import (
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/umschlag/umschlag-api/pkg/model"
+	"github.com/umschlag/umschlag-api/pkg/store"
+)
+
+type memory struct {
+	dsn     *url.URL
+	mu      sync.RWMutex
+	users   map[string]*model.User
+	teams   map[string]*model.Team
+	members map[string]map[string]string
+	tokens  map[string]*model.AccessToken
+	revoked map[string]*model.Revocation
+	cutoffs map[string]time.Time
+
+	namespaces   map[string]*model.Namespace
+	repositories map[string]*model.Repository
+	grants       map[string]map[string]string
+	tags         map[string]map[string]*model.Tag
+	manifests    map[string]map[string]*model.Manifest
+	retentions   map[string]*model.Retention
+	reports      map[string][]*model.Report
+	immutables   map[string]*model.Immutable
+	quotas       map[string]*model.Quota
+}
+
+// Close simply drops all stored records.
+func (s *memory) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.users = make(map[string]*model.User)
+	s.teams = make(map[string]*model.Team)
+	s.members = make(map[string]map[string]string)
+	s.tokens = make(map[string]*model.AccessToken)
+	s.revoked = make(map[string]*model.Revocation)
+	s.cutoffs = make(map[string]time.Time)
+	s.namespaces = make(map[string]*model.Namespace)
+	s.repositories = make(map[string]*model.Repository)
+	s.grants = make(map[string]map[string]string)
+	s.tags = make(map[string]map[string]*model.Tag)
+	s.manifests = make(map[string]map[string]*model.Manifest)
+	s.retentions = make(map[string]*model.Retention)
+	s.reports = make(map[string][]*model.Report)
+	s.immutables = make(map[string]*model.Immutable)
+	s.quotas = make(map[string]*model.Quota)
+
+	return nil
+}
+
+// Users provides access to the stored users.
+func (s *memory) Users() store.UserStore {
+	return &users{
+		memory: s,
+	}
+}
+
+// Teams provides access to the stored teams.
+func (s *memory) Teams() store.TeamStore {
+	return &teams{
+		memory: s,
+	}
+}
+
+// Members provides access to the team memberships.
+func (s *memory) Members() store.MemberStore {
+	return &members{
+		memory: s,
+	}
+}
+
+// Tokens provides access to the personal access tokens.
+func (s *memory) Tokens() store.TokenStore {
+	return &tokens{
+		memory: s,
+	}
+}
+
+// Revocations provides access to the revoked tokens.
+func (s *memory) Revocations() store.RevocationStore {
+	return &revocations{
+		memory: s,
+	}
+}
+
+// Namespaces provides access to the stored namespaces.
+func (s *memory) Namespaces() store.NamespaceStore {
+	return &namespaces{
+		memory: s,
+	}
+}
+
+// Repositories provides access to the stored repositories.
+func (s *memory) Repositories() store.RepositoryStore {
+	return &repositories{
+		memory: s,
+	}
+}
+
+// Tags provides access to the tags of repositories.
+func (s *memory) Tags() store.TagStore {
+	return &tags{
+		memory: s,
+	}
+}
+
+// Manifests provides access to the manifests of repositories.
+func (s *memory) Manifests() store.ManifestStore {
+	return &manifests{
+		memory: s,
+	}
+}
+
+// Retentions provides access to the retention policies.
+func (s *memory) Retentions() store.RetentionStore {
+	return &retentions{
+		memory: s,
+	}
+}
+
+// Immutables provides access to the immutable tag rules.
+func (s *memory) Immutables() store.ImmutableStore {
+	return &immutables{
+		memory: s,
+	}
+}
+
+// Quotas provides access to the storage quotas.
+func (s *memory) Quotas() store.QuotaStore {
+	return &quotas{
+		memory: s,
+	}
+}
+
+// New initializes a new in-memory store, the records get optionally seeded
+// from a JSON or YAML file defined by the fixture query param.
+func New(dsn *url.URL) (store.Store, error) {
+	s := &memory{
+		dsn:     dsn,
+		users:   make(map[string]*model.User),
+		teams:   make(map[string]*model.Team),
+		members: make(map[string]map[string]string),
+		tokens:  make(map[string]*model.AccessToken),
+		revoked: make(map[string]*model.Revocation),
+		cutoffs: make(map[string]time.Time),
+
+		namespaces:   make(map[string]*model.Namespace),
+		repositories: make(map[string]*model.Repository),
+		grants:       make(map[string]map[string]string),
+		tags:         make(map[string]map[string]*model.Tag),
+		manifests:    make(map[string]map[string]*model.Manifest),
+		retentions:   make(map[string]*model.Retention),
+		reports:      make(map[string][]*model.Report),
+		immutables:   make(map[string]*model.Immutable),
+		quotas:       make(map[string]*model.Quota),
+	}
+
+	if path := dsn.Query().Get("fixture"); path != "" {
+		if err := seed(s, path); err != nil {
+			return nil, err
+		}
+	}
+
+	return s, nil
+}
+
+// dropNamespace removes a namespace including the repositories, their team
+// permissions, the retention policies, the immutable tag rules and the
+// quota, the lock must be held.
+func (s *memory) dropNamespace(id string) {
+	for repoID, repository := range s.repositories {
+		if repository.NamespaceID == id {
+			s.dropRepository(repoID)
+		}
+	}
+
+	for retentionID, retention := range s.retentions {
+		if retention.NamespaceID == id {
+			s.dropRetention(retentionID)
+		}
+	}
+
+	for immutableID, immutable := range s.immutables {
+		if immutable.NamespaceID == id {
+			delete(s.immutables, immutableID)
+		}
+	}
+
+	delete(s.quotas, quotaKey(model.QuotaNamespace, id))
+	delete(s.namespaces, id)
+}
+
+// dropRepository removes a repository including the team permissions, tags,
+// manifests and the retention policy, the lock must be held.
+func (s *memory) dropRepository(id string) {
+	for retentionID, retention := range s.retentions {
+		if retention.RepositoryID == id {
+			s.dropRetention(retentionID)
+		}
+	}
+
+	delete(s.grants, id)
+	delete(s.tags, id)
+	delete(s.manifests, id)
+	delete(s.repositories, id)
+}
+
+// dropRetention removes a retention policy including the reports, the lock
+// must be held.
+func (s *memory) dropRetention(id string) {
+	delete(s.reports, id)
+	delete(s.retentions, id)
+}
+
+// Must simply calls New and panics on an error.
+func Must(dsn *url.URL) store.Store {
+	db, err := New(dsn)
+
+	if err != nil {
+		panic(err)
+	}
+
+	return db
+}
@@ -0,0 +1,21 @@
+package memory
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/umschlag/umschlag-api/pkg/store"
+	"github.com/umschlag/umschlag-api/pkg/store/storetest"
+)
+
+func TestStore(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) store.Store {
+		dsn, err := url.Parse("memory://")
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return Must(dsn)
+	})
+}
@@ -0,0 +1,148 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/umschlag/umschlag-api/pkg/model"
+	"github.com/umschlag/umschlag-api/pkg/store"
+)
+
+type namespaces struct {
+	*memory
+}
+
+// List retrieves all available namespaces.
+func (n *namespaces) List(ctx context.Context) ([]*model.Namespace, error) {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
+	records := make([]*model.Namespace, 0, len(n.namespaces))
+
+	for _, namespace := range n.namespaces {
+		record := *namespace
+		records = append(records, &record)
+	}
+
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].Name < records[j].Name
+	})
+
+	return records, nil
+}
+
+// Show retrieves a namespace by ID or name.
+func (n *namespaces) Show(ctx context.Context, id string) (*model.Namespace, error) {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
+	namespace := n.namespace(id)
+
+	if namespace == nil {
+		return nil, store.ErrNotFound
+	}
+
+	record := *namespace
+	return &record, nil
+}
+
+// Create stores a new namespace and fills the generated fields.
+func (n *namespaces) Create(ctx context.Context, namespace *model.Namespace) (*model.Namespace, error) {
+	record := *namespace
+	record.ID = uuid.New().String()
+	record.CreatedAt = time.Now().UTC()
+	record.UpdatedAt = record.CreatedAt
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if err := n.validate(&record); err != nil {
+		return nil, err
+	}
+
+	stored := record
+	n.namespaces[record.ID] = &stored
+
+	return &record, nil
+}
+
+// Update stores the changes of an existing namespace.
+func (n *namespaces) Update(ctx context.Context, namespace *model.Namespace) (*model.Namespace, error) {
+	record := *namespace
+	record.UpdatedAt = time.Now().UTC()
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	existing, ok := n.namespaces[record.ID]
+
+	if !ok {
+		return nil, store.ErrNotFound
+	}
+
+	if err := n.validate(&record); err != nil {
+		return nil, err
+	}
+
+	record.CreatedAt = existing.CreatedAt
+
+	stored := record
+	n.namespaces[record.ID] = &stored
+
+	return &record, nil
+}
+
+// Delete removes a namespace by ID or name including the repositories.
+func (n *namespaces) Delete(ctx context.Context, id string) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	namespace := n.namespace(id)
+
+	if namespace == nil {
+		return store.ErrNotFound
+	}
+
+	n.dropNamespace(namespace.ID)
+	return nil
+}
+
+// namespace looks up a namespace by ID or name, the lock must be held.
+func (n *namespaces) namespace(id string) *model.Namespace {
+	if namespace, ok := n.namespaces[id]; ok {
+		return namespace
+	}
+
+	for _, namespace := range n.namespaces {
+		if namespace.Name == id {
+			return namespace
+		}
+	}
+
+	return nil
+}
+
+// validate checks the owner and the unique name of the namespace.
+func (n *namespaces) validate(record *model.Namespace) error {
+	if (record.UserID == "") == (record.TeamID == "") {
+		return store.ErrNotFound
+	}
+
+	if _, ok := n.users[record.UserID]; record.UserID != "" && !ok {
+		return store.ErrNotFound
+	}
+
+	if _, ok := n.teams[record.TeamID]; record.TeamID != "" && !ok {
+		return store.ErrNotFound
+	}
+
+	for _, namespace := range n.namespaces {
+		if namespace.ID != record.ID && namespace.Name == record.Name {
+			return store.ErrDuplicateName
+		}
+	}
+
+	return nil
+}
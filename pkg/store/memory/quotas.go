@@ -0,0 +1,81 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/umschlag/umschlag-api/pkg/model"
+	"github.com/umschlag/umschlag-api/pkg/store"
+)
+
+type quotas struct {
+	*memory
+}
+
+// Show retrieves the quota of a namespace or a team.
+func (q *quotas) Show(ctx context.Context, kind, ownerID string) (*model.Quota, error) {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	if quota, ok := q.quotas[quotaKey(kind, ownerID)]; ok {
+		record := *quota
+		return &record, nil
+	}
+
+	return nil, store.ErrNotFound
+}
+
+// Save creates or updates the quota of a namespace or a team.
+func (q *quotas) Save(ctx context.Context, quota *model.Quota) (*model.Quota, error) {
+	record := *quota
+	record.UpdatedAt = time.Now().UTC()
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	switch record.Kind {
+	case model.QuotaNamespace:
+		if _, ok := q.namespaces[record.OwnerID]; !ok {
+			return nil, store.ErrNotFound
+		}
+	case model.QuotaTeam:
+		if _, ok := q.teams[record.OwnerID]; !ok {
+			return nil, store.ErrNotFound
+		}
+	default:
+		return nil, store.ErrNotFound
+	}
+
+	key := quotaKey(record.Kind, record.OwnerID)
+
+	if current, ok := q.quotas[key]; ok {
+		record.CreatedAt = current.CreatedAt
+	} else {
+		record.CreatedAt = record.UpdatedAt
+	}
+
+	stored := record
+	q.quotas[key] = &stored
+
+	return &record, nil
+}
+
+// Delete removes the quota of a namespace or a team.
+func (q *quotas) Delete(ctx context.Context, kind, ownerID string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	key := quotaKey(kind, ownerID)
+
+	if _, ok := q.quotas[key]; !ok {
+		return store.ErrNotFound
+	}
+
+	delete(q.quotas, key)
+	return nil
+}
+
+// quotaKey builds the map key of a quota.
+func quotaKey(kind, ownerID string) string {
+	return kind + "/" + ownerID
+}
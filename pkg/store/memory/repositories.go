@@ -0,0 +1,201 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/umschlag/umschlag-api/pkg/model"
+	"github.com/umschlag/umschlag-api/pkg/store"
+)
+
+type repositories struct {
+	*memory
+}
+
+// List retrieves all repositories of a namespace.
+func (r *repositories) List(ctx context.Context, namespaceID string) ([]*model.Repository, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	records := make([]*model.Repository, 0)
+
+	for _, repository := range r.repositories {
+		if repository.NamespaceID == namespaceID {
+			record := *repository
+			records = append(records, &record)
+		}
+	}
+
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].Name < records[j].Name
+	})
+
+	return records, nil
+}
+
+// Show retrieves a repository of a namespace by ID or name.
+func (r *repositories) Show(ctx context.Context, namespaceID, id string) (*model.Repository, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if repository, ok := r.repositories[id]; ok && repository.NamespaceID == namespaceID {
+		record := *repository
+		return &record, nil
+	}
+
+	for _, repository := range r.repositories {
+		if repository.NamespaceID == namespaceID && repository.Name == id {
+			record := *repository
+			return &record, nil
+		}
+	}
+
+	return nil, store.ErrNotFound
+}
+
+// Create stores a new repository and fills the generated fields.
+func (r *repositories) Create(ctx context.Context, repository *model.Repository) (*model.Repository, error) {
+	record := *repository
+	record.ID = uuid.New().String()
+	record.CreatedAt = time.Now().UTC()
+	record.UpdatedAt = record.CreatedAt
+
+	if record.Visibility == "" {
+		record.Visibility = model.VisibilityPrivate
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.namespaces[record.NamespaceID]; !ok {
+		return nil, store.ErrNotFound
+	}
+
+	if err := r.conflicts(&record); err != nil {
+		return nil, err
+	}
+
+	stored := record
+	r.repositories[record.ID] = &stored
+	r.grants[record.ID] = make(map[string]string)
+
+	return &record, nil
+}
+
+// Update stores the changes of an existing repository, the namespace of a
+// repository can't be changed.
+func (r *repositories) Update(ctx context.Context, repository *model.Repository) (*model.Repository, error) {
+	record := *repository
+	record.UpdatedAt = time.Now().UTC()
+
+	if record.Visibility == "" {
+		record.Visibility = model.VisibilityPrivate
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.repositories[record.ID]
+
+	if !ok {
+		return nil, store.ErrNotFound
+	}
+
+	record.NamespaceID = existing.NamespaceID
+	record.CreatedAt = existing.CreatedAt
+
+	if err := r.conflicts(&record); err != nil {
+		return nil, err
+	}
+
+	stored := record
+	r.repositories[record.ID] = &stored
+
+	return &record, nil
+}
+
+// Delete removes a repository by ID including the team permissions, tags
+// and manifests.
+func (r *repositories) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.repositories[id]; !ok {
+		return store.ErrNotFound
+	}
+
+	r.dropRepository(id)
+	return nil
+}
+
+// ListTeams retrieves the team permissions of a repository including the teams.
+func (r *repositories) ListTeams(ctx context.Context, repositoryID string) ([]*model.RepositoryTeam, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	records := make([]*model.RepositoryTeam, 0)
+
+	for teamID, perm := range r.grants[repositoryID] {
+		team := *r.teams[teamID]
+
+		records = append(records, &model.RepositoryTeam{
+			RepositoryID: repositoryID,
+			TeamID:       teamID,
+			Team:         &team,
+			Perm:         perm,
+		})
+	}
+
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].Team.Name < records[j].Team.Name
+	})
+
+	return records, nil
+}
+
+// PermitTeam grants or changes the permission of a team on a repository.
+func (r *repositories) PermitTeam(ctx context.Context, repositoryID, teamID, perm string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.repositories[repositoryID]; !ok {
+		return store.ErrNotFound
+	}
+
+	if _, ok := r.teams[teamID]; !ok {
+		return store.ErrNotFound
+	}
+
+	r.grants[repositoryID][teamID] = perm
+	return nil
+}
+
+// RevokeTeam removes the permission of a team on a repository.
+func (r *repositories) RevokeTeam(ctx context.Context, repositoryID, teamID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.grants[repositoryID][teamID]; !ok {
+		return store.ErrNotAssigned
+	}
+
+	delete(r.grants[repositoryID], teamID)
+	return nil
+}
+
+// conflicts checks the unique name within the namespace.
+func (r *repositories) conflicts(record *model.Repository) error {
+	for _, repository := range r.repositories {
+		if repository.ID == record.ID {
+			continue
+		}
+
+		if repository.NamespaceID == record.NamespaceID && repository.Name == record.Name {
+			return store.ErrDuplicateName
+		}
+	}
+
+	return nil
+}
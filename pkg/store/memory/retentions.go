@@ -0,0 +1,157 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/umschlag/umschlag-api/pkg/model"
+	"github.com/umschlag/umschlag-api/pkg/store"
+)
+
+type retentions struct {
+	*memory
+}
+
+// List retrieves all retention policies.
+func (r *retentions) List(ctx context.Context) ([]*model.Retention, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	records := make([]*model.Retention, 0, len(r.retentions))
+
+	for _, retention := range r.retentions {
+		record := *retention
+		records = append(records, &record)
+	}
+
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].CreatedAt.Before(records[j].CreatedAt)
+	})
+
+	return records, nil
+}
+
+// Show retrieves the retention policy of a namespace or a repository.
+func (r *retentions) Show(ctx context.Context, namespaceID, repositoryID string) (*model.Retention, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if retention := r.retention(namespaceID, repositoryID); retention != nil {
+		record := *retention
+		return &record, nil
+	}
+
+	return nil, store.ErrNotFound
+}
+
+// Save creates or updates the retention policy of a namespace or a
+// repository.
+func (r *retentions) Save(ctx context.Context, retention *model.Retention) (*model.Retention, error) {
+	record := *retention
+	record.UpdatedAt = time.Now().UTC()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.namespaces[record.NamespaceID]; !ok {
+		return nil, store.ErrNotFound
+	}
+
+	if record.RepositoryID != "" {
+		if repository, ok := r.repositories[record.RepositoryID]; !ok || repository.NamespaceID != record.NamespaceID {
+			return nil, store.ErrNotFound
+		}
+	}
+
+	if current := r.retention(record.NamespaceID, record.RepositoryID); current != nil {
+		record.ID = current.ID
+		record.CreatedAt = current.CreatedAt
+	} else {
+		record.ID = uuid.New().String()
+		record.CreatedAt = record.UpdatedAt
+	}
+
+	stored := record
+	r.retentions[record.ID] = &stored
+
+	return &record, nil
+}
+
+// Delete removes the retention policy of a namespace or a repository
+// including the reports.
+func (r *retentions) Delete(ctx context.Context, namespaceID, repositoryID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	retention := r.retention(namespaceID, repositoryID)
+
+	if retention == nil {
+		return store.ErrNotFound
+	}
+
+	r.dropRetention(retention.ID)
+	return nil
+}
+
+// ListReports retrieves the reports of a retention policy, newest first.
+func (r *retentions) ListReports(ctx context.Context, retentionID string) ([]*model.Report, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	records := make([]*model.Report, 0, len(r.reports[retentionID]))
+
+	for _, report := range r.reports[retentionID] {
+		records = append(records, cloneReport(report))
+	}
+
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].StartedAt.After(records[j].StartedAt)
+	})
+
+	return records, nil
+}
+
+// CreateReport stores the report of a retention policy run.
+func (r *retentions) CreateReport(ctx context.Context, report *model.Report) (*model.Report, error) {
+	record := cloneReport(report)
+	record.ID = uuid.New().String()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.retentions[record.RetentionID]; !ok {
+		return nil, store.ErrNotFound
+	}
+
+	r.reports[record.RetentionID] = append(r.reports[record.RetentionID], cloneReport(record))
+	return record, nil
+}
+
+// retention finds the policy of a namespace or a repository, the lock must
+// be held.
+func (r *retentions) retention(namespaceID, repositoryID string) *model.Retention {
+	for _, retention := range r.retentions {
+		if retention.NamespaceID == namespaceID && retention.RepositoryID == repositoryID {
+			return retention
+		}
+	}
+
+	return nil
+}
+
+// cloneReport copies the report including the entries to keep the stored
+// record isolated.
+func cloneReport(report *model.Report) *model.Report {
+	record := *report
+	record.Entries = make([]*model.ReportEntry, 0, len(report.Entries))
+
+	for _, entry := range report.Entries {
+		e := *entry
+		e.Tags = append([]string{}, entry.Tags...)
+		record.Entries = append(record.Entries, &e)
+	}
+
+	return &record
+}
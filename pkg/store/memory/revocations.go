@@ -0,0 +1,86 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/umschlag/umschlag-api/pkg/model"
+	"github.com/umschlag/umschlag-api/pkg/store"
+)
+
+type revocations struct {
+	*memory
+}
+
+// Revoke stores the revocation of a single token.
+func (r *revocations) Revoke(ctx context.Context, revocation *model.Revocation) error {
+	record := *revocation
+	record.CreatedAt = time.Now().UTC()
+
+	if record.ExpiresAt != nil {
+		expires := record.ExpiresAt.UTC()
+		record.ExpiresAt = &expires
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.users[record.UserID]; !ok {
+		return store.ErrNotFound
+	}
+
+	r.revoked[record.TokenID] = &record
+	return nil
+}
+
+// Revoked checks if a token has been revoked.
+func (r *revocations) Revoked(ctx context.Context, tokenID string) (bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	_, ok := r.revoked[tokenID]
+	return ok, nil
+}
+
+// RevokeBefore revokes all tokens of a user issued before the time, an
+// already later point in time is kept.
+func (r *revocations) RevokeBefore(ctx context.Context, userID string, before time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.users[userID]; !ok {
+		return store.ErrNotFound
+	}
+
+	if before.After(r.cutoffs[userID]) {
+		r.cutoffs[userID] = before.UTC()
+	}
+
+	return nil
+}
+
+// RevokedBefore retrieves the time before that all tokens of a user have
+// been revoked, it's zero if that never happened.
+func (r *revocations) RevokedBefore(ctx context.Context, userID string) (time.Time, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.cutoffs[userID], nil
+}
+
+// Prune removes the revocations of tokens that are expired anyway.
+func (r *revocations) Prune(ctx context.Context, now time.Time) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	count := 0
+
+	for id, record := range r.revoked {
+		if record.Expired(now) {
+			delete(r.revoked, id)
+			count++
+		}
+	}
+
+	return count, nil
+}
@@ -0,0 +1,125 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/umschlag/umschlag-api/pkg/model"
+	"github.com/umschlag/umschlag-api/pkg/store"
+)
+
+type tags struct {
+	*memory
+}
+
+// List retrieves all tags of a repository.
+func (t *tags) List(ctx context.Context, repositoryID string) ([]*model.Tag, error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	records := make([]*model.Tag, 0, len(t.tags[repositoryID]))
+
+	for _, tag := range t.tags[repositoryID] {
+		record := *tag
+		records = append(records, &record)
+	}
+
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].Name < records[j].Name
+	})
+
+	return records, nil
+}
+
+// Show retrieves a tag of a repository by name.
+func (t *tags) Show(ctx context.Context, repositoryID, name string) (*model.Tag, error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	if tag, ok := t.tags[repositoryID][name]; ok {
+		record := *tag
+		return &record, nil
+	}
+
+	return nil, store.ErrNotFound
+}
+
+// Save creates or updates a tag of a repository.
+func (t *tags) Save(ctx context.Context, tag *model.Tag) (*model.Tag, error) {
+	record := *tag
+	record.UpdatedAt = time.Now().UTC()
+	record.CreatedAt = record.UpdatedAt
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if _, ok := t.repositories[record.RepositoryID]; !ok {
+		return nil, store.ErrNotFound
+	}
+
+	if _, ok := t.tags[record.RepositoryID]; !ok {
+		t.tags[record.RepositoryID] = make(map[string]*model.Tag)
+	}
+
+	if current, ok := t.tags[record.RepositoryID][record.Name]; ok {
+		record.Pulls = current.Pulls
+		record.PulledAt = current.PulledAt
+		record.OverwrittenAt = current.OverwrittenAt
+		record.CreatedAt = current.CreatedAt
+	}
+
+	stored := record
+	t.tags[record.RepositoryID][record.Name] = &stored
+
+	return &record, nil
+}
+
+// Pull increments the pull counter of a tag.
+func (t *tags) Pull(ctx context.Context, repositoryID, name string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	tag, ok := t.tags[repositoryID][name]
+
+	if !ok {
+		return store.ErrNotFound
+	}
+
+	pulled := time.Now().UTC()
+
+	tag.Pulls++
+	tag.PulledAt = &pulled
+
+	return nil
+}
+
+// Flag marks a tag as overwritten.
+func (t *tags) Flag(ctx context.Context, repositoryID, name string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	tag, ok := t.tags[repositoryID][name]
+
+	if !ok {
+		return store.ErrNotFound
+	}
+
+	overwritten := time.Now().UTC()
+	tag.OverwrittenAt = &overwritten
+
+	return nil
+}
+
+// Delete removes a tag of a repository by name.
+func (t *tags) Delete(ctx context.Context, repositoryID, name string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if _, ok := t.tags[repositoryID][name]; !ok {
+		return store.ErrNotFound
+	}
+
+	delete(t.tags[repositoryID], name)
+	return nil
+}
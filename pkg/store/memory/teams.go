@@ -0,0 +1,158 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/umschlag/umschlag-api/pkg/model"
+	"github.com/umschlag/umschlag-api/pkg/store"
+)
+
+type teams struct {
+	*memory
+}
+
+// List retrieves all available teams.
+func (t *teams) List(ctx context.Context) ([]*model.Team, error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	records := make([]*model.Team, 0, len(t.teams))
+
+	for _, team := range t.teams {
+		record := *team
+		records = append(records, &record)
+	}
+
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].Name < records[j].Name
+	})
+
+	return records, nil
+}
+
+// Show retrieves a team by ID or slug.
+func (t *teams) Show(ctx context.Context, id string) (*model.Team, error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	team := t.team(id)
+
+	if team == nil {
+		return nil, store.ErrNotFound
+	}
+
+	record := *team
+	return &record, nil
+}
+
+// Create stores a new team and fills the generated fields.
+func (t *teams) Create(ctx context.Context, team *model.Team) (*model.Team, error) {
+	record := *team
+	record.ID = uuid.New().String()
+	record.CreatedAt = time.Now().UTC()
+	record.UpdatedAt = record.CreatedAt
+
+	if record.Slug == "" {
+		record.Slug = store.Slugify(record.Name)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if err := t.conflicts(&record); err != nil {
+		return nil, err
+	}
+
+	stored := record
+	t.teams[record.ID] = &stored
+	t.members[record.ID] = make(map[string]string)
+
+	return &record, nil
+}
+
+// Update stores the changes of an existing team.
+func (t *teams) Update(ctx context.Context, team *model.Team) (*model.Team, error) {
+	record := *team
+	record.UpdatedAt = time.Now().UTC()
+
+	if record.Slug == "" {
+		record.Slug = store.Slugify(record.Name)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	existing, ok := t.teams[record.ID]
+
+	if !ok {
+		return nil, store.ErrNotFound
+	}
+
+	if err := t.conflicts(&record); err != nil {
+		return nil, err
+	}
+
+	record.CreatedAt = existing.CreatedAt
+
+	stored := record
+	t.teams[record.ID] = &stored
+
+	return &record, nil
+}
+
+// Delete removes a team by ID or slug including all related records.
+func (t *teams) Delete(ctx context.Context, id string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	team := t.team(id)
+
+	if team == nil {
+		return store.ErrNotFound
+	}
+
+	for id, namespace := range t.namespaces {
+		if namespace.TeamID == team.ID {
+			t.dropNamespace(id)
+		}
+	}
+
+	for _, granted := range t.grants {
+		delete(granted, team.ID)
+	}
+
+	delete(t.quotas, quotaKey(model.QuotaTeam, team.ID))
+	delete(t.members, team.ID)
+	delete(t.teams, team.ID)
+
+	return nil
+}
+
+// team looks up a team by ID or slug, the lock must be held.
+func (t *teams) team(id string) *model.Team {
+	if team, ok := t.teams[id]; ok {
+		return team
+	}
+
+	for _, team := range t.teams {
+		if team.Slug == id {
+			return team
+		}
+	}
+
+	return nil
+}
+
+// conflicts checks the unique fields against all other teams.
+func (t *teams) conflicts(record *model.Team) error {
+	for _, team := range t.teams {
+		if team.ID != record.ID && team.Slug == record.Slug {
+			return store.ErrDuplicateSlug
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,126 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/umschlag/umschlag-api/pkg/model"
+	"github.com/umschlag/umschlag-api/pkg/store"
+)
+
+type tokens struct {
+	*memory
+}
+
+// List retrieves all access tokens of a user.
+func (t *tokens) List(ctx context.Context, userID string) ([]*model.AccessToken, error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	records := make([]*model.AccessToken, 0)
+
+	for _, token := range t.tokens {
+		if token.UserID == userID {
+			records = append(records, cloneToken(token))
+		}
+	}
+
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].Name < records[j].Name
+	})
+
+	return records, nil
+}
+
+// ByHash retrieves an access token by the hash of the secret.
+func (t *tokens) ByHash(ctx context.Context, hash string) (*model.AccessToken, error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	for _, token := range t.tokens {
+		if token.Hash == hash {
+			return cloneToken(token), nil
+		}
+	}
+
+	return nil, store.ErrNotFound
+}
+
+// Create stores a new access token and fills the generated fields.
+func (t *tokens) Create(ctx context.Context, token *model.AccessToken) (*model.AccessToken, error) {
+	record := cloneToken(token)
+	record.ID = uuid.New().String()
+	record.CreatedAt = time.Now().UTC()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if _, ok := t.users[record.UserID]; !ok {
+		return nil, store.ErrNotFound
+	}
+
+	for _, existing := range t.tokens {
+		if existing.Hash == record.Hash {
+			return nil, store.ErrDuplicateName
+		}
+
+		if existing.UserID == record.UserID && existing.Name == record.Name {
+			return nil, store.ErrDuplicateName
+		}
+	}
+
+	t.tokens[record.ID] = cloneToken(record)
+	return record, nil
+}
+
+// Touch updates the last usage of an access token.
+func (t *tokens) Touch(ctx context.Context, id string, at time.Time) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	token, ok := t.tokens[id]
+
+	if !ok {
+		return store.ErrNotFound
+	}
+
+	used := at.UTC()
+	token.LastUsedAt = &used
+
+	return nil
+}
+
+// Delete removes an access token of a user.
+func (t *tokens) Delete(ctx context.Context, userID, id string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	token, ok := t.tokens[id]
+
+	if !ok || token.UserID != userID {
+		return store.ErrNotFound
+	}
+
+	delete(t.tokens, id)
+	return nil
+}
+
+// cloneToken creates a deep copy so callers never share stored state.
+func cloneToken(token *model.AccessToken) *model.AccessToken {
+	record := *token
+	record.Scopes = append([]string{}, token.Scopes...)
+
+	if token.ExpiresAt != nil {
+		expires := *token.ExpiresAt
+		record.ExpiresAt = &expires
+	}
+
+	if token.LastUsedAt != nil {
+		used := *token.LastUsedAt
+		record.LastUsedAt = &used
+	}
+
+	return &record
+}
@@ -0,0 +1,196 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/umschlag/umschlag-api/pkg/model"
+	"github.com/umschlag/umschlag-api/pkg/store"
+)
+
+type users struct {
+	*memory
+}
+
+// List retrieves all available users.
+func (u *users) List(ctx context.Context) ([]*model.User, error) {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+
+	records := make([]*model.User, 0, len(u.users))
+
+	for _, user := range u.users {
+		record := *user
+		records = append(records, &record)
+	}
+
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].Username < records[j].Username
+	})
+
+	return records, nil
+}
+
+// Show retrieves a user by ID or slug.
+func (u *users) Show(ctx context.Context, id string) (*model.User, error) {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+
+	user := u.user(id)
+
+	if user == nil {
+		return nil, store.ErrNotFound
+	}
+
+	record := *user
+	return &record, nil
+}
+
+// ByUsername retrieves a user by the case-insensitive username.
+func (u *users) ByUsername(ctx context.Context, username string) (*model.User, error) {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+
+	for _, user := range u.users {
+		if strings.EqualFold(user.Username, username) {
+			record := *user
+			return &record, nil
+		}
+	}
+
+	return nil, store.ErrNotFound
+}
+
+// Create stores a new user and fills the generated fields.
+func (u *users) Create(ctx context.Context, user *model.User) (*model.User, error) {
+	record := *user
+	record.ID = uuid.New().String()
+	record.CreatedAt = time.Now().UTC()
+	record.UpdatedAt = record.CreatedAt
+
+	if record.Slug == "" {
+		record.Slug = store.Slugify(record.Username)
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if err := u.conflicts(&record); err != nil {
+		return nil, err
+	}
+
+	stored := record
+	u.users[record.ID] = &stored
+
+	return &record, nil
+}
+
+// Update stores the changes of an existing user.
+func (u *users) Update(ctx context.Context, user *model.User) (*model.User, error) {
+	record := *user
+	record.UpdatedAt = time.Now().UTC()
+
+	if record.Slug == "" {
+		record.Slug = store.Slugify(record.Username)
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	existing, ok := u.users[record.ID]
+
+	if !ok {
+		return nil, store.ErrNotFound
+	}
+
+	if err := u.conflicts(&record); err != nil {
+		return nil, err
+	}
+
+	record.CreatedAt = existing.CreatedAt
+
+	stored := record
+	u.users[record.ID] = &stored
+
+	return &record, nil
+}
+
+// Delete removes a user by ID or slug including all related records.
+func (u *users) Delete(ctx context.Context, id string) error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	user := u.user(id)
+
+	if user == nil {
+		return store.ErrNotFound
+	}
+
+	for _, assigned := range u.members {
+		delete(assigned, user.ID)
+	}
+
+	for id, token := range u.tokens {
+		if token.UserID == user.ID {
+			delete(u.tokens, id)
+		}
+	}
+
+	for id, revocation := range u.revoked {
+		if revocation.UserID == user.ID {
+			delete(u.revoked, id)
+		}
+	}
+
+	delete(u.cutoffs, user.ID)
+
+	for id, namespace := range u.namespaces {
+		if namespace.UserID == user.ID {
+			u.dropNamespace(id)
+		}
+	}
+
+	delete(u.users, user.ID)
+	return nil
+}
+
+// user looks up a user by ID or slug, the lock must be held.
+func (u *users) user(id string) *model.User {
+	if user, ok := u.users[id]; ok {
+		return user
+	}
+
+	for _, user := range u.users {
+		if user.Slug == id {
+			return user
+		}
+	}
+
+	return nil
+}
+
+// conflicts checks the unique fields against all other users.
+func (u *users) conflicts(record *model.User) error {
+	for _, user := range u.users {
+		if user.ID == record.ID {
+			continue
+		}
+
+		if user.Slug == record.Slug {
+			return store.ErrDuplicateSlug
+		}
+
+		if strings.EqualFold(user.Username, record.Username) {
+			return store.ErrDuplicateUsername
+		}
+
+		if strings.EqualFold(user.Email, record.Email) {
+			return store.ErrDuplicateEmail
+		}
+	}
+
+	return nil
+}
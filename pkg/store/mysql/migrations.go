@@ -0,0 +1,285 @@
+package mysql
+
+import (
+	"github.com/umschlag/umschlag-api/pkg/store/sqlstore"
+)
+
+var migrations = []sqlstore.Migration{
+	{
+		Version: 1,
+		Name:    "create_users_table",
+		Statements: []string{
+			`CREATE TABLE users (
+				id CHAR(36) NOT NULL,
+				slug VARCHAR(191) NOT NULL,
+				username VARCHAR(191) NOT NULL COLLATE utf8mb4_unicode_ci,
+				password VARCHAR(255) NOT NULL DEFAULT '',
+				email VARCHAR(191) NOT NULL COLLATE utf8mb4_unicode_ci,
+				admin BOOLEAN NOT NULL DEFAULT FALSE,
+				active BOOLEAN NOT NULL DEFAULT FALSE,
+				created_at DATETIME(6) NOT NULL,
+				updated_at DATETIME(6) NOT NULL,
+				PRIMARY KEY (id),
+				UNIQUE KEY users_slug_key (slug),
+				UNIQUE KEY users_username_key (username),
+				UNIQUE KEY users_email_key (email)
+			) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin`,
+		},
+	},
+	{
+		Version: 2,
+		Name:    "create_teams_table",
+		Statements: []string{
+			`CREATE TABLE teams (
+				id CHAR(36) NOT NULL,
+				slug VARCHAR(191) NOT NULL,
+				name VARCHAR(255) NOT NULL,
+				created_at DATETIME(6) NOT NULL,
+				updated_at DATETIME(6) NOT NULL,
+				PRIMARY KEY (id),
+				UNIQUE KEY teams_slug_key (slug)
+			) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin`,
+		},
+	},
+	{
+		Version: 3,
+		Name:    "create_team_users_table",
+		Statements: []string{
+			`CREATE TABLE team_users (
+				team_id CHAR(36) NOT NULL,
+				user_id CHAR(36) NOT NULL,
+				perm VARCHAR(32) NOT NULL,
+				PRIMARY KEY (team_id, user_id),
+				KEY team_users_user_id_idx (user_id),
+				CONSTRAINT team_users_team_id_fkey FOREIGN KEY (team_id) REFERENCES teams (id) ON DELETE CASCADE,
+				CONSTRAINT team_users_user_id_fkey FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+			) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin`,
+		},
+	},
+	{
+		Version: 4,
+		Name:    "create_access_tokens_table",
+		Statements: []string{
+			`CREATE TABLE access_tokens (
+				id CHAR(36) NOT NULL,
+				user_id CHAR(36) NOT NULL,
+				name VARCHAR(191) NOT NULL,
+				hash CHAR(64) NOT NULL,
+				scopes VARCHAR(255) NOT NULL DEFAULT '',
+				expires_at DATETIME(6) NULL,
+				last_used_at DATETIME(6) NULL,
+				created_at DATETIME(6) NOT NULL,
+				PRIMARY KEY (id),
+				UNIQUE KEY access_tokens_hash_key (hash),
+				UNIQUE KEY access_tokens_name_key (user_id, name),
+				CONSTRAINT access_tokens_user_id_fkey FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+			) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin`,
+		},
+	},
+	{
+		Version: 5,
+		Name:    "create_revocations_tables",
+		Statements: []string{
+			`CREATE TABLE token_revocations (
+				token_id VARCHAR(64) NOT NULL,
+				user_id CHAR(36) NOT NULL,
+				expires_at DATETIME(6) NULL,
+				created_at DATETIME(6) NOT NULL,
+				PRIMARY KEY (token_id),
+				KEY token_revocations_expires_at_idx (expires_at),
+				CONSTRAINT token_revocations_user_id_fkey FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+			) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin`,
+			`CREATE TABLE user_revocations (
+				user_id CHAR(36) NOT NULL,
+				revoked_before DATETIME(6) NOT NULL,
+				PRIMARY KEY (user_id),
+				CONSTRAINT user_revocations_user_id_fkey FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+			) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin`,
+		},
+	},
+	{
+		Version: 6,
+		Name:    "create_namespaces_table",
+		Statements: []string{
+			`CREATE TABLE namespaces (
+				id CHAR(36) NOT NULL,
+				name VARCHAR(191) NOT NULL,
+				description TEXT NOT NULL,
+				user_id CHAR(36) NULL,
+				team_id CHAR(36) NULL,
+				created_at DATETIME(6) NOT NULL,
+				updated_at DATETIME(6) NOT NULL,
+				PRIMARY KEY (id),
+				UNIQUE KEY namespaces_name_key (name),
+				KEY namespaces_user_id_idx (user_id),
+				KEY namespaces_team_id_idx (team_id),
+				CONSTRAINT namespaces_user_id_fkey FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+				CONSTRAINT namespaces_team_id_fkey FOREIGN KEY (team_id) REFERENCES teams (id) ON DELETE CASCADE
+			) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin`,
+		},
+	},
+	{
+		Version: 7,
+		Name:    "create_repositories_table",
+		Statements: []string{
+			`CREATE TABLE repositories (
+				id CHAR(36) NOT NULL,
+				namespace_id CHAR(36) NOT NULL,
+				name VARCHAR(191) NOT NULL,
+				description TEXT NOT NULL,
+				visibility VARCHAR(32) NOT NULL,
+				created_at DATETIME(6) NOT NULL,
+				updated_at DATETIME(6) NOT NULL,
+				PRIMARY KEY (id),
+				UNIQUE KEY repositories_name_key (namespace_id, name),
+				CONSTRAINT repositories_namespace_id_fkey FOREIGN KEY (namespace_id) REFERENCES namespaces (id) ON DELETE CASCADE
+			) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin`,
+		},
+	},
+	{
+		Version: 8,
+		Name:    "create_repository_teams_table",
+		Statements: []string{
+			`CREATE TABLE repository_teams (
+				repository_id CHAR(36) NOT NULL,
+				team_id CHAR(36) NOT NULL,
+				perm VARCHAR(32) NOT NULL,
+				PRIMARY KEY (repository_id, team_id),
+				KEY repository_teams_team_id_idx (team_id),
+				CONSTRAINT repository_teams_repository_id_fkey FOREIGN KEY (repository_id) REFERENCES repositories (id) ON DELETE CASCADE,
+				CONSTRAINT repository_teams_team_id_fkey FOREIGN KEY (team_id) REFERENCES teams (id) ON DELETE CASCADE
+			) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin`,
+		},
+	},
+	{
+		Version: 9,
+		Name:    "create_tags_manifests_tables",
+		Statements: []string{
+			`CREATE TABLE manifests (
+				repository_id CHAR(36) NOT NULL,
+				digest VARCHAR(191) NOT NULL,
+				media_type VARCHAR(255) NOT NULL DEFAULT '',
+				size BIGINT NOT NULL DEFAULT 0,
+				blobs MEDIUMTEXT NOT NULL,
+				created_at DATETIME(6) NULL,
+				synced_at DATETIME(6) NOT NULL,
+				PRIMARY KEY (repository_id, digest),
+				CONSTRAINT manifests_repository_id_fkey FOREIGN KEY (repository_id) REFERENCES repositories (id) ON DELETE CASCADE
+			) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin`,
+			`CREATE TABLE tags (
+				repository_id CHAR(36) NOT NULL,
+				name VARCHAR(128) NOT NULL,
+				digest VARCHAR(191) NOT NULL,
+				updated_at DATETIME(6) NOT NULL,
+				PRIMARY KEY (repository_id, name),
+				KEY tags_digest_idx (repository_id, digest),
+				CONSTRAINT tags_repository_id_fkey FOREIGN KEY (repository_id) REFERENCES repositories (id) ON DELETE CASCADE
+			) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin`,
+		},
+	},
+	{
+		Version: 10,
+		Name:    "add_tags_pulls_columns",
+		Statements: []string{
+			`ALTER TABLE tags ADD COLUMN pulls BIGINT NOT NULL DEFAULT 0`,
+			`ALTER TABLE tags ADD COLUMN pulled_at DATETIME(6) NULL`,
+		},
+	},
+	{
+		Version: 11,
+		Name:    "create_retentions_reports_tables",
+		Statements: []string{
+			`CREATE TABLE retentions (
+				id CHAR(36) NOT NULL,
+				namespace_id CHAR(36) NOT NULL,
+				repository_id CHAR(36) NULL,
+				keep_last INT NOT NULL DEFAULT 0,
+				keep_pattern VARCHAR(255) NOT NULL DEFAULT '',
+				older_than INT NOT NULL DEFAULT 0,
+				created_at DATETIME(6) NOT NULL,
+				updated_at DATETIME(6) NOT NULL,
+				PRIMARY KEY (id),
+				KEY retentions_namespace_id_idx (namespace_id),
+				UNIQUE KEY retentions_repository_id_key (repository_id),
+				CONSTRAINT retentions_namespace_id_fkey FOREIGN KEY (namespace_id) REFERENCES namespaces (id) ON DELETE CASCADE,
+				CONSTRAINT retentions_repository_id_fkey FOREIGN KEY (repository_id) REFERENCES repositories (id) ON DELETE CASCADE
+			) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin`,
+			`CREATE TABLE reports (
+				id CHAR(36) NOT NULL,
+				retention_id CHAR(36) NOT NULL,
+				started_at DATETIME(6) NOT NULL,
+				finished_at DATETIME(6) NOT NULL,
+				entries MEDIUMTEXT NOT NULL,
+				PRIMARY KEY (id),
+				KEY reports_retention_id_idx (retention_id, started_at),
+				CONSTRAINT reports_retention_id_fkey FOREIGN KEY (retention_id) REFERENCES retentions (id) ON DELETE CASCADE
+			) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin`,
+		},
+	},
+	{
+		Version: 12,
+		Name:    "add_tags_overwritten_at_column",
+		Statements: []string{
+			`ALTER TABLE tags ADD COLUMN overwritten_at DATETIME(6) NULL`,
+		},
+	},
+	{
+		Version: 13,
+		Name:    "create_immutables_table",
+		Statements: []string{
+			`CREATE TABLE immutables (
+				id CHAR(36) NOT NULL,
+				namespace_id CHAR(36) NOT NULL,
+				pattern VARCHAR(255) NOT NULL,
+				exclude BOOLEAN NOT NULL DEFAULT FALSE,
+				created_at DATETIME(6) NOT NULL,
+				PRIMARY KEY (id),
+				UNIQUE KEY immutables_pattern_key (namespace_id, pattern),
+				CONSTRAINT immutables_namespace_id_fkey FOREIGN KEY (namespace_id) REFERENCES namespaces (id) ON DELETE CASCADE
+			) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin`,
+		},
+	},
+	{
+		Version: 14,
+		Name:    "create_quotas_table",
+		Statements: []string{
+			`CREATE TABLE quotas (
+				namespace_id CHAR(36) NULL,
+				team_id CHAR(36) NULL,
+				hard BIGINT NOT NULL DEFAULT 0,
+				soft BIGINT NOT NULL DEFAULT 0,
+				created_at DATETIME(6) NOT NULL,
+				updated_at DATETIME(6) NOT NULL,
+				UNIQUE KEY quotas_namespace_id_key (namespace_id),
+				UNIQUE KEY quotas_team_id_key (team_id),
+				CONSTRAINT quotas_namespace_id_fkey FOREIGN KEY (namespace_id) REFERENCES namespaces (id) ON DELETE CASCADE,
+				CONSTRAINT quotas_team_id_fkey FOREIGN KEY (team_id) REFERENCES teams (id) ON DELETE CASCADE
+			) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin`,
+		},
+	},
+	{
+		Version: 15,
+		Name:    "add_avatar_columns",
+		Statements: []string{
+			`ALTER TABLE users ADD COLUMN avatar VARCHAR(64) NOT NULL DEFAULT ''`,
+			`ALTER TABLE teams ADD COLUMN avatar VARCHAR(64) NOT NULL DEFAULT ''`,
+		},
+	},
+	{
+		Version: 16,
+		Name:    "add_tags_created_at_column",
+		Statements: []string{
+			`ALTER TABLE tags ADD COLUMN created_at DATETIME(6) NULL`,
+			`UPDATE tags SET created_at = updated_at`,
+			`ALTER TABLE tags MODIFY created_at DATETIME(6) NOT NULL`,
+		},
+	},
+	{
+		Version: 17,
+		Name:    "add_retentions_namespace_id_key",
+		Statements: []string{
+			`ALTER TABLE retentions ADD COLUMN repository_key CHAR(36) AS (COALESCE(repository_id, '')) VIRTUAL`,
+			`ALTER TABLE retentions ADD UNIQUE KEY retentions_namespace_id_key (namespace_id, repository_key)`,
+		},
+	},
+}
@@ -1,25 +1,153 @@
 package mysql
 
 import (
+	"context"
+	"database/sql"
+	"net"
 	"net/url"
+	"strings"
+	"time"
 
+	driver "github.com/go-sql-driver/mysql"
+	"github.com/pkg/errors"
 	"github.com/umschlag/umschlag-api/pkg/store"
+	"github.com/umschlag/umschlag-api/pkg/store/sqlstore"
 )
 
-type mysql struct {
-	dsn *url.URL
+const (
+	// migrationLock is the name of the named migration lock.
+	migrationLock = "umschlag_migrations"
+)
+
+var (
+	// ErrLockTimeout is returned if the migration lock can't be acquired.
+	ErrLockTimeout = errors.New("timeout acquiring migration lock")
+)
+
+type dialect struct{}
+
+// Name returns the name of the database driver.
+func (d *dialect) Name() string {
+	return "mysql"
+}
+
+// Placeholder returns the question mark bind variable.
+func (d *dialect) Placeholder(n int) string {
+	return "?"
 }
 
-// Close simply closes the MySQL connection.
-func (s *mysql) Close() error {
+// Fold keeps the expression, the columns use a case-insensitive collation.
+func (d *dialect) Fold(expr string) string {
+	return expr
+}
+
+// OnConflict returns the on duplicate key clause to update the columns.
+func (d *dialect) OnConflict(keys, columns []string) string {
+	updates := make([]string, 0, len(columns))
+
+	for _, column := range columns {
+		updates = append(updates, column+" = VALUES("+column+")")
+	}
+
+	return "ON DUPLICATE KEY UPDATE " + strings.Join(updates, ", ")
+}
+
+// Constraint returns the name of a violated unique constraint, the key name
+// gets extracted from the error message as MySQL does not expose it. Only
+// MySQL 8.0 prefixes the key with the table, MariaDB and MySQL 5.7 report
+// a violated primary key as PRIMARY without any table.
+func (d *dialect) Constraint(err error) (string, bool) {
+	mysqlErr, ok := err.(*driver.MySQLError)
+
+	if !ok || mysqlErr.Number != 1062 {
+		return "", false
+	}
+
+	msg := strings.TrimSuffix(mysqlErr.Message, "'")
+	key := msg[strings.LastIndex(msg, "'")+1:]
+
+	if idx := strings.Index(key, "."); idx >= 0 {
+		table := key[:idx]
+		key = key[idx+1:]
+
+		if key == sqlstore.PrimaryKey {
+			key = table + "_pkey"
+		}
+	}
+
+	return key, true
+}
+
+// Lock acquires a named lock used during migrations.
+func (d *dialect) Lock(ctx context.Context, conn *sql.Conn) error {
+	var result sql.NullInt64
+
+	if err := conn.QueryRowContext(ctx, "SELECT GET_LOCK(?, 60)", migrationLock).Scan(&result); err != nil {
+		return err
+	}
+
+	if !result.Valid || result.Int64 != 1 {
+		return ErrLockTimeout
+	}
+
 	return nil
 }
 
+// Unlock releases the named lock acquired by Lock.
+func (d *dialect) Unlock(ctx context.Context, conn *sql.Conn) error {
+	_, err := conn.ExecContext(ctx, "SELECT RELEASE_LOCK(?)", migrationLock)
+	return err
+}
+
+// config converts the dsn into the configuration of the driver.
+func config(dsn *url.URL) *driver.Config {
+	cfg := driver.NewConfig()
+	cfg.Net = "tcp"
+	cfg.Addr = dsn.Host
+	cfg.DBName = strings.TrimPrefix(dsn.Path, "/")
+	cfg.Params = make(map[string]string)
+
+	if _, _, err := net.SplitHostPort(cfg.Addr); err != nil {
+		cfg.Addr = net.JoinHostPort(cfg.Addr, "3306")
+	}
+
+	if dsn.User != nil {
+		cfg.User = dsn.User.Username()
+		cfg.Passwd, _ = dsn.User.Password()
+	}
+
+	for key, vals := range dsn.Query() {
+		cfg.Params[key] = vals[0]
+	}
+
+	if _, ok := cfg.Params["charset"]; !ok {
+		cfg.Params["charset"] = "utf8mb4"
+	}
+
+	cfg.Collation = "utf8mb4_unicode_ci"
+	cfg.ParseTime = true
+	cfg.ClientFoundRows = true
+	cfg.Loc = time.UTC
+
+	return cfg
+}
+
 // New initializes a new MySQL connection.
 func New(dsn *url.URL) (store.Store, error) {
-	return &mysql{
-		dsn: dsn,
-	}, nil
+	db, err := sql.Open("mysql", config(dsn).FormatDSN())
+
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open database")
+	}
+
+	s, err := sqlstore.New(db, &dialect{}, migrations)
+
+	if err != nil {
+		db.Close()
+		return nil, errors.Wrap(err, "failed to prepare database")
+	}
+
+	return s, nil
 }
 
 // Must simply calls New and panics on an error.
@@ -0,0 +1,160 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"net/url"
+	"os"
+	"testing"
+
+	driver "github.com/go-sql-driver/mysql"
+	"github.com/umschlag/umschlag-api/pkg/store"
+	"github.com/umschlag/umschlag-api/pkg/store/sqlstore"
+	"github.com/umschlag/umschlag-api/pkg/store/storetest"
+)
+
+// TestStore runs the conformance suite against the database defined by
+// UMSCHLAG_API_TEST_MYSQL_DSN, all tables get dropped before every case,
+// so never point it to a database with valuable content.
+func TestStore(t *testing.T) {
+	raw := os.Getenv("UMSCHLAG_API_TEST_MYSQL_DSN")
+
+	if raw == "" {
+		t.Skip("UMSCHLAG_API_TEST_MYSQL_DSN is not defined")
+	}
+
+	dsn, err := url.Parse(raw)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	storetest.Run(t, func(t *testing.T) store.Store {
+		if err := reset(dsn); err != nil {
+			t.Fatal(err)
+		}
+
+		s, err := New(dsn)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return s
+	})
+}
+
+// reset drops all tables of the test database on a single connection, as
+// the foreign key checks are only disabled for the session.
+func reset(dsn *url.URL) error {
+	ctx := context.Background()
+	db, err := sql.Open("mysql", config(dsn).FormatDSN())
+
+	if err != nil {
+		return err
+	}
+
+	defer db.Close()
+
+	conn, err := db.Conn(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	defer conn.Close()
+
+	rows, err := conn.QueryContext(ctx, "SELECT table_name FROM information_schema.tables WHERE table_schema = DATABASE()")
+
+	if err != nil {
+		return err
+	}
+
+	tables := make([]string, 0)
+
+	for rows.Next() {
+		var table string
+
+		if err := rows.Scan(&table); err != nil {
+			rows.Close()
+			return err
+		}
+
+		tables = append(tables, table)
+	}
+
+	rows.Close()
+
+	if _, err := conn.ExecContext(ctx, "SET FOREIGN_KEY_CHECKS = 0"); err != nil {
+		return err
+	}
+
+	for _, table := range tables {
+		if _, err := conn.ExecContext(ctx, "DROP TABLE `"+table+"`"); err != nil {
+			return err
+		}
+	}
+
+	_, err = conn.ExecContext(ctx, "SET FOREIGN_KEY_CHECKS = 1")
+	return err
+}
+
+func TestConstraint(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		key  string
+		ok   bool
+	}{
+		{
+			name: "primary with table",
+			err:  &driver.MySQLError{Number: 1062, Message: "Duplicate entry 'a-b' for key 'team_users.PRIMARY'"},
+			key:  "team_users_pkey",
+			ok:   true,
+		},
+		{
+			name: "primary without table",
+			err:  &driver.MySQLError{Number: 1062, Message: "Duplicate entry 'a-b' for key 'PRIMARY'"},
+			key:  sqlstore.PrimaryKey,
+			ok:   true,
+		},
+		{
+			name: "unique with table",
+			err:  &driver.MySQLError{Number: 1062, Message: "Duplicate entry 'jane' for key 'users.users_username_key'"},
+			key:  "users_username_key",
+			ok:   true,
+		},
+		{
+			name: "unique without table",
+			err:  &driver.MySQLError{Number: 1062, Message: "Duplicate entry 'jane' for key 'users_username_key'"},
+			key:  "users_username_key",
+			ok:   true,
+		},
+		{
+			name: "entry with quotes and dots",
+			err:  &driver.MySQLError{Number: 1062, Message: "Duplicate entry 'it's.v1' for key 'immutables_pattern_key'"},
+			key:  "immutables_pattern_key",
+			ok:   true,
+		},
+		{
+			name: "other mysql error",
+			err:  &driver.MySQLError{Number: 1452, Message: "Cannot add or update a child row"},
+		},
+		{
+			name: "other error",
+			err:  sql.ErrNoRows,
+		},
+	}
+
+	d := &dialect{}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, ok := d.Constraint(tt.err)
+
+			if key != tt.key || ok != tt.ok {
+				t.Errorf("expected %q, %v, got %q, %v", tt.key, tt.ok, key, ok)
+			}
+		})
+	}
+}
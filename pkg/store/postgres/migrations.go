@@ -0,0 +1,277 @@
+package postgres
+
+import (
+	"github.com/umschlag/umschlag-api/pkg/store/sqlstore"
+)
+
+var migrations = []sqlstore.Migration{
+	{
+		Version: 1,
+		Name:    "create_users_table",
+		Statements: []string{
+			`CREATE TABLE users (
+				id UUID NOT NULL,
+				slug VARCHAR(255) NOT NULL,
+				username VARCHAR(255) NOT NULL,
+				password VARCHAR(255) NOT NULL DEFAULT '',
+				email VARCHAR(255) NOT NULL,
+				admin BOOLEAN NOT NULL DEFAULT FALSE,
+				active BOOLEAN NOT NULL DEFAULT FALSE,
+				created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+				updated_at TIMESTAMP WITH TIME ZONE NOT NULL,
+				CONSTRAINT users_pkey PRIMARY KEY (id)
+			)`,
+			`CREATE UNIQUE INDEX users_slug_key ON users (slug)`,
+			`CREATE UNIQUE INDEX users_username_key ON users (LOWER(username))`,
+			`CREATE UNIQUE INDEX users_email_key ON users (LOWER(email))`,
+		},
+	},
+	{
+		Version: 2,
+		Name:    "create_teams_table",
+		Statements: []string{
+			`CREATE TABLE teams (
+				id UUID NOT NULL,
+				slug VARCHAR(255) NOT NULL,
+				name VARCHAR(255) NOT NULL,
+				created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+				updated_at TIMESTAMP WITH TIME ZONE NOT NULL,
+				CONSTRAINT teams_pkey PRIMARY KEY (id)
+			)`,
+			`CREATE UNIQUE INDEX teams_slug_key ON teams (slug)`,
+		},
+	},
+	{
+		Version: 3,
+		Name:    "create_team_users_table",
+		Statements: []string{
+			`CREATE TABLE team_users (
+				team_id UUID NOT NULL,
+				user_id UUID NOT NULL,
+				perm VARCHAR(32) NOT NULL,
+				CONSTRAINT team_users_pkey PRIMARY KEY (team_id, user_id),
+				CONSTRAINT team_users_team_id_fkey FOREIGN KEY (team_id) REFERENCES teams (id) ON DELETE CASCADE,
+				CONSTRAINT team_users_user_id_fkey FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+			)`,
+			`CREATE INDEX team_users_user_id_idx ON team_users (user_id)`,
+		},
+	},
+	{
+		Version: 4,
+		Name:    "create_access_tokens_table",
+		Statements: []string{
+			`CREATE TABLE access_tokens (
+				id UUID NOT NULL,
+				user_id UUID NOT NULL,
+				name VARCHAR(255) NOT NULL,
+				hash CHAR(64) NOT NULL,
+				scopes VARCHAR(255) NOT NULL DEFAULT '',
+				expires_at TIMESTAMP WITH TIME ZONE NULL,
+				last_used_at TIMESTAMP WITH TIME ZONE NULL,
+				created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+				CONSTRAINT access_tokens_pkey PRIMARY KEY (id),
+				CONSTRAINT access_tokens_user_id_fkey FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+			)`,
+			`CREATE UNIQUE INDEX access_tokens_hash_key ON access_tokens (hash)`,
+			`CREATE UNIQUE INDEX access_tokens_name_key ON access_tokens (user_id, name)`,
+		},
+	},
+	{
+		Version: 5,
+		Name:    "create_revocations_tables",
+		Statements: []string{
+			`CREATE TABLE token_revocations (
+				token_id VARCHAR(64) NOT NULL,
+				user_id UUID NOT NULL,
+				expires_at TIMESTAMP WITH TIME ZONE NULL,
+				created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+				CONSTRAINT token_revocations_pkey PRIMARY KEY (token_id),
+				CONSTRAINT token_revocations_user_id_fkey FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+			)`,
+			`CREATE INDEX token_revocations_expires_at_idx ON token_revocations (expires_at)`,
+			`CREATE TABLE user_revocations (
+				user_id UUID NOT NULL,
+				revoked_before TIMESTAMP WITH TIME ZONE NOT NULL,
+				CONSTRAINT user_revocations_pkey PRIMARY KEY (user_id),
+				CONSTRAINT user_revocations_user_id_fkey FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+			)`,
+		},
+	},
+	{
+		Version: 6,
+		Name:    "create_namespaces_table",
+		Statements: []string{
+			`CREATE TABLE namespaces (
+				id UUID NOT NULL,
+				name VARCHAR(255) NOT NULL,
+				description TEXT NOT NULL DEFAULT '',
+				user_id UUID NULL,
+				team_id UUID NULL,
+				created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+				updated_at TIMESTAMP WITH TIME ZONE NOT NULL,
+				CONSTRAINT namespaces_pkey PRIMARY KEY (id),
+				CONSTRAINT namespaces_user_id_fkey FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+				CONSTRAINT namespaces_team_id_fkey FOREIGN KEY (team_id) REFERENCES teams (id) ON DELETE CASCADE
+			)`,
+			`CREATE UNIQUE INDEX namespaces_name_key ON namespaces (name)`,
+			`CREATE INDEX namespaces_user_id_idx ON namespaces (user_id)`,
+			`CREATE INDEX namespaces_team_id_idx ON namespaces (team_id)`,
+		},
+	},
+	{
+		Version: 7,
+		Name:    "create_repositories_table",
+		Statements: []string{
+			`CREATE TABLE repositories (
+				id UUID NOT NULL,
+				namespace_id UUID NOT NULL,
+				name VARCHAR(255) NOT NULL,
+				description TEXT NOT NULL DEFAULT '',
+				visibility VARCHAR(32) NOT NULL,
+				created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+				updated_at TIMESTAMP WITH TIME ZONE NOT NULL,
+				CONSTRAINT repositories_pkey PRIMARY KEY (id),
+				CONSTRAINT repositories_namespace_id_fkey FOREIGN KEY (namespace_id) REFERENCES namespaces (id) ON DELETE CASCADE
+			)`,
+			`CREATE UNIQUE INDEX repositories_name_key ON repositories (namespace_id, name)`,
+		},
+	},
+	{
+		Version: 8,
+		Name:    "create_repository_teams_table",
+		Statements: []string{
+			`CREATE TABLE repository_teams (
+				repository_id UUID NOT NULL,
+				team_id UUID NOT NULL,
+				perm VARCHAR(32) NOT NULL,
+				CONSTRAINT repository_teams_pkey PRIMARY KEY (repository_id, team_id),
+				CONSTRAINT repository_teams_repository_id_fkey FOREIGN KEY (repository_id) REFERENCES repositories (id) ON DELETE CASCADE,
+				CONSTRAINT repository_teams_team_id_fkey FOREIGN KEY (team_id) REFERENCES teams (id) ON DELETE CASCADE
+			)`,
+			`CREATE INDEX repository_teams_team_id_idx ON repository_teams (team_id)`,
+		},
+	},
+	{
+		Version: 9,
+		Name:    "create_tags_manifests_tables",
+		Statements: []string{
+			`CREATE TABLE manifests (
+				repository_id UUID NOT NULL,
+				digest VARCHAR(255) NOT NULL,
+				media_type VARCHAR(255) NOT NULL DEFAULT '',
+				size BIGINT NOT NULL DEFAULT 0,
+				blobs TEXT NOT NULL,
+				created_at TIMESTAMP WITH TIME ZONE NULL,
+				synced_at TIMESTAMP WITH TIME ZONE NOT NULL,
+				CONSTRAINT manifests_pkey PRIMARY KEY (repository_id, digest),
+				CONSTRAINT manifests_repository_id_fkey FOREIGN KEY (repository_id) REFERENCES repositories (id) ON DELETE CASCADE
+			)`,
+			`CREATE TABLE tags (
+				repository_id UUID NOT NULL,
+				name VARCHAR(128) NOT NULL,
+				digest VARCHAR(255) NOT NULL,
+				updated_at TIMESTAMP WITH TIME ZONE NOT NULL,
+				CONSTRAINT tags_pkey PRIMARY KEY (repository_id, name),
+				CONSTRAINT tags_repository_id_fkey FOREIGN KEY (repository_id) REFERENCES repositories (id) ON DELETE CASCADE
+			)`,
+			`CREATE INDEX tags_digest_idx ON tags (repository_id, digest)`,
+		},
+	},
+	{
+		Version: 10,
+		Name:    "add_tags_pulls_columns",
+		Statements: []string{
+			`ALTER TABLE tags ADD COLUMN pulls BIGINT NOT NULL DEFAULT 0`,
+			`ALTER TABLE tags ADD COLUMN pulled_at TIMESTAMP WITH TIME ZONE NULL`,
+		},
+	},
+	{
+		Version: 11,
+		Name:    "create_retentions_reports_tables",
+		Statements: []string{
+			`CREATE TABLE retentions (
+				id UUID NOT NULL,
+				namespace_id UUID NOT NULL,
+				repository_id UUID NULL,
+				keep_last INTEGER NOT NULL DEFAULT 0,
+				keep_pattern VARCHAR(255) NOT NULL DEFAULT '',
+				older_than INTEGER NOT NULL DEFAULT 0,
+				created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+				updated_at TIMESTAMP WITH TIME ZONE NOT NULL,
+				CONSTRAINT retentions_pkey PRIMARY KEY (id),
+				CONSTRAINT retentions_namespace_id_fkey FOREIGN KEY (namespace_id) REFERENCES namespaces (id) ON DELETE CASCADE,
+				CONSTRAINT retentions_repository_id_fkey FOREIGN KEY (repository_id) REFERENCES repositories (id) ON DELETE CASCADE
+			)`,
+			`CREATE UNIQUE INDEX retentions_namespace_id_key ON retentions (namespace_id) WHERE repository_id IS NULL`,
+			`CREATE UNIQUE INDEX retentions_repository_id_key ON retentions (repository_id)`,
+			`CREATE TABLE reports (
+				id UUID NOT NULL,
+				retention_id UUID NOT NULL,
+				started_at TIMESTAMP WITH TIME ZONE NOT NULL,
+				finished_at TIMESTAMP WITH TIME ZONE NOT NULL,
+				entries TEXT NOT NULL,
+				CONSTRAINT reports_pkey PRIMARY KEY (id),
+				CONSTRAINT reports_retention_id_fkey FOREIGN KEY (retention_id) REFERENCES retentions (id) ON DELETE CASCADE
+			)`,
+			`CREATE INDEX reports_retention_id_idx ON reports (retention_id, started_at)`,
+		},
+	},
+	{
+		Version: 12,
+		Name:    "add_tags_overwritten_at_column",
+		Statements: []string{
+			`ALTER TABLE tags ADD COLUMN overwritten_at TIMESTAMP WITH TIME ZONE NULL`,
+		},
+	},
+	{
+		Version: 13,
+		Name:    "create_immutables_table",
+		Statements: []string{
+			`CREATE TABLE immutables (
+				id UUID NOT NULL,
+				namespace_id UUID NOT NULL,
+				pattern VARCHAR(255) NOT NULL,
+				exclude BOOLEAN NOT NULL DEFAULT FALSE,
+				created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+				CONSTRAINT immutables_pkey PRIMARY KEY (id),
+				CONSTRAINT immutables_namespace_id_fkey FOREIGN KEY (namespace_id) REFERENCES namespaces (id) ON DELETE CASCADE
+			)`,
+			`CREATE UNIQUE INDEX immutables_pattern_key ON immutables (namespace_id, pattern)`,
+		},
+	},
+	{
+		Version: 14,
+		Name:    "create_quotas_table",
+		Statements: []string{
+			`CREATE TABLE quotas (
+				namespace_id UUID NULL,
+				team_id UUID NULL,
+				hard BIGINT NOT NULL DEFAULT 0,
+				soft BIGINT NOT NULL DEFAULT 0,
+				created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+				updated_at TIMESTAMP WITH TIME ZONE NOT NULL,
+				CONSTRAINT quotas_namespace_id_fkey FOREIGN KEY (namespace_id) REFERENCES namespaces (id) ON DELETE CASCADE,
+				CONSTRAINT quotas_team_id_fkey FOREIGN KEY (team_id) REFERENCES teams (id) ON DELETE CASCADE
+			)`,
+			`CREATE UNIQUE INDEX quotas_namespace_id_key ON quotas (namespace_id)`,
+			`CREATE UNIQUE INDEX quotas_team_id_key ON quotas (team_id)`,
+		},
+	},
+	{
+		Version: 15,
+		Name:    "add_avatar_columns",
+		Statements: []string{
+			`ALTER TABLE users ADD COLUMN avatar VARCHAR(64) NOT NULL DEFAULT ''`,
+			`ALTER TABLE teams ADD COLUMN avatar VARCHAR(64) NOT NULL DEFAULT ''`,
+		},
+	},
+	{
+		Version: 16,
+		Name:    "add_tags_created_at_column",
+		Statements: []string{
+			`ALTER TABLE tags ADD COLUMN created_at TIMESTAMP WITH TIME ZONE NULL`,
+			`UPDATE tags SET created_at = updated_at`,
+			`ALTER TABLE tags ALTER COLUMN created_at SET NOT NULL`,
+		},
+	},
+}
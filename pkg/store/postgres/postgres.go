@@ -1,25 +1,88 @@
 package postgres
 
 import (
+	"context"
+	"database/sql"
 	"net/url"
+	"strconv"
+	"strings"
 
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
 	"github.com/umschlag/umschlag-api/pkg/store"
+	"github.com/umschlag/umschlag-api/pkg/store/sqlstore"
 )
 
-type postgres struct {
-	dsn *url.URL
+const (
+	// migrationLock is a random key for the advisory migration lock.
+	migrationLock = 7301937153
+)
+
+type dialect struct{}
+
+// Name returns the name of the database driver.
+func (d *dialect) Name() string {
+	return "postgres"
+}
+
+// Placeholder returns the numbered bind variable.
+func (d *dialect) Placeholder(n int) string {
+	return "$" + strconv.Itoa(n)
+}
+
+// Fold lowers the expression, indexes are defined on the lowered values.
+func (d *dialect) Fold(expr string) string {
+	return "LOWER(" + expr + ")"
+}
+
+// OnConflict returns the on conflict clause to update the columns.
+func (d *dialect) OnConflict(keys, columns []string) string {
+	updates := make([]string, 0, len(columns))
+
+	for _, column := range columns {
+		updates = append(updates, column+" = EXCLUDED."+column)
+	}
+
+	return "ON CONFLICT (" + strings.Join(keys, ", ") + ") DO UPDATE SET " + strings.Join(updates, ", ")
+}
+
+// Constraint returns the name of a violated unique constraint.
+func (d *dialect) Constraint(err error) (string, bool) {
+	if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+		return pqErr.Constraint, true
+	}
+
+	return "", false
 }
 
-// Close simply closes the PostgreSQL connection.
-func (s *postgres) Close() error {
-	return nil
+// Lock acquires an advisory lock used during migrations.
+func (d *dialect) Lock(ctx context.Context, conn *sql.Conn) error {
+	_, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", migrationLock)
+	return err
+}
+
+// Unlock releases the advisory lock acquired by Lock.
+func (d *dialect) Unlock(ctx context.Context, conn *sql.Conn) error {
+	_, err := conn.ExecContext(ctx, "SELECT pg_advisory_unlock($1)", migrationLock)
+	return err
 }
 
 // New initializes a new PostgreSQL connection.
 func New(dsn *url.URL) (store.Store, error) {
-	return &postgres{
-		dsn: dsn,
-	}, nil
+	db, err := sql.Open("postgres", dsn.String())
+
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open database")
+	}
+
+	s, err := sqlstore.New(db, &dialect{}, migrations)
+
+	if err != nil {
+		db.Close()
+		return nil, errors.Wrap(err, "failed to prepare database")
+	}
+
+	return s, nil
 }
 
 // Must simply calls New and panics on an error.
@@ -0,0 +1,50 @@
+package postgres
+
+import (
+	"database/sql"
+	"net/url"
+	"os"
+	"testing"
+
+	"github.com/umschlag/umschlag-api/pkg/store"
+	"github.com/umschlag/umschlag-api/pkg/store/storetest"
+)
+
+// TestStore runs the conformance suite against the database defined by
+// UMSCHLAG_API_TEST_POSTGRES_DSN, the public schema gets dropped before
+// every case, so never point it to a database with valuable content.
+func TestStore(t *testing.T) {
+	raw := os.Getenv("UMSCHLAG_API_TEST_POSTGRES_DSN")
+
+	if raw == "" {
+		t.Skip("UMSCHLAG_API_TEST_POSTGRES_DSN is not defined")
+	}
+
+	dsn, err := url.Parse(raw)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	storetest.Run(t, func(t *testing.T) store.Store {
+		db, err := sql.Open("postgres", dsn.String())
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		defer db.Close()
+
+		if _, err := db.Exec("DROP SCHEMA public CASCADE; CREATE SCHEMA public"); err != nil {
+			t.Fatal(err)
+		}
+
+		s, err := New(dsn)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return s
+	})
+}
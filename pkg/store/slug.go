@@ -0,0 +1,44 @@
+package store
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Slugify converts a name into a lowercase and URL safe slug.
+func Slugify(val string) string {
+	stripped, _, err := transform.String(
+		transform.Chain(
+			norm.NFKD,
+			runes.Remove(runes.In(unicode.Mn)),
+			norm.NFC,
+		),
+		val,
+	)
+
+	if err != nil {
+		stripped = val
+	}
+
+	var (
+		b    strings.Builder
+		dash bool
+	)
+
+	for _, r := range strings.ToLower(stripped) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			b.WriteRune(r)
+			dash = false
+		case b.Len() > 0 && !dash:
+			b.WriteRune('-')
+			dash = true
+		}
+	}
+
+	return strings.TrimSuffix(b.String(), "-")
+}
@@ -0,0 +1,36 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+)
+
+// PrimaryKey is the constraint name returned by dialects which report a
+// violated primary key without the name of the table.
+const PrimaryKey = "PRIMARY"
+
+// Dialect provides the database specific behavior for the SQL store.
+type Dialect interface {
+	// Name returns the name of the database driver.
+	Name() string
+
+	// Placeholder returns the bind variable for the n-th argument.
+	Placeholder(int) string
+
+	// Fold wraps an expression to compare it case-insensitively.
+	Fold(string) string
+
+	// OnConflict returns the clause for an insert statement to update the
+	// columns if a record with the same keys already exists.
+	OnConflict([]string, []string) string
+
+	// Constraint returns the name of a violated unique constraint, or
+	// PrimaryKey if only the violation of a primary key is known.
+	Constraint(error) (string, bool)
+
+	// Lock acquires an exclusive lock used during migrations.
+	Lock(context.Context, *sql.Conn) error
+
+	// Unlock releases the lock acquired by Lock.
+	Unlock(context.Context, *sql.Conn) error
+}
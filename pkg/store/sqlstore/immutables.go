@@ -0,0 +1,125 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/umschlag/umschlag-api/pkg/model"
+	"github.com/umschlag/umschlag-api/pkg/store"
+)
+
+var (
+	immutableColumns = []string{
+		"id",
+		"namespace_id",
+		"pattern",
+		"exclude",
+		"created_at",
+	}
+)
+
+type immutables struct {
+	*Store
+}
+
+// List retrieves all immutable tag rules of a namespace.
+func (i *immutables) List(ctx context.Context, namespaceID string) ([]*model.Immutable, error) {
+	records := make([]*model.Immutable, 0)
+
+	if !isUUID(namespaceID) {
+		return records, nil
+	}
+
+	rows, err := i.db.QueryContext(
+		ctx,
+		i.rebind("SELECT "+columns("", immutableColumns)+" FROM immutables WHERE namespace_id = ? ORDER BY created_at"),
+		namespaceID,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		record, err := scanImmutable(rows)
+
+		if err != nil {
+			return nil, err
+		}
+
+		records = append(records, record)
+	}
+
+	return records, rows.Err()
+}
+
+// Create adds a new immutable tag rule to a namespace.
+func (i *immutables) Create(ctx context.Context, immutable *model.Immutable) (*model.Immutable, error) {
+	record := *immutable
+	record.ID = uuid.New().String()
+	record.CreatedAt = time.Now().UTC()
+
+	if err := i.exists(ctx, "namespaces", record.NamespaceID); err != nil {
+		return nil, err
+	}
+
+	if _, err := i.db.ExecContext(
+		ctx,
+		i.rebind("INSERT INTO immutables ("+columns("", immutableColumns)+") VALUES ("+binds(immutableColumns)+")"),
+		record.ID,
+		record.NamespaceID,
+		record.Pattern,
+		record.Exclude,
+		record.CreatedAt,
+	); err != nil {
+		return nil, i.translate(err)
+	}
+
+	return &record, nil
+}
+
+// Delete removes an immutable tag rule of a namespace.
+func (i *immutables) Delete(ctx context.Context, namespaceID, id string) error {
+	if !isUUID(namespaceID) || !isUUID(id) {
+		return store.ErrNotFound
+	}
+
+	res, err := i.db.ExecContext(
+		ctx,
+		i.rebind("DELETE FROM immutables WHERE namespace_id = ? AND id = ?"),
+		namespaceID,
+		id,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	return affected(res)
+}
+
+func scanImmutable(row scanner) (*model.Immutable, error) {
+	record := &model.Immutable{}
+
+	if err := row.Scan(
+		&record.ID,
+		&record.NamespaceID,
+		&record.Pattern,
+		&record.Exclude,
+		&record.CreatedAt,
+	); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, store.ErrNotFound
+		}
+
+		return nil, err
+	}
+
+	record.CreatedAt = record.CreatedAt.UTC()
+
+	return record, nil
+}
@@ -0,0 +1,190 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/umschlag/umschlag-api/pkg/model"
+	"github.com/umschlag/umschlag-api/pkg/store"
+)
+
+var (
+	manifestColumns = []string{
+		"repository_id",
+		"digest",
+		"media_type",
+		"size",
+		"blobs",
+		"created_at",
+		"synced_at",
+	}
+)
+
+type manifests struct {
+	*Store
+}
+
+// List retrieves all manifests of a repository.
+func (m *manifests) List(ctx context.Context, repositoryID string) ([]*model.Manifest, error) {
+	records := make([]*model.Manifest, 0)
+
+	if !isUUID(repositoryID) {
+		return records, nil
+	}
+
+	rows, err := m.db.QueryContext(
+		ctx,
+		m.rebind("SELECT "+columns("", manifestColumns)+" FROM manifests WHERE repository_id = ? ORDER BY digest"),
+		repositoryID,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		record, err := scanManifest(rows)
+
+		if err != nil {
+			return nil, err
+		}
+
+		records = append(records, record)
+	}
+
+	return records, rows.Err()
+}
+
+// Show retrieves a manifest of a repository by digest.
+func (m *manifests) Show(ctx context.Context, repositoryID, digest string) (*model.Manifest, error) {
+	if !isUUID(repositoryID) {
+		return nil, store.ErrNotFound
+	}
+
+	return scanManifest(m.db.QueryRowContext(
+		ctx,
+		m.rebind("SELECT "+columns("", manifestColumns)+" FROM manifests WHERE repository_id = ? AND digest = ?"),
+		repositoryID,
+		digest,
+	))
+}
+
+// Save creates or updates a manifest of a repository.
+func (m *manifests) Save(ctx context.Context, manifest *model.Manifest) (*model.Manifest, error) {
+	record := *manifest
+	record.SyncedAt = time.Now().UTC()
+
+	if record.Blobs == nil {
+		record.Blobs = make([]*model.Blob, 0)
+	}
+
+	if err := m.exists(ctx, "repositories", record.RepositoryID); err != nil {
+		return nil, err
+	}
+
+	blobs, err := json.Marshal(record.Blobs)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := m.db.ExecContext(
+		ctx,
+		m.upsert("manifests", []string{"repository_id", "digest"}, manifestColumns),
+		record.RepositoryID,
+		record.Digest,
+		record.MediaType,
+		record.Size,
+		string(blobs),
+		record.CreatedAt,
+		record.SyncedAt,
+	); err != nil {
+		return nil, m.translate(err)
+	}
+
+	return &record, nil
+}
+
+// Delete removes a manifest of a repository including the tags pointing
+// to it.
+func (m *manifests) Delete(ctx context.Context, repositoryID, digest string) error {
+	if !isUUID(repositoryID) {
+		return store.ErrNotFound
+	}
+
+	tx, err := m.db.BeginTx(ctx, nil)
+
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(
+		ctx,
+		m.rebind("DELETE FROM tags WHERE repository_id = ? AND digest = ?"),
+		repositoryID,
+		digest,
+	); err != nil {
+		return err
+	}
+
+	res, err := tx.ExecContext(
+		ctx,
+		m.rebind("DELETE FROM manifests WHERE repository_id = ? AND digest = ?"),
+		repositoryID,
+		digest,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	if err := affected(res); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func scanManifest(row scanner) (*model.Manifest, error) {
+	var (
+		blobs  string
+		record = &model.Manifest{}
+	)
+
+	if err := row.Scan(
+		&record.RepositoryID,
+		&record.Digest,
+		&record.MediaType,
+		&record.Size,
+		&blobs,
+		&record.CreatedAt,
+		&record.SyncedAt,
+	); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, store.ErrNotFound
+		}
+
+		return nil, err
+	}
+
+	record.Blobs = make([]*model.Blob, 0)
+
+	if err := json.Unmarshal([]byte(blobs), &record.Blobs); err != nil {
+		return nil, err
+	}
+
+	record.SyncedAt = record.SyncedAt.UTC()
+
+	if record.CreatedAt != nil {
+		created := record.CreatedAt.UTC()
+		record.CreatedAt = &created
+	}
+
+	return record, nil
+}
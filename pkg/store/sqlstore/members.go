@@ -0,0 +1,196 @@
+package sqlstore
+
+import (
+	"context"
+
+	"github.com/umschlag/umschlag-api/pkg/model"
+	"github.com/umschlag/umschlag-api/pkg/store"
+)
+
+var (
+	memberColumns = []string{
+		"team_id",
+		"user_id",
+		"perm",
+	}
+)
+
+type members struct {
+	*Store
+}
+
+// ListByTeam retrieves all memberships of a team including the users.
+func (m *members) ListByTeam(ctx context.Context, teamID string) ([]*model.TeamUser, error) {
+	return m.list(
+		ctx,
+		"WHERE team_users.team_id = ? ORDER BY users.username",
+		teamID,
+	)
+}
+
+// ListByUser retrieves all memberships of a user including the teams.
+func (m *members) ListByUser(ctx context.Context, userID string) ([]*model.TeamUser, error) {
+	return m.list(
+		ctx,
+		"WHERE team_users.user_id = ? ORDER BY teams.name",
+		userID,
+	)
+}
+
+// Assign creates a new membership of a user within a team.
+func (m *members) Assign(ctx context.Context, teamID, userID, perm string) error {
+	if err := m.exists(ctx, teamID, userID); err != nil {
+		return err
+	}
+
+	if _, err := m.db.ExecContext(
+		ctx,
+		m.rebind("INSERT INTO team_users (team_id, user_id, perm) VALUES (?, ?, ?)"),
+		teamID,
+		userID,
+		perm,
+	); err != nil {
+		return m.translateTable("team_users", err)
+	}
+
+	return nil
+}
+
+// Permit updates the permission of an existing membership.
+func (m *members) Permit(ctx context.Context, teamID, userID, perm string) error {
+	res, err := m.db.ExecContext(
+		ctx,
+		m.rebind("UPDATE team_users SET perm = ? WHERE team_id = ? AND user_id = ?"),
+		perm,
+		teamID,
+		userID,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	if err := affected(res); err == store.ErrNotFound {
+		return store.ErrNotAssigned
+	} else if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Unassign removes the membership of a user within a team.
+func (m *members) Unassign(ctx context.Context, teamID, userID string) error {
+	res, err := m.db.ExecContext(
+		ctx,
+		m.rebind("DELETE FROM team_users WHERE team_id = ? AND user_id = ?"),
+		teamID,
+		userID,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	if err := affected(res); err == store.ErrNotFound {
+		return store.ErrNotAssigned
+	} else if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// exists checks that both sides of a membership are present and that
+// the membership does not exist yet.
+func (m *members) exists(ctx context.Context, teamID, userID string) error {
+	if !isUUID(teamID) || !isUUID(userID) {
+		return store.ErrNotFound
+	}
+
+	var count int
+
+	if err := m.db.QueryRowContext(
+		ctx,
+		m.rebind("SELECT (SELECT COUNT(*) FROM teams WHERE id = ?) + (SELECT COUNT(*) FROM users WHERE id = ?)"),
+		teamID,
+		userID,
+	).Scan(&count); err != nil {
+		return err
+	}
+
+	if count != 2 {
+		return store.ErrNotFound
+	}
+
+	if err := m.db.QueryRowContext(
+		ctx,
+		m.rebind("SELECT COUNT(*) FROM team_users WHERE team_id = ? AND user_id = ?"),
+		teamID,
+		userID,
+	).Scan(&count); err != nil {
+		return err
+	}
+
+	if count != 0 {
+		return store.ErrAlreadyAssigned
+	}
+
+	return nil
+}
+
+func (m *members) list(ctx context.Context, where string, args ...interface{}) ([]*model.TeamUser, error) {
+	rows, err := m.db.QueryContext(
+		ctx,
+		m.rebind("SELECT "+columns("team_users", memberColumns)+", "+columns("teams", teamColumns)+", "+columns("users", userColumns)+" FROM team_users INNER JOIN teams ON teams.id = team_users.team_id INNER JOIN users ON users.id = team_users.user_id "+where),
+		args...,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	records := make([]*model.TeamUser, 0)
+
+	for rows.Next() {
+		record := &model.TeamUser{
+			Team: &model.Team{},
+			User: &model.User{},
+		}
+
+		if err := rows.Scan(
+			&record.TeamID,
+			&record.UserID,
+			&record.Perm,
+			&record.Team.ID,
+			&record.Team.Slug,
+			&record.Team.Name,
+			&record.Team.Avatar,
+			&record.Team.CreatedAt,
+			&record.Team.UpdatedAt,
+			&record.User.ID,
+			&record.User.Slug,
+			&record.User.Username,
+			&record.User.Password,
+			&record.User.Email,
+			&record.User.Admin,
+			&record.User.Active,
+			&record.User.Avatar,
+			&record.User.CreatedAt,
+			&record.User.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+
+		record.Team.CreatedAt = record.Team.CreatedAt.UTC()
+		record.Team.UpdatedAt = record.Team.UpdatedAt.UTC()
+		record.User.CreatedAt = record.User.CreatedAt.UTC()
+		record.User.UpdatedAt = record.User.UpdatedAt.UTC()
+
+		records = append(records, record)
+	}
+
+	return records, rows.Err()
+}
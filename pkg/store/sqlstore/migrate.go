@@ -0,0 +1,128 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+// Migration defines a single versioned schema change.
+type Migration struct {
+	Version    int
+	Name       string
+	Statements []string
+}
+
+// Migrate applies all pending migrations within a transaction per version
+// and records the applied versions within the schema_migrations table.
+// MySQL commits every DDL statement implicitly, so a failing version is not
+// rolled back there and the already applied statements have to be reverted
+// manually before the migration can be retried.
+func Migrate(ctx context.Context, db *sql.DB, dialect Dialect, migrations []Migration) error {
+	conn, err := db.Conn(ctx)
+
+	if err != nil {
+		return errors.Wrap(err, "failed to acquire connection")
+	}
+
+	defer conn.Close()
+
+	if err := dialect.Lock(ctx, conn); err != nil {
+		return errors.Wrap(err, "failed to acquire migration lock")
+	}
+
+	defer dialect.Unlock(ctx, conn)
+
+	if _, err := conn.ExecContext(
+		ctx,
+		"CREATE TABLE IF NOT EXISTS schema_migrations (version INTEGER NOT NULL PRIMARY KEY, name VARCHAR(255) NOT NULL, applied_at TIMESTAMP NOT NULL)",
+	); err != nil {
+		return errors.Wrap(err, "failed to create migrations table")
+	}
+
+	applied, err := appliedVersions(ctx, conn)
+
+	if err != nil {
+		return err
+	}
+
+	sorted := make([]Migration, len(migrations))
+	copy(sorted, migrations)
+
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Version < sorted[j].Version
+	})
+
+	for _, migration := range sorted {
+		if applied[migration.Version] {
+			continue
+		}
+
+		if err := apply(ctx, conn, dialect, migration); err != nil {
+			return errors.Wrapf(err, "failed to apply migration %d", migration.Version)
+		}
+
+		log.Info().
+			Int("version", migration.Version).
+			Str("name", migration.Name).
+			Msg("applied database migration")
+	}
+
+	return nil
+}
+
+func appliedVersions(ctx context.Context, conn *sql.Conn) (map[int]bool, error) {
+	rows, err := conn.QueryContext(ctx, "SELECT version FROM schema_migrations")
+
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list migrations")
+	}
+
+	defer rows.Close()
+
+	result := make(map[int]bool)
+
+	for rows.Next() {
+		var version int
+
+		if err := rows.Scan(&version); err != nil {
+			return nil, err
+		}
+
+		result[version] = true
+	}
+
+	return result, rows.Err()
+}
+
+func apply(ctx context.Context, conn *sql.Conn, dialect Dialect, migration Migration) error {
+	tx, err := conn.BeginTx(ctx, nil)
+
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	for _, statement := range migration.Statements {
+		if _, err := tx.ExecContext(ctx, statement); err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.ExecContext(
+		ctx,
+		rebind(dialect, "INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, ?)"),
+		migration.Version,
+		migration.Name,
+		time.Now().UTC(),
+	); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,229 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/umschlag/umschlag-api/pkg/model"
+	"github.com/umschlag/umschlag-api/pkg/store"
+)
+
+var (
+	namespaceColumns = []string{
+		"id",
+		"name",
+		"description",
+		"user_id",
+		"team_id",
+		"created_at",
+		"updated_at",
+	}
+)
+
+type namespaces struct {
+	*Store
+}
+
+// List retrieves all available namespaces.
+func (n *namespaces) List(ctx context.Context) ([]*model.Namespace, error) {
+	rows, err := n.db.QueryContext(
+		ctx,
+		"SELECT "+columns("namespaces", namespaceColumns)+" FROM namespaces ORDER BY name",
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	records := make([]*model.Namespace, 0)
+
+	for rows.Next() {
+		record, err := scanNamespace(rows)
+
+		if err != nil {
+			return nil, err
+		}
+
+		records = append(records, record)
+	}
+
+	return records, rows.Err()
+}
+
+// Show retrieves a namespace by ID or name.
+func (n *namespaces) Show(ctx context.Context, id string) (*model.Namespace, error) {
+	if isUUID(id) {
+		record, err := scanNamespace(n.db.QueryRowContext(
+			ctx,
+			n.rebind("SELECT "+columns("namespaces", namespaceColumns)+" FROM namespaces WHERE id = ?"),
+			id,
+		))
+
+		if err != store.ErrNotFound {
+			return record, err
+		}
+	}
+
+	return scanNamespace(n.db.QueryRowContext(
+		ctx,
+		n.rebind("SELECT "+columns("namespaces", namespaceColumns)+" FROM namespaces WHERE name = ?"),
+		id,
+	))
+}
+
+// Create stores a new namespace and fills the generated fields.
+func (n *namespaces) Create(ctx context.Context, namespace *model.Namespace) (*model.Namespace, error) {
+	record := *namespace
+	record.ID = uuid.New().String()
+	record.CreatedAt = time.Now().UTC()
+	record.UpdatedAt = record.CreatedAt
+
+	if err := n.owner(ctx, &record); err != nil {
+		return nil, err
+	}
+
+	if _, err := n.db.ExecContext(
+		ctx,
+		n.rebind("INSERT INTO namespaces ("+columns("", namespaceColumns)+") VALUES ("+binds(namespaceColumns)+")"),
+		record.ID,
+		record.Name,
+		record.Description,
+		nullable(record.UserID),
+		nullable(record.TeamID),
+		record.CreatedAt,
+		record.UpdatedAt,
+	); err != nil {
+		return nil, n.translate(err)
+	}
+
+	return &record, nil
+}
+
+// Update stores the changes of an existing namespace.
+func (n *namespaces) Update(ctx context.Context, namespace *model.Namespace) (*model.Namespace, error) {
+	record := *namespace
+	record.UpdatedAt = time.Now().UTC()
+
+	if !isUUID(record.ID) {
+		return nil, store.ErrNotFound
+	}
+
+	if err := n.owner(ctx, &record); err != nil {
+		return nil, err
+	}
+
+	res, err := n.db.ExecContext(
+		ctx,
+		n.rebind("UPDATE namespaces SET name = ?, description = ?, user_id = ?, team_id = ?, updated_at = ? WHERE id = ?"),
+		record.Name,
+		record.Description,
+		nullable(record.UserID),
+		nullable(record.TeamID),
+		record.UpdatedAt,
+		record.ID,
+	)
+
+	if err != nil {
+		return nil, n.translate(err)
+	}
+
+	if err := affected(res); err != nil {
+		return nil, err
+	}
+
+	return n.Show(ctx, record.ID)
+}
+
+// Delete removes a namespace by ID or name, repositories cascade.
+func (n *namespaces) Delete(ctx context.Context, id string) error {
+	record, err := n.Show(ctx, id)
+
+	if err != nil {
+		return err
+	}
+
+	res, err := n.db.ExecContext(
+		ctx,
+		n.rebind("DELETE FROM namespaces WHERE id = ?"),
+		record.ID,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	return affected(res)
+}
+
+// owner checks that exactly one owner is defined and that it exists.
+func (n *namespaces) owner(ctx context.Context, record *model.Namespace) error {
+	table, id := "users", record.UserID
+
+	if record.TeamID != "" {
+		table, id = "teams", record.TeamID
+	}
+
+	if (record.UserID == "") == (record.TeamID == "") || !isUUID(id) {
+		return store.ErrNotFound
+	}
+
+	var count int
+
+	if err := n.db.QueryRowContext(
+		ctx,
+		n.rebind("SELECT COUNT(*) FROM "+table+" WHERE id = ?"),
+		id,
+	).Scan(&count); err != nil {
+		return err
+	}
+
+	if count != 1 {
+		return store.ErrNotFound
+	}
+
+	return nil
+}
+
+func scanNamespace(row scanner) (*model.Namespace, error) {
+	var (
+		userID sql.NullString
+		teamID sql.NullString
+		record = &model.Namespace{}
+	)
+
+	if err := row.Scan(
+		&record.ID,
+		&record.Name,
+		&record.Description,
+		&userID,
+		&teamID,
+		&record.CreatedAt,
+		&record.UpdatedAt,
+	); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, store.ErrNotFound
+		}
+
+		return nil, err
+	}
+
+	record.UserID = userID.String
+	record.TeamID = teamID.String
+	record.CreatedAt = record.CreatedAt.UTC()
+	record.UpdatedAt = record.UpdatedAt.UTC()
+
+	return record, nil
+}
+
+// nullable maps empty strings to NULL for optional references.
+func nullable(val string) interface{} {
+	if val == "" {
+		return nil
+	}
+
+	return val
+}
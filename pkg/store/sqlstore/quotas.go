@@ -0,0 +1,166 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/umschlag/umschlag-api/pkg/model"
+	"github.com/umschlag/umschlag-api/pkg/store"
+)
+
+var (
+	quotaColumns = []string{
+		"namespace_id",
+		"team_id",
+		"hard",
+		"soft",
+		"created_at",
+		"updated_at",
+	}
+
+	quotaOwners = map[string]string{
+		model.QuotaNamespace: "namespace_id",
+		model.QuotaTeam:      "team_id",
+	}
+
+	quotaTables = map[string]string{
+		model.QuotaNamespace: "namespaces",
+		model.QuotaTeam:      "teams",
+	}
+)
+
+type quotas struct {
+	*Store
+}
+
+// Show retrieves the quota of a namespace or a team.
+func (q *quotas) Show(ctx context.Context, kind, ownerID string) (*model.Quota, error) {
+	column, ok := quotaOwners[kind]
+
+	if !ok || !isUUID(ownerID) {
+		return nil, store.ErrNotFound
+	}
+
+	return scanQuota(q.db.QueryRowContext(
+		ctx,
+		q.rebind("SELECT "+columns("", quotaColumns)+" FROM quotas WHERE "+column+" = ?"),
+		ownerID,
+	))
+}
+
+// Save creates or updates the quota of a namespace or a team.
+func (q *quotas) Save(ctx context.Context, quota *model.Quota) (*model.Quota, error) {
+	record := *quota
+	record.UpdatedAt = time.Now().UTC()
+
+	column, ok := quotaOwners[record.Kind]
+
+	if !ok {
+		return nil, store.ErrNotFound
+	}
+
+	if err := q.exists(ctx, quotaTables[record.Kind], record.OwnerID); err != nil {
+		return nil, err
+	}
+
+	current, err := q.Show(ctx, record.Kind, record.OwnerID)
+
+	switch {
+	case err == nil:
+		record.CreatedAt = current.CreatedAt
+
+		_, err = q.db.ExecContext(
+			ctx,
+			q.rebind("UPDATE quotas SET hard = ?, soft = ?, updated_at = ? WHERE "+column+" = ?"),
+			record.Hard,
+			record.Soft,
+			record.UpdatedAt,
+			record.OwnerID,
+		)
+	case err == store.ErrNotFound:
+		var namespaceID, teamID string
+
+		if record.Kind == model.QuotaNamespace {
+			namespaceID = record.OwnerID
+		} else {
+			teamID = record.OwnerID
+		}
+
+		record.CreatedAt = record.UpdatedAt
+
+		_, err = q.db.ExecContext(
+			ctx,
+			q.rebind("INSERT INTO quotas ("+columns("", quotaColumns)+") VALUES ("+binds(quotaColumns)+")"),
+			nullable(namespaceID),
+			nullable(teamID),
+			record.Hard,
+			record.Soft,
+			record.CreatedAt,
+			record.UpdatedAt,
+		)
+	}
+
+	if err != nil {
+		return nil, q.translate(err)
+	}
+
+	return &record, nil
+}
+
+// Delete removes the quota of a namespace or a team.
+func (q *quotas) Delete(ctx context.Context, kind, ownerID string) error {
+	column, ok := quotaOwners[kind]
+
+	if !ok || !isUUID(ownerID) {
+		return store.ErrNotFound
+	}
+
+	res, err := q.db.ExecContext(
+		ctx,
+		q.rebind("DELETE FROM quotas WHERE "+column+" = ?"),
+		ownerID,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	return affected(res)
+}
+
+func scanQuota(row scanner) (*model.Quota, error) {
+	var (
+		namespaceID sql.NullString
+		teamID      sql.NullString
+		record      = &model.Quota{}
+	)
+
+	if err := row.Scan(
+		&namespaceID,
+		&teamID,
+		&record.Hard,
+		&record.Soft,
+		&record.CreatedAt,
+		&record.UpdatedAt,
+	); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, store.ErrNotFound
+		}
+
+		return nil, err
+	}
+
+	if namespaceID.Valid {
+		record.Kind = model.QuotaNamespace
+		record.OwnerID = namespaceID.String
+	} else {
+		record.Kind = model.QuotaTeam
+		record.OwnerID = teamID.String
+	}
+
+	record.CreatedAt = record.CreatedAt.UTC()
+	record.UpdatedAt = record.UpdatedAt.UTC()
+
+	return record, nil
+}
@@ -0,0 +1,306 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/umschlag/umschlag-api/pkg/model"
+	"github.com/umschlag/umschlag-api/pkg/store"
+)
+
+var (
+	repositoryColumns = []string{
+		"id",
+		"namespace_id",
+		"name",
+		"description",
+		"visibility",
+		"created_at",
+		"updated_at",
+	}
+
+	repositoryTeamColumns = []string{
+		"repository_id",
+		"team_id",
+		"perm",
+	}
+)
+
+type repositories struct {
+	*Store
+}
+
+// List retrieves all repositories of a namespace.
+func (r *repositories) List(ctx context.Context, namespaceID string) ([]*model.Repository, error) {
+	records := make([]*model.Repository, 0)
+
+	if !isUUID(namespaceID) {
+		return records, nil
+	}
+
+	rows, err := r.db.QueryContext(
+		ctx,
+		r.rebind("SELECT "+columns("repositories", repositoryColumns)+" FROM repositories WHERE namespace_id = ? ORDER BY name"),
+		namespaceID,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		record, err := scanRepository(rows)
+
+		if err != nil {
+			return nil, err
+		}
+
+		records = append(records, record)
+	}
+
+	return records, rows.Err()
+}
+
+// Show retrieves a repository of a namespace by ID or name.
+func (r *repositories) Show(ctx context.Context, namespaceID, id string) (*model.Repository, error) {
+	if !isUUID(namespaceID) {
+		return nil, store.ErrNotFound
+	}
+
+	if isUUID(id) {
+		record, err := scanRepository(r.db.QueryRowContext(
+			ctx,
+			r.rebind("SELECT "+columns("repositories", repositoryColumns)+" FROM repositories WHERE namespace_id = ? AND id = ?"),
+			namespaceID,
+			id,
+		))
+
+		if err != store.ErrNotFound {
+			return record, err
+		}
+	}
+
+	return scanRepository(r.db.QueryRowContext(
+		ctx,
+		r.rebind("SELECT "+columns("repositories", repositoryColumns)+" FROM repositories WHERE namespace_id = ? AND name = ?"),
+		namespaceID,
+		id,
+	))
+}
+
+// Create stores a new repository and fills the generated fields.
+func (r *repositories) Create(ctx context.Context, repository *model.Repository) (*model.Repository, error) {
+	record := *repository
+	record.ID = uuid.New().String()
+	record.CreatedAt = time.Now().UTC()
+	record.UpdatedAt = record.CreatedAt
+
+	if record.Visibility == "" {
+		record.Visibility = model.VisibilityPrivate
+	}
+
+	if err := r.exists(ctx, "namespaces", record.NamespaceID); err != nil {
+		return nil, err
+	}
+
+	if _, err := r.db.ExecContext(
+		ctx,
+		r.rebind("INSERT INTO repositories ("+columns("", repositoryColumns)+") VALUES ("+binds(repositoryColumns)+")"),
+		record.ID,
+		record.NamespaceID,
+		record.Name,
+		record.Description,
+		record.Visibility,
+		record.CreatedAt,
+		record.UpdatedAt,
+	); err != nil {
+		return nil, r.translate(err)
+	}
+
+	return &record, nil
+}
+
+// Update stores the changes of an existing repository, the namespace of a
+// repository can't be changed.
+func (r *repositories) Update(ctx context.Context, repository *model.Repository) (*model.Repository, error) {
+	record := *repository
+	record.UpdatedAt = time.Now().UTC()
+
+	if record.Visibility == "" {
+		record.Visibility = model.VisibilityPrivate
+	}
+
+	if !isUUID(record.ID) {
+		return nil, store.ErrNotFound
+	}
+
+	res, err := r.db.ExecContext(
+		ctx,
+		r.rebind("UPDATE repositories SET name = ?, description = ?, visibility = ?, updated_at = ? WHERE id = ?"),
+		record.Name,
+		record.Description,
+		record.Visibility,
+		record.UpdatedAt,
+		record.ID,
+	)
+
+	if err != nil {
+		return nil, r.translate(err)
+	}
+
+	if err := affected(res); err != nil {
+		return nil, err
+	}
+
+	return scanRepository(r.db.QueryRowContext(
+		ctx,
+		r.rebind("SELECT "+columns("repositories", repositoryColumns)+" FROM repositories WHERE id = ?"),
+		record.ID,
+	))
+}
+
+// Delete removes a repository by ID, team permissions, tags and manifests
+// cascade.
+func (r *repositories) Delete(ctx context.Context, id string) error {
+	if !isUUID(id) {
+		return store.ErrNotFound
+	}
+
+	res, err := r.db.ExecContext(
+		ctx,
+		r.rebind("DELETE FROM repositories WHERE id = ?"),
+		id,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	return affected(res)
+}
+
+// ListTeams retrieves the team permissions of a repository including the teams.
+func (r *repositories) ListTeams(ctx context.Context, repositoryID string) ([]*model.RepositoryTeam, error) {
+	records := make([]*model.RepositoryTeam, 0)
+
+	if !isUUID(repositoryID) {
+		return records, nil
+	}
+
+	rows, err := r.db.QueryContext(
+		ctx,
+		r.rebind("SELECT "+columns("repository_teams", repositoryTeamColumns)+", "+columns("teams", teamColumns)+" FROM repository_teams INNER JOIN teams ON teams.id = repository_teams.team_id WHERE repository_teams.repository_id = ? ORDER BY teams.name"),
+		repositoryID,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		record := &model.RepositoryTeam{
+			Team: &model.Team{},
+		}
+
+		if err := rows.Scan(
+			&record.RepositoryID,
+			&record.TeamID,
+			&record.Perm,
+			&record.Team.ID,
+			&record.Team.Slug,
+			&record.Team.Name,
+			&record.Team.Avatar,
+			&record.Team.CreatedAt,
+			&record.Team.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+
+		record.Team.CreatedAt = record.Team.CreatedAt.UTC()
+		record.Team.UpdatedAt = record.Team.UpdatedAt.UTC()
+
+		records = append(records, record)
+	}
+
+	return records, rows.Err()
+}
+
+// PermitTeam grants or changes the permission of a team on a repository.
+func (r *repositories) PermitTeam(ctx context.Context, repositoryID, teamID, perm string) error {
+	if err := r.exists(ctx, "repositories", repositoryID); err != nil {
+		return err
+	}
+
+	if err := r.exists(ctx, "teams", teamID); err != nil {
+		return err
+	}
+
+	if _, err := r.db.ExecContext(
+		ctx,
+		r.upsert("repository_teams", []string{"repository_id", "team_id"}, repositoryTeamColumns),
+		repositoryID,
+		teamID,
+		perm,
+	); err != nil {
+		return r.translate(err)
+	}
+
+	return nil
+}
+
+// RevokeTeam removes the permission of a team on a repository.
+func (r *repositories) RevokeTeam(ctx context.Context, repositoryID, teamID string) error {
+	if !isUUID(repositoryID) || !isUUID(teamID) {
+		return store.ErrNotAssigned
+	}
+
+	res, err := r.db.ExecContext(
+		ctx,
+		r.rebind("DELETE FROM repository_teams WHERE repository_id = ? AND team_id = ?"),
+		repositoryID,
+		teamID,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	if err := affected(res); err == store.ErrNotFound {
+		return store.ErrNotAssigned
+	} else if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func scanRepository(row scanner) (*model.Repository, error) {
+	record := &model.Repository{}
+
+	if err := row.Scan(
+		&record.ID,
+		&record.NamespaceID,
+		&record.Name,
+		&record.Description,
+		&record.Visibility,
+		&record.CreatedAt,
+		&record.UpdatedAt,
+	); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, store.ErrNotFound
+		}
+
+		return nil, err
+	}
+
+	record.CreatedAt = record.CreatedAt.UTC()
+	record.UpdatedAt = record.UpdatedAt.UTC()
+
+	return record, nil
+}
@@ -0,0 +1,307 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/umschlag/umschlag-api/pkg/model"
+	"github.com/umschlag/umschlag-api/pkg/store"
+)
+
+var (
+	retentionColumns = []string{
+		"id",
+		"namespace_id",
+		"repository_id",
+		"keep_last",
+		"keep_pattern",
+		"older_than",
+		"created_at",
+		"updated_at",
+	}
+
+	reportColumns = []string{
+		"id",
+		"retention_id",
+		"started_at",
+		"finished_at",
+		"entries",
+	}
+)
+
+type retentions struct {
+	*Store
+}
+
+// List retrieves all retention policies.
+func (r *retentions) List(ctx context.Context) ([]*model.Retention, error) {
+	records := make([]*model.Retention, 0)
+
+	rows, err := r.db.QueryContext(
+		ctx,
+		"SELECT "+columns("", retentionColumns)+" FROM retentions ORDER BY created_at",
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		record, err := scanRetention(rows)
+
+		if err != nil {
+			return nil, err
+		}
+
+		records = append(records, record)
+	}
+
+	return records, rows.Err()
+}
+
+// Show retrieves the retention policy of a namespace or a repository.
+func (r *retentions) Show(ctx context.Context, namespaceID, repositoryID string) (*model.Retention, error) {
+	if !isUUID(namespaceID) || (repositoryID != "" && !isUUID(repositoryID)) {
+		return nil, store.ErrNotFound
+	}
+
+	if repositoryID == "" {
+		return scanRetention(r.db.QueryRowContext(
+			ctx,
+			r.rebind("SELECT "+columns("", retentionColumns)+" FROM retentions WHERE namespace_id = ? AND repository_id IS NULL"),
+			namespaceID,
+		))
+	}
+
+	return scanRetention(r.db.QueryRowContext(
+		ctx,
+		r.rebind("SELECT "+columns("", retentionColumns)+" FROM retentions WHERE namespace_id = ? AND repository_id = ?"),
+		namespaceID,
+		repositoryID,
+	))
+}
+
+// Save creates or updates the retention policy of a namespace or a
+// repository.
+func (r *retentions) Save(ctx context.Context, retention *model.Retention) (*model.Retention, error) {
+	record := *retention
+	record.UpdatedAt = time.Now().UTC()
+
+	if err := r.exists(ctx, "namespaces", record.NamespaceID); err != nil {
+		return nil, err
+	}
+
+	if record.RepositoryID != "" {
+		var namespaceID string
+
+		if err := r.exists(ctx, "repositories", record.RepositoryID); err != nil {
+			return nil, err
+		}
+
+		if err := r.db.QueryRowContext(
+			ctx,
+			r.rebind("SELECT namespace_id FROM repositories WHERE id = ?"),
+			record.RepositoryID,
+		).Scan(&namespaceID); err != nil {
+			return nil, r.translate(err)
+		}
+
+		if namespaceID != record.NamespaceID {
+			return nil, store.ErrNotFound
+		}
+	}
+
+	current, err := r.Show(ctx, record.NamespaceID, record.RepositoryID)
+
+	switch {
+	case err == nil:
+		record.ID = current.ID
+		record.CreatedAt = current.CreatedAt
+
+		_, err = r.db.ExecContext(
+			ctx,
+			r.rebind("UPDATE retentions SET keep_last = ?, keep_pattern = ?, older_than = ?, updated_at = ? WHERE id = ?"),
+			record.KeepLast,
+			record.KeepPattern,
+			record.OlderThan,
+			record.UpdatedAt,
+			record.ID,
+		)
+	case err == store.ErrNotFound:
+		record.ID = uuid.New().String()
+		record.CreatedAt = record.UpdatedAt
+
+		_, err = r.db.ExecContext(
+			ctx,
+			r.rebind("INSERT INTO retentions ("+columns("", retentionColumns)+") VALUES ("+binds(retentionColumns)+")"),
+			record.ID,
+			record.NamespaceID,
+			nullable(record.RepositoryID),
+			record.KeepLast,
+			record.KeepPattern,
+			record.OlderThan,
+			record.CreatedAt,
+			record.UpdatedAt,
+		)
+	}
+
+	if err != nil {
+		return nil, r.translate(err)
+	}
+
+	return &record, nil
+}
+
+// Delete removes the retention policy of a namespace or a repository
+// including the reports.
+func (r *retentions) Delete(ctx context.Context, namespaceID, repositoryID string) error {
+	record, err := r.Show(ctx, namespaceID, repositoryID)
+
+	if err != nil {
+		return err
+	}
+
+	res, err := r.db.ExecContext(
+		ctx,
+		r.rebind("DELETE FROM retentions WHERE id = ?"),
+		record.ID,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	return affected(res)
+}
+
+// ListReports retrieves the reports of a retention policy, newest first.
+func (r *retentions) ListReports(ctx context.Context, retentionID string) ([]*model.Report, error) {
+	records := make([]*model.Report, 0)
+
+	if !isUUID(retentionID) {
+		return records, nil
+	}
+
+	rows, err := r.db.QueryContext(
+		ctx,
+		r.rebind("SELECT "+columns("", reportColumns)+" FROM reports WHERE retention_id = ? ORDER BY started_at DESC"),
+		retentionID,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		record, err := scanReport(rows)
+
+		if err != nil {
+			return nil, err
+		}
+
+		records = append(records, record)
+	}
+
+	return records, rows.Err()
+}
+
+// CreateReport stores the report of a retention policy run.
+func (r *retentions) CreateReport(ctx context.Context, report *model.Report) (*model.Report, error) {
+	record := *report
+	record.ID = uuid.New().String()
+
+	if record.Entries == nil {
+		record.Entries = make([]*model.ReportEntry, 0)
+	}
+
+	if err := r.exists(ctx, "retentions", record.RetentionID); err != nil {
+		return nil, err
+	}
+
+	entries, err := json.Marshal(record.Entries)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := r.db.ExecContext(
+		ctx,
+		r.rebind("INSERT INTO reports ("+columns("", reportColumns)+") VALUES ("+binds(reportColumns)+")"),
+		record.ID,
+		record.RetentionID,
+		record.StartedAt,
+		record.FinishedAt,
+		string(entries),
+	); err != nil {
+		return nil, r.translate(err)
+	}
+
+	return &record, nil
+}
+
+func scanRetention(row scanner) (*model.Retention, error) {
+	var (
+		repositoryID sql.NullString
+		record       = &model.Retention{}
+	)
+
+	if err := row.Scan(
+		&record.ID,
+		&record.NamespaceID,
+		&repositoryID,
+		&record.KeepLast,
+		&record.KeepPattern,
+		&record.OlderThan,
+		&record.CreatedAt,
+		&record.UpdatedAt,
+	); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, store.ErrNotFound
+		}
+
+		return nil, err
+	}
+
+	record.RepositoryID = repositoryID.String
+	record.CreatedAt = record.CreatedAt.UTC()
+	record.UpdatedAt = record.UpdatedAt.UTC()
+
+	return record, nil
+}
+
+func scanReport(row scanner) (*model.Report, error) {
+	var (
+		entries string
+		record  = &model.Report{}
+	)
+
+	if err := row.Scan(
+		&record.ID,
+		&record.RetentionID,
+		&record.StartedAt,
+		&record.FinishedAt,
+		&entries,
+	); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, store.ErrNotFound
+		}
+
+		return nil, err
+	}
+
+	if err := json.Unmarshal([]byte(entries), &record.Entries); err != nil {
+		return nil, err
+	}
+
+	record.StartedAt = record.StartedAt.UTC()
+	record.FinishedAt = record.FinishedAt.UTC()
+
+	return record, nil
+}
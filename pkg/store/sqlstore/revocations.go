@@ -0,0 +1,163 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/umschlag/umschlag-api/pkg/model"
+	"github.com/umschlag/umschlag-api/pkg/store"
+)
+
+var (
+	revocationColumns = []string{
+		"token_id",
+		"user_id",
+		"expires_at",
+		"created_at",
+	}
+
+	userRevocationColumns = []string{
+		"user_id",
+		"revoked_before",
+	}
+)
+
+type revocations struct {
+	*Store
+}
+
+// Revoke stores the revocation of a single token.
+func (r *revocations) Revoke(ctx context.Context, revocation *model.Revocation) error {
+	record := *revocation
+	record.CreatedAt = time.Now().UTC()
+
+	if err := r.exists(ctx, record.UserID); err != nil {
+		return err
+	}
+
+	if _, err := r.db.ExecContext(
+		ctx,
+		r.upsert("token_revocations", []string{"token_id"}, revocationColumns),
+		record.TokenID,
+		record.UserID,
+		record.ExpiresAt,
+		record.CreatedAt,
+	); err != nil {
+		return r.translate(err)
+	}
+
+	return nil
+}
+
+// Revoked checks if a token has been revoked.
+func (r *revocations) Revoked(ctx context.Context, tokenID string) (bool, error) {
+	var count int
+
+	if err := r.db.QueryRowContext(
+		ctx,
+		r.rebind("SELECT COUNT(*) FROM token_revocations WHERE token_id = ?"),
+		tokenID,
+	).Scan(&count); err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+// RevokeBefore revokes all tokens of a user issued before the time, an
+// already later point in time is kept.
+func (r *revocations) RevokeBefore(ctx context.Context, userID string, before time.Time) error {
+	if err := r.exists(ctx, userID); err != nil {
+		return err
+	}
+
+	current, err := r.RevokedBefore(ctx, userID)
+
+	if err != nil {
+		return err
+	}
+
+	if !before.After(current) {
+		return nil
+	}
+
+	if _, err := r.db.ExecContext(
+		ctx,
+		r.upsert("user_revocations", []string{"user_id"}, userRevocationColumns),
+		userID,
+		before.UTC(),
+	); err != nil {
+		return r.translate(err)
+	}
+
+	return nil
+}
+
+// RevokedBefore retrieves the time before that all tokens of a user have
+// been revoked, it's zero if that never happened.
+func (r *revocations) RevokedBefore(ctx context.Context, userID string) (time.Time, error) {
+	if !isUUID(userID) {
+		return time.Time{}, nil
+	}
+
+	var before time.Time
+
+	if err := r.db.QueryRowContext(
+		ctx,
+		r.rebind("SELECT revoked_before FROM user_revocations WHERE user_id = ?"),
+		userID,
+	).Scan(&before); err != nil {
+		if err == sql.ErrNoRows {
+			return time.Time{}, nil
+		}
+
+		return time.Time{}, err
+	}
+
+	return before.UTC(), nil
+}
+
+// Prune removes the revocations of tokens that are expired anyway.
+func (r *revocations) Prune(ctx context.Context, now time.Time) (int, error) {
+	res, err := r.db.ExecContext(
+		ctx,
+		r.rebind("DELETE FROM token_revocations WHERE expires_at IS NOT NULL AND expires_at < ?"),
+		now.UTC(),
+	)
+
+	if err != nil {
+		return 0, err
+	}
+
+	count, err := res.RowsAffected()
+
+	if err != nil {
+		return 0, err
+	}
+
+	return int(count), nil
+}
+
+// exists checks that the user of a revocation is present.
+func (r *revocations) exists(ctx context.Context, userID string) error {
+	if !isUUID(userID) {
+		return store.ErrNotFound
+	}
+
+	var count int
+
+	if err := r.db.QueryRowContext(
+		ctx,
+		r.rebind("SELECT COUNT(*) FROM users WHERE id = ?"),
+		userID,
+	).Scan(&count); err != nil {
+		return err
+	}
+
+	if count != 1 {
+		return store.ErrNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,269 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/umschlag/umschlag-api/pkg/store"
+)
+
+var (
+	// constraints maps the names of unique constraints to store errors.
+	constraints = map[string]error{
+		"users_slug_key":         store.ErrDuplicateSlug,
+		"users_username_key":     store.ErrDuplicateUsername,
+		"users_email_key":        store.ErrDuplicateEmail,
+		"teams_slug_key":         store.ErrDuplicateSlug,
+		"team_users_pkey":        store.ErrAlreadyAssigned,
+		"access_tokens_hash_key": store.ErrDuplicateName,
+		"access_tokens_name_key": store.ErrDuplicateName,
+		"namespaces_name_key":    store.ErrDuplicateName,
+		"repositories_name_key":  store.ErrDuplicateName,
+		"immutables_pattern_key": store.ErrDuplicatePattern,
+	}
+)
+
+// Store implements the store interface on top of database/sql.
+type Store struct {
+	db      *sql.DB
+	dialect Dialect
+}
+
+// Close simply closes the database connection.
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// Users provides access to the stored users.
+func (s *Store) Users() store.UserStore {
+	return &users{
+		Store: s,
+	}
+}
+
+// Teams provides access to the stored teams.
+func (s *Store) Teams() store.TeamStore {
+	return &teams{
+		Store: s,
+	}
+}
+
+// Members provides access to the team memberships.
+func (s *Store) Members() store.MemberStore {
+	return &members{
+		Store: s,
+	}
+}
+
+// Tokens provides access to the personal access tokens.
+func (s *Store) Tokens() store.TokenStore {
+	return &tokens{
+		Store: s,
+	}
+}
+
+// Revocations provides access to the revoked tokens.
+func (s *Store) Revocations() store.RevocationStore {
+	return &revocations{
+		Store: s,
+	}
+}
+
+// Namespaces provides access to the stored namespaces.
+func (s *Store) Namespaces() store.NamespaceStore {
+	return &namespaces{
+		Store: s,
+	}
+}
+
+// Repositories provides access to the stored repositories.
+func (s *Store) Repositories() store.RepositoryStore {
+	return &repositories{
+		Store: s,
+	}
+}
+
+// Tags provides access to the tags of repositories.
+func (s *Store) Tags() store.TagStore {
+	return &tags{
+		Store: s,
+	}
+}
+
+// Manifests provides access to the manifests of repositories.
+func (s *Store) Manifests() store.ManifestStore {
+	return &manifests{
+		Store: s,
+	}
+}
+
+// Retentions provides access to the retention policies.
+func (s *Store) Retentions() store.RetentionStore {
+	return &retentions{
+		Store: s,
+	}
+}
+
+// Immutables provides access to the immutable tag rules.
+func (s *Store) Immutables() store.ImmutableStore {
+	return &immutables{
+		Store: s,
+	}
+}
+
+// Quotas provides access to the storage quotas.
+func (s *Store) Quotas() store.QuotaStore {
+	return &quotas{
+		Store: s,
+	}
+}
+
+// New initializes the SQL store and applies pending migrations.
+func New(db *sql.DB, dialect Dialect, migrations []Migration) (*Store, error) {
+	if err := db.Ping(); err != nil {
+		return nil, err
+	}
+
+	if err := Migrate(context.Background(), db, dialect, migrations); err != nil {
+		return nil, err
+	}
+
+	return &Store{
+		db:      db,
+		dialect: dialect,
+	}, nil
+}
+
+// rebind replaces the question mark placeholders for the dialect.
+func (s *Store) rebind(query string) string {
+	return rebind(s.dialect, query)
+}
+
+// translate maps unique constraint violations to store errors.
+func (s *Store) translate(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	if err == sql.ErrNoRows {
+		return store.ErrNotFound
+	}
+
+	if name, ok := s.dialect.Constraint(err); ok {
+		if mapped, ok := constraints[name]; ok {
+			return mapped
+		}
+	}
+
+	return err
+}
+
+// translateTable maps constraint violations like translate, but resolves a
+// violated primary key without table name by the given table.
+func (s *Store) translateTable(table string, err error) error {
+	if name, ok := s.dialect.Constraint(err); ok && name == PrimaryKey {
+		if mapped, ok := constraints[table+"_pkey"]; ok {
+			return mapped
+		}
+	}
+
+	return s.translate(err)
+}
+
+func rebind(dialect Dialect, query string) string {
+	var (
+		b strings.Builder
+		n int
+	)
+
+	for _, r := range query {
+		if r == '?' {
+			n++
+			b.WriteString(dialect.Placeholder(n))
+			continue
+		}
+
+		b.WriteRune(r)
+	}
+
+	return b.String()
+}
+
+// columns joins the column names, optionally qualified by a table.
+func columns(table string, names []string) string {
+	result := make([]string, len(names))
+
+	for i, name := range names {
+		if table != "" {
+			result[i] = table + "." + name
+		} else {
+			result[i] = name
+		}
+	}
+
+	return strings.Join(result, ", ")
+}
+
+// binds returns a placeholder for every column.
+func binds(names []string) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", len(names)), ", ")
+}
+
+// upsert builds an insert statement that updates all non-key columns if
+// a record with the same keys already exists.
+func (s *Store) upsert(table string, keys, names []string) string {
+	updates := make([]string, 0, len(names))
+
+	for _, name := range names {
+		key := false
+
+		for _, k := range keys {
+			if k == name {
+				key = true
+				break
+			}
+		}
+
+		if !key {
+			updates = append(updates, name)
+		}
+	}
+
+	return s.rebind("INSERT INTO " + table + " (" + columns("", names) + ") VALUES (" + binds(names) + ") " + s.dialect.OnConflict(keys, updates))
+}
+
+// exists checks that a referenced record is present.
+func (s *Store) exists(ctx context.Context, table, id string) error {
+	if !isUUID(id) {
+		return store.ErrNotFound
+	}
+
+	var count int
+
+	if err := s.db.QueryRowContext(
+		ctx,
+		s.rebind("SELECT COUNT(*) FROM "+table+" WHERE id = ?"),
+		id,
+	).Scan(&count); err != nil {
+		return err
+	}
+
+	if count != 1 {
+		return store.ErrNotFound
+	}
+
+	return nil
+}
+
+// isUUID checks if the value could be used as primary key.
+func isUUID(val string) bool {
+	_, err := uuid.Parse(val)
+	return err == nil
+}
+
+// scanner abstracts sql.Row and sql.Rows for the scan helpers.
+type scanner interface {
+	Scan(...interface{}) error
+}
@@ -0,0 +1,173 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	sqlite3 "github.com/mattn/go-sqlite3"
+	"github.com/umschlag/umschlag-api/pkg/store"
+	"github.com/umschlag/umschlag-api/pkg/store/storetest"
+)
+
+var (
+	// uniqueIndex matches unique indexes on plain columns, SQLite only
+	// reports the columns of them instead of the index name.
+	uniqueIndex = regexp.MustCompile(`CREATE UNIQUE INDEX (\w+) ON (\w+) \(([\w, ]+)\)`)
+
+	// databases numbers the in-memory databases to isolate the cases.
+	databases int64
+)
+
+// sqlite implements an in-process dialect to run the shared layer without
+// external services, the schema mirrors the PostgreSQL migrations.
+type sqlite struct {
+	indexes map[string]string
+}
+
+func newSqlite(migrations []Migration) *sqlite {
+	d := &sqlite{
+		indexes: make(map[string]string),
+	}
+
+	for _, migration := range migrations {
+		for _, statement := range migration.Statements {
+			for _, match := range uniqueIndex.FindAllStringSubmatch(statement, -1) {
+				names := strings.Split(match[3], ",")
+
+				for i, name := range names {
+					names[i] = match[2] + "." + strings.TrimSpace(name)
+				}
+
+				d.indexes[strings.Join(names, ", ")] = match[1]
+			}
+		}
+	}
+
+	return d
+}
+
+func (d *sqlite) Name() string {
+	return "sqlite3"
+}
+
+func (d *sqlite) Placeholder(n int) string {
+	return "?"
+}
+
+func (d *sqlite) Fold(expr string) string {
+	return "LOWER(" + expr + ")"
+}
+
+func (d *sqlite) OnConflict(keys, columns []string) string {
+	updates := make([]string, 0, len(columns))
+
+	for _, column := range columns {
+		updates = append(updates, column+" = excluded."+column)
+	}
+
+	return "ON CONFLICT (" + strings.Join(keys, ", ") + ") DO UPDATE SET " + strings.Join(updates, ", ")
+}
+
+func (d *sqlite) Constraint(err error) (string, bool) {
+	sqliteErr, ok := err.(sqlite3.Error)
+
+	if !ok {
+		return "", false
+	}
+
+	switch sqliteErr.ExtendedCode {
+	case sqlite3.ErrConstraintPrimaryKey:
+		return PrimaryKey, true
+	case sqlite3.ErrConstraintUnique:
+		failed := strings.TrimPrefix(sqliteErr.Error(), "UNIQUE constraint failed: ")
+
+		if strings.HasPrefix(failed, "index '") {
+			return strings.Trim(strings.TrimPrefix(failed, "index "), "'"), true
+		}
+
+		name, ok := d.indexes[failed]
+		return name, ok
+	}
+
+	return "", false
+}
+
+// Lock is a no-op, SQLite serializes all writes on the database.
+func (d *sqlite) Lock(ctx context.Context, conn *sql.Conn) error {
+	return nil
+}
+
+// Unlock is a no-op like Lock.
+func (d *sqlite) Unlock(ctx context.Context, conn *sql.Conn) error {
+	return nil
+}
+
+func TestStore(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) store.Store {
+		db, err := sql.Open("sqlite3", fmt.Sprintf(
+			"file:storetest%d?mode=memory&cache=shared&_foreign_keys=1",
+			atomic.AddInt64(&databases, 1),
+		))
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		db.SetMaxOpenConns(1)
+
+		s, err := New(db, newSqlite(sqliteMigrations), sqliteMigrations)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return s
+	})
+}
+
+func TestConstraint(t *testing.T) {
+	d := newSqlite(sqliteMigrations)
+
+	tests := []struct {
+		err  error
+		name string
+		ok   bool
+	}{
+		{
+			err:  sqlite3.Error{ExtendedCode: sqlite3.ErrConstraintUnique},
+			name: "",
+			ok:   false,
+		},
+		{
+			err:  sqlite3.Error{ExtendedCode: sqlite3.ErrConstraintPrimaryKey},
+			name: PrimaryKey,
+			ok:   true,
+		},
+		{
+			err:  fmt.Errorf("UNIQUE constraint failed: users.slug"),
+			name: "",
+			ok:   false,
+		},
+	}
+
+	for _, tt := range tests {
+		if name, ok := d.Constraint(tt.err); name != tt.name || ok != tt.ok {
+			t.Errorf("expected %q, %v for %v, got %q, %v", tt.name, tt.ok, tt.err, name, ok)
+		}
+	}
+
+	for columns, name := range map[string]string{
+		"users.slug": "users_slug_key",
+		"access_tokens.user_id, access_tokens.name": "access_tokens_name_key",
+		"retentions.namespace_id":                   "retentions_namespace_id_key",
+	} {
+		if d.indexes[columns] != name {
+			t.Errorf("expected index %q for %q, got %q", name, columns, d.indexes[columns])
+		}
+	}
+}
@@ -0,0 +1,202 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/umschlag/umschlag-api/pkg/model"
+	"github.com/umschlag/umschlag-api/pkg/store"
+)
+
+var (
+	tagColumns = []string{
+		"repository_id",
+		"name",
+		"digest",
+		"updated_at",
+		"pulls",
+		"pulled_at",
+		"overwritten_at",
+		"created_at",
+	}
+)
+
+type tags struct {
+	*Store
+}
+
+// List retrieves all tags of a repository.
+func (t *tags) List(ctx context.Context, repositoryID string) ([]*model.Tag, error) {
+	records := make([]*model.Tag, 0)
+
+	if !isUUID(repositoryID) {
+		return records, nil
+	}
+
+	rows, err := t.db.QueryContext(
+		ctx,
+		t.rebind("SELECT "+columns("", tagColumns)+" FROM tags WHERE repository_id = ? ORDER BY name"),
+		repositoryID,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		record, err := scanTag(rows)
+
+		if err != nil {
+			return nil, err
+		}
+
+		records = append(records, record)
+	}
+
+	return records, rows.Err()
+}
+
+// Show retrieves a tag of a repository by name.
+func (t *tags) Show(ctx context.Context, repositoryID, name string) (*model.Tag, error) {
+	if !isUUID(repositoryID) {
+		return nil, store.ErrNotFound
+	}
+
+	return scanTag(t.db.QueryRowContext(
+		ctx,
+		t.rebind("SELECT "+columns("", tagColumns)+" FROM tags WHERE repository_id = ? AND name = ?"),
+		repositoryID,
+		name,
+	))
+}
+
+// Save creates or updates a tag of a repository.
+func (t *tags) Save(ctx context.Context, tag *model.Tag) (*model.Tag, error) {
+	record := *tag
+	record.UpdatedAt = time.Now().UTC()
+
+	if err := t.exists(ctx, "repositories", record.RepositoryID); err != nil {
+		return nil, err
+	}
+
+	// the pull statistics and overwrite flags are only written by Pull and
+	// Flag, so they are excluded from the upsert and survive updates of the
+	// digest. The creation date is only written by the insert.
+	if _, err := t.db.ExecContext(
+		ctx,
+		t.rebind("INSERT INTO tags (repository_id, name, digest, updated_at, created_at) VALUES (?, ?, ?, ?, ?) "+t.dialect.OnConflict(
+			[]string{"repository_id", "name"},
+			[]string{"digest", "updated_at"},
+		)),
+		record.RepositoryID,
+		record.Name,
+		record.Digest,
+		record.UpdatedAt,
+		record.UpdatedAt,
+	); err != nil {
+		return nil, t.translate(err)
+	}
+
+	return t.Show(ctx, record.RepositoryID, record.Name)
+}
+
+// Pull increments the pull counter of a tag.
+func (t *tags) Pull(ctx context.Context, repositoryID, name string) error {
+	if !isUUID(repositoryID) {
+		return store.ErrNotFound
+	}
+
+	res, err := t.db.ExecContext(
+		ctx,
+		t.rebind("UPDATE tags SET pulls = pulls + 1, pulled_at = ? WHERE repository_id = ? AND name = ?"),
+		time.Now().UTC(),
+		repositoryID,
+		name,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	return affected(res)
+}
+
+// Flag marks a tag as overwritten.
+func (t *tags) Flag(ctx context.Context, repositoryID, name string) error {
+	if !isUUID(repositoryID) {
+		return store.ErrNotFound
+	}
+
+	res, err := t.db.ExecContext(
+		ctx,
+		t.rebind("UPDATE tags SET overwritten_at = ? WHERE repository_id = ? AND name = ?"),
+		time.Now().UTC(),
+		repositoryID,
+		name,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	return affected(res)
+}
+
+// Delete removes a tag of a repository by name.
+func (t *tags) Delete(ctx context.Context, repositoryID, name string) error {
+	if !isUUID(repositoryID) {
+		return store.ErrNotFound
+	}
+
+	res, err := t.db.ExecContext(
+		ctx,
+		t.rebind("DELETE FROM tags WHERE repository_id = ? AND name = ?"),
+		repositoryID,
+		name,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	return affected(res)
+}
+
+func scanTag(row scanner) (*model.Tag, error) {
+	record := &model.Tag{}
+
+	if err := row.Scan(
+		&record.RepositoryID,
+		&record.Name,
+		&record.Digest,
+		&record.UpdatedAt,
+		&record.Pulls,
+		&record.PulledAt,
+		&record.OverwrittenAt,
+		&record.CreatedAt,
+	); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, store.ErrNotFound
+		}
+
+		return nil, err
+	}
+
+	record.CreatedAt = record.CreatedAt.UTC()
+	record.UpdatedAt = record.UpdatedAt.UTC()
+
+	if record.PulledAt != nil {
+		pulled := record.PulledAt.UTC()
+		record.PulledAt = &pulled
+	}
+
+	if record.OverwrittenAt != nil {
+		overwritten := record.OverwrittenAt.UTC()
+		record.OverwrittenAt = &overwritten
+	}
+
+	return record, nil
+}
@@ -0,0 +1,192 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/umschlag/umschlag-api/pkg/model"
+	"github.com/umschlag/umschlag-api/pkg/store"
+)
+
+var (
+	teamColumns = []string{
+		"id",
+		"slug",
+		"name",
+		"avatar",
+		"created_at",
+		"updated_at",
+	}
+)
+
+type teams struct {
+	*Store
+}
+
+// List retrieves all available teams.
+func (t *teams) List(ctx context.Context) ([]*model.Team, error) {
+	rows, err := t.db.QueryContext(
+		ctx,
+		"SELECT "+columns("teams", teamColumns)+" FROM teams ORDER BY name",
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	records := make([]*model.Team, 0)
+
+	for rows.Next() {
+		record, err := scanTeam(rows)
+
+		if err != nil {
+			return nil, err
+		}
+
+		records = append(records, record)
+	}
+
+	return records, rows.Err()
+}
+
+// Show retrieves a team by ID or slug.
+func (t *teams) Show(ctx context.Context, id string) (*model.Team, error) {
+	if isUUID(id) {
+		record, err := scanTeam(t.db.QueryRowContext(
+			ctx,
+			t.rebind("SELECT "+columns("teams", teamColumns)+" FROM teams WHERE id = ?"),
+			id,
+		))
+
+		if err != store.ErrNotFound {
+			return record, err
+		}
+	}
+
+	return scanTeam(t.db.QueryRowContext(
+		ctx,
+		t.rebind("SELECT "+columns("teams", teamColumns)+" FROM teams WHERE slug = ?"),
+		id,
+	))
+}
+
+// Create stores a new team and fills the generated fields.
+func (t *teams) Create(ctx context.Context, team *model.Team) (*model.Team, error) {
+	record := *team
+	record.ID = uuid.New().String()
+	record.CreatedAt = time.Now().UTC()
+	record.UpdatedAt = record.CreatedAt
+
+	if record.Slug == "" {
+		record.Slug = store.Slugify(record.Name)
+	}
+
+	if _, err := t.db.ExecContext(
+		ctx,
+		t.rebind("INSERT INTO teams ("+columns("", teamColumns)+") VALUES ("+binds(teamColumns)+")"),
+		record.ID,
+		record.Slug,
+		record.Name,
+		record.Avatar,
+		record.CreatedAt,
+		record.UpdatedAt,
+	); err != nil {
+		return nil, t.translate(err)
+	}
+
+	return &record, nil
+}
+
+// Update stores the changes of an existing team.
+func (t *teams) Update(ctx context.Context, team *model.Team) (*model.Team, error) {
+	record := *team
+	record.UpdatedAt = time.Now().UTC()
+
+	if record.Slug == "" {
+		record.Slug = store.Slugify(record.Name)
+	}
+
+	res, err := t.db.ExecContext(
+		ctx,
+		t.rebind("UPDATE teams SET slug = ?, name = ?, avatar = ?, updated_at = ? WHERE id = ?"),
+		record.Slug,
+		record.Name,
+		record.Avatar,
+		record.UpdatedAt,
+		record.ID,
+	)
+
+	if err != nil {
+		return nil, t.translate(err)
+	}
+
+	if err := affected(res); err != nil {
+		return nil, err
+	}
+
+	return t.Show(ctx, record.ID)
+}
+
+// Delete removes a team by ID or slug, memberships cascade.
+func (t *teams) Delete(ctx context.Context, id string) error {
+	record, err := t.Show(ctx, id)
+
+	if err != nil {
+		return err
+	}
+
+	res, err := t.db.ExecContext(
+		ctx,
+		t.rebind("DELETE FROM teams WHERE id = ?"),
+		record.ID,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	return affected(res)
+}
+
+func scanTeam(row scanner) (*model.Team, error) {
+	record := &model.Team{}
+
+	if err := row.Scan(
+		&record.ID,
+		&record.Slug,
+		&record.Name,
+		&record.Avatar,
+		&record.CreatedAt,
+		&record.UpdatedAt,
+	); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, store.ErrNotFound
+		}
+
+		return nil, err
+	}
+
+	record.CreatedAt = record.CreatedAt.UTC()
+	record.UpdatedAt = record.UpdatedAt.UTC()
+
+	return record, nil
+}
+
+// affected reports a missing record if no row has been changed.
+func affected(res sql.Result) error {
+	count, err := res.RowsAffected()
+
+	if err != nil {
+		return err
+	}
+
+	if count == 0 {
+		return store.ErrNotFound
+	}
+
+	return nil
+}
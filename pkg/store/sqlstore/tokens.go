@@ -0,0 +1,197 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/umschlag/umschlag-api/pkg/model"
+	"github.com/umschlag/umschlag-api/pkg/store"
+)
+
+var (
+	tokenColumns = []string{
+		"id",
+		"user_id",
+		"name",
+		"hash",
+		"scopes",
+		"expires_at",
+		"last_used_at",
+		"created_at",
+	}
+)
+
+type tokens struct {
+	*Store
+}
+
+// List retrieves all access tokens of a user.
+func (t *tokens) List(ctx context.Context, userID string) ([]*model.AccessToken, error) {
+	records := make([]*model.AccessToken, 0)
+
+	if !isUUID(userID) {
+		return records, nil
+	}
+
+	rows, err := t.db.QueryContext(
+		ctx,
+		t.rebind("SELECT "+columns("access_tokens", tokenColumns)+" FROM access_tokens WHERE user_id = ? ORDER BY name"),
+		userID,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		record, err := scanToken(rows)
+
+		if err != nil {
+			return nil, err
+		}
+
+		records = append(records, record)
+	}
+
+	return records, rows.Err()
+}
+
+// ByHash retrieves an access token by the hash of the secret.
+func (t *tokens) ByHash(ctx context.Context, hash string) (*model.AccessToken, error) {
+	return scanToken(t.db.QueryRowContext(
+		ctx,
+		t.rebind("SELECT "+columns("access_tokens", tokenColumns)+" FROM access_tokens WHERE hash = ?"),
+		hash,
+	))
+}
+
+// Create stores a new access token and fills the generated fields.
+func (t *tokens) Create(ctx context.Context, token *model.AccessToken) (*model.AccessToken, error) {
+	record := *token
+	record.ID = uuid.New().String()
+	record.CreatedAt = time.Now().UTC()
+
+	if !isUUID(record.UserID) {
+		return nil, store.ErrNotFound
+	}
+
+	var count int
+
+	if err := t.db.QueryRowContext(
+		ctx,
+		t.rebind("SELECT COUNT(*) FROM users WHERE id = ?"),
+		record.UserID,
+	).Scan(&count); err != nil {
+		return nil, err
+	}
+
+	if count != 1 {
+		return nil, store.ErrNotFound
+	}
+
+	if _, err := t.db.ExecContext(
+		ctx,
+		t.rebind("INSERT INTO access_tokens ("+columns("", tokenColumns)+") VALUES ("+binds(tokenColumns)+")"),
+		record.ID,
+		record.UserID,
+		record.Name,
+		record.Hash,
+		strings.Join(record.Scopes, ","),
+		record.ExpiresAt,
+		record.LastUsedAt,
+		record.CreatedAt,
+	); err != nil {
+		return nil, t.translate(err)
+	}
+
+	return &record, nil
+}
+
+// Touch updates the last usage of an access token.
+func (t *tokens) Touch(ctx context.Context, id string, at time.Time) error {
+	if !isUUID(id) {
+		return store.ErrNotFound
+	}
+
+	res, err := t.db.ExecContext(
+		ctx,
+		t.rebind("UPDATE access_tokens SET last_used_at = ? WHERE id = ?"),
+		at.UTC(),
+		id,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	return affected(res)
+}
+
+// Delete removes an access token of a user.
+func (t *tokens) Delete(ctx context.Context, userID, id string) error {
+	if !isUUID(userID) || !isUUID(id) {
+		return store.ErrNotFound
+	}
+
+	res, err := t.db.ExecContext(
+		ctx,
+		t.rebind("DELETE FROM access_tokens WHERE id = ? AND user_id = ?"),
+		id,
+		userID,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	return affected(res)
+}
+
+func scanToken(row scanner) (*model.AccessToken, error) {
+	var (
+		scopes string
+		record = &model.AccessToken{}
+	)
+
+	if err := row.Scan(
+		&record.ID,
+		&record.UserID,
+		&record.Name,
+		&record.Hash,
+		&scopes,
+		&record.ExpiresAt,
+		&record.LastUsedAt,
+		&record.CreatedAt,
+	); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, store.ErrNotFound
+		}
+
+		return nil, err
+	}
+
+	record.Scopes = make([]string, 0)
+
+	if scopes != "" {
+		record.Scopes = strings.Split(scopes, ",")
+	}
+
+	record.CreatedAt = record.CreatedAt.UTC()
+
+	if record.ExpiresAt != nil {
+		expires := record.ExpiresAt.UTC()
+		record.ExpiresAt = &expires
+	}
+
+	if record.LastUsedAt != nil {
+		used := record.LastUsedAt.UTC()
+		record.LastUsedAt = &used
+	}
+
+	return record, nil
+}
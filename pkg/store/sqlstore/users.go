@@ -0,0 +1,202 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/umschlag/umschlag-api/pkg/model"
+	"github.com/umschlag/umschlag-api/pkg/store"
+)
+
+var (
+	userColumns = []string{
+		"id",
+		"slug",
+		"username",
+		"password",
+		"email",
+		"admin",
+		"active",
+		"avatar",
+		"created_at",
+		"updated_at",
+	}
+)
+
+type users struct {
+	*Store
+}
+
+// List retrieves all available users.
+func (u *users) List(ctx context.Context) ([]*model.User, error) {
+	rows, err := u.db.QueryContext(
+		ctx,
+		"SELECT "+columns("users", userColumns)+" FROM users ORDER BY username",
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	records := make([]*model.User, 0)
+
+	for rows.Next() {
+		record, err := scanUser(rows)
+
+		if err != nil {
+			return nil, err
+		}
+
+		records = append(records, record)
+	}
+
+	return records, rows.Err()
+}
+
+// Show retrieves a user by ID or slug.
+func (u *users) Show(ctx context.Context, id string) (*model.User, error) {
+	if isUUID(id) {
+		record, err := scanUser(u.db.QueryRowContext(
+			ctx,
+			u.rebind("SELECT "+columns("users", userColumns)+" FROM users WHERE id = ?"),
+			id,
+		))
+
+		if err != store.ErrNotFound {
+			return record, err
+		}
+	}
+
+	return scanUser(u.db.QueryRowContext(
+		ctx,
+		u.rebind("SELECT "+columns("users", userColumns)+" FROM users WHERE slug = ?"),
+		id,
+	))
+}
+
+// ByUsername retrieves a user by the case-insensitive username.
+func (u *users) ByUsername(ctx context.Context, username string) (*model.User, error) {
+	return scanUser(u.db.QueryRowContext(
+		ctx,
+		u.rebind("SELECT "+columns("users", userColumns)+" FROM users WHERE "+u.dialect.Fold("username")+" = "+u.dialect.Fold("?")),
+		username,
+	))
+}
+
+// Create stores a new user and fills the generated fields.
+func (u *users) Create(ctx context.Context, user *model.User) (*model.User, error) {
+	record := *user
+	record.ID = uuid.New().String()
+	record.CreatedAt = time.Now().UTC()
+	record.UpdatedAt = record.CreatedAt
+
+	if record.Slug == "" {
+		record.Slug = store.Slugify(record.Username)
+	}
+
+	if _, err := u.db.ExecContext(
+		ctx,
+		u.rebind("INSERT INTO users ("+columns("", userColumns)+") VALUES ("+binds(userColumns)+")"),
+		record.ID,
+		record.Slug,
+		record.Username,
+		record.Password,
+		record.Email,
+		record.Admin,
+		record.Active,
+		record.Avatar,
+		record.CreatedAt,
+		record.UpdatedAt,
+	); err != nil {
+		return nil, u.translate(err)
+	}
+
+	return &record, nil
+}
+
+// Update stores the changes of an existing user.
+func (u *users) Update(ctx context.Context, user *model.User) (*model.User, error) {
+	record := *user
+	record.UpdatedAt = time.Now().UTC()
+
+	if record.Slug == "" {
+		record.Slug = store.Slugify(record.Username)
+	}
+
+	res, err := u.db.ExecContext(
+		ctx,
+		u.rebind("UPDATE users SET slug = ?, username = ?, password = ?, email = ?, admin = ?, active = ?, avatar = ?, updated_at = ? WHERE id = ?"),
+		record.Slug,
+		record.Username,
+		record.Password,
+		record.Email,
+		record.Admin,
+		record.Active,
+		record.Avatar,
+		record.UpdatedAt,
+		record.ID,
+	)
+
+	if err != nil {
+		return nil, u.translate(err)
+	}
+
+	if err := affected(res); err != nil {
+		return nil, err
+	}
+
+	return u.Show(ctx, record.ID)
+}
+
+// Delete removes a user by ID or slug, memberships cascade.
+func (u *users) Delete(ctx context.Context, id string) error {
+	record, err := u.Show(ctx, id)
+
+	if err != nil {
+		return err
+	}
+
+	res, err := u.db.ExecContext(
+		ctx,
+		u.rebind("DELETE FROM users WHERE id = ?"),
+		record.ID,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	return affected(res)
+}
+
+func scanUser(row scanner) (*model.User, error) {
+	record := &model.User{}
+
+	if err := row.Scan(
+		&record.ID,
+		&record.Slug,
+		&record.Username,
+		&record.Password,
+		&record.Email,
+		&record.Admin,
+		&record.Active,
+		&record.Avatar,
+		&record.CreatedAt,
+		&record.UpdatedAt,
+	); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, store.ErrNotFound
+		}
+
+		return nil, err
+	}
+
+	record.CreatedAt = record.CreatedAt.UTC()
+	record.UpdatedAt = record.UpdatedAt.UTC()
+
+	return record, nil
+}
@@ -1,15 +1,171 @@
 package store
 
 import (
+	"context"
+	"time"
+
 	"github.com/pkg/errors"
+	"github.com/umschlag/umschlag-api/pkg/model"
 )
 
 var (
 	// ErrUnknownDriver defines a named error for unknown store drivers.
 	ErrUnknownDriver = errors.New("unknown database driver")
+
+	// ErrNotFound defines a named error for missing records.
+	ErrNotFound = errors.New("record not found")
+
+	// ErrDuplicateSlug defines a named error for already taken slugs.
+	ErrDuplicateSlug = errors.New("slug is already taken")
+
+	// ErrDuplicateUsername defines a named error for already taken usernames.
+	ErrDuplicateUsername = errors.New("username is already taken")
+
+	// ErrDuplicateEmail defines a named error for already taken emails.
+	ErrDuplicateEmail = errors.New("email is already taken")
+
+	// ErrAlreadyAssigned defines a named error for existing memberships.
+	ErrAlreadyAssigned = errors.New("user is already assigned")
+
+	// ErrNotAssigned defines a named error for missing memberships.
+	ErrNotAssigned = errors.New("user is not assigned")
+
+	// ErrDuplicateName defines a named error for already taken names.
+	ErrDuplicateName = errors.New("name is already taken")
+
+	// ErrDuplicatePattern defines a named error for already defined patterns.
+	ErrDuplicatePattern = errors.New("pattern is already defined")
 )
 
 // Store provides the interface for the store implementations.
 type Store interface {
 	Close() error
+	Users() UserStore
+	Teams() TeamStore
+	Members() MemberStore
+	Tokens() TokenStore
+	Revocations() RevocationStore
+	Namespaces() NamespaceStore
+	Repositories() RepositoryStore
+	Tags() TagStore
+	Manifests() ManifestStore
+	Retentions() RetentionStore
+	Immutables() ImmutableStore
+	Quotas() QuotaStore
+}
+
+// UserStore provides the interface to access the stored users.
+type UserStore interface {
+	List(context.Context) ([]*model.User, error)
+	Show(context.Context, string) (*model.User, error)
+	ByUsername(context.Context, string) (*model.User, error)
+	Create(context.Context, *model.User) (*model.User, error)
+	Update(context.Context, *model.User) (*model.User, error)
+	Delete(context.Context, string) error
+}
+
+// TeamStore provides the interface to access the stored teams.
+type TeamStore interface {
+	List(context.Context) ([]*model.Team, error)
+	Show(context.Context, string) (*model.Team, error)
+	Create(context.Context, *model.Team) (*model.Team, error)
+	Update(context.Context, *model.Team) (*model.Team, error)
+	Delete(context.Context, string) error
+}
+
+// MemberStore provides the interface to access the team memberships.
+type MemberStore interface {
+	ListByTeam(context.Context, string) ([]*model.TeamUser, error)
+	ListByUser(context.Context, string) ([]*model.TeamUser, error)
+	Assign(context.Context, string, string, string) error
+	Permit(context.Context, string, string, string) error
+	Unassign(context.Context, string, string) error
+}
+
+// TokenStore provides the interface to access the personal access tokens.
+type TokenStore interface {
+	List(context.Context, string) ([]*model.AccessToken, error)
+	ByHash(context.Context, string) (*model.AccessToken, error)
+	Create(context.Context, *model.AccessToken) (*model.AccessToken, error)
+	Touch(context.Context, string, time.Time) error
+	Delete(context.Context, string, string) error
+}
+
+// RevocationStore provides the interface to access the revoked tokens.
+type RevocationStore interface {
+	Revoke(context.Context, *model.Revocation) error
+	Revoked(context.Context, string) (bool, error)
+	RevokeBefore(context.Context, string, time.Time) error
+	RevokedBefore(context.Context, string) (time.Time, error)
+	Prune(context.Context, time.Time) (int, error)
+}
+
+// NamespaceStore provides the interface to access the stored namespaces.
+type NamespaceStore interface {
+	List(context.Context) ([]*model.Namespace, error)
+	Show(context.Context, string) (*model.Namespace, error)
+	Create(context.Context, *model.Namespace) (*model.Namespace, error)
+	Update(context.Context, *model.Namespace) (*model.Namespace, error)
+	Delete(context.Context, string) error
+}
+
+// RepositoryStore provides the interface to access the stored repositories
+// and the permissions of teams on them.
+type RepositoryStore interface {
+	List(context.Context, string) ([]*model.Repository, error)
+	Show(context.Context, string, string) (*model.Repository, error)
+	Create(context.Context, *model.Repository) (*model.Repository, error)
+	Update(context.Context, *model.Repository) (*model.Repository, error)
+	Delete(context.Context, string) error
+	ListTeams(context.Context, string) ([]*model.RepositoryTeam, error)
+	PermitTeam(context.Context, string, string, string) error
+	RevokeTeam(context.Context, string, string) error
+}
+
+// TagStore provides the interface to access the tags of repositories,
+// saving a tag keeps the recorded pull statistics and overwrite flags.
+type TagStore interface {
+	List(context.Context, string) ([]*model.Tag, error)
+	Show(context.Context, string, string) (*model.Tag, error)
+	Save(context.Context, *model.Tag) (*model.Tag, error)
+	Pull(context.Context, string, string) error
+	Flag(context.Context, string, string) error
+	Delete(context.Context, string, string) error
+}
+
+// ManifestStore provides the interface to access the manifests of
+// repositories, deleting a manifest also deletes the tags pointing to it.
+type ManifestStore interface {
+	List(context.Context, string) ([]*model.Manifest, error)
+	Show(context.Context, string, string) (*model.Manifest, error)
+	Save(context.Context, *model.Manifest) (*model.Manifest, error)
+	Delete(context.Context, string, string) error
+}
+
+// RetentionStore provides the interface to access the retention policies of
+// namespaces and repositories together with the reports of their runs. The
+// policies are identified by the namespace and the optional repository.
+type RetentionStore interface {
+	List(context.Context) ([]*model.Retention, error)
+	Show(context.Context, string, string) (*model.Retention, error)
+	Save(context.Context, *model.Retention) (*model.Retention, error)
+	Delete(context.Context, string, string) error
+	ListReports(context.Context, string) ([]*model.Report, error)
+	CreateReport(context.Context, *model.Report) (*model.Report, error)
+}
+
+// ImmutableStore provides the interface to access the immutable tag rules
+// of namespaces.
+type ImmutableStore interface {
+	List(context.Context, string) ([]*model.Immutable, error)
+	Create(context.Context, *model.Immutable) (*model.Immutable, error)
+	Delete(context.Context, string, string) error
+}
+
+// QuotaStore provides the interface to access the storage quotas of
+// namespaces and teams, identified by the kind and the owner ID.
+type QuotaStore interface {
+	Show(context.Context, string, string) (*model.Quota, error)
+	Save(context.Context, *model.Quota) (*model.Quota, error)
+	Delete(context.Context, string, string) error
 }
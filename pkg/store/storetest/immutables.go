@@ -0,0 +1,94 @@
+package storetest
+
+import (
+	"testing"
+
+	"github.com/umschlag/umschlag-api/pkg/model"
+	"github.com/umschlag/umschlag-api/pkg/store"
+)
+
+var immutableCases = []testCase{
+	{"ImmutableCreate", testImmutableCreate},
+	{"ImmutableDelete", testImmutableDelete},
+	{"ImmutableCascade", testImmutableCascade},
+}
+
+func createImmutable(t *testing.T, s store.Store, namespace *model.Namespace, pattern string, exclude bool) *model.Immutable {
+	t.Helper()
+
+	immutable, err := s.Immutables().Create(ctx(), &model.Immutable{
+		NamespaceID: namespace.ID,
+		Pattern:     pattern,
+		Exclude:     exclude,
+	})
+
+	must(t, err)
+
+	return immutable
+}
+
+func testImmutableCreate(t *testing.T, s store.Store) {
+	namespace := createNamespace(t, s, "jane", createUser(t, s, "jane"))
+	other := createNamespace(t, s, "core", createTeam(t, s, "core"))
+
+	first := createImmutable(t, s, namespace, "v*", false)
+	createImmutable(t, s, namespace, "latest", true)
+	createImmutable(t, s, other, "v*", false)
+
+	if first.ID == "" || first.CreatedAt.IsZero() {
+		t.Fatalf("expected generated fields, got %+v", first)
+	}
+
+	_, err := s.Immutables().Create(ctx(), &model.Immutable{
+		NamespaceID: namespace.ID,
+		Pattern:     "v*",
+	})
+
+	expect(t, err, store.ErrDuplicatePattern)
+
+	_, err = s.Immutables().Create(ctx(), &model.Immutable{
+		NamespaceID: "00000000-0000-0000-0000-000000000000",
+		Pattern:     "v*",
+	})
+
+	expect(t, err, store.ErrNotFound)
+
+	records, err := s.Immutables().List(ctx(), namespace.ID)
+	must(t, err)
+
+	if len(records) != 2 || records[0].Pattern != "v*" || records[1].Pattern != "latest" || !records[1].Exclude {
+		t.Fatalf("expected rules sorted by creation, got %+v", records)
+	}
+}
+
+func testImmutableDelete(t *testing.T, s store.Store) {
+	namespace := createNamespace(t, s, "jane", createUser(t, s, "jane"))
+	other := createNamespace(t, s, "core", createTeam(t, s, "core"))
+
+	immutable := createImmutable(t, s, namespace, "v*", false)
+
+	expect(t, s.Immutables().Delete(ctx(), other.ID, immutable.ID), store.ErrNotFound)
+	must(t, s.Immutables().Delete(ctx(), namespace.ID, immutable.ID))
+	expect(t, s.Immutables().Delete(ctx(), namespace.ID, immutable.ID), store.ErrNotFound)
+
+	records, err := s.Immutables().List(ctx(), namespace.ID)
+	must(t, err)
+
+	if len(records) != 0 {
+		t.Fatalf("expected rule to be removed, got %+v", records)
+	}
+}
+
+func testImmutableCascade(t *testing.T, s store.Store) {
+	namespace := createNamespace(t, s, "jane", createUser(t, s, "jane"))
+	createImmutable(t, s, namespace, "v*", false)
+
+	must(t, s.Namespaces().Delete(ctx(), namespace.ID))
+
+	records, err := s.Immutables().List(ctx(), namespace.ID)
+	must(t, err)
+
+	if len(records) != 0 {
+		t.Fatalf("expected rules to be removed, got %+v", records)
+	}
+}
@@ -0,0 +1,138 @@
+package storetest
+
+import (
+	"testing"
+
+	"github.com/umschlag/umschlag-api/pkg/model"
+	"github.com/umschlag/umschlag-api/pkg/store"
+)
+
+var memberCases = []testCase{
+	{"MemberAssign", testMemberAssign},
+	{"MemberList", testMemberList},
+	{"MemberPermit", testMemberPermit},
+	{"MemberUnassign", testMemberUnassign},
+	{"MemberCascade", testMemberCascade},
+}
+
+func testMemberAssign(t *testing.T, s store.Store) {
+	team := createTeam(t, s, "core")
+	user := createUser(t, s, "jane")
+
+	must(t, s.Members().Assign(ctx(), team.ID, user.ID, model.PermUser))
+
+	expect(t, s.Members().Assign(ctx(), team.ID, user.ID, model.PermOwner), store.ErrAlreadyAssigned)
+	expect(t, s.Members().Assign(ctx(), "00000000-0000-0000-0000-000000000000", user.ID, model.PermUser), store.ErrNotFound)
+	expect(t, s.Members().Assign(ctx(), team.ID, "00000000-0000-0000-0000-000000000000", model.PermUser), store.ErrNotFound)
+}
+
+func testMemberList(t *testing.T, s store.Store) {
+	core := createTeam(t, s, "core")
+	web := createTeam(t, s, "web")
+	jane := createUser(t, s, "jane")
+	john := createUser(t, s, "john")
+
+	must(t, s.Members().Assign(ctx(), core.ID, jane.ID, model.PermOwner))
+	must(t, s.Members().Assign(ctx(), core.ID, john.ID, model.PermUser))
+	must(t, s.Members().Assign(ctx(), web.ID, jane.ID, model.PermAdmin))
+
+	byTeam, err := s.Members().ListByTeam(ctx(), core.ID)
+	must(t, err)
+
+	if len(byTeam) != 2 {
+		t.Fatalf("expected 2 team members, got %d", len(byTeam))
+	}
+
+	for _, member := range byTeam {
+		if member.TeamID != core.ID || member.User == nil || member.User.ID != member.UserID {
+			t.Fatalf("unexpected team member: %+v", member)
+		}
+	}
+
+	byUser, err := s.Members().ListByUser(ctx(), jane.ID)
+	must(t, err)
+
+	if len(byUser) != 2 {
+		t.Fatalf("expected 2 user teams, got %d", len(byUser))
+	}
+
+	perms := map[string]string{}
+
+	for _, member := range byUser {
+		if member.UserID != jane.ID || member.Team == nil || member.Team.ID != member.TeamID {
+			t.Fatalf("unexpected user team: %+v", member)
+		}
+
+		perms[member.Team.Slug] = member.Perm
+	}
+
+	if perms["core"] != model.PermOwner || perms["web"] != model.PermAdmin {
+		t.Fatalf("unexpected user perms: %v", perms)
+	}
+}
+
+func testMemberPermit(t *testing.T, s store.Store) {
+	team := createTeam(t, s, "core")
+	user := createUser(t, s, "jane")
+
+	expect(t, s.Members().Permit(ctx(), team.ID, user.ID, model.PermAdmin), store.ErrNotAssigned)
+
+	must(t, s.Members().Assign(ctx(), team.ID, user.ID, model.PermUser))
+	must(t, s.Members().Permit(ctx(), team.ID, user.ID, model.PermAdmin))
+	must(t, s.Members().Permit(ctx(), team.ID, user.ID, model.PermAdmin))
+
+	records, err := s.Members().ListByTeam(ctx(), team.ID)
+	must(t, err)
+
+	if len(records) != 1 || records[0].Perm != model.PermAdmin {
+		t.Fatalf("expected admin permission, got %+v", records)
+	}
+}
+
+func testMemberUnassign(t *testing.T, s store.Store) {
+	team := createTeam(t, s, "core")
+	user := createUser(t, s, "jane")
+
+	expect(t, s.Members().Unassign(ctx(), team.ID, user.ID), store.ErrNotAssigned)
+
+	must(t, s.Members().Assign(ctx(), team.ID, user.ID, model.PermUser))
+	must(t, s.Members().Unassign(ctx(), team.ID, user.ID))
+
+	records, err := s.Members().ListByUser(ctx(), user.ID)
+	must(t, err)
+
+	if len(records) != 0 {
+		t.Fatalf("expected no memberships, got %d", len(records))
+	}
+
+	must(t, s.Members().Assign(ctx(), team.ID, user.ID, model.PermUser))
+}
+
+func testMemberCascade(t *testing.T, s store.Store) {
+	core := createTeam(t, s, "core")
+	web := createTeam(t, s, "web")
+	jane := createUser(t, s, "jane")
+	john := createUser(t, s, "john")
+
+	must(t, s.Members().Assign(ctx(), core.ID, jane.ID, model.PermOwner))
+	must(t, s.Members().Assign(ctx(), core.ID, john.ID, model.PermUser))
+	must(t, s.Members().Assign(ctx(), web.ID, jane.ID, model.PermOwner))
+
+	must(t, s.Users().Delete(ctx(), john.ID))
+
+	records, err := s.Members().ListByTeam(ctx(), core.ID)
+	must(t, err)
+
+	if len(records) != 1 {
+		t.Fatalf("expected 1 remaining member, got %d", len(records))
+	}
+
+	must(t, s.Teams().Delete(ctx(), web.ID))
+
+	records, err = s.Members().ListByUser(ctx(), jane.ID)
+	must(t, err)
+
+	if len(records) != 1 || records[0].TeamID != core.ID {
+		t.Fatalf("expected only core membership, got %+v", records)
+	}
+}
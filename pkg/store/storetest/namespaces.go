@@ -0,0 +1,177 @@
+package storetest
+
+import (
+	"testing"
+
+	"github.com/umschlag/umschlag-api/pkg/model"
+	"github.com/umschlag/umschlag-api/pkg/store"
+)
+
+var namespaceCases = []testCase{
+	{"NamespaceCreate", testNamespaceCreate},
+	{"NamespaceShow", testNamespaceShow},
+	{"NamespaceList", testNamespaceList},
+	{"NamespaceUpdate", testNamespaceUpdate},
+	{"NamespaceDelete", testNamespaceDelete},
+	{"NamespaceCascade", testNamespaceCascade},
+}
+
+func createNamespace(t *testing.T, s store.Store, name string, owner interface{}) *model.Namespace {
+	t.Helper()
+
+	record := &model.Namespace{
+		Name: name,
+	}
+
+	switch o := owner.(type) {
+	case *model.User:
+		record.UserID = o.ID
+	case *model.Team:
+		record.TeamID = o.ID
+	}
+
+	namespace, err := s.Namespaces().Create(ctx(), record)
+
+	must(t, err)
+	return namespace
+}
+
+func testNamespaceCreate(t *testing.T, s store.Store) {
+	user := createUser(t, s, "jane")
+	team := createTeam(t, s, "core")
+
+	namespace := createNamespace(t, s, "jane", user)
+
+	if namespace.ID == "" || namespace.CreatedAt.IsZero() || namespace.UpdatedAt.IsZero() {
+		t.Fatalf("expected generated fields, got %+v", namespace)
+	}
+
+	_, err := s.Namespaces().Create(ctx(), &model.Namespace{
+		Name:   "jane",
+		TeamID: team.ID,
+	})
+
+	expect(t, err, store.ErrDuplicateName)
+
+	_, err = s.Namespaces().Create(ctx(), &model.Namespace{
+		Name: "orphan",
+	})
+
+	expect(t, err, store.ErrNotFound)
+
+	_, err = s.Namespaces().Create(ctx(), &model.Namespace{
+		Name:   "both",
+		UserID: user.ID,
+		TeamID: team.ID,
+	})
+
+	expect(t, err, store.ErrNotFound)
+
+	_, err = s.Namespaces().Create(ctx(), &model.Namespace{
+		Name:   "missing",
+		TeamID: "00000000-0000-0000-0000-000000000000",
+	})
+
+	expect(t, err, store.ErrNotFound)
+}
+
+func testNamespaceShow(t *testing.T, s store.Store) {
+	namespace := createNamespace(t, s, "core", createTeam(t, s, "core"))
+
+	byID, err := s.Namespaces().Show(ctx(), namespace.ID)
+	must(t, err)
+
+	if byID.Name != "core" || byID.TeamID == "" || byID.UserID != "" {
+		t.Fatalf("unexpected namespace by id: %+v", byID)
+	}
+
+	byName, err := s.Namespaces().Show(ctx(), "core")
+	must(t, err)
+
+	if byName.ID != namespace.ID {
+		t.Fatalf("expected namespace %s by name, got %s", namespace.ID, byName.ID)
+	}
+
+	_, err = s.Namespaces().Show(ctx(), "missing")
+	expect(t, err, store.ErrNotFound)
+}
+
+func testNamespaceList(t *testing.T, s store.Store) {
+	user := createUser(t, s, "jane")
+
+	createNamespace(t, s, "web", user)
+	createNamespace(t, s, "base", user)
+
+	records, err := s.Namespaces().List(ctx())
+	must(t, err)
+
+	if len(records) != 2 || records[0].Name != "base" || records[1].Name != "web" {
+		t.Fatalf("expected namespaces sorted by name, got %+v", records)
+	}
+}
+
+func testNamespaceUpdate(t *testing.T, s store.Store) {
+	user := createUser(t, s, "jane")
+	team := createTeam(t, s, "core")
+
+	namespace := createNamespace(t, s, "jane", user)
+	createNamespace(t, s, "core", team)
+
+	namespace.Name = "janes"
+	namespace.Description = "Personal images"
+
+	updated, err := s.Namespaces().Update(ctx(), namespace)
+	must(t, err)
+
+	if updated.Name != "janes" || updated.Description != "Personal images" {
+		t.Fatalf("unexpected updated namespace: %+v", updated)
+	}
+
+	if _, err := s.Namespaces().Show(ctx(), "jane"); err != store.ErrNotFound {
+		t.Fatalf("expected old name to be released, got %v", err)
+	}
+
+	namespace.Name = "core"
+	_, err = s.Namespaces().Update(ctx(), namespace)
+	expect(t, err, store.ErrDuplicateName)
+
+	namespace.Name = "janes"
+	namespace.UserID = ""
+	namespace.TeamID = team.ID
+
+	moved, err := s.Namespaces().Update(ctx(), namespace)
+	must(t, err)
+
+	if moved.TeamID != team.ID || moved.UserID != "" {
+		t.Fatalf("expected namespace to be owned by team, got %+v", moved)
+	}
+}
+
+func testNamespaceDelete(t *testing.T, s store.Store) {
+	namespace := createNamespace(t, s, "jane", createUser(t, s, "jane"))
+
+	must(t, s.Namespaces().Delete(ctx(), "jane"))
+
+	_, err := s.Namespaces().Show(ctx(), namespace.ID)
+	expect(t, err, store.ErrNotFound)
+
+	expect(t, s.Namespaces().Delete(ctx(), namespace.ID), store.ErrNotFound)
+}
+
+func testNamespaceCascade(t *testing.T, s store.Store) {
+	user := createUser(t, s, "jane")
+	team := createTeam(t, s, "core")
+
+	createNamespace(t, s, "jane", user)
+	createNamespace(t, s, "core", team)
+
+	must(t, s.Users().Delete(ctx(), user.ID))
+
+	_, err := s.Namespaces().Show(ctx(), "jane")
+	expect(t, err, store.ErrNotFound)
+
+	must(t, s.Teams().Delete(ctx(), team.ID))
+
+	_, err = s.Namespaces().Show(ctx(), "core")
+	expect(t, err, store.ErrNotFound)
+}
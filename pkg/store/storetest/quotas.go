@@ -0,0 +1,102 @@
+package storetest
+
+import (
+	"testing"
+
+	"github.com/umschlag/umschlag-api/pkg/model"
+	"github.com/umschlag/umschlag-api/pkg/store"
+)
+
+var quotaCases = []testCase{
+	{"QuotaSave", testQuotaSave},
+	{"QuotaDelete", testQuotaDelete},
+	{"QuotaCascade", testQuotaCascade},
+}
+
+func createQuota(t *testing.T, s store.Store, kind, ownerID string, hard, soft int64) *model.Quota {
+	t.Helper()
+
+	quota, err := s.Quotas().Save(ctx(), &model.Quota{
+		Kind:    kind,
+		OwnerID: ownerID,
+		Hard:    hard,
+		Soft:    soft,
+	})
+
+	must(t, err)
+
+	return quota
+}
+
+func testQuotaSave(t *testing.T, s store.Store) {
+	team := createTeam(t, s, "core")
+	namespace := createNamespace(t, s, "core", team)
+
+	quota := createQuota(t, s, model.QuotaNamespace, namespace.ID, 100, 80)
+
+	if quota.CreatedAt.IsZero() || quota.UpdatedAt.IsZero() {
+		t.Fatalf("expected generated fields, got %+v", quota)
+	}
+
+	updated := createQuota(t, s, model.QuotaNamespace, namespace.ID, 200, 0)
+
+	if !updated.CreatedAt.Equal(quota.CreatedAt) {
+		t.Fatalf("expected quota to be updated, got %+v", updated)
+	}
+
+	createQuota(t, s, model.QuotaTeam, team.ID, 500, 400)
+
+	record, err := s.Quotas().Show(ctx(), model.QuotaNamespace, namespace.ID)
+	must(t, err)
+
+	if record.Kind != model.QuotaNamespace || record.OwnerID != namespace.ID || record.Hard != 200 || record.Soft != 0 {
+		t.Fatalf("unexpected namespace quota: %+v", record)
+	}
+
+	record, err = s.Quotas().Show(ctx(), model.QuotaTeam, team.ID)
+	must(t, err)
+
+	if record.Kind != model.QuotaTeam || record.OwnerID != team.ID || record.Hard != 500 || record.Soft != 400 {
+		t.Fatalf("unexpected team quota: %+v", record)
+	}
+
+	_, err = s.Quotas().Save(ctx(), &model.Quota{
+		Kind:    model.QuotaTeam,
+		OwnerID: namespace.ID,
+		Hard:    100,
+	})
+
+	expect(t, err, store.ErrNotFound)
+
+	_, err = s.Quotas().Show(ctx(), model.QuotaTeam, namespace.ID)
+	expect(t, err, store.ErrNotFound)
+}
+
+func testQuotaDelete(t *testing.T, s store.Store) {
+	namespace := createNamespace(t, s, "jane", createUser(t, s, "jane"))
+	createQuota(t, s, model.QuotaNamespace, namespace.ID, 100, 0)
+
+	must(t, s.Quotas().Delete(ctx(), model.QuotaNamespace, namespace.ID))
+	expect(t, s.Quotas().Delete(ctx(), model.QuotaNamespace, namespace.ID), store.ErrNotFound)
+
+	_, err := s.Quotas().Show(ctx(), model.QuotaNamespace, namespace.ID)
+	expect(t, err, store.ErrNotFound)
+}
+
+func testQuotaCascade(t *testing.T, s store.Store) {
+	team := createTeam(t, s, "core")
+	namespace := createNamespace(t, s, "core", team)
+
+	createQuota(t, s, model.QuotaNamespace, namespace.ID, 100, 0)
+	createQuota(t, s, model.QuotaTeam, team.ID, 100, 0)
+
+	must(t, s.Namespaces().Delete(ctx(), namespace.ID))
+
+	_, err := s.Quotas().Show(ctx(), model.QuotaNamespace, namespace.ID)
+	expect(t, err, store.ErrNotFound)
+
+	must(t, s.Teams().Delete(ctx(), team.ID))
+
+	_, err = s.Quotas().Show(ctx(), model.QuotaTeam, team.ID)
+	expect(t, err, store.ErrNotFound)
+}
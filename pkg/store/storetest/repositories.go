@@ -0,0 +1,200 @@
+package storetest
+
+import (
+	"testing"
+
+	"github.com/umschlag/umschlag-api/pkg/model"
+	"github.com/umschlag/umschlag-api/pkg/store"
+)
+
+var repositoryCases = []testCase{
+	{"RepositoryCreate", testRepositoryCreate},
+	{"RepositoryShow", testRepositoryShow},
+	{"RepositoryList", testRepositoryList},
+	{"RepositoryUpdate", testRepositoryUpdate},
+	{"RepositoryDelete", testRepositoryDelete},
+	{"RepositoryTeams", testRepositoryTeams},
+	{"RepositoryCascade", testRepositoryCascade},
+}
+
+func createRepository(t *testing.T, s store.Store, namespace *model.Namespace, name string) *model.Repository {
+	t.Helper()
+
+	repository, err := s.Repositories().Create(ctx(), &model.Repository{
+		NamespaceID: namespace.ID,
+		Name:        name,
+	})
+
+	must(t, err)
+	return repository
+}
+
+func testRepositoryCreate(t *testing.T, s store.Store) {
+	namespace := createNamespace(t, s, "jane", createUser(t, s, "jane"))
+	repository := createRepository(t, s, namespace, "nginx")
+
+	if repository.ID == "" || repository.CreatedAt.IsZero() || repository.UpdatedAt.IsZero() {
+		t.Fatalf("expected generated fields, got %+v", repository)
+	}
+
+	if repository.Visibility != model.VisibilityPrivate {
+		t.Fatalf("expected private visibility by default, got %q", repository.Visibility)
+	}
+
+	_, err := s.Repositories().Create(ctx(), &model.Repository{
+		NamespaceID: namespace.ID,
+		Name:        "nginx",
+	})
+
+	expect(t, err, store.ErrDuplicateName)
+
+	_, err = s.Repositories().Create(ctx(), &model.Repository{
+		NamespaceID: "00000000-0000-0000-0000-000000000000",
+		Name:        "nginx",
+	})
+
+	expect(t, err, store.ErrNotFound)
+}
+
+func testRepositoryShow(t *testing.T, s store.Store) {
+	user := createUser(t, s, "jane")
+	jane := createNamespace(t, s, "jane", user)
+	other := createNamespace(t, s, "other", user)
+	repository := createRepository(t, s, jane, "nginx")
+
+	byID, err := s.Repositories().Show(ctx(), jane.ID, repository.ID)
+	must(t, err)
+
+	if byID.Name != "nginx" || byID.NamespaceID != jane.ID {
+		t.Fatalf("unexpected repository by id: %+v", byID)
+	}
+
+	byName, err := s.Repositories().Show(ctx(), jane.ID, "nginx")
+	must(t, err)
+
+	if byName.ID != repository.ID {
+		t.Fatalf("expected repository %s by name, got %s", repository.ID, byName.ID)
+	}
+
+	_, err = s.Repositories().Show(ctx(), other.ID, repository.ID)
+	expect(t, err, store.ErrNotFound)
+
+	_, err = s.Repositories().Show(ctx(), other.ID, "nginx")
+	expect(t, err, store.ErrNotFound)
+}
+
+func testRepositoryList(t *testing.T, s store.Store) {
+	user := createUser(t, s, "jane")
+	jane := createNamespace(t, s, "jane", user)
+	other := createNamespace(t, s, "other", user)
+
+	createRepository(t, s, jane, "redis")
+	createRepository(t, s, jane, "nginx")
+	createRepository(t, s, other, "alpine")
+
+	records, err := s.Repositories().List(ctx(), jane.ID)
+	must(t, err)
+
+	if len(records) != 2 || records[0].Name != "nginx" || records[1].Name != "redis" {
+		t.Fatalf("expected repositories sorted by name, got %+v", records)
+	}
+}
+
+func testRepositoryUpdate(t *testing.T, s store.Store) {
+	user := createUser(t, s, "jane")
+	jane := createNamespace(t, s, "jane", user)
+	other := createNamespace(t, s, "other", user)
+
+	repository := createRepository(t, s, jane, "nginx")
+	createRepository(t, s, jane, "redis")
+
+	repository.Name = "httpd"
+	repository.Visibility = model.VisibilityPublic
+	repository.NamespaceID = other.ID
+
+	updated, err := s.Repositories().Update(ctx(), repository)
+	must(t, err)
+
+	if updated.Name != "httpd" || !updated.Public() || updated.NamespaceID != jane.ID {
+		t.Fatalf("unexpected updated repository: %+v", updated)
+	}
+
+	if _, err := s.Repositories().Show(ctx(), jane.ID, "nginx"); err != store.ErrNotFound {
+		t.Fatalf("expected old name to be released, got %v", err)
+	}
+
+	repository.Name = "redis"
+	_, err = s.Repositories().Update(ctx(), repository)
+	expect(t, err, store.ErrDuplicateName)
+}
+
+func testRepositoryDelete(t *testing.T, s store.Store) {
+	namespace := createNamespace(t, s, "jane", createUser(t, s, "jane"))
+	repository := createRepository(t, s, namespace, "nginx")
+
+	must(t, s.Repositories().Delete(ctx(), repository.ID))
+
+	_, err := s.Repositories().Show(ctx(), namespace.ID, repository.ID)
+	expect(t, err, store.ErrNotFound)
+
+	expect(t, s.Repositories().Delete(ctx(), repository.ID), store.ErrNotFound)
+}
+
+func testRepositoryTeams(t *testing.T, s store.Store) {
+	namespace := createNamespace(t, s, "jane", createUser(t, s, "jane"))
+	repository := createRepository(t, s, namespace, "nginx")
+	ops := createTeam(t, s, "ops")
+	dev := createTeam(t, s, "dev")
+
+	must(t, s.Repositories().PermitTeam(ctx(), repository.ID, ops.ID, model.RepoPull))
+	must(t, s.Repositories().PermitTeam(ctx(), repository.ID, dev.ID, model.RepoPull))
+	must(t, s.Repositories().PermitTeam(ctx(), repository.ID, ops.ID, model.RepoPush))
+
+	records, err := s.Repositories().ListTeams(ctx(), repository.ID)
+	must(t, err)
+
+	if len(records) != 2 || records[0].Team.Name != "dev" || records[1].Team.Name != "ops" {
+		t.Fatalf("expected teams sorted by name, got %+v", records)
+	}
+
+	if records[0].Perm != model.RepoPull || records[1].Perm != model.RepoPush {
+		t.Fatalf("unexpected team permissions: %+v, %+v", records[0], records[1])
+	}
+
+	expect(t, s.Repositories().PermitTeam(ctx(), repository.ID, "00000000-0000-0000-0000-000000000000", model.RepoPull), store.ErrNotFound)
+	expect(t, s.Repositories().PermitTeam(ctx(), "00000000-0000-0000-0000-000000000000", ops.ID, model.RepoPull), store.ErrNotFound)
+
+	must(t, s.Repositories().RevokeTeam(ctx(), repository.ID, dev.ID))
+	expect(t, s.Repositories().RevokeTeam(ctx(), repository.ID, dev.ID), store.ErrNotAssigned)
+
+	records, err = s.Repositories().ListTeams(ctx(), repository.ID)
+	must(t, err)
+
+	if len(records) != 1 || records[0].TeamID != ops.ID {
+		t.Fatalf("expected only ops to remain, got %+v", records)
+	}
+}
+
+func testRepositoryCascade(t *testing.T, s store.Store) {
+	namespace := createNamespace(t, s, "jane", createUser(t, s, "jane"))
+	nginx := createRepository(t, s, namespace, "nginx")
+	redis := createRepository(t, s, namespace, "redis")
+	ops := createTeam(t, s, "ops")
+
+	must(t, s.Repositories().PermitTeam(ctx(), nginx.ID, ops.ID, model.RepoPush))
+	must(t, s.Teams().Delete(ctx(), ops.ID))
+
+	records, err := s.Repositories().ListTeams(ctx(), nginx.ID)
+	must(t, err)
+
+	if len(records) != 0 {
+		t.Fatalf("expected team permissions to be removed, got %+v", records)
+	}
+
+	must(t, s.Namespaces().Delete(ctx(), namespace.ID))
+
+	_, err = s.Repositories().Show(ctx(), namespace.ID, redis.ID)
+	expect(t, err, store.ErrNotFound)
+
+	expect(t, s.Repositories().Delete(ctx(), nginx.ID), store.ErrNotFound)
+}
@@ -0,0 +1,224 @@
+package storetest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/umschlag/umschlag-api/pkg/model"
+	"github.com/umschlag/umschlag-api/pkg/store"
+)
+
+var retentionCases = []testCase{
+	{"RetentionSave", testRetentionSave},
+	{"RetentionList", testRetentionList},
+	{"RetentionDelete", testRetentionDelete},
+	{"RetentionReports", testRetentionReports},
+	{"RetentionCascade", testRetentionCascade},
+}
+
+func createRetention(t *testing.T, s store.Store, namespace *model.Namespace, repository *model.Repository, keep int) *model.Retention {
+	t.Helper()
+
+	record := &model.Retention{
+		NamespaceID: namespace.ID,
+		KeepLast:    keep,
+	}
+
+	if repository != nil {
+		record.RepositoryID = repository.ID
+	}
+
+	retention, err := s.Retentions().Save(ctx(), record)
+	must(t, err)
+
+	return retention
+}
+
+func createReport(t *testing.T, s store.Store, retention *model.Retention, started time.Time) *model.Report {
+	t.Helper()
+
+	report, err := s.Retentions().CreateReport(ctx(), &model.Report{
+		RetentionID: retention.ID,
+		StartedAt:   started,
+		FinishedAt:  started.Add(time.Minute),
+		Entries: []*model.ReportEntry{
+			{
+				Repository: "jane/nginx",
+				Digest:     "sha256:one",
+				Tags:       []string{"v1", "v2"},
+				Action:     model.ReportDeleted,
+			},
+		},
+	})
+
+	must(t, err)
+
+	return report
+}
+
+func testRetentionSave(t *testing.T, s store.Store) {
+	namespace := createNamespace(t, s, "jane", createUser(t, s, "jane"))
+	repository := createRepository(t, s, namespace, "nginx")
+
+	retention := createRetention(t, s, namespace, nil, 5)
+
+	if retention.ID == "" || retention.CreatedAt.IsZero() || retention.UpdatedAt.IsZero() {
+		t.Fatalf("expected generated fields, got %+v", retention)
+	}
+
+	updated, err := s.Retentions().Save(ctx(), &model.Retention{
+		NamespaceID: namespace.ID,
+		KeepLast:    10,
+		KeepPattern: "^v",
+		OlderThan:   30,
+	})
+
+	must(t, err)
+
+	if updated.ID != retention.ID || !updated.CreatedAt.Equal(retention.CreatedAt) {
+		t.Fatalf("expected policy to be updated, got %+v", updated)
+	}
+
+	own := createRetention(t, s, namespace, repository, 3)
+
+	if own.ID == retention.ID {
+		t.Fatal("expected separate policy for the repository")
+	}
+
+	record, err := s.Retentions().Show(ctx(), namespace.ID, "")
+	must(t, err)
+
+	if record.KeepLast != 10 || record.KeepPattern != "^v" || record.OlderThan != 30 || record.RepositoryID != "" {
+		t.Fatalf("unexpected namespace policy: %+v", record)
+	}
+
+	record, err = s.Retentions().Show(ctx(), namespace.ID, repository.ID)
+	must(t, err)
+
+	if record.ID != own.ID || record.KeepLast != 3 {
+		t.Fatalf("unexpected repository policy: %+v", record)
+	}
+
+	other := createNamespace(t, s, "core", createTeam(t, s, "core"))
+
+	_, err = s.Retentions().Save(ctx(), &model.Retention{
+		NamespaceID:  other.ID,
+		RepositoryID: repository.ID,
+	})
+
+	expect(t, err, store.ErrNotFound)
+
+	_, err = s.Retentions().Save(ctx(), &model.Retention{
+		NamespaceID: "00000000-0000-0000-0000-000000000000",
+	})
+
+	expect(t, err, store.ErrNotFound)
+
+	_, err = s.Retentions().Show(ctx(), other.ID, "")
+	expect(t, err, store.ErrNotFound)
+}
+
+func testRetentionList(t *testing.T, s store.Store) {
+	namespace := createNamespace(t, s, "jane", createUser(t, s, "jane"))
+	repository := createRepository(t, s, namespace, "nginx")
+
+	createRetention(t, s, namespace, nil, 5)
+	createRetention(t, s, namespace, repository, 3)
+
+	records, err := s.Retentions().List(ctx())
+	must(t, err)
+
+	if len(records) != 2 || records[0].KeepLast != 5 || records[1].KeepLast != 3 {
+		t.Fatalf("expected policies sorted by creation, got %+v", records)
+	}
+}
+
+func testRetentionDelete(t *testing.T, s store.Store) {
+	namespace := createNamespace(t, s, "jane", createUser(t, s, "jane"))
+	repository := createRepository(t, s, namespace, "nginx")
+
+	retention := createRetention(t, s, namespace, nil, 5)
+	createRetention(t, s, namespace, repository, 3)
+	createReport(t, s, retention, time.Now().UTC())
+
+	must(t, s.Retentions().Delete(ctx(), namespace.ID, ""))
+	expect(t, s.Retentions().Delete(ctx(), namespace.ID, ""), store.ErrNotFound)
+
+	reports, err := s.Retentions().ListReports(ctx(), retention.ID)
+	must(t, err)
+
+	if len(reports) != 0 {
+		t.Fatalf("expected reports to be removed, got %+v", reports)
+	}
+
+	if _, err := s.Retentions().Show(ctx(), namespace.ID, repository.ID); err != nil {
+		t.Fatalf("expected repository policy to remain, got %v", err)
+	}
+}
+
+func testRetentionReports(t *testing.T, s store.Store) {
+	namespace := createNamespace(t, s, "jane", createUser(t, s, "jane"))
+	retention := createRetention(t, s, namespace, nil, 5)
+
+	started := time.Date(2019, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	first := createReport(t, s, retention, started)
+	second := createReport(t, s, retention, started.Add(time.Hour))
+
+	if first.ID == "" || first.ID == second.ID {
+		t.Fatalf("expected generated IDs, got %q and %q", first.ID, second.ID)
+	}
+
+	records, err := s.Retentions().ListReports(ctx(), retention.ID)
+	must(t, err)
+
+	if len(records) != 2 || records[0].ID != second.ID || records[1].ID != first.ID {
+		t.Fatalf("expected reports newest first, got %+v", records)
+	}
+
+	entry := records[1].Entries
+
+	if len(entry) != 1 || entry[0].Digest != "sha256:one" || len(entry[0].Tags) != 2 || entry[0].Action != model.ReportDeleted {
+		t.Fatalf("unexpected stored entries: %+v", entry)
+	}
+
+	if !records[1].StartedAt.Equal(started) || !records[1].FinishedAt.Equal(started.Add(time.Minute)) {
+		t.Fatalf("unexpected stored timestamps: %+v", records[1])
+	}
+
+	_, err = s.Retentions().CreateReport(ctx(), &model.Report{
+		RetentionID: "00000000-0000-0000-0000-000000000000",
+		StartedAt:   started,
+		FinishedAt:  started,
+	})
+
+	expect(t, err, store.ErrNotFound)
+}
+
+func testRetentionCascade(t *testing.T, s store.Store) {
+	namespace := createNamespace(t, s, "jane", createUser(t, s, "jane"))
+	nginx := createRepository(t, s, namespace, "nginx")
+	redis := createRepository(t, s, namespace, "redis")
+
+	createRetention(t, s, namespace, nil, 5)
+	createRetention(t, s, namespace, nginx, 3)
+	createRetention(t, s, namespace, redis, 3)
+
+	must(t, s.Repositories().Delete(ctx(), nginx.ID))
+
+	records, err := s.Retentions().List(ctx())
+	must(t, err)
+
+	if len(records) != 2 {
+		t.Fatalf("expected repository policy to be removed, got %+v", records)
+	}
+
+	must(t, s.Namespaces().Delete(ctx(), namespace.ID))
+
+	records, err = s.Retentions().List(ctx())
+	must(t, err)
+
+	if len(records) != 0 {
+		t.Fatalf("expected all policies to be removed, got %+v", records)
+	}
+}
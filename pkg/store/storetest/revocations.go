@@ -0,0 +1,143 @@
+package storetest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/umschlag/umschlag-api/pkg/model"
+	"github.com/umschlag/umschlag-api/pkg/store"
+)
+
+var revocationCases = []testCase{
+	{"RevocationRevoke", testRevocationRevoke},
+	{"RevocationBefore", testRevocationBefore},
+	{"RevocationPrune", testRevocationPrune},
+	{"RevocationCascade", testRevocationCascade},
+}
+
+func testRevocationRevoke(t *testing.T, s store.Store) {
+	user := createUser(t, s, "jane")
+	expires := time.Now().Add(time.Hour).UTC()
+
+	revoked, err := s.Revocations().Revoked(ctx(), "token-1")
+	must(t, err)
+
+	if revoked {
+		t.Fatalf("expected token to be valid")
+	}
+
+	must(t, s.Revocations().Revoke(ctx(), &model.Revocation{
+		TokenID:   "token-1",
+		UserID:    user.ID,
+		ExpiresAt: &expires,
+	}))
+
+	must(t, s.Revocations().Revoke(ctx(), &model.Revocation{
+		TokenID:   "token-1",
+		UserID:    user.ID,
+		ExpiresAt: &expires,
+	}))
+
+	revoked, err = s.Revocations().Revoked(ctx(), "token-1")
+	must(t, err)
+
+	if !revoked {
+		t.Fatalf("expected token to be revoked")
+	}
+
+	expect(t, s.Revocations().Revoke(ctx(), &model.Revocation{
+		TokenID: "token-2",
+		UserID:  "00000000-0000-0000-0000-000000000000",
+	}), store.ErrNotFound)
+}
+
+func testRevocationBefore(t *testing.T, s store.Store) {
+	user := createUser(t, s, "jane")
+
+	before, err := s.Revocations().RevokedBefore(ctx(), user.ID)
+	must(t, err)
+
+	if !before.IsZero() {
+		t.Fatalf("expected no revocation, got %v", before)
+	}
+
+	later := time.Now().UTC().Truncate(time.Second)
+	earlier := later.Add(-time.Hour)
+
+	must(t, s.Revocations().RevokeBefore(ctx(), user.ID, later))
+	must(t, s.Revocations().RevokeBefore(ctx(), user.ID, earlier))
+
+	before, err = s.Revocations().RevokedBefore(ctx(), user.ID)
+	must(t, err)
+
+	if !before.Equal(later) {
+		t.Fatalf("expected revocation at %v, got %v", later, before)
+	}
+
+	expect(t, s.Revocations().RevokeBefore(ctx(), "00000000-0000-0000-0000-000000000000", later), store.ErrNotFound)
+}
+
+func testRevocationPrune(t *testing.T, s store.Store) {
+	user := createUser(t, s, "jane")
+	now := time.Now().UTC()
+	expired := now.Add(-time.Hour)
+	valid := now.Add(time.Hour)
+
+	for id, expires := range map[string]*time.Time{
+		"expired":   &expired,
+		"valid":     &valid,
+		"unlimited": nil,
+	} {
+		must(t, s.Revocations().Revoke(ctx(), &model.Revocation{
+			TokenID:   id,
+			UserID:    user.ID,
+			ExpiresAt: expires,
+		}))
+	}
+
+	count, err := s.Revocations().Prune(ctx(), now)
+	must(t, err)
+
+	if count != 1 {
+		t.Fatalf("expected 1 pruned revocation, got %d", count)
+	}
+
+	for id, want := range map[string]bool{
+		"expired":   false,
+		"valid":     true,
+		"unlimited": true,
+	} {
+		revoked, err := s.Revocations().Revoked(ctx(), id)
+		must(t, err)
+
+		if revoked != want {
+			t.Fatalf("expected revocation of %s to be %v", id, want)
+		}
+	}
+}
+
+func testRevocationCascade(t *testing.T, s store.Store) {
+	user := createUser(t, s, "jane")
+
+	must(t, s.Revocations().Revoke(ctx(), &model.Revocation{
+		TokenID: "token-1",
+		UserID:  user.ID,
+	}))
+
+	must(t, s.Revocations().RevokeBefore(ctx(), user.ID, time.Now()))
+	must(t, s.Users().Delete(ctx(), user.ID))
+
+	revoked, err := s.Revocations().Revoked(ctx(), "token-1")
+	must(t, err)
+
+	if revoked {
+		t.Fatalf("expected revocation to be removed with the user")
+	}
+
+	before, err := s.Revocations().RevokedBefore(ctx(), user.ID)
+	must(t, err)
+
+	if !before.IsZero() {
+		t.Fatalf("expected no revocation for removed user, got %v", before)
+	}
+}
@@ -0,0 +1,65 @@
+// Package storetest provides a conformance suite that every implementation
+// of the store interface should pass to behave like the other drivers.
+package storetest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/umschlag/umschlag-api/pkg/store"
+)
+
+// Factory creates a fresh and empty store for every single test.
+type Factory func(t *testing.T) store.Store
+
+type testCase struct {
+	name string
+	fn   func(*testing.T, store.Store)
+}
+
+// Run executes the whole conformance suite against the factory.
+func Run(t *testing.T, factory Factory) {
+	cases := make([]testCase, 0)
+	cases = append(cases, userCases...)
+	cases = append(cases, teamCases...)
+	cases = append(cases, memberCases...)
+	cases = append(cases, tokenCases...)
+	cases = append(cases, revocationCases...)
+	cases = append(cases, namespaceCases...)
+	cases = append(cases, repositoryCases...)
+	cases = append(cases, tagCases...)
+	cases = append(cases, retentionCases...)
+	cases = append(cases, immutableCases...)
+	cases = append(cases, quotaCases...)
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			s := factory(t)
+			defer s.Close()
+
+			tc.fn(t, s)
+		})
+	}
+}
+
+func ctx() context.Context {
+	return context.Background()
+}
+
+func must(t *testing.T, err error) {
+	t.Helper()
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func expect(t *testing.T, got, want error) {
+	t.Helper()
+
+	if got != want {
+		t.Fatalf("expected error %v, got %v", want, got)
+	}
+}
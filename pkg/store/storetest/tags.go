@@ -0,0 +1,276 @@
+package storetest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/umschlag/umschlag-api/pkg/model"
+	"github.com/umschlag/umschlag-api/pkg/store"
+)
+
+var tagCases = []testCase{
+	{"TagSave", testTagSave},
+	{"TagList", testTagList},
+	{"TagPull", testTagPull},
+	{"TagFlag", testTagFlag},
+	{"TagDelete", testTagDelete},
+	{"ManifestSave", testManifestSave},
+	{"ManifestDelete", testManifestDelete},
+	{"ManifestCascade", testManifestCascade},
+}
+
+func createManifest(t *testing.T, s store.Store, repository *model.Repository, digest string, tags ...string) *model.Manifest {
+	t.Helper()
+
+	created := time.Date(2019, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	manifest, err := s.Manifests().Save(ctx(), &model.Manifest{
+		RepositoryID: repository.ID,
+		Digest:       digest,
+		MediaType:    "application/vnd.docker.distribution.manifest.v2+json",
+		Size:         30,
+		Blobs: []*model.Blob{
+			{Digest: "sha256:config", Size: 10},
+			{Digest: "sha256:layer", Size: 20},
+		},
+		CreatedAt: &created,
+	})
+
+	must(t, err)
+
+	for _, name := range tags {
+		_, err := s.Tags().Save(ctx(), &model.Tag{
+			RepositoryID: repository.ID,
+			Name:         name,
+			Digest:       digest,
+		})
+
+		must(t, err)
+	}
+
+	return manifest
+}
+
+func testTagSave(t *testing.T, s store.Store) {
+	namespace := createNamespace(t, s, "jane", createUser(t, s, "jane"))
+	repository := createRepository(t, s, namespace, "nginx")
+
+	tag, err := s.Tags().Save(ctx(), &model.Tag{
+		RepositoryID: repository.ID,
+		Name:         "latest",
+		Digest:       "sha256:one",
+	})
+
+	must(t, err)
+
+	if tag.UpdatedAt.IsZero() {
+		t.Fatal("expected generated timestamp")
+	}
+
+	if tag.CreatedAt.IsZero() {
+		t.Fatal("expected generated creation date")
+	}
+
+	time.Sleep(10 * time.Millisecond)
+
+	_, err = s.Tags().Save(ctx(), &model.Tag{
+		RepositoryID: repository.ID,
+		Name:         "latest",
+		Digest:       "sha256:two",
+	})
+
+	must(t, err)
+
+	record, err := s.Tags().Show(ctx(), repository.ID, "latest")
+	must(t, err)
+
+	if record.Digest != "sha256:two" {
+		t.Fatalf("expected updated digest, got %+v", record)
+	}
+
+	if !record.CreatedAt.Equal(tag.CreatedAt) || !record.UpdatedAt.After(tag.UpdatedAt) {
+		t.Fatalf("expected kept creation and new update date, got %+v", record)
+	}
+
+	_, err = s.Tags().Save(ctx(), &model.Tag{
+		RepositoryID: "00000000-0000-0000-0000-000000000000",
+		Name:         "latest",
+		Digest:       "sha256:one",
+	})
+
+	expect(t, err, store.ErrNotFound)
+
+	_, err = s.Tags().Show(ctx(), repository.ID, "missing")
+	expect(t, err, store.ErrNotFound)
+}
+
+func testTagList(t *testing.T, s store.Store) {
+	user := createUser(t, s, "jane")
+	namespace := createNamespace(t, s, "jane", user)
+	nginx := createRepository(t, s, namespace, "nginx")
+	redis := createRepository(t, s, namespace, "redis")
+
+	createManifest(t, s, nginx, "sha256:one", "v2", "latest", "v1")
+	createManifest(t, s, redis, "sha256:two", "edge")
+
+	records, err := s.Tags().List(ctx(), nginx.ID)
+	must(t, err)
+
+	if len(records) != 3 || records[0].Name != "latest" || records[1].Name != "v1" || records[2].Name != "v2" {
+		t.Fatalf("expected tags sorted by name, got %+v", records)
+	}
+}
+
+func testTagPull(t *testing.T, s store.Store) {
+	namespace := createNamespace(t, s, "jane", createUser(t, s, "jane"))
+	repository := createRepository(t, s, namespace, "nginx")
+
+	createManifest(t, s, repository, "sha256:one", "latest")
+
+	must(t, s.Tags().Pull(ctx(), repository.ID, "latest"))
+	must(t, s.Tags().Pull(ctx(), repository.ID, "latest"))
+
+	record, err := s.Tags().Show(ctx(), repository.ID, "latest")
+	must(t, err)
+
+	if record.Pulls != 2 || record.PulledAt == nil {
+		t.Fatalf("expected recorded pulls, got %+v", record)
+	}
+
+	_, err = s.Tags().Save(ctx(), &model.Tag{
+		RepositoryID: repository.ID,
+		Name:         "latest",
+		Digest:       "sha256:two",
+	})
+
+	must(t, err)
+
+	record, err = s.Tags().Show(ctx(), repository.ID, "latest")
+	must(t, err)
+
+	if record.Digest != "sha256:two" || record.Pulls != 2 {
+		t.Fatalf("expected pulls to survive updates, got %+v", record)
+	}
+
+	expect(t, s.Tags().Pull(ctx(), repository.ID, "missing"), store.ErrNotFound)
+	expect(t, s.Tags().Pull(ctx(), "00000000-0000-0000-0000-000000000000", "latest"), store.ErrNotFound)
+}
+
+func testTagFlag(t *testing.T, s store.Store) {
+	namespace := createNamespace(t, s, "jane", createUser(t, s, "jane"))
+	repository := createRepository(t, s, namespace, "nginx")
+
+	createManifest(t, s, repository, "sha256:one", "v1")
+
+	must(t, s.Tags().Flag(ctx(), repository.ID, "v1"))
+
+	_, err := s.Tags().Save(ctx(), &model.Tag{
+		RepositoryID: repository.ID,
+		Name:         "v1",
+		Digest:       "sha256:two",
+	})
+
+	must(t, err)
+
+	record, err := s.Tags().Show(ctx(), repository.ID, "v1")
+	must(t, err)
+
+	if record.Digest != "sha256:two" || record.OverwrittenAt == nil {
+		t.Fatalf("expected flag to survive updates, got %+v", record)
+	}
+
+	expect(t, s.Tags().Flag(ctx(), repository.ID, "missing"), store.ErrNotFound)
+}
+
+func testTagDelete(t *testing.T, s store.Store) {
+	namespace := createNamespace(t, s, "jane", createUser(t, s, "jane"))
+	repository := createRepository(t, s, namespace, "nginx")
+
+	createManifest(t, s, repository, "sha256:one", "latest")
+
+	must(t, s.Tags().Delete(ctx(), repository.ID, "latest"))
+	expect(t, s.Tags().Delete(ctx(), repository.ID, "latest"), store.ErrNotFound)
+
+	if _, err := s.Manifests().Show(ctx(), repository.ID, "sha256:one"); err != nil {
+		t.Fatalf("expected manifest to remain, got %v", err)
+	}
+}
+
+func testManifestSave(t *testing.T, s store.Store) {
+	namespace := createNamespace(t, s, "jane", createUser(t, s, "jane"))
+	repository := createRepository(t, s, namespace, "nginx")
+
+	manifest := createManifest(t, s, repository, "sha256:one")
+
+	if manifest.SyncedAt.IsZero() {
+		t.Fatal("expected generated timestamp")
+	}
+
+	record, err := s.Manifests().Show(ctx(), repository.ID, "sha256:one")
+	must(t, err)
+
+	if record.Size != 30 || len(record.Blobs) != 2 || record.Blobs[1].Digest != "sha256:layer" || record.Blobs[1].Size != 20 {
+		t.Fatalf("unexpected stored manifest: %+v", record)
+	}
+
+	if record.CreatedAt == nil || !record.CreatedAt.Equal(*manifest.CreatedAt) {
+		t.Fatalf("expected creation date %v, got %v", manifest.CreatedAt, record.CreatedAt)
+	}
+
+	record.Size = 50
+	record.CreatedAt = nil
+
+	_, err = s.Manifests().Save(ctx(), record)
+	must(t, err)
+
+	records, err := s.Manifests().List(ctx(), repository.ID)
+	must(t, err)
+
+	if len(records) != 1 || records[0].Size != 50 || records[0].CreatedAt != nil {
+		t.Fatalf("expected updated manifest, got %+v", records)
+	}
+
+	_, err = s.Manifests().Save(ctx(), &model.Manifest{
+		RepositoryID: "00000000-0000-0000-0000-000000000000",
+		Digest:       "sha256:one",
+	})
+
+	expect(t, err, store.ErrNotFound)
+}
+
+func testManifestDelete(t *testing.T, s store.Store) {
+	namespace := createNamespace(t, s, "jane", createUser(t, s, "jane"))
+	repository := createRepository(t, s, namespace, "nginx")
+
+	createManifest(t, s, repository, "sha256:one", "latest", "v1")
+	createManifest(t, s, repository, "sha256:two", "v2")
+
+	must(t, s.Manifests().Delete(ctx(), repository.ID, "sha256:one"))
+	expect(t, s.Manifests().Delete(ctx(), repository.ID, "sha256:one"), store.ErrNotFound)
+
+	records, err := s.Tags().List(ctx(), repository.ID)
+	must(t, err)
+
+	if len(records) != 1 || records[0].Name != "v2" {
+		t.Fatalf("expected only tags of other manifests to remain, got %+v", records)
+	}
+}
+
+func testManifestCascade(t *testing.T, s store.Store) {
+	namespace := createNamespace(t, s, "jane", createUser(t, s, "jane"))
+	repository := createRepository(t, s, namespace, "nginx")
+
+	createManifest(t, s, repository, "sha256:one", "latest")
+
+	must(t, s.Repositories().Delete(ctx(), repository.ID))
+
+	tags, err := s.Tags().List(ctx(), repository.ID)
+	must(t, err)
+
+	manifests, err := s.Manifests().List(ctx(), repository.ID)
+	must(t, err)
+
+	if len(tags) != 0 || len(manifests) != 0 {
+		t.Fatalf("expected tags and manifests to be removed, got %+v, %+v", tags, manifests)
+	}
+}
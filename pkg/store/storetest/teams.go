@@ -0,0 +1,123 @@
+package storetest
+
+import (
+	"testing"
+
+	"github.com/umschlag/umschlag-api/pkg/model"
+	"github.com/umschlag/umschlag-api/pkg/store"
+)
+
+var teamCases = []testCase{
+	{"TeamCreate", testTeamCreate},
+	{"TeamShow", testTeamShow},
+	{"TeamList", testTeamList},
+	{"TeamUpdate", testTeamUpdate},
+	{"TeamDelete", testTeamDelete},
+	{"TeamDuplicates", testTeamDuplicates},
+}
+
+func createTeam(t *testing.T, s store.Store, name string) *model.Team {
+	t.Helper()
+
+	team, err := s.Teams().Create(ctx(), &model.Team{
+		Name: name,
+	})
+
+	must(t, err)
+	return team
+}
+
+func testTeamCreate(t *testing.T, s store.Store) {
+	team := createTeam(t, s, "Core Team")
+
+	if team.ID == "" {
+		t.Fatal("expected generated id")
+	}
+
+	if team.Slug != "core-team" {
+		t.Fatalf("expected generated slug, got %q", team.Slug)
+	}
+
+	if team.CreatedAt.IsZero() || team.UpdatedAt.IsZero() {
+		t.Fatal("expected generated timestamps")
+	}
+}
+
+func testTeamShow(t *testing.T, s store.Store) {
+	team := createTeam(t, s, "core")
+
+	byID, err := s.Teams().Show(ctx(), team.ID)
+	must(t, err)
+
+	if byID.Name != "core" {
+		t.Fatalf("unexpected team by id: %+v", byID)
+	}
+
+	bySlug, err := s.Teams().Show(ctx(), team.Slug)
+	must(t, err)
+
+	if bySlug.ID != team.ID {
+		t.Fatalf("expected team %s by slug, got %s", team.ID, bySlug.ID)
+	}
+
+	_, err = s.Teams().Show(ctx(), "missing")
+	expect(t, err, store.ErrNotFound)
+}
+
+func testTeamList(t *testing.T, s store.Store) {
+	createTeam(t, s, "core")
+	createTeam(t, s, "web")
+
+	records, err := s.Teams().List(ctx())
+	must(t, err)
+
+	if len(records) != 2 {
+		t.Fatalf("expected 2 teams, got %d", len(records))
+	}
+}
+
+func testTeamUpdate(t *testing.T, s store.Store) {
+	team := createTeam(t, s, "core")
+
+	team.Name = "Platform"
+	team.Slug = ""
+	team.Avatar = "0123456789abcdef"
+
+	updated, err := s.Teams().Update(ctx(), team)
+	must(t, err)
+
+	if updated.Name != "Platform" || updated.Slug != "platform" || updated.Avatar != "0123456789abcdef" {
+		t.Fatalf("unexpected updated team: %+v", updated)
+	}
+
+	_, err = s.Teams().Show(ctx(), "core")
+	expect(t, err, store.ErrNotFound)
+
+	_, err = s.Teams().Update(ctx(), &model.Team{
+		ID:   "00000000-0000-0000-0000-000000000000",
+		Name: "missing",
+	})
+
+	expect(t, err, store.ErrNotFound)
+}
+
+func testTeamDelete(t *testing.T, s store.Store) {
+	team := createTeam(t, s, "core")
+
+	must(t, s.Teams().Delete(ctx(), team.ID))
+
+	_, err := s.Teams().Show(ctx(), team.Slug)
+	expect(t, err, store.ErrNotFound)
+
+	expect(t, s.Teams().Delete(ctx(), team.Slug), store.ErrNotFound)
+}
+
+func testTeamDuplicates(t *testing.T, s store.Store) {
+	createTeam(t, s, "core")
+
+	_, err := s.Teams().Create(ctx(), &model.Team{
+		Name: "Core",
+	})
+
+	expect(t, err, store.ErrDuplicateSlug)
+}
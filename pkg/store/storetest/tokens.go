@@ -0,0 +1,190 @@
+package storetest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/umschlag/umschlag-api/pkg/model"
+	"github.com/umschlag/umschlag-api/pkg/store"
+)
+
+var tokenCases = []testCase{
+	{"TokenCreate", testTokenCreate},
+	{"TokenList", testTokenList},
+	{"TokenByHash", testTokenByHash},
+	{"TokenTouch", testTokenTouch},
+	{"TokenDelete", testTokenDelete},
+	{"TokenCascade", testTokenCascade},
+}
+
+func testTokenCreate(t *testing.T, s store.Store) {
+	user := createUser(t, s, "jane")
+	expires := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+
+	record, err := s.Tokens().Create(ctx(), &model.AccessToken{
+		UserID:    user.ID,
+		Name:      "ci",
+		Hash:      "hash-ci",
+		Scopes:    []string{model.ScopeRegistryPull, model.ScopeAPIRead},
+		ExpiresAt: &expires,
+	})
+
+	must(t, err)
+
+	if record.ID == "" || record.CreatedAt.IsZero() {
+		t.Fatalf("expected generated fields, got %+v", record)
+	}
+
+	_, err = s.Tokens().Create(ctx(), &model.AccessToken{
+		UserID: user.ID,
+		Name:   "ci",
+		Hash:   "hash-other",
+	})
+
+	expect(t, err, store.ErrDuplicateName)
+
+	_, err = s.Tokens().Create(ctx(), &model.AccessToken{
+		UserID: "00000000-0000-0000-0000-000000000000",
+		Name:   "ci",
+		Hash:   "hash-missing",
+	})
+
+	expect(t, err, store.ErrNotFound)
+}
+
+func testTokenList(t *testing.T, s store.Store) {
+	jane := createUser(t, s, "jane")
+	john := createUser(t, s, "john")
+
+	for _, name := range []string{"web", "ci"} {
+		_, err := s.Tokens().Create(ctx(), &model.AccessToken{
+			UserID: jane.ID,
+			Name:   name,
+			Hash:   "hash-jane-" + name,
+		})
+
+		must(t, err)
+	}
+
+	_, err := s.Tokens().Create(ctx(), &model.AccessToken{
+		UserID: john.ID,
+		Name:   "ci",
+		Hash:   "hash-john-ci",
+	})
+
+	must(t, err)
+
+	records, err := s.Tokens().List(ctx(), jane.ID)
+	must(t, err)
+
+	if len(records) != 2 || records[0].Name != "ci" || records[1].Name != "web" {
+		t.Fatalf("expected sorted tokens of jane, got %+v", records)
+	}
+}
+
+func testTokenByHash(t *testing.T, s store.Store) {
+	user := createUser(t, s, "jane")
+	expires := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+
+	created, err := s.Tokens().Create(ctx(), &model.AccessToken{
+		UserID:    user.ID,
+		Name:      "ci",
+		Hash:      "hash-ci",
+		Scopes:    []string{model.ScopeRegistryPull, model.ScopeAPIRead},
+		ExpiresAt: &expires,
+	})
+
+	must(t, err)
+
+	record, err := s.Tokens().ByHash(ctx(), "hash-ci")
+	must(t, err)
+
+	if record.ID != created.ID || record.UserID != user.ID {
+		t.Fatalf("unexpected token: %+v", record)
+	}
+
+	if len(record.Scopes) != 2 || !record.HasScope(model.ScopeRegistryPull) || !record.HasScope(model.ScopeAPIRead) {
+		t.Fatalf("expected scopes to persist, got %v", record.Scopes)
+	}
+
+	if record.ExpiresAt == nil || !record.ExpiresAt.Equal(expires) {
+		t.Fatalf("expected expiry %v, got %v", expires, record.ExpiresAt)
+	}
+
+	if record.LastUsedAt != nil {
+		t.Fatalf("expected no last usage, got %v", record.LastUsedAt)
+	}
+
+	_, err = s.Tokens().ByHash(ctx(), "hash-missing")
+	expect(t, err, store.ErrNotFound)
+}
+
+func testTokenTouch(t *testing.T, s store.Store) {
+	user := createUser(t, s, "jane")
+
+	created, err := s.Tokens().Create(ctx(), &model.AccessToken{
+		UserID: user.ID,
+		Name:   "ci",
+		Hash:   "hash-ci",
+	})
+
+	must(t, err)
+
+	used := time.Now().UTC().Truncate(time.Second)
+	must(t, s.Tokens().Touch(ctx(), created.ID, used))
+
+	record, err := s.Tokens().ByHash(ctx(), "hash-ci")
+	must(t, err)
+
+	if record.LastUsedAt == nil || !record.LastUsedAt.Equal(used) {
+		t.Fatalf("expected last usage %v, got %v", used, record.LastUsedAt)
+	}
+
+	expect(t, s.Tokens().Touch(ctx(), "00000000-0000-0000-0000-000000000000", used), store.ErrNotFound)
+}
+
+func testTokenDelete(t *testing.T, s store.Store) {
+	jane := createUser(t, s, "jane")
+	john := createUser(t, s, "john")
+
+	created, err := s.Tokens().Create(ctx(), &model.AccessToken{
+		UserID: jane.ID,
+		Name:   "ci",
+		Hash:   "hash-ci",
+	})
+
+	must(t, err)
+
+	expect(t, s.Tokens().Delete(ctx(), john.ID, created.ID), store.ErrNotFound)
+	must(t, s.Tokens().Delete(ctx(), jane.ID, created.ID))
+	expect(t, s.Tokens().Delete(ctx(), jane.ID, created.ID), store.ErrNotFound)
+
+	_, err = s.Tokens().ByHash(ctx(), "hash-ci")
+	expect(t, err, store.ErrNotFound)
+}
+
+func testTokenCascade(t *testing.T, s store.Store) {
+	user := createUser(t, s, "jane")
+
+	_, err := s.Tokens().Create(ctx(), &model.AccessToken{
+		UserID: user.ID,
+		Name:   "ci",
+		Hash:   "hash-ci",
+	})
+
+	must(t, err)
+	must(t, s.Users().Delete(ctx(), user.ID))
+
+	_, err = s.Tokens().ByHash(ctx(), "hash-ci")
+	expect(t, err, store.ErrNotFound)
+
+	again := createUser(t, s, "jane")
+
+	_, err = s.Tokens().Create(ctx(), &model.AccessToken{
+		UserID: again.ID,
+		Name:   "ci",
+		Hash:   "hash-ci",
+	})
+
+	must(t, err)
+}
@@ -0,0 +1,204 @@
+package storetest
+
+import (
+	"testing"
+
+	"github.com/umschlag/umschlag-api/pkg/model"
+	"github.com/umschlag/umschlag-api/pkg/store"
+)
+
+var userCases = []testCase{
+	{"UserCreate", testUserCreate},
+	{"UserShow", testUserShow},
+	{"UserByUsername", testUserByUsername},
+	{"UserList", testUserList},
+	{"UserUpdate", testUserUpdate},
+	{"UserDelete", testUserDelete},
+	{"UserDuplicates", testUserDuplicates},
+}
+
+func createUser(t *testing.T, s store.Store, username string) *model.User {
+	t.Helper()
+
+	user, err := s.Users().Create(ctx(), &model.User{
+		Username: username,
+		Password: "secret",
+		Email:    username + "@example.com",
+		Active:   true,
+	})
+
+	must(t, err)
+	return user
+}
+
+func testUserCreate(t *testing.T, s store.Store) {
+	user := createUser(t, s, "Jane Doe")
+
+	if user.ID == "" {
+		t.Fatal("expected generated id")
+	}
+
+	if user.Slug != "jane-doe" {
+		t.Fatalf("expected generated slug, got %q", user.Slug)
+	}
+
+	if user.CreatedAt.IsZero() || user.UpdatedAt.IsZero() {
+		t.Fatal("expected generated timestamps")
+	}
+
+	custom, err := s.Users().Create(ctx(), &model.User{
+		Slug:     "custom",
+		Username: "john",
+		Email:    "john@example.com",
+	})
+
+	must(t, err)
+
+	if custom.Slug != "custom" {
+		t.Fatalf("expected provided slug, got %q", custom.Slug)
+	}
+}
+
+func testUserShow(t *testing.T, s store.Store) {
+	user := createUser(t, s, "jane")
+
+	byID, err := s.Users().Show(ctx(), user.ID)
+	must(t, err)
+
+	if byID.Username != "jane" || byID.Email != "jane@example.com" || !byID.Active {
+		t.Fatalf("unexpected user by id: %+v", byID)
+	}
+
+	bySlug, err := s.Users().Show(ctx(), user.Slug)
+	must(t, err)
+
+	if bySlug.ID != user.ID {
+		t.Fatalf("expected user %s by slug, got %s", user.ID, bySlug.ID)
+	}
+
+	_, err = s.Users().Show(ctx(), "missing")
+	expect(t, err, store.ErrNotFound)
+
+	_, err = s.Users().Show(ctx(), "00000000-0000-0000-0000-000000000000")
+	expect(t, err, store.ErrNotFound)
+}
+
+func testUserByUsername(t *testing.T, s store.Store) {
+	user := createUser(t, s, "Jane")
+
+	found, err := s.Users().ByUsername(ctx(), "jANE")
+	must(t, err)
+
+	if found.ID != user.ID {
+		t.Fatalf("expected user %s, got %s", user.ID, found.ID)
+	}
+
+	_, err = s.Users().ByUsername(ctx(), "john")
+	expect(t, err, store.ErrNotFound)
+}
+
+func testUserList(t *testing.T, s store.Store) {
+	records, err := s.Users().List(ctx())
+	must(t, err)
+
+	if len(records) != 0 {
+		t.Fatalf("expected empty list, got %d", len(records))
+	}
+
+	createUser(t, s, "jane")
+	createUser(t, s, "john")
+
+	records, err = s.Users().List(ctx())
+	must(t, err)
+
+	if len(records) != 2 {
+		t.Fatalf("expected 2 users, got %d", len(records))
+	}
+}
+
+func testUserUpdate(t *testing.T, s store.Store) {
+	user := createUser(t, s, "jane")
+
+	user.Username = "janet"
+	user.Slug = "janet"
+	user.Email = "janet@example.com"
+	user.Admin = true
+	user.Avatar = "0123456789abcdef"
+
+	updated, err := s.Users().Update(ctx(), user)
+	must(t, err)
+
+	if updated.Username != "janet" || updated.Email != "janet@example.com" || !updated.Admin || updated.Avatar != "0123456789abcdef" {
+		t.Fatalf("unexpected updated user: %+v", updated)
+	}
+
+	if !updated.CreatedAt.Equal(user.CreatedAt) {
+		t.Fatalf("expected created at %s, got %s", user.CreatedAt, updated.CreatedAt)
+	}
+
+	if _, err := s.Users().Show(ctx(), "janet"); err != nil {
+		t.Fatalf("expected user by new slug: %v", err)
+	}
+
+	_, err = s.Users().Show(ctx(), "jane")
+	expect(t, err, store.ErrNotFound)
+
+	if _, err := s.Users().ByUsername(ctx(), "janet"); err != nil {
+		t.Fatalf("expected user by new username: %v", err)
+	}
+
+	_, err = s.Users().Update(ctx(), &model.User{
+		ID:       "00000000-0000-0000-0000-000000000000",
+		Username: "missing",
+		Email:    "missing@example.com",
+	})
+
+	expect(t, err, store.ErrNotFound)
+}
+
+func testUserDelete(t *testing.T, s store.Store) {
+	user := createUser(t, s, "jane")
+
+	must(t, s.Users().Delete(ctx(), user.Slug))
+
+	_, err := s.Users().Show(ctx(), user.ID)
+	expect(t, err, store.ErrNotFound)
+
+	expect(t, s.Users().Delete(ctx(), user.ID), store.ErrNotFound)
+
+	createUser(t, s, "jane")
+}
+
+func testUserDuplicates(t *testing.T, s store.Store) {
+	user := createUser(t, s, "jane")
+
+	_, err := s.Users().Create(ctx(), &model.User{
+		Slug:     "other",
+		Username: "JANE",
+		Email:    "other@example.com",
+	})
+
+	expect(t, err, store.ErrDuplicateUsername)
+
+	_, err = s.Users().Create(ctx(), &model.User{
+		Slug:     "other",
+		Username: "other",
+		Email:    "JANE@example.com",
+	})
+
+	expect(t, err, store.ErrDuplicateEmail)
+
+	_, err = s.Users().Create(ctx(), &model.User{
+		Slug:     user.Slug,
+		Username: "other",
+		Email:    "other@example.com",
+	})
+
+	expect(t, err, store.ErrDuplicateSlug)
+
+	other := createUser(t, s, "john")
+	other.Username = "jane"
+
+	_, err = s.Users().Update(ctx(), other)
+	expect(t, err, store.ErrDuplicateUsername)
+}
@@ -0,0 +1,67 @@
+package token
+
+import (
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"encoding/base64"
+	"math/big"
+)
+
+// JWK represents a single public key as JSON Web Key.
+type JWK struct {
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+// JWKS represents a set of public keys as JSON Web Key Set.
+type JWKS struct {
+	Keys []*JWK `json:"keys"`
+}
+
+// JWK converts the public part of the key, it returns nil for secrets.
+func (k *Key) JWK() *JWK {
+	switch public := k.public.(type) {
+	case *rsa.PublicKey:
+		return &JWK{
+			Kty: "RSA",
+			Use: "sig",
+			Kid: k.ID,
+			Alg: k.Method.Alg(),
+			N:   encodeBigInt(public.N, 0),
+			E:   encodeBigInt(big.NewInt(int64(public.E)), 0),
+		}
+	case *ecdsa.PublicKey:
+		size := (public.Curve.Params().BitSize + 7) / 8
+
+		return &JWK{
+			Kty: "EC",
+			Use: "sig",
+			Kid: k.ID,
+			Alg: k.Method.Alg(),
+			Crv: public.Curve.Params().Name,
+			X:   encodeBigInt(public.X, size),
+			Y:   encodeBigInt(public.Y, size),
+		}
+	}
+
+	return nil
+}
+
+func encodeBigInt(val *big.Int, size int) string {
+	raw := val.Bytes()
+
+	if len(raw) < size {
+		padded := make([]byte, size)
+		copy(padded[size-len(raw):], raw)
+		raw = padded
+	}
+
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
@@ -0,0 +1,291 @@
+package token
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base32"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/pkg/errors"
+)
+
+var (
+	// ErrInvalidKey is returned when a key could not be parsed.
+	ErrInvalidKey = errors.New("invalid or unsupported key")
+
+	// ErrUnknownKey is returned when no key matches the token.
+	ErrUnknownKey = errors.New("unknown signing key")
+
+	// ErrUnsupportedAlgorithm is returned for unknown key algorithms.
+	ErrUnsupportedAlgorithm = errors.New("unsupported key algorithm")
+)
+
+// Key represents a key to sign and verify tokens.
+type Key struct {
+	ID     string
+	Method jwt.SigningMethod
+	signer interface{}
+	public crypto.PublicKey
+}
+
+// Public returns the public key, it's nil for shared secrets.
+func (k *Key) Public() crypto.PublicKey {
+	return k.public
+}
+
+// Sign signs the claims and attaches the key ID to the header.
+func (k *Key) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(k.Method, claims)
+	token.Header["kid"] = k.ID
+
+	return token.SignedString(k.signer)
+}
+
+// verifier returns the key material used to verify signatures.
+func (k *Key) verifier() interface{} {
+	if k.public != nil {
+		return k.public
+	}
+
+	return k.signer
+}
+
+// NewSecretKey initializes a HMAC key from a base32 encoded secret.
+func NewSecretKey(secret string) (*Key, error) {
+	raw, err := base32.StdEncoding.DecodeString(secret)
+
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode secret")
+	}
+
+	if len(raw) == 0 {
+		return nil, ErrInvalidKey
+	}
+
+	sum := sha256.Sum256(raw)
+
+	return &Key{
+		ID:     encodeKeyID(sum[:30]),
+		Method: jwt.SigningMethodHS256,
+		signer: raw,
+	}, nil
+}
+
+// NewKey initializes a key from a RSA or ECDSA private key.
+func NewKey(private crypto.PrivateKey) (*Key, error) {
+	var (
+		method jwt.SigningMethod
+		public crypto.PublicKey
+	)
+
+	switch k := private.(type) {
+	case *rsa.PrivateKey:
+		method = jwt.SigningMethodRS256
+		public = k.Public()
+	case *ecdsa.PrivateKey:
+		switch k.Curve {
+		case elliptic.P256():
+			method = jwt.SigningMethodES256
+		case elliptic.P384():
+			method = jwt.SigningMethodES384
+		case elliptic.P521():
+			method = jwt.SigningMethodES512
+		default:
+			return nil, ErrInvalidKey
+		}
+
+		public = k.Public()
+	default:
+		return nil, ErrInvalidKey
+	}
+
+	id, err := KeyID(public)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &Key{
+		ID:     id,
+		Method: method,
+		signer: private,
+		public: public,
+	}, nil
+}
+
+// ParseKey parses a PEM encoded RSA or ECDSA private key.
+func ParseKey(content []byte) (*Key, error) {
+	for {
+		block, rest := pem.Decode(content)
+
+		if block == nil {
+			return nil, ErrInvalidKey
+		}
+
+		switch block.Type {
+		case "RSA PRIVATE KEY":
+			private, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to parse rsa key")
+			}
+
+			return NewKey(private)
+		case "EC PRIVATE KEY":
+			private, err := x509.ParseECPrivateKey(block.Bytes)
+
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to parse ecdsa key")
+			}
+
+			return NewKey(private)
+		case "PRIVATE KEY":
+			private, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to parse pkcs8 key")
+			}
+
+			return NewKey(private)
+		}
+
+		content = rest
+	}
+}
+
+// LoadKey reads a PEM encoded private key from the filesystem.
+func LoadKey(path string) (*Key, error) {
+	content, err := ioutil.ReadFile(path)
+
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read key")
+	}
+
+	return ParseKey(content)
+}
+
+// KeyID generates the key identifier like docker distribution expects it,
+// which is a base32 encoded and truncated fingerprint of the public key.
+func KeyID(public crypto.PublicKey) (string, error) {
+	der, err := x509.MarshalPKIXPublicKey(public)
+
+	if err != nil {
+		return "", errors.Wrap(err, "failed to marshal public key")
+	}
+
+	sum := sha256.Sum256(der)
+	return encodeKeyID(sum[:30]), nil
+}
+
+func encodeKeyID(raw []byte) string {
+	val := strings.TrimRight(base32.StdEncoding.EncodeToString(raw), "=")
+
+	var (
+		buf bytes.Buffer
+		i   int
+	)
+
+	for i = 0; i < len(val)/4-1; i++ {
+		buf.WriteString(val[i*4 : i*4+4])
+		buf.WriteString(":")
+	}
+
+	buf.WriteString(val[i*4:])
+
+	return buf.String()
+}
+
+// GenerateKey generates a new private key for the requested algorithm.
+func GenerateKey(alg string) (*Key, error) {
+	var (
+		private crypto.PrivateKey
+		err     error
+	)
+
+	switch alg {
+	case jwt.SigningMethodRS256.Alg():
+		private, err = rsa.GenerateKey(rand.Reader, 2048)
+	case jwt.SigningMethodES256.Alg():
+		private, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case jwt.SigningMethodES384.Alg():
+		private, err = ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	case jwt.SigningMethodES512.Alg():
+		private, err = ecdsa.GenerateKey(elliptic.P521(), rand.Reader)
+	default:
+		return nil, ErrUnsupportedAlgorithm
+	}
+
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to generate key")
+	}
+
+	return NewKey(private)
+}
+
+// MarshalKey encodes the private key as PEM, secrets can't be encoded.
+func MarshalKey(key *Key) ([]byte, error) {
+	if key.public == nil {
+		return nil, ErrInvalidKey
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(key.signer)
+
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal key")
+	}
+
+	return pem.EncodeToMemory(&pem.Block{
+		Type:  "PRIVATE KEY",
+		Bytes: der,
+	}), nil
+}
+
+// Certificate generates a self-signed certificate for the key, it can be
+// used within the root certificate bundle of docker distribution.
+func Certificate(key *Key, name string, lifetime time.Duration) ([]byte, error) {
+	if key.public == nil {
+		return nil, ErrInvalidKey
+	}
+
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to generate serial")
+	}
+
+	now := time.Now()
+
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject: pkix.Name{
+			CommonName: name,
+		},
+		NotBefore:             now.Add(-time.Minute),
+		NotAfter:              now.Add(lifetime),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.public, key.signer)
+
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create certificate")
+	}
+
+	return pem.EncodeToMemory(&pem.Block{
+		Type:  "CERTIFICATE",
+		Bytes: der,
+	}), nil
+}
@@ -0,0 +1,223 @@
+package token
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	activeName   = "active"
+	stagedName   = "staged"
+	retiredName  = "retired-"
+	keyExt       = ".pem"
+	certExt      = ".crt"
+	certName     = "umschlag"
+	certLifetime = 10 * 365 * 24 * time.Hour
+)
+
+var (
+	// ErrMissingKey is returned when the directory has no active key.
+	ErrMissingKey = errors.New("missing active signing key")
+)
+
+// KeyDir manages the signing keys stored within a directory. The active
+// key signs new tokens, the staged key is already published to verifiers
+// and gets activated by the next rotation, retired keys are kept to verify
+// tokens that have been signed before a rotation.
+type KeyDir struct {
+	Path      string
+	Algorithm string
+	Retain    int
+}
+
+// Load reads all keys from the directory into a key set.
+func (d *KeyDir) Load() (*KeySet, error) {
+	active, err := LoadKey(d.file(activeName, keyExt))
+
+	if os.IsNotExist(errors.Cause(err)) {
+		return nil, ErrMissingKey
+	}
+
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to load active key")
+	}
+
+	set := NewKeySet(active)
+
+	names, err := d.retired()
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, name := range append([]string{stagedName}, names...) {
+		key, err := LoadKey(d.file(name, keyExt))
+
+		if os.IsNotExist(errors.Cause(err)) {
+			continue
+		}
+
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to load %s key", name)
+		}
+
+		set.Add(key)
+	}
+
+	return set, nil
+}
+
+// Rotate retires the active key, activates the staged key and stages a
+// freshly generated key. Retired keys exceeding the retention get removed.
+func (d *KeyDir) Rotate() error {
+	if err := os.MkdirAll(d.Path, 0700); err != nil {
+		return errors.Wrap(err, "failed to create key directory")
+	}
+
+	if d.exists(activeName) {
+		if err := d.move(activeName, fmt.Sprintf("%s%d", retiredName, time.Now().UnixNano())); err != nil {
+			return err
+		}
+	}
+
+	if d.exists(stagedName) {
+		if err := d.move(stagedName, activeName); err != nil {
+			return err
+		}
+	} else {
+		if err := d.generate(activeName); err != nil {
+			return err
+		}
+	}
+
+	if err := d.generate(stagedName); err != nil {
+		return err
+	}
+
+	return d.prune()
+}
+
+// Bundle concatenates the certificates of all keys, the result can be
+// used as root certificate bundle for docker distribution.
+func (d *KeyDir) Bundle() ([]byte, error) {
+	names, err := d.retired()
+
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+
+	for _, name := range append([]string{activeName, stagedName}, names...) {
+		content, err := ioutil.ReadFile(d.file(name, certExt))
+
+		if os.IsNotExist(err) {
+			continue
+		}
+
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read %s certificate", name)
+		}
+
+		buf.Write(content)
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (d *KeyDir) generate(name string) error {
+	key, err := GenerateKey(d.Algorithm)
+
+	if err != nil {
+		return err
+	}
+
+	content, err := MarshalKey(key)
+
+	if err != nil {
+		return err
+	}
+
+	if err := ioutil.WriteFile(d.file(name, keyExt), content, 0600); err != nil {
+		return errors.Wrapf(err, "failed to write %s key", name)
+	}
+
+	cert, err := Certificate(key, certName, certLifetime)
+
+	if err != nil {
+		return err
+	}
+
+	if err := ioutil.WriteFile(d.file(name, certExt), cert, 0644); err != nil {
+		return errors.Wrapf(err, "failed to write %s certificate", name)
+	}
+
+	return nil
+}
+
+func (d *KeyDir) move(from, to string) error {
+	for _, ext := range []string{keyExt, certExt} {
+		if err := os.Rename(d.file(from, ext), d.file(to, ext)); err != nil && !os.IsNotExist(err) {
+			return errors.Wrapf(err, "failed to move %s key", from)
+		}
+	}
+
+	return nil
+}
+
+func (d *KeyDir) prune() error {
+	names, err := d.retired()
+
+	if err != nil {
+		return err
+	}
+
+	if len(names) <= d.Retain {
+		return nil
+	}
+
+	for _, name := range names[d.Retain:] {
+		for _, ext := range []string{keyExt, certExt} {
+			if err := os.Remove(d.file(name, ext)); err != nil && !os.IsNotExist(err) {
+				return errors.Wrapf(err, "failed to remove %s key", name)
+			}
+		}
+	}
+
+	return nil
+}
+
+// retired lists the names of retired keys, newest first.
+func (d *KeyDir) retired() ([]string, error) {
+	matches, err := filepath.Glob(d.file(retiredName+"*", keyExt))
+
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list retired keys")
+	}
+
+	result := make([]string, 0, len(matches))
+
+	for _, match := range matches {
+		result = append(result, strings.TrimSuffix(filepath.Base(match), keyExt))
+	}
+
+	sort.Sort(sort.Reverse(sort.StringSlice(result)))
+	return result, nil
+}
+
+func (d *KeyDir) exists(name string) bool {
+	_, err := os.Stat(d.file(name, keyExt))
+	return err == nil
+}
+
+func (d *KeyDir) file(name, ext string) string {
+	return filepath.Join(d.Path, name+ext)
+}
@@ -0,0 +1,113 @@
+package token
+
+import (
+	"sort"
+	"sync"
+)
+
+// KeySet holds the active signing key and further keys that are only used
+// to verify tokens, like staged or retired keys.
+type KeySet struct {
+	mu     sync.RWMutex
+	active *Key
+	keys   map[string]*Key
+}
+
+// Add appends keys to the set, keys with the same ID get replaced.
+func (s *KeySet) Add(keys ...*Key) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, key := range keys {
+		s.keys[key.ID] = key
+	}
+}
+
+// Activate adds the key to the set and uses it to sign new tokens.
+func (s *KeySet) Activate(key *Key) {
+	s.Add(key)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.active = key
+}
+
+// Active returns the key used to sign new tokens.
+func (s *KeySet) Active() *Key {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.active
+}
+
+// Keys returns all keys within the set sorted by ID.
+func (s *KeySet) Keys() []*Key {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := make([]*Key, 0, len(s.keys))
+
+	for _, key := range s.keys {
+		result = append(result, key)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].ID < result[j].ID
+	})
+
+	return result
+}
+
+// JWKS returns the public keys of the set, secrets are never included.
+func (s *KeySet) JWKS() *JWKS {
+	result := &JWKS{
+		Keys: make([]*JWK, 0),
+	}
+
+	for _, key := range s.Keys() {
+		if jwk := key.JWK(); jwk != nil {
+			result.Keys = append(result.Keys, jwk)
+		}
+	}
+
+	return result
+}
+
+// Lookup returns the key matching the key ID of the token, it can be used
+// as a KeyFunc. Tokens without a key ID are only accepted if the set
+// contains a single key.
+func (s *KeySet) Lookup(t *Token) (*Key, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if t.KeyID == "" {
+		if len(s.keys) == 1 {
+			for _, key := range s.keys {
+				return key, nil
+			}
+		}
+
+		return nil, ErrUnknownKey
+	}
+
+	if key, ok := s.keys[t.KeyID]; ok {
+		return key, nil
+	}
+
+	return nil, ErrUnknownKey
+}
+
+// NewKeySet initializes a new key set, the first key gets activated.
+func NewKeySet(keys ...*Key) *KeySet {
+	s := &KeySet{
+		keys: make(map[string]*Key),
+	}
+
+	if len(keys) > 0 {
+		s.Activate(keys[0])
+	}
+
+	s.Add(keys...)
+	return s
+}
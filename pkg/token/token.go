@@ -1,12 +1,14 @@
 package token
 
 import (
-	"encoding/base32"
+	"encoding/json"
+	"math"
 	"net/http"
 	"time"
 
 	"github.com/dgrijalva/jwt-go"
 	"github.com/dgrijalva/jwt-go/request"
+	"github.com/google/uuid"
 )
 
 const (
@@ -15,13 +17,10 @@ const (
 
 	// SessToken is the kind of token to represent a session token.
 	SessToken = "sess"
-
-	// SignerAlgo is the default algorithm used to sign JWT tokens.
-	SignerAlgo = "HS256"
 )
 
-// SecretFunc is a helper function to retrieve the used JWT secret.
-type SecretFunc func(*Token) ([]byte, error)
+// KeyFunc is a helper function to retrieve the key to verify a token.
+type KeyFunc func(*Token) (*Key, error)
 
 // Result represents to token to the outer world for HTTP responses.
 type Result struct {
@@ -29,31 +28,44 @@ type Result struct {
 	Expire string `json:"expire,omitempty"`
 }
 
-// Token is internally used to differ between the kinds of tokens.
+// Token is internally used to differ between the kinds of tokens. The ID
+// and the issue date are missing for tokens signed by older versions.
 type Token struct {
-	Kind string
-	Text string
+	Kind     string
+	Text     string
+	KeyID    string
+	ID       string
+	IssuedAt time.Time
+	Expire   time.Time
 }
 
 // SignUnlimited signs a token the never expires.
-func (t *Token) SignUnlimited(secret string) (*Result, error) {
-	return t.SignExpiring(secret, 0)
+func (t *Token) SignUnlimited(key *Key) (*Result, error) {
+	return t.SignExpiring(key, 0)
 }
 
-// SignExpiring signs a token that maybe expires.
-func (t *Token) SignExpiring(secret string, exp time.Duration) (*Result, error) {
-	token := jwt.New(jwt.SigningMethodHS256)
-	claims := token.Claims.(jwt.MapClaims)
+// SignExpiring signs a token that maybe expires, every signed token gets a
+// unique ID to be able to revoke it. The issue date keeps milliseconds to
+// differ between tokens issued right before and after a revocation.
+func (t *Token) SignExpiring(key *Key, exp time.Duration) (*Result, error) {
+	now := time.Now().Truncate(time.Millisecond)
 
-	claims["type"] = t.Kind
-	claims["text"] = t.Text
+	t.ID = uuid.New().String()
+	t.IssuedAt = now
 
-	signingKey, _ := base32.StdEncoding.DecodeString(secret)
-	tokenString, err := token.SignedString(signingKey)
+	claims := jwt.MapClaims{
+		"type": t.Kind,
+		"text": t.Text,
+		"jti":  t.ID,
+		"iat":  float64(now.UnixNano()/int64(time.Millisecond)) / 1000,
+	}
 
 	if exp > 0 {
-		expire := time.Now().Add(exp)
+		expire := now.Add(exp).Truncate(time.Second)
 		claims["exp"] = expire.Unix()
+		t.Expire = expire
+
+		tokenString, err := key.Sign(claims)
 
 		return &Result{
 			Token:  tokenString,
@@ -61,6 +73,8 @@ func (t *Token) SignExpiring(secret string, exp time.Duration) (*Result, error)
 		}, err
 	}
 
+	tokenString, err := key.Sign(claims)
+
 	return &Result{
 		Token: tokenString,
 	}, err
@@ -74,29 +88,24 @@ func New(kind, text string) *Token {
 	}
 }
 
-// Parse can parse the authorization information from a request.
-func Parse(r *http.Request, fn SecretFunc) (*Token, error) {
-	token := &Token{}
-
-	raw, err := request.OAuth2Extractor.ExtractToken(r)
-
-	if err != nil {
-		return nil, err
-	}
+// Extract retrieves the raw token from the authorization of a request.
+func Extract(r *http.Request) (string, error) {
+	return request.OAuth2Extractor.ExtractToken(r)
+}
 
-	parsed, err := jwt.Parse(raw, keyFunc(token, fn))
+// Parse can parse the authorization information from a request.
+func Parse(r *http.Request, fn KeyFunc) (*Token, error) {
+	raw, err := Extract(r)
 
 	if err != nil {
 		return nil, err
-	} else if !parsed.Valid {
-		return nil, jwt.ValidationError{}
 	}
 
-	return token, nil
+	return Direct(raw, fn)
 }
 
 // Direct can parse the token directly without a request.
-func Direct(val string, fn SecretFunc) (*Token, error) {
+func Direct(val string, fn KeyFunc) (*Token, error) {
 	token := &Token{}
 
 	parsed, err := jwt.Parse(val, keyFunc(token, fn))
@@ -110,11 +119,9 @@ func Direct(val string, fn SecretFunc) (*Token, error) {
 	return token, nil
 }
 
-func keyFunc(token *Token, fn SecretFunc) jwt.Keyfunc {
+func keyFunc(token *Token, fn KeyFunc) jwt.Keyfunc {
 	return func(t *jwt.Token) (interface{}, error) {
-		if t.Method.Alg() != SignerAlgo {
-			return nil, jwt.ErrSignatureInvalid
-		}
+		token.KeyID, _ = t.Header["kid"].(string)
 
 		claims := t.Claims.(jwt.MapClaims)
 
@@ -134,6 +141,34 @@ func keyFunc(token *Token, fn SecretFunc) jwt.Keyfunc {
 
 		token.Text, _ = textv.(string)
 
-		return fn(token)
+		token.ID, _ = claims["jti"].(string)
+		token.IssuedAt = timestamp(claims["iat"])
+		token.Expire = timestamp(claims["exp"])
+
+		key, err := fn(token)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if t.Method.Alg() != key.Method.Alg() {
+			return nil, jwt.ErrSignatureInvalid
+		}
+
+		return key.verifier(), nil
 	}
 }
+
+// timestamp converts a numeric date claim, it's zero if the claim is missing.
+func timestamp(val interface{}) time.Time {
+	switch v := val.(type) {
+	case float64:
+		return time.Unix(0, int64(math.Round(v*1000))*int64(time.Millisecond))
+	case json.Number:
+		if f, err := v.Float64(); err == nil {
+			return time.Unix(0, int64(math.Round(f*1000))*int64(time.Millisecond))
+		}
+	}
+
+	return time.Time{}
+}
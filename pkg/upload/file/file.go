@@ -1,16 +1,28 @@
 package file
 
 import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
 	"net/http"
 	"net/url"
-	"path"
 	"os"
-	"fmt"
+	"path"
+	"path/filepath"
 	"strconv"
+	"strings"
 
+	"github.com/pkg/errors"
 	"github.com/umschlag/umschlag-api/pkg/upload"
 )
 
+const (
+	// metaDir defines the hidden directory storing the content types.
+	metaDir = ".meta"
+)
+
 type file struct {
 	dsn *url.URL
 }
@@ -36,16 +48,233 @@ func (u *file) Close() error {
 	return nil
 }
 
-// Handler implements an HTTP handler for asset uploads.
+// Handler implements an HTTP handler for asset uploads, the stored content
+// types get served and the metadata directory is hidden.
 func (u *file) Handler(root string) http.Handler {
 	return http.StripPrefix(
-		root + "/",
-		http.FileServer(
-			http.Dir(u.path()),
+		root+"/",
+		u.typed(
+			http.FileServer(
+				hidden{http.Dir(u.path())},
+			),
 		),
 	)
 }
 
+// Put atomically writes the content to the object with the given key, the
+// content type gets stored within the metadata directory.
+func (u *file) Put(ctx context.Context, key string, content io.Reader, ctype string) error {
+	target, err := u.target(key)
+
+	if err != nil {
+		return err
+	}
+
+	if ctype == "" {
+		ctype = "application/octet-stream"
+	}
+
+	if err := u.write(ctx, target, content); err != nil {
+		return err
+	}
+
+	meta, _ := u.meta(key)
+	return u.write(ctx, meta, strings.NewReader(ctype))
+}
+
+// Get opens the object with the given key for reading.
+func (u *file) Get(ctx context.Context, key string) (io.ReadCloser, *upload.Object, error) {
+	record, err := u.Stat(ctx, key)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	target, _ := u.target(key)
+	handle, err := os.Open(target)
+
+	if os.IsNotExist(err) {
+		return nil, nil, upload.ErrNotFound
+	}
+
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to open file")
+	}
+
+	return handle, record, nil
+}
+
+// Stat retrieves the metadata of the object with the given key.
+func (u *file) Stat(ctx context.Context, key string) (*upload.Object, error) {
+	target, err := u.target(key)
+
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := os.Stat(target)
+
+	if os.IsNotExist(err) {
+		return nil, upload.ErrNotFound
+	}
+
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to stat file")
+	}
+
+	if info.IsDir() {
+		return nil, upload.ErrNotFound
+	}
+
+	ctype := u.ctype(key)
+
+	if ctype == "" {
+		ctype = mime.TypeByExtension(filepath.Ext(target))
+	}
+
+	if ctype == "" {
+		ctype = "application/octet-stream"
+	}
+
+	return &upload.Object{
+		Key:         path.Clean(key),
+		ContentType: ctype,
+		Size:        info.Size(),
+		ModifiedAt:  info.ModTime().UTC(),
+	}, nil
+}
+
+// Delete removes the object with the given key.
+func (u *file) Delete(ctx context.Context, key string) error {
+	if _, err := u.Stat(ctx, key); err != nil {
+		return err
+	}
+
+	target, _ := u.target(key)
+
+	if err := os.Remove(target); err != nil {
+		return errors.Wrap(err, "failed to remove file")
+	}
+
+	meta, _ := u.meta(key)
+
+	if err := os.Remove(meta); err != nil && !os.IsNotExist(err) {
+		return errors.Wrap(err, "failed to remove metadata")
+	}
+
+	return nil
+}
+
+// write atomically replaces the file with the content.
+func (u *file) write(ctx context.Context, target string, content io.Reader) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(target), u.perms()); err != nil {
+		return errors.Wrap(err, "failed to create directory")
+	}
+
+	temp, err := ioutil.TempFile(filepath.Dir(target), ".upload-")
+
+	if err != nil {
+		return errors.Wrap(err, "failed to create temporary file")
+	}
+
+	defer os.Remove(temp.Name())
+
+	if _, err := io.Copy(temp, content); err != nil {
+		temp.Close()
+		return errors.Wrap(err, "failed to write temporary file")
+	}
+
+	if err := temp.Sync(); err != nil {
+		temp.Close()
+		return errors.Wrap(err, "failed to sync temporary file")
+	}
+
+	if err := temp.Close(); err != nil {
+		return errors.Wrap(err, "failed to close temporary file")
+	}
+
+	if err := os.Chmod(temp.Name(), 0644); err != nil {
+		return errors.Wrap(err, "failed to update file permissions")
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if err := os.Rename(temp.Name(), target); err != nil {
+		return errors.Wrap(err, "failed to move temporary file")
+	}
+
+	return nil
+}
+
+// typed sets the stored content type before the file gets served.
+func (u *file) typed(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ctype := u.ctype(strings.TrimPrefix(path.Clean("/"+r.URL.Path), "/")); ctype != "" {
+			w.Header().Set("Content-Type", ctype)
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// ctype reads the stored content type of an object, objects written before
+// the content types got stored return an empty string.
+func (u *file) ctype(key string) string {
+	meta, err := u.meta(key)
+
+	if err != nil {
+		return ""
+	}
+
+	content, err := ioutil.ReadFile(meta)
+
+	if err != nil {
+		return ""
+	}
+
+	return strings.TrimSpace(string(content))
+}
+
+// target validates the key and returns the path within the storage, keys
+// within the metadata directory are rejected.
+func (u *file) target(key string) (string, error) {
+	cleaned, err := upload.Key(key)
+
+	if err != nil {
+		return "", err
+	}
+
+	if hiddenPath(cleaned) {
+		return "", upload.ErrInvalidKey
+	}
+
+	return filepath.Join(
+		u.path(),
+		filepath.FromSlash(cleaned),
+	), nil
+}
+
+// meta validates the key and returns the path of the stored content type.
+func (u *file) meta(key string) (string, error) {
+	if _, err := u.target(key); err != nil {
+		return "", err
+	}
+
+	cleaned, _ := upload.Key(key)
+
+	return filepath.Join(
+		u.path(),
+		metaDir,
+		filepath.FromSlash(cleaned),
+	), nil
+}
+
 // perms retrieves the dir perms from dsn or fallback.
 func (u *file) perms() os.FileMode {
 	if val := u.dsn.Query().Get("perms"); val != "" {
@@ -69,6 +298,52 @@ func (u *file) path() string {
 	)
 }
 
+// hidden wraps the served directory to hide the metadata directory.
+type hidden struct {
+	http.FileSystem
+}
+
+// Open opens the file unless it's part of the metadata directory.
+func (h hidden) Open(name string) (http.File, error) {
+	cleaned := strings.TrimPrefix(path.Clean("/"+name), "/")
+
+	if hiddenPath(cleaned) {
+		return nil, os.ErrNotExist
+	}
+
+	f, err := h.FileSystem.Open(name)
+
+	if err != nil || cleaned != "" {
+		return f, err
+	}
+
+	return hiddenFile{f}, nil
+}
+
+// hiddenFile drops the metadata directory from the root listing.
+type hiddenFile struct {
+	http.File
+}
+
+// Readdir lists the directory without the metadata directory.
+func (f hiddenFile) Readdir(count int) ([]os.FileInfo, error) {
+	infos, err := f.File.Readdir(count)
+	result := infos[:0]
+
+	for _, info := range infos {
+		if info.Name() != metaDir {
+			result = append(result, info)
+		}
+	}
+
+	return result, err
+}
+
+// hiddenPath checks if the cleaned key is within the metadata directory.
+func hiddenPath(key string) bool {
+	return key == metaDir || strings.HasPrefix(key, metaDir+"/")
+}
+
 // New initializes a new file handler.
 func New(dsn *url.URL) (upload.Upload, error) {
 	f := &file{
@@ -0,0 +1,176 @@
+package file
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/umschlag/umschlag-api/pkg/upload"
+)
+
+func prepare(t *testing.T) (string, upload.Upload) {
+	t.Helper()
+
+	root := t.TempDir()
+	result, err := New(&url.URL{Scheme: "file", Path: root})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return root, result
+}
+
+func TestObjects(t *testing.T) {
+	ctx := context.Background()
+	root, storage := prepare(t)
+
+	if err := storage.Put(ctx, "avatars/jane", strings.NewReader("content"), "image/png"); err != nil {
+		t.Fatal(err)
+	}
+
+	object, err := storage.Stat(ctx, "avatars/jane")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if object.Key != "avatars/jane" || object.ContentType != "image/png" || object.Size != 7 {
+		t.Errorf("unexpected object %+v", object)
+	}
+
+	reader, object, err := storage.Get(ctx, "avatars/jane")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	content, err := ioutil.ReadAll(reader)
+	reader.Close()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(content) != "content" || object.ContentType != "image/png" {
+		t.Errorf("unexpected content %q of type %q", content, object.ContentType)
+	}
+
+	if err := storage.Put(ctx, "avatars/jane", strings.NewReader("replaced"), "image/gif"); err != nil {
+		t.Fatal(err)
+	}
+
+	if object, err := storage.Stat(ctx, "avatars/jane"); err != nil || object.ContentType != "image/gif" || object.Size != 8 {
+		t.Errorf("expected replaced object, got %+v, %v", object, err)
+	}
+
+	if err := storage.Delete(ctx, "avatars/jane"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := os.Stat(filepath.Join(root, metaDir, "avatars", "jane")); !os.IsNotExist(err) {
+		t.Errorf("expected content type to be removed, got %v", err)
+	}
+
+	if _, err := storage.Stat(ctx, "avatars/jane"); err != upload.ErrNotFound {
+		t.Errorf("expected stat of deleted object to fail with not found, got %v", err)
+	}
+
+	if err := storage.Delete(ctx, "avatars/jane"); err != upload.ErrNotFound {
+		t.Errorf("expected delete of deleted object to fail with not found, got %v", err)
+	}
+
+	for _, key := range []string{"../escape", metaDir + "/avatars/jane", metaDir} {
+		if err := storage.Put(ctx, key, strings.NewReader("content"), ""); err != upload.ErrInvalidKey {
+			t.Errorf("expected invalid key for %q, got %v", key, err)
+		}
+	}
+}
+
+func TestContentType(t *testing.T) {
+	ctx := context.Background()
+	root, storage := prepare(t)
+
+	if err := storage.Put(ctx, "blob", strings.NewReader("content"), ""); err != nil {
+		t.Fatal(err)
+	}
+
+	if object, err := storage.Stat(ctx, "blob"); err != nil || object.ContentType != "application/octet-stream" {
+		t.Errorf("expected fallback content type, got %+v, %v", object, err)
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(root, "legacy.png"), []byte("content"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if object, err := storage.Stat(ctx, "legacy.png"); err != nil || object.ContentType != "image/png" {
+		t.Errorf("expected content type by extension, got %+v, %v", object, err)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	_, storage := prepare(t)
+
+	if err := storage.Put(context.Background(), "avatars/jane.txt", strings.NewReader("0123456789"), "image/png"); err != nil {
+		t.Fatal(err)
+	}
+
+	handler := storage.Handler("/storage")
+
+	tests := []struct {
+		name   string
+		path   string
+		code   int
+		body   string
+		ctype  string
+		hidden bool
+	}{
+		{
+			name:  "stored type",
+			path:  "/storage/avatars/jane.txt",
+			code:  http.StatusOK,
+			body:  "0123456789",
+			ctype: "image/png",
+		},
+		{
+			name: "metadata",
+			path: "/storage/" + metaDir + "/avatars/jane.txt",
+			code: http.StatusNotFound,
+		},
+		{
+			name:   "listing",
+			path:   "/storage/",
+			code:   http.StatusOK,
+			hidden: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			if rec.Code != tt.code {
+				t.Fatalf("expected status %d, got %d", tt.code, rec.Code)
+			}
+
+			if tt.body != "" && rec.Body.String() != tt.body {
+				t.Errorf("expected body %q, got %q", tt.body, rec.Body.String())
+			}
+
+			if tt.ctype != "" && rec.Header().Get("Content-Type") != tt.ctype {
+				t.Errorf("expected content type %q, got %q", tt.ctype, rec.Header().Get("Content-Type"))
+			}
+
+			if tt.hidden && strings.Contains(rec.Body.String(), metaDir) {
+				t.Errorf("expected metadata directory to be hidden, got %q", rec.Body.String())
+			}
+		})
+	}
+}
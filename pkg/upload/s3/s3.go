@@ -1,39 +1,394 @@
 package s3
 
 import (
+	"context"
+	"fmt"
+	"io"
 	"net/http"
 	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
 	"github.com/umschlag/umschlag-api/pkg/upload"
 )
 
-type s3 struct {
-	dsn *url.URL
+var (
+	// ErrMissingBucket defines the error if the dsn doesn't define a bucket.
+	ErrMissingBucket = errors.New("missing bucket within dsn")
+)
+
+type client struct {
+	dsn    *url.URL
+	client *s3.S3
 }
 
 // Info prepares some informational message about the handler.
-func (u *s3) Info() string {
-	return ""
+func (u *client) Info() string {
+	return fmt.Sprintf("prepared s3 storage at %s/%s", u.endpoint(), u.bucket())
 }
 
-// Prepare simply prepares the upload handler.
-func (u *s3) Prepare() (upload.Upload, error) {
+// Prepare verifies the bucket and prepares the upload handler.
+func (u *client) Prepare() (upload.Upload, error) {
+	if u.bucket() == "" {
+		return nil, ErrMissingBucket
+	}
+
+	cfg := &aws.Config{
+		Region:           aws.String(u.region()),
+		S3ForcePathStyle: aws.Bool(u.pathStyle()),
+		DisableSSL:       aws.Bool(!u.ssl()),
+	}
+
+	if u.dsn.Host != "" {
+		cfg.Endpoint = aws.String(u.dsn.Host)
+	}
+
+	if u.dsn.User != nil {
+		secret, _ := u.dsn.User.Password()
+
+		cfg.Credentials = credentials.NewStaticCredentials(
+			u.dsn.User.Username(),
+			secret,
+			"",
+		)
+	}
+
+	sess, err := session.NewSession(cfg)
+
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create s3 session")
+	}
+
+	u.client = s3.New(sess)
+
+	if _, err := u.client.HeadBucket(&s3.HeadBucketInput{
+		Bucket: aws.String(u.bucket()),
+	}); err != nil {
+		return nil, errors.Wrapf(err, "failed to access bucket %s", u.bucket())
+	}
+
 	return u, nil
 }
 
 // Close simply closes the upload handler.
-func (u *s3) Close() error {
+func (u *client) Close() error {
 	return nil
 }
 
 // Handler implements an HTTP handler for asset uploads.
-func (u *s3) Handler(root string) http.Handler {
+func (u *client) Handler(root string) http.Handler {
+	return http.StripPrefix(
+		root+"/",
+		http.HandlerFunc(u.serve),
+	)
+}
+
+// Put uploads the content to the object with the given key.
+func (u *client) Put(ctx context.Context, key string, content io.Reader, ctype string) error {
+	cleaned, err := upload.Key(key)
+
+	if err != nil {
+		return err
+	}
+
+	if ctype == "" {
+		ctype = "application/octet-stream"
+	}
+
+	if _, err := s3manager.NewUploaderWithClient(u.client).UploadWithContext(ctx, &s3manager.UploadInput{
+		Bucket:      aws.String(u.bucket()),
+		Key:         aws.String(u.key(cleaned)),
+		ContentType: aws.String(ctype),
+		Body:        content,
+	}); err != nil {
+		return errors.Wrap(err, "failed to upload object")
+	}
+
+	return nil
+}
+
+// Get opens the object with the given key for reading.
+func (u *client) Get(ctx context.Context, key string) (io.ReadCloser, *upload.Object, error) {
+	cleaned, err := upload.Key(key)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	object, err := u.client.GetObjectWithContext(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(u.bucket()),
+		Key:    aws.String(u.key(cleaned)),
+	})
+
+	if err != nil {
+		return nil, nil, u.wrap(err, "failed to fetch object")
+	}
+
+	return object.Body, &upload.Object{
+		Key:         cleaned,
+		ContentType: aws.StringValue(object.ContentType),
+		Size:        aws.Int64Value(object.ContentLength),
+		ModifiedAt:  aws.TimeValue(object.LastModified).UTC(),
+	}, nil
+}
+
+// Stat retrieves the metadata of the object with the given key.
+func (u *client) Stat(ctx context.Context, key string) (*upload.Object, error) {
+	cleaned, err := upload.Key(key)
+
+	if err != nil {
+		return nil, err
+	}
+
+	object, err := u.client.HeadObjectWithContext(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(u.bucket()),
+		Key:    aws.String(u.key(cleaned)),
+	})
+
+	if err != nil {
+		return nil, u.wrap(err, "failed to stat object")
+	}
+
+	return &upload.Object{
+		Key:         cleaned,
+		ContentType: aws.StringValue(object.ContentType),
+		Size:        aws.Int64Value(object.ContentLength),
+		ModifiedAt:  aws.TimeValue(object.LastModified).UTC(),
+	}, nil
+}
+
+// Delete removes the object with the given key, as S3 silently accepts
+// deletes of missing objects it gets checked for existence before.
+func (u *client) Delete(ctx context.Context, key string) error {
+	cleaned, err := upload.Key(key)
+
+	if err != nil {
+		return err
+	}
+
+	if _, err := u.Stat(ctx, cleaned); err != nil {
+		return err
+	}
+
+	if _, err := u.client.DeleteObjectWithContext(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(u.bucket()),
+		Key:    aws.String(u.key(cleaned)),
+	}); err != nil {
+		return u.wrap(err, "failed to delete object")
+	}
+
 	return nil
 }
 
+// wrap converts missing objects to the upload error and wraps the rest.
+func (u *client) wrap(err error, msg string) error {
+	if aerr, ok := err.(awserr.RequestFailure); ok && aerr.StatusCode() == http.StatusNotFound {
+		return upload.ErrNotFound
+	}
+
+	return errors.Wrap(err, msg)
+}
+
+// serve streams the requested object from the bucket.
+func (u *client) serve(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	name := strings.TrimPrefix(path.Clean("/"+r.URL.Path), "/")
+
+	if name == "" {
+		http.NotFound(w, r)
+		return
+	}
+
+	if r.Method == http.MethodHead {
+		object, err := u.client.HeadObjectWithContext(r.Context(), &s3.HeadObjectInput{
+			Bucket: aws.String(u.bucket()),
+			Key:    aws.String(u.key(name)),
+		})
+
+		if err != nil {
+			u.failure(w, r, name, err)
+			return
+		}
+
+		u.headers(w, object.ContentType, object.ContentLength, object.ETag, object.LastModified)
+		w.WriteHeader(http.StatusOK)
+
+		return
+	}
+
+	input := &s3.GetObjectInput{
+		Bucket: aws.String(u.bucket()),
+		Key:    aws.String(u.key(name)),
+	}
+
+	if val := r.Header.Get("Range"); val != "" {
+		input.Range = aws.String(val)
+	}
+
+	object, err := u.client.GetObjectWithContext(r.Context(), input)
+
+	if err != nil {
+		u.failure(w, r, name, err)
+		return
+	}
+
+	defer object.Body.Close()
+
+	u.headers(w, object.ContentType, object.ContentLength, object.ETag, object.LastModified)
+	w.Header().Set("Accept-Ranges", "bytes")
+
+	if val := aws.StringValue(object.ContentRange); val != "" {
+		w.Header().Set("Content-Range", val)
+		w.WriteHeader(http.StatusPartialContent)
+	} else {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	if _, err := io.Copy(w, object.Body); err != nil {
+		log.Warn().
+			Err(err).
+			Str("object", name).
+			Msg("failed to stream object")
+	}
+}
+
+// headers writes the object metadata to the response.
+func (u *client) headers(w http.ResponseWriter, ctype *string, length *int64, etag *string, modified *time.Time) {
+	if val := aws.StringValue(ctype); val != "" {
+		w.Header().Set("Content-Type", val)
+	} else {
+		w.Header().Set("Content-Type", "application/octet-stream")
+	}
+
+	if length != nil {
+		w.Header().Set("Content-Length", strconv.FormatInt(*length, 10))
+	}
+
+	if val := aws.StringValue(etag); val != "" {
+		w.Header().Set("ETag", val)
+	}
+
+	if modified != nil {
+		w.Header().Set("Last-Modified", modified.UTC().Format(http.TimeFormat))
+	}
+}
+
+// failure maps errors of the s3 client to proper responses.
+func (u *client) failure(w http.ResponseWriter, r *http.Request, name string, err error) {
+	if aerr, ok := err.(awserr.RequestFailure); ok {
+		switch aerr.StatusCode() {
+		case http.StatusNotFound:
+			http.NotFound(w, r)
+			return
+		case http.StatusRequestedRangeNotSatisfiable:
+			http.Error(w, http.StatusText(aerr.StatusCode()), aerr.StatusCode())
+			return
+		}
+	}
+
+	log.Error().
+		Err(err).
+		Str("object", name).
+		Msg("failed to fetch object")
+
+	http.Error(w, http.StatusText(http.StatusBadGateway), http.StatusBadGateway)
+}
+
+// key prefixes the name with the path defined after the bucket.
+func (u *client) key(name string) string {
+	return strings.TrimPrefix(path.Join(u.prefix(), name), "/")
+}
+
+// bucket retrieves the bucket from the first path segment of the dsn.
+func (u *client) bucket() string {
+	return strings.SplitN(strings.Trim(u.dsn.Path, "/"), "/", 2)[0]
+}
+
+// prefix retrieves the optional key prefix after the bucket of the dsn.
+func (u *client) prefix() string {
+	parts := strings.SplitN(strings.Trim(u.dsn.Path, "/"), "/", 2)
+
+	if len(parts) < 2 {
+		return ""
+	}
+
+	return parts[1]
+}
+
+// endpoint retrieves a printable endpoint from dsn or fallback.
+func (u *client) endpoint() string {
+	scheme := "https"
+
+	if !u.ssl() {
+		scheme = "http"
+	}
+
+	if u.dsn.Host != "" {
+		return fmt.Sprintf("%s://%s", scheme, u.dsn.Host)
+	}
+
+	return fmt.Sprintf("%s://s3.%s.amazonaws.com", scheme, u.region())
+}
+
+// region retrieves the region from dsn or fallback.
+func (u *client) region() string {
+	if val := u.dsn.Query().Get("region"); val != "" {
+		return val
+	}
+
+	return "us-east-1"
+}
+
+// pathStyle retrieves the path style from dsn or fallback, minio
+// defaults to path style as it doesn't support virtual hosts by default.
+func (u *client) pathStyle() bool {
+	if val := u.dsn.Query().Get("path_style"); val != "" {
+		b, err := strconv.ParseBool(val)
+
+		if err != nil {
+			return u.dsn.Scheme == "minio"
+		}
+
+		return b
+	}
+
+	return u.dsn.Scheme == "minio"
+}
+
+// ssl retrieves the ssl flag from dsn or fallback.
+func (u *client) ssl() bool {
+	if val := u.dsn.Query().Get("ssl"); val != "" {
+		b, err := strconv.ParseBool(val)
+
+		if err != nil {
+			return true
+		}
+
+		return b
+	}
+
+	return true
+}
+
 // New initializes a new S3 handler.
 func New(dsn *url.URL) (upload.Upload, error) {
-	f := &s3{
+	f := &client{
 		dsn: dsn,
 	}
 
@@ -0,0 +1,471 @@
+package s3
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/umschlag/umschlag-api/pkg/upload"
+)
+
+type fakeObject struct {
+	content  []byte
+	ctype    string
+	modified time.Time
+}
+
+// fakeS3 implements the path style bucket and object operations used by
+// the driver, ranges and HEAD requests are handled by http.ServeContent.
+type fakeS3 struct {
+	mu      sync.Mutex
+	bucket  string
+	objects map[string]*fakeObject
+	auth    string
+}
+
+func newFakeS3(bucket string) *fakeS3 {
+	return &fakeS3{
+		bucket:  bucket,
+		objects: make(map[string]*fakeObject),
+	}
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.auth = r.Header.Get("Authorization")
+	parts := strings.SplitN(strings.TrimPrefix(r.URL.Path, "/"), "/", 2)
+
+	if parts[0] != f.bucket {
+		f.missing(w, r, "NoSuchBucket")
+		return
+	}
+
+	if len(parts) == 1 || parts[1] == "" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	key := parts[1]
+
+	switch r.Method {
+	case http.MethodPut:
+		content, err := ioutil.ReadAll(r.Body)
+
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		f.objects[key] = &fakeObject{
+			content:  content,
+			ctype:    r.Header.Get("Content-Type"),
+			modified: time.Now().UTC().Truncate(time.Second),
+		}
+
+		w.Header().Set("ETag", `"etag"`)
+		w.WriteHeader(http.StatusOK)
+	case http.MethodGet, http.MethodHead:
+		object, ok := f.objects[key]
+
+		if !ok {
+			f.missing(w, r, "NoSuchKey")
+			return
+		}
+
+		w.Header().Set("Content-Type", object.ctype)
+		w.Header().Set("ETag", `"etag"`)
+
+		http.ServeContent(w, r, key, object.modified, bytes.NewReader(object.content))
+	case http.MethodDelete:
+		delete(f.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (f *fakeS3) missing(w http.ResponseWriter, r *http.Request, code string) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(http.StatusNotFound)
+
+	if r.Method != http.MethodHead {
+		w.Write([]byte("<Error><Code>" + code + "</Code></Error>"))
+	}
+}
+
+func prepare(t *testing.T, path string) (*fakeS3, upload.Upload) {
+	t.Helper()
+
+	fake := newFakeS3("assets")
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	dsn, err := url.Parse(
+		"minio://access:secret@" + strings.TrimPrefix(server.URL, "http://") + path + "?ssl=false&region=eu-west-1",
+	)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := New(dsn)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return fake, result
+}
+
+func TestObjects(t *testing.T) {
+	ctx := context.Background()
+	fake, storage := prepare(t, "/assets/prefix")
+
+	if !strings.Contains(fake.auth, "Credential=access/") || !strings.Contains(fake.auth, "/eu-west-1/s3/") {
+		t.Errorf("expected credentials and region from dsn, got %q", fake.auth)
+	}
+
+	if err := storage.Put(ctx, "avatars/jane.png", strings.NewReader("content"), "image/png"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := fake.objects["prefix/avatars/jane.png"]; !ok {
+		t.Fatal("expected object to be stored with the prefix of the dsn")
+	}
+
+	object, err := storage.Stat(ctx, "avatars/jane.png")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if object.Key != "avatars/jane.png" || object.ContentType != "image/png" || object.Size != 7 {
+		t.Errorf("unexpected object %+v", object)
+	}
+
+	reader, object, err := storage.Get(ctx, "avatars/jane.png")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	content, err := ioutil.ReadAll(reader)
+	reader.Close()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(content) != "content" || object.ContentType != "image/png" {
+		t.Errorf("unexpected content %q of type %q", content, object.ContentType)
+	}
+
+	if err := storage.Delete(ctx, "avatars/jane.png"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := storage.Stat(ctx, "avatars/jane.png"); err != upload.ErrNotFound {
+		t.Errorf("expected stat of deleted object to fail with not found, got %v", err)
+	}
+
+	if _, _, err := storage.Get(ctx, "avatars/jane.png"); err != upload.ErrNotFound {
+		t.Errorf("expected get of deleted object to fail with not found, got %v", err)
+	}
+
+	if err := storage.Delete(ctx, "avatars/jane.png"); err != upload.ErrNotFound {
+		t.Errorf("expected delete of deleted object to fail with not found, got %v", err)
+	}
+
+	if err := storage.Put(ctx, "../escape", strings.NewReader("content"), ""); err != upload.ErrInvalidKey {
+		t.Errorf("expected invalid key, got %v", err)
+	}
+}
+
+func TestDefaultContentType(t *testing.T) {
+	ctx := context.Background()
+	_, storage := prepare(t, "/assets")
+
+	if err := storage.Put(ctx, "blob", strings.NewReader("content"), ""); err != nil {
+		t.Fatal(err)
+	}
+
+	object, err := storage.Stat(ctx, "blob")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if object.ContentType != "application/octet-stream" {
+		t.Errorf("expected fallback content type, got %q", object.ContentType)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	_, storage := prepare(t, "/assets")
+
+	if err := storage.Put(context.Background(), "avatars/jane.png", strings.NewReader("0123456789"), "image/png"); err != nil {
+		t.Fatal(err)
+	}
+
+	handler := storage.Handler("/storage")
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		rng    string
+		code   int
+		body   string
+		header map[string]string
+	}{
+		{
+			name:   "get",
+			method: http.MethodGet,
+			path:   "/storage/avatars/jane.png",
+			code:   http.StatusOK,
+			body:   "0123456789",
+			header: map[string]string{
+				"Content-Type":   "image/png",
+				"Content-Length": "10",
+				"Accept-Ranges":  "bytes",
+				"ETag":           `"etag"`,
+			},
+		},
+		{
+			name:   "range",
+			method: http.MethodGet,
+			path:   "/storage/avatars/jane.png",
+			rng:    "bytes=2-5",
+			code:   http.StatusPartialContent,
+			body:   "2345",
+			header: map[string]string{
+				"Content-Range":  "bytes 2-5/10",
+				"Content-Length": "4",
+			},
+		},
+		{
+			name:   "unsatisfiable",
+			method: http.MethodGet,
+			path:   "/storage/avatars/jane.png",
+			rng:    "bytes=20-30",
+			code:   http.StatusRequestedRangeNotSatisfiable,
+		},
+		{
+			name:   "head",
+			method: http.MethodHead,
+			path:   "/storage/avatars/jane.png",
+			code:   http.StatusOK,
+			header: map[string]string{
+				"Content-Type":   "image/png",
+				"Content-Length": "10",
+			},
+		},
+		{
+			name:   "missing",
+			method: http.MethodGet,
+			path:   "/storage/avatars/john.png",
+			code:   http.StatusNotFound,
+		},
+		{
+			name:   "missing head",
+			method: http.MethodHead,
+			path:   "/storage/avatars/john.png",
+			code:   http.StatusNotFound,
+		},
+		{
+			name:   "method",
+			method: http.MethodPost,
+			path:   "/storage/avatars/jane.png",
+			code:   http.StatusMethodNotAllowed,
+			header: map[string]string{
+				"Allow": "GET, HEAD",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+
+			if tt.rng != "" {
+				req.Header.Set("Range", tt.rng)
+			}
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.code {
+				t.Fatalf("expected status %d, got %d", tt.code, rec.Code)
+			}
+
+			if tt.body != "" && rec.Body.String() != tt.body {
+				t.Errorf("expected body %q, got %q", tt.body, rec.Body.String())
+			}
+
+			if tt.method == http.MethodHead && tt.code == http.StatusOK && rec.Body.Len() != 0 {
+				t.Errorf("expected empty body for head, got %q", rec.Body.String())
+			}
+
+			for key, val := range tt.header {
+				if got := rec.Header().Get(key); got != val {
+					t.Errorf("expected header %s to be %q, got %q", key, val, got)
+				}
+			}
+		})
+	}
+}
+
+func TestPrepare(t *testing.T) {
+	fake := newFakeS3("assets")
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	host := strings.TrimPrefix(server.URL, "http://")
+
+	tests := []struct {
+		name string
+		dsn  string
+		err  string
+	}{
+		{
+			name: "missing bucket",
+			dsn:  "minio://access:secret@" + host + "?ssl=false",
+			err:  ErrMissingBucket.Error(),
+		},
+		{
+			name: "unknown bucket",
+			dsn:  "minio://access:secret@" + host + "/unknown?ssl=false",
+			err:  "failed to access bucket unknown",
+		},
+		{
+			name: "existing bucket",
+			dsn:  "minio://access:secret@" + host + "/assets?ssl=false",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dsn, err := url.Parse(tt.dsn)
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			_, err = New(dsn)
+
+			switch {
+			case tt.err == "" && err != nil:
+				t.Errorf("expected no error, got %v", err)
+			case tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)):
+				t.Errorf("expected error %q, got %v", tt.err, err)
+			}
+		})
+	}
+}
+
+func TestDSN(t *testing.T) {
+	tests := []struct {
+		dsn       string
+		bucket    string
+		prefix    string
+		region    string
+		pathStyle bool
+		ssl       bool
+		endpoint  string
+	}{
+		{
+			dsn:       "s3:///assets",
+			bucket:    "assets",
+			region:    "us-east-1",
+			pathStyle: false,
+			ssl:       true,
+			endpoint:  "https://s3.us-east-1.amazonaws.com",
+		},
+		{
+			dsn:       "s3:///assets/avatars/nested?region=eu-central-1",
+			bucket:    "assets",
+			prefix:    "avatars/nested",
+			region:    "eu-central-1",
+			pathStyle: false,
+			ssl:       true,
+			endpoint:  "https://s3.eu-central-1.amazonaws.com",
+		},
+		{
+			dsn:       "s3://storage.example.com/assets?path_style=true&ssl=false",
+			bucket:    "assets",
+			region:    "us-east-1",
+			pathStyle: true,
+			ssl:       false,
+			endpoint:  "http://storage.example.com",
+		},
+		{
+			dsn:       "minio://minio:9000/assets",
+			bucket:    "assets",
+			region:    "us-east-1",
+			pathStyle: true,
+			ssl:       true,
+			endpoint:  "https://minio:9000",
+		},
+		{
+			dsn:       "minio://minio:9000/assets?path_style=false&ssl=invalid",
+			bucket:    "assets",
+			region:    "us-east-1",
+			pathStyle: false,
+			ssl:       true,
+			endpoint:  "https://minio:9000",
+		},
+		{
+			dsn:       "minio://minio:9000/assets?path_style=invalid",
+			bucket:    "assets",
+			region:    "us-east-1",
+			pathStyle: true,
+			ssl:       true,
+			endpoint:  "https://minio:9000",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.dsn, func(t *testing.T) {
+			dsn, err := url.Parse(tt.dsn)
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			c := &client{dsn: dsn}
+
+			if got := c.bucket(); got != tt.bucket {
+				t.Errorf("expected bucket %q, got %q", tt.bucket, got)
+			}
+
+			if got := c.prefix(); got != tt.prefix {
+				t.Errorf("expected prefix %q, got %q", tt.prefix, got)
+			}
+
+			if got := c.region(); got != tt.region {
+				t.Errorf("expected region %q, got %q", tt.region, got)
+			}
+
+			if got := c.pathStyle(); got != tt.pathStyle {
+				t.Errorf("expected path style %v, got %v", tt.pathStyle, got)
+			}
+
+			if got := c.ssl(); got != tt.ssl {
+				t.Errorf("expected ssl %v, got %v", tt.ssl, got)
+			}
+
+			if got := c.endpoint(); got != tt.endpoint {
+				t.Errorf("expected endpoint %q, got %q", tt.endpoint, got)
+			}
+		})
+	}
+}
@@ -1,7 +1,12 @@
 package upload
 
 import (
+	"context"
+	"io"
 	"net/http"
+	"path"
+	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 )
@@ -9,6 +14,12 @@ import (
 var (
 	// ErrUnknownDriver defines a named error for unknown upload drivers.
 	ErrUnknownDriver = errors.New("unknown upload driver")
+
+	// ErrNotFound defines a named error for missing objects.
+	ErrNotFound = errors.New("object not found")
+
+	// ErrInvalidKey defines a named error for keys escaping the storage.
+	ErrInvalidKey = errors.New("invalid object key")
 )
 
 // Upload provides the interface for the upload implementations.
@@ -17,4 +28,38 @@ type Upload interface {
 	Prepare() (Upload, error)
 	Close() error
 	Handler(string) http.Handler
+	Put(context.Context, string, io.Reader, string) error
+	Get(context.Context, string) (io.ReadCloser, *Object, error)
+	Stat(context.Context, string) (*Object, error)
+	Delete(context.Context, string) error
+}
+
+// Object defines the metadata of a stored object.
+type Object struct {
+	Key         string
+	ContentType string
+	Size        int64
+	ModifiedAt  time.Time
+}
+
+// Key validates the key of an object and returns the cleaned version, keys
+// must be relative and are not allowed to leave the root of the storage.
+func Key(key string) (string, error) {
+	if key == "" || strings.ContainsAny(key, "\\\x00") || strings.HasPrefix(key, "/") {
+		return "", ErrInvalidKey
+	}
+
+	for _, part := range strings.Split(key, "/") {
+		if part == ".." {
+			return "", ErrInvalidKey
+		}
+	}
+
+	cleaned := path.Clean(key)
+
+	if cleaned == "." {
+		return "", ErrInvalidKey
+	}
+
+	return cleaned, nil
 }